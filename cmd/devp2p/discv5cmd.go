@@ -17,6 +17,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"slices"
@@ -24,7 +25,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/cmd/devp2p/internal/v5test"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/urfave/cli/v2"
 )
 
@@ -38,6 +41,8 @@ var (
 			discv5CrawlCommand,
 			discv5TestCommand,
 			discv5ListenCommand,
+			discv5RegisterCommand,
+			discv5SearchCommand,
 		},
 	}
 	discv5PingCommand = &cli.Command{
@@ -75,10 +80,33 @@ var (
 		Name:   "listen",
 		Usage:  "Runs a node",
 		Action: discv5Listen,
-		Flags:  discoveryNodeFlags,
+		Flags: slices.Concat(discoveryNodeFlags, []cli.Flag{
+			topicsFlag,
+		}),
+	}
+	discv5RegisterCommand = &cli.Command{
+		Name:      "register",
+		Usage:     "Advertises the node for a topic (experimental)",
+		Action:    discv5Register,
+		ArgsUsage: "<topic>",
+		Flags:     discoveryNodeFlags,
+	}
+	discv5SearchCommand = &cli.Command{
+		Name:      "search",
+		Usage:     "Searches for nodes advertised for a topic (experimental)",
+		Action:    discv5Search,
+		ArgsUsage: "<topic>",
+		Flags: slices.Concat(discoveryNodeFlags, []cli.Flag{
+			crawlTimeoutFlag,
+		}),
 	}
 )
 
+var topicsFlag = &cli.BoolFlag{
+	Name:  "topics",
+	Usage: "Enables the experimental topic advertisement protocol",
+}
+
 func discv5Ping(ctx *cli.Context) error {
 	n := getNodeArg(ctx)
 	disc, _ := startV5(ctx)
@@ -139,9 +167,61 @@ func discv5Listen(ctx *cli.Context) error {
 	select {}
 }
 
+func discv5Register(ctx *cli.Context) error {
+	topic := getTopicArg(ctx)
+	disc, _ := startV5Topics(ctx)
+	defer disc.Close()
+
+	fmt.Println(disc.Self())
+	return disc.RegisterTopic(context.Background(), topic)
+}
+
+func discv5Search(ctx *cli.Context) error {
+	topic := getTopicArg(ctx)
+	disc, _ := startV5Topics(ctx)
+	defer disc.Close()
+
+	it := disc.TopicNodes(topic)
+	defer it.Close()
+	if ctx.IsSet(crawlTimeoutFlag.Name) {
+		timeout := time.AfterFunc(ctx.Duration(crawlTimeoutFlag.Name), it.Close)
+		defer timeout.Stop()
+	}
+	for it.Next() {
+		fmt.Println(it.Node())
+	}
+	return nil
+}
+
+// getTopicArg parses the topic argument, which can be a topic name or a hex
+// encoded topic hash.
+func getTopicArg(ctx *cli.Context) common.Hash {
+	if ctx.NArg() < 1 {
+		exit("missing topic as command-line argument")
+	}
+	arg := ctx.Args().First()
+	if hash, err := hexutil.Decode(arg); err == nil && len(hash) == common.HashLength {
+		return common.BytesToHash(hash)
+	}
+	return discover.TopicHash(arg)
+}
+
 // startV5 starts an ephemeral discovery v5 node.
 func startV5(ctx *cli.Context) (*discover.UDPv5, discover.Config) {
 	ln, config := makeDiscoveryConfig(ctx)
+	config.V5Topics = ctx.Bool(topicsFlag.Name)
+	return listenV5(ctx, ln, config)
+}
+
+// startV5Topics starts an ephemeral discovery v5 node with topic advertisement
+// enabled.
+func startV5Topics(ctx *cli.Context) (*discover.UDPv5, discover.Config) {
+	ln, config := makeDiscoveryConfig(ctx)
+	config.V5Topics = true
+	return listenV5(ctx, ln, config)
+}
+
+func listenV5(ctx *cli.Context, ln *enode.LocalNode, config discover.Config) (*discover.UDPv5, discover.Config) {
 	socket := listen(ctx, ln)
 	disc, err := discover.ListenV5(socket, ln, config)
 	if err != nil {
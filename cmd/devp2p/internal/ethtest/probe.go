@@ -0,0 +1,194 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package ethtest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/rlpx"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ProbeResult is the information collected by Probe.
+type ProbeResult struct {
+	Name string   // client name from the devp2p handshake
+	Caps []string // protocols offered by the node, e.g. "eth/69"
+
+	// Fields below are set when the eth protocol was negotiated.
+	Eth       uint // negotiated eth protocol version
+	NetworkID uint64
+	Genesis   common.Hash
+	ForkID    forkid.ID
+	Head      common.Hash
+
+	// History range, available on eth/69 only. If the node sent a
+	// BlockRangeUpdate while waiting, the range is taken from it.
+	HasRange      bool
+	EarliestBlock uint64
+	LatestBlock   uint64
+	RangeUpdated  bool
+}
+
+// ProbeConfig configures Probe.
+type ProbeConfig struct {
+	Timeout   time.Duration // timeout of the dial and handshakes
+	RangeWait time.Duration // how long to wait for a BlockRangeUpdate on eth/69
+}
+
+// Probe connects to a node and performs the devp2p and eth handshakes to find
+// out its client name, protocols and chain state. The node's status message is
+// sent back to it, so it keeps the connection open while waiting for
+// BlockRangeUpdate messages.
+//
+// Probe returns the partial result together with the error if the eth
+// handshake fails after the devp2p handshake was completed.
+func Probe(n *enode.Node, cfg ProbeConfig) (*ProbeResult, error) {
+	tcpEndpoint, ok := n.TCPEndpoint()
+	if !ok {
+		return nil, errors.New("node has no TCP endpoint")
+	}
+	fd, err := net.DialTimeout("tcp", tcpEndpoint.String(), cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	key, _ := crypto.GenerateKey()
+	c := &Conn{
+		Conn:                   rlpx.NewConn(fd, n.Pubkey()),
+		ourKey:                 key,
+		ourHighestProtoVersion: eth.ETH69,
+		caps:                   []p2p.Cap{{Name: "eth", Version: eth.ETH68}, {Name: "eth", Version: eth.ETH69}},
+	}
+	defer c.Close()
+
+	deadline := time.Now().Add(cfg.Timeout)
+	c.SetDeadline(deadline)
+	if _, err := c.Handshake(key); err != nil {
+		return nil, err
+	}
+
+	// Exchange hello messages.
+	pub0 := crypto.FromECDSAPub(&key.PublicKey)[1:]
+	if err := c.writeProbe(handshakeMsg, &protoHandshake{Version: 5, Caps: c.caps, ID: pub0}); err != nil {
+		return nil, err
+	}
+	hello := new(protoHandshake)
+	if err := c.readProbe(handshakeMsg, hello); err != nil {
+		return nil, err
+	}
+	if hello.Version >= 5 {
+		c.SetSnappy(true)
+	}
+	res := &ProbeResult{Name: hello.Name}
+	for _, cap := range hello.Caps {
+		res.Caps = append(res.Caps, cap.String())
+	}
+	c.negotiateEthProtocol(hello.Caps)
+	if c.negotiatedProtoVersion == 0 {
+		return res, nil
+	}
+	res.Eth = c.negotiatedProtoVersion
+
+	// Read the status and send it back.
+	code, data, err := c.readProbeRaw(baseProtoLen + eth.StatusMsg)
+	if err != nil {
+		return res, fmt.Errorf("status exchange failed: %v", err)
+	}
+	status, err := DecodeMessage("eth", res.Eth, eth.StatusMsg, data)
+	if err != nil {
+		return res, err
+	}
+	switch s := status.(type) {
+	case *eth.StatusPacket68:
+		res.NetworkID, res.Genesis, res.ForkID, res.Head = s.NetworkID, s.Genesis, s.ForkID, s.Head
+	case *eth.StatusPacket69:
+		res.NetworkID, res.Genesis, res.ForkID, res.Head = s.NetworkID, s.Genesis, s.ForkID, s.LatestBlockHash
+		res.HasRange, res.EarliestBlock, res.LatestBlock = true, s.EarliestBlock, s.LatestBlock
+	}
+	if res.Eth < eth.ETH69 || cfg.RangeWait <= 0 {
+		return res, nil
+	}
+	c.SetWriteDeadline(deadline)
+	if _, err := c.Conn.Write(code, data); err != nil {
+		return res, nil
+	}
+
+	// Wait for a block range update.
+	c.SetDeadline(time.Now().Add(cfg.RangeWait))
+	update := new(eth.BlockRangeUpdatePacket)
+	if err := c.readProbe(baseProtoLen+eth.BlockRangeUpdateMsg, update); err != nil {
+		// The node didn't send an update in time, or dropped the connection.
+		// This is not an error since the status contains the range.
+		return res, nil
+	}
+	res.EarliestBlock, res.LatestBlock, res.Head = update.EarliestBlock, update.LatestBlock, update.LatestBlockHash
+	res.RangeUpdated = true
+	c.writeProbe(discMsg, []p2p.DiscReason{p2p.DiscQuitting})
+	return res, nil
+}
+
+// writeProbe writes a message without resetting the connection deadline.
+func (c *Conn) writeProbe(code uint64, msg any) error {
+	payload, err := rlp.EncodeToBytes(msg)
+	if err != nil {
+		return err
+	}
+	_, err = c.Conn.Write(code, payload)
+	return err
+}
+
+// readProbe reads until a message with the given code is received, and decodes
+// it into msg.
+func (c *Conn) readProbe(want uint64, msg any) error {
+	_, data, err := c.readProbeRaw(want)
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(data, msg)
+}
+
+// readProbeRaw reads until a message with the given code is received. Pings are
+// answered, other messages are skipped.
+func (c *Conn) readProbeRaw(want uint64) (uint64, []byte, error) {
+	for {
+		code, data, _, err := c.Conn.Read()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch {
+		case code == want:
+			return code, data, nil
+		case code == pingMsg:
+			c.Conn.Write(pongMsg, []byte{0xc0})
+		case code == discMsg:
+			var reason []p2p.DiscReason
+			rlp.DecodeBytes(data, &reason)
+			if len(reason) == 0 {
+				return 0, nil, errDisc
+			}
+			return 0, nil, fmt.Errorf("%w: %v", errDisc, reason[0])
+		}
+	}
+}
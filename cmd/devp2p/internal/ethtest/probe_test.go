@@ -0,0 +1,62 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package ethtest
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+)
+
+func TestProbe(t *testing.T) {
+	jwtPath, _, err := makeJWTSecret(t)
+	if err != nil {
+		t.Fatalf("could not make jwt secret: %v", err)
+	}
+	geth, err := runGeth("./testdata", jwtPath)
+	if err != nil {
+		t.Fatalf("could not run geth: %v", err)
+	}
+	defer geth.Close()
+	chain, err := NewChain("./testdata")
+	if err != nil {
+		t.Fatalf("could not load chain: %v", err)
+	}
+
+	res, err := Probe(geth.Server().Self(), ProbeConfig{Timeout: 5 * time.Second, RangeWait: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if res.Name != geth.Server().Config.Name {
+		t.Errorf("wrong client name %q", res.Name)
+	}
+	if !slices.Contains(res.Caps, "eth/69") {
+		t.Errorf("eth/69 missing from caps %v", res.Caps)
+	}
+	head := chain.blocks[chain.Len()-1]
+	if res.Eth != eth.ETH69 {
+		t.Fatalf("wrong eth version %d", res.Eth)
+	}
+	if res.Genesis != chain.blocks[0].Hash() || res.ForkID != chain.ForkID() {
+		t.Errorf("wrong chain: genesis %x, fork ID %v", res.Genesis, res.ForkID)
+	}
+	if !res.HasRange || res.LatestBlock != head.NumberU64() || res.Head != head.Hash() {
+		t.Errorf("wrong head: range %t, latest %d, hash %x", res.HasRange, res.LatestBlock, res.Head)
+	}
+}
@@ -0,0 +1,348 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package ethtest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/eth/protocols/snap"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/capture"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/rlpx"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// protocolLengths are the number of message codes used by the replayable
+// protocol versions.
+var protocolLengths = map[string]map[uint]uint64{
+	"eth":  {eth.ETH68: 17, eth.ETH69: 18},
+	"snap": {snap.SNAP1: 8},
+}
+
+// DecodeMessage decodes an eth or snap protocol message into its packet type.
+func DecodeMessage(proto string, version uint, code uint64, data []byte) (any, error) {
+	var msg any
+	switch proto {
+	case "eth":
+		msg = newEthPacket(version, code)
+	case "snap":
+		msg = newSnapPacket(code)
+	default:
+		return nil, fmt.Errorf("unsupported protocol %s", proto)
+	}
+	if msg == nil {
+		return nil, fmt.Errorf("unknown %s/%d message code %d", proto, version, code)
+	}
+	if err := rlp.DecodeBytes(data, msg); err != nil {
+		return nil, fmt.Errorf("invalid %s/%d message %T: %v", proto, version, msg, err)
+	}
+	return msg, nil
+}
+
+func newEthPacket(version uint, code uint64) any {
+	switch code {
+	case eth.StatusMsg:
+		if version < eth.ETH69 {
+			return new(eth.StatusPacket68)
+		}
+		return new(eth.StatusPacket69)
+	case eth.NewBlockHashesMsg:
+		return new(eth.NewBlockHashesPacket)
+	case eth.TransactionsMsg:
+		return new(eth.TransactionsPacket)
+	case eth.GetBlockHeadersMsg:
+		return new(eth.GetBlockHeadersPacket)
+	case eth.BlockHeadersMsg:
+		return new(eth.BlockHeadersPacket)
+	case eth.GetBlockBodiesMsg:
+		return new(eth.GetBlockBodiesPacket)
+	case eth.BlockBodiesMsg:
+		return new(eth.BlockBodiesPacket)
+	case eth.NewBlockMsg:
+		return new(eth.NewBlockPacket)
+	case eth.NewPooledTransactionHashesMsg:
+		return new(eth.NewPooledTransactionHashesPacket)
+	case eth.GetPooledTransactionsMsg:
+		return new(eth.GetPooledTransactionsPacket)
+	case eth.PooledTransactionsMsg:
+		return new(eth.PooledTransactionsPacket)
+	case eth.GetReceiptsMsg:
+		return new(eth.GetReceiptsPacket)
+	case eth.ReceiptsMsg:
+		return new(eth.ReceiptsRLPPacket)
+	case eth.BlockRangeUpdateMsg:
+		return new(eth.BlockRangeUpdatePacket)
+	}
+	return nil
+}
+
+func newSnapPacket(code uint64) any {
+	switch code {
+	case snap.GetAccountRangeMsg:
+		return new(snap.GetAccountRangePacket)
+	case snap.AccountRangeMsg:
+		return new(snap.AccountRangePacket)
+	case snap.GetStorageRangesMsg:
+		return new(snap.GetStorageRangesPacket)
+	case snap.StorageRangesMsg:
+		return new(snap.StorageRangesPacket)
+	case snap.GetByteCodesMsg:
+		return new(snap.GetByteCodesPacket)
+	case snap.ByteCodesMsg:
+		return new(snap.ByteCodesPacket)
+	case snap.GetTrieNodesMsg:
+		return new(snap.GetTrieNodesPacket)
+	case snap.TrieNodesMsg:
+		return new(snap.TrieNodesPacket)
+	}
+	return nil
+}
+
+// isResponse reports whether a message is the response to a request. Captured
+// responses don't match the requests of the replay target, so they are not
+// replayed by default.
+func isResponse(proto string, code uint64) bool {
+	switch proto {
+	case "eth":
+		switch code {
+		case eth.BlockHeadersMsg, eth.BlockBodiesMsg, eth.PooledTransactionsMsg, eth.ReceiptsMsg:
+			return true
+		}
+	case "snap":
+		switch code {
+		case snap.AccountRangeMsg, snap.StorageRangesMsg, snap.ByteCodesMsg, snap.TrieNodesMsg:
+			return true
+		}
+	}
+	return false
+}
+
+// ReplayConfig configures the replay of a capture.
+type ReplayConfig struct {
+	Dest      *enode.Node   // node to replay the capture against
+	Timing    bool          // keep the original delays between the messages
+	Responses bool          // also replay the responses sent by the captured peer
+	Linger    time.Duration // how long to wait for messages of the node after the replay
+	Out       io.Writer     // receives the messages sent by the node
+}
+
+// Replay connects to a node and sends it the messages received from the peer
+// in the capture, in their original order. Messages sent by the node during the
+// replay are decoded and written to the output.
+func Replay(cfg ReplayConfig, r *capture.Reader) error {
+	caps, err := parseCaps(r.Header().Caps)
+	if err != nil {
+		return err
+	}
+	c, err := dialReplay(cfg.Dest, caps)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	// Compute the message code offsets of the agreed protocols. They are
+	// ordered by name, like in package p2p. Records of protocols the node
+	// did not agree to are skipped.
+	var (
+		offsets  = make(map[string]uint64)
+		versions = make(map[string]uint)
+		next     = uint64(baseProtoLen)
+	)
+	for _, cap := range agreedCaps(caps, c.remoteCaps) {
+		offsets[cap.Name], versions[cap.Name] = next, cap.Version
+		next += protocolLengths[cap.Name][cap.Version]
+	}
+	if _, ok := versions["eth"]; !ok {
+		return fmt.Errorf("node does not support the eth versions of the capture (remote caps: %v)", c.remoteCaps)
+	}
+
+	// Start reading the messages of the node. Writes are done from both the
+	// reader, answering pings, and the replay loop.
+	var (
+		wmu   sync.Mutex
+		write = func(code uint64, data []byte) error {
+			wmu.Lock()
+			defer wmu.Unlock()
+			c.SetWriteDeadline(time.Now().Add(timeout))
+			_, err := c.Conn.Write(code, data)
+			return err
+		}
+		start = time.Now()
+		errc  = make(chan error, 1)
+	)
+	go func() {
+		errc <- c.readReplay(cfg.Out, start, offsets, versions, write)
+	}()
+
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		offset, ok := offsets[rec.Protocol]
+		if !ok || rec.Direction != capture.Ingress || versions[rec.Protocol] != rec.Version {
+			continue
+		}
+		if !cfg.Responses && isResponse(rec.Protocol, rec.Code) {
+			continue
+		}
+		if cfg.Timing {
+			time.Sleep(rec.Elapsed() - time.Since(start))
+		}
+		if err := write(offset+rec.Code, rec.Payload); err != nil {
+			return fmt.Errorf("failed to send message: %v", err)
+		}
+		select {
+		case err := <-errc:
+			return err
+		default:
+		}
+	}
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(cfg.Linger):
+		return nil
+	}
+}
+
+// parseCaps parses the protocols of a capture header, keeping the ones which
+// can be replayed.
+func parseCaps(caps []string) ([]p2p.Cap, error) {
+	var result []p2p.Cap
+	for _, s := range caps {
+		name, version, ok := strings.Cut(s, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid capability %q", s)
+		}
+		v, err := strconv.ParseUint(version, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid capability %q", s)
+		}
+		if _, ok := protocolLengths[name][uint(v)]; ok {
+			result = append(result, p2p.Cap{Name: name, Version: uint(v)})
+		}
+	}
+	if len(result) == 0 || result[0].Name != "eth" {
+		return nil, errors.New("capture contains no eth protocol traffic")
+	}
+	return result, nil
+}
+
+// agreedCaps returns the protocols both sides support, at the highest common
+// version, ordered by name.
+func agreedCaps(ours, theirs []p2p.Cap) []p2p.Cap {
+	agreed := make(map[string]uint)
+	for _, our := range ours {
+		for _, their := range theirs {
+			if our == their && our.Version > agreed[our.Name] {
+				agreed[our.Name] = our.Version
+			}
+		}
+	}
+	result := make([]p2p.Cap, 0, len(agreed))
+	for name, version := range agreed {
+		result = append(result, p2p.Cap{Name: name, Version: version})
+	}
+	slices.SortFunc(result, p2p.Cap.Cmp)
+	return result
+}
+
+// dialReplay connects to the node, offering the protocols of the capture.
+func dialReplay(dest *enode.Node, caps []p2p.Cap) (*Conn, error) {
+	tcpEndpoint, ok := dest.TCPEndpoint()
+	if !ok {
+		return nil, errors.New("node has no TCP endpoint")
+	}
+	fd, err := net.Dial("tcp", tcpEndpoint.String())
+	if err != nil {
+		return nil, err
+	}
+	key, _ := crypto.GenerateKey()
+	c := &Conn{Conn: rlpx.NewConn(fd, dest.Pubkey()), ourKey: key, caps: caps}
+	for _, cap := range caps {
+		if cap.Name == "eth" {
+			c.ourHighestProtoVersion = max(c.ourHighestProtoVersion, cap.Version)
+		}
+	}
+	if _, err := c.Handshake(key); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if err := c.handshake(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// readReplay reads the messages of the node until the connection fails or the
+// node disconnects.
+func (c *Conn) readReplay(out io.Writer, start time.Time, offsets map[string]uint64, versions map[string]uint, write func(uint64, []byte) error) error {
+	for {
+		c.SetReadDeadline(time.Time{})
+		code, data, _, err := c.Conn.Read()
+		if err != nil {
+			return err
+		}
+		switch {
+		case code == pingMsg:
+			write(pongMsg, []byte{0xc0})
+			continue
+		case code == discMsg:
+			var reason []p2p.DiscReason
+			rlp.DecodeBytes(data, &reason)
+			if len(reason) == 0 {
+				return errDisc
+			}
+			return fmt.Errorf("%w: %v", errDisc, reason[0])
+		case code < baseProtoLen:
+			continue
+		}
+		// Find the protocol of the message.
+		proto, offset := "", uint64(0)
+		for name, o := range offsets {
+			if code >= o && code < o+protocolLengths[name][versions[name]] {
+				proto, offset = name, o
+			}
+		}
+		if proto == "" {
+			fmt.Fprintf(out, "%-12v <- unknown message code %d\n", time.Since(start).Round(time.Millisecond), code)
+			continue
+		}
+		msg, err := DecodeMessage(proto, versions[proto], code-offset, data)
+		if err != nil {
+			fmt.Fprintf(out, "%-12v <- %s/%d: %v\n", time.Since(start).Round(time.Millisecond), proto, versions[proto], err)
+			continue
+		}
+		fmt.Fprintf(out, "%-12v <- %s/%d %T\n%s", time.Since(start).Round(time.Millisecond), proto, versions[proto], msg, pretty.Sdump(msg))
+	}
+}
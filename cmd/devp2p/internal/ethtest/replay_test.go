@@ -0,0 +1,128 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package ethtest
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p/capture"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// This test checks that captured requests are replayed against a node, and that
+// its responses are decoded.
+func TestReplay(t *testing.T) {
+	jwtPath, _, err := makeJWTSecret(t)
+	if err != nil {
+		t.Fatalf("could not make jwt secret: %v", err)
+	}
+	geth, err := runGeth("./testdata", jwtPath)
+	if err != nil {
+		t.Fatalf("could not run geth: %v", err)
+	}
+	defer geth.Close()
+	chain, err := NewChain("./testdata")
+	if err != nil {
+		t.Fatalf("could not load chain: %v", err)
+	}
+
+	// Create a capture of a peer syncing from the node.
+	var (
+		buf     = new(bytes.Buffer)
+		head    = chain.blocks[chain.Len()-1]
+		packets = []any{
+			&eth.StatusPacket69{
+				ProtocolVersion: eth.ETH69,
+				NetworkID:       chain.config.ChainID.Uint64(),
+				Genesis:         chain.blocks[0].Hash(),
+				ForkID:          chain.ForkID(),
+				LatestBlock:     head.NumberU64(),
+				LatestBlockHash: head.Hash(),
+			},
+			&eth.GetBlockHeadersPacket{
+				RequestId: 33,
+				GetBlockHeadersRequest: &eth.GetBlockHeadersRequest{
+					Origin: eth.HashOrNumber{Number: 1},
+					Amount: 2,
+				},
+			},
+		}
+	)
+	w, err := capture.NewWriter(buf, &capture.Header{Caps: []string{"eth/69"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, code := range []uint64{eth.StatusMsg, eth.GetBlockHeadersMsg} {
+		payload, _ := rlp.EncodeToBytes(packets[i])
+		w.Write(capture.Ingress, "eth", eth.ETH69, code, payload)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := capture.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := new(lockedBuffer)
+	err = Replay(ReplayConfig{Dest: geth.Server().Self(), Linger: time.Second, Out: out}, r)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "*eth.BlockHeadersPacket") {
+		t.Fatalf("no headers received, output:\n%s", out)
+	}
+}
+
+func TestDecodeMessage(t *testing.T) {
+	payload, _ := rlp.EncodeToBytes(&eth.BlockRangeUpdatePacket{EarliestBlock: 1, LatestBlock: 10})
+	msg, err := DecodeMessage("eth", eth.ETH69, eth.BlockRangeUpdateMsg, payload)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if p, ok := msg.(*eth.BlockRangeUpdatePacket); !ok || p.LatestBlock != 10 {
+		t.Fatalf("wrong message %T %v", msg, msg)
+	}
+	if _, err := DecodeMessage("eth", eth.ETH69, 0x30, payload); err == nil {
+		t.Fatal("unknown message code decoded")
+	}
+	if _, err := DecodeMessage("les", 4, 0, payload); err == nil {
+		t.Fatal("unknown protocol decoded")
+	}
+}
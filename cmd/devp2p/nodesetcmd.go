@@ -27,6 +27,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -40,6 +41,7 @@ var (
 		Subcommands: []*cli.Command{
 			nodesetInfoCommand,
 			nodesetFilterCommand,
+			nodesetReportCommand,
 		},
 	}
 	nodesetInfoCommand = &cli.Command{
@@ -225,21 +227,28 @@ func minAgeFilter(args []string) (nodeFilter, error) {
 	return f, nil
 }
 
-func ethFilter(args []string) (nodeFilter, error) {
-	var filter forkid.Filter
-	switch args[0] {
+// ethNetwork returns the chain config and genesis block of a known network.
+func ethNetwork(name string) (*params.ChainConfig, *types.Block, error) {
+	switch name {
 	case "mainnet":
-		filter = forkid.NewStaticFilter(params.MainnetChainConfig, core.DefaultGenesisBlock().ToBlock())
+		return params.MainnetChainConfig, core.DefaultGenesisBlock().ToBlock(), nil
 	case "sepolia":
-		filter = forkid.NewStaticFilter(params.SepoliaChainConfig, core.DefaultSepoliaGenesisBlock().ToBlock())
+		return params.SepoliaChainConfig, core.DefaultSepoliaGenesisBlock().ToBlock(), nil
 	case "holesky":
-		filter = forkid.NewStaticFilter(params.HoleskyChainConfig, core.DefaultHoleskyGenesisBlock().ToBlock())
+		return params.HoleskyChainConfig, core.DefaultHoleskyGenesisBlock().ToBlock(), nil
 	case "hoodi":
-		filter = forkid.NewStaticFilter(params.HoodiChainConfig, core.DefaultHoodiGenesisBlock().ToBlock())
+		return params.HoodiChainConfig, core.DefaultHoodiGenesisBlock().ToBlock(), nil
 	default:
-		return nil, fmt.Errorf("unknown network %q", args[0])
+		return nil, nil, fmt.Errorf("unknown network %q", name)
 	}
+}
 
+func ethFilter(args []string) (nodeFilter, error) {
+	config, genesis, err := ethNetwork(args[0])
+	if err != nil {
+		return nil, err
+	}
+	filter := forkid.NewStaticFilter(config, genesis)
 	f := func(n nodeJSON) bool {
 		var eth struct {
 			ForkID forkid.ID
@@ -0,0 +1,463 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/devp2p/internal/ethtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	nodesetReportCommand = &cli.Command{
+		Name:      "report",
+		Usage:     "Connects to the nodes of a node set and reports client diversity and chain state",
+		ArgsUsage: "<nodes.json>",
+		Action:    nodesetReport,
+		Flags: []cli.Flag{
+			reportParallelismFlag,
+			reportTimeoutFlag,
+			reportRangeWaitFlag,
+			reportNetworkFlag,
+			reportGenesisFlag,
+			reportJSONFlag,
+			reportCSVFlag,
+		},
+	}
+	reportParallelismFlag = &cli.IntFlag{
+		Name:  "parallel",
+		Usage: "How many nodes to connect to in parallel.",
+		Value: 16,
+	}
+	reportTimeoutFlag = &cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "Time limit for connecting to a node and performing the handshakes.",
+		Value: 10 * time.Second,
+	}
+	reportRangeWaitFlag = &cli.DurationFlag{
+		Name:  "range-wait",
+		Usage: "How long to wait for a BlockRangeUpdate from eth/69 nodes.",
+	}
+	reportNetworkFlag = &cli.StringFlag{
+		Name:  "network",
+		Usage: "Network to check fork readiness against (mainnet, sepolia, holesky, hoodi).",
+	}
+	reportGenesisFlag = &cli.StringFlag{
+		Name:  "genesis",
+		Usage: "Genesis file of a custom network to check fork readiness against.",
+	}
+	reportJSONFlag = &cli.StringFlag{
+		Name:  "json",
+		Usage: "Write the report as JSON to this file ('-' for stdout).",
+	}
+	reportCSVFlag = &cli.StringFlag{
+		Name:  "csv",
+		Usage: "Write the aggregate tables as CSV to this file ('-' for stdout).",
+	}
+)
+
+// Fork readiness classes.
+const (
+	forkReady        = "ready"        // same fork ID as expected, including the next fork
+	forkUnscheduled  = "unscheduled"  // current fork matches, but the next fork differs
+	forkBehind       = "behind"       // compatible, but not on the current fork yet
+	forkIncompatible = "incompatible" // rejected by the fork ID filter
+)
+
+// nodeReport is the result of probing a single node.
+type nodeReport struct {
+	ID      enode.ID `json:"id"`
+	Addr    string   `json:"addr,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Client  string   `json:"client,omitempty"`
+	Version string   `json:"version,omitempty"`
+	Caps    []string `json:"caps,omitempty"`
+
+	// eth protocol status.
+	Eth       uint         `json:"eth,omitempty"`
+	NetworkID uint64       `json:"networkID,omitempty"`
+	ForkID    string       `json:"forkID,omitempty"`
+	Fork      string       `json:"fork,omitempty"`
+	Head      *common.Hash `json:"head,omitempty"`
+
+	// History range, eth/69 only. RangeUpdated is set if the range was taken
+	// from a BlockRangeUpdate instead of the status message.
+	EarliestBlock *uint64 `json:"earliestBlock,omitempty"`
+	LatestBlock   *uint64 `json:"latestBlock,omitempty"`
+	RangeUpdated  bool    `json:"rangeUpdated,omitempty"`
+}
+
+// reportEntry is a row of an aggregate table.
+type reportEntry struct {
+	Key   string  `json:"key"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// networkReport is the output of 'nodeset report'.
+type networkReport struct {
+	Time      time.Time `json:"time"`
+	Network   string    `json:"network,omitempty"`
+	Nodes     int       `json:"nodes"`
+	Reachable int       `json:"reachable"`
+
+	// Aggregate tables. Client tables are relative to the reachable nodes, the
+	// fork tables to the eth peers, the history table to the eth/69 peers. The
+	// history keys are the earliest available block and the message the range
+	// was taken from, e.g. "1000/status" or "1000/update".
+	Clients        []reportEntry `json:"clients"`
+	ClientVersions []reportEntry `json:"clientVersions"`
+	Protocols      []reportEntry `json:"protocols"`
+	ForkIDs        []reportEntry `json:"forkIDs"`
+	Readiness      []reportEntry `json:"readiness,omitempty"`
+	History        []reportEntry `json:"history"`
+
+	Peers []nodeReport `json:"peers"`
+}
+
+func nodesetReport(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need nodes file as argument")
+	}
+	var (
+		ns          = loadNodesJSON(ctx.Args().First())
+		network     = ctx.String(reportNetworkFlag.Name)
+		genesisFile = ctx.String(reportGenesisFlag.Name)
+		classify    func(forkid.ID) string
+	)
+	if network != "" && genesisFile != "" {
+		return fmt.Errorf("-%s and -%s are mutually exclusive", reportNetworkFlag.Name, reportGenesisFlag.Name)
+	}
+	if network != "" || genesisFile != "" {
+		var (
+			config  *params.ChainConfig
+			genesis *types.Block
+			err     error
+		)
+		if network != "" {
+			config, genesis, err = ethNetwork(network)
+			if err != nil {
+				return fmt.Errorf("-%s: %v", reportNetworkFlag.Name, err)
+			}
+		} else {
+			config, genesis, err = loadGenesis(genesisFile)
+			if err != nil {
+				return fmt.Errorf("-%s: %v", reportGenesisFlag.Name, err)
+			}
+			network = filepath.Base(genesisFile)
+		}
+		filter := forkid.NewStaticFilter(config, genesis)
+		want := forkid.NewID(config, genesis, math.MaxUint64, uint64(time.Now().Unix()))
+		classify = func(id forkid.ID) string { return forkReadiness(id, want, filter) }
+	}
+	cfg := ethtest.ProbeConfig{
+		Timeout:   ctx.Duration(reportTimeoutFlag.Name),
+		RangeWait: ctx.Duration(reportRangeWaitFlag.Name),
+	}
+	peers := probeNodes(ns.nodes(), cfg, ctx.Int(reportParallelismFlag.Name), classify)
+	report := makeNetworkReport(peers, network, time.Now())
+
+	jsonOut, csvOut := ctx.String(reportJSONFlag.Name), ctx.String(reportCSVFlag.Name)
+	if jsonOut == "" && csvOut == "" {
+		jsonOut = "-"
+	}
+	if jsonOut != "" {
+		if err := writeReportFile(jsonOut, report.writeJSON); err != nil {
+			return err
+		}
+	}
+	if csvOut != "" {
+		if err := writeReportFile(csvOut, report.writeCSV); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// probeNodes connects to all nodes, running up to 'parallel' probes at once.
+func probeNodes(nodes []*enode.Node, cfg ethtest.ProbeConfig, parallel int, classify func(forkid.ID) string) []nodeReport {
+	var (
+		results = make([]nodeReport, len(nodes))
+		next    = make(chan int)
+		wg      sync.WaitGroup
+	)
+	log.Info("Probing nodes", "count", len(nodes), "parallel", parallel)
+	for range max(parallel, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				res, err := ethtest.Probe(nodes[i], cfg)
+				results[i] = newNodeReport(nodes[i], res, err, classify)
+				log.Debug("Probed node", "id", nodes[i].ID(), "name", results[i].Name, "err", err)
+			}
+		}()
+	}
+	for i := range nodes {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// newNodeReport converts a probe result.
+func newNodeReport(n *enode.Node, res *ethtest.ProbeResult, err error, classify func(forkid.ID) string) nodeReport {
+	r := nodeReport{ID: n.ID()}
+	if addr, ok := n.TCPEndpoint(); ok {
+		r.Addr = addr.String()
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	if res == nil {
+		return r
+	}
+	r.Name, r.Caps = res.Name, res.Caps
+	r.Client, r.Version = parseClientName(res.Name)
+	if r.Eth = res.Eth; res.Head == (common.Hash{}) {
+		return r // no status received
+	}
+	head := res.Head
+	r.NetworkID, r.Head = res.NetworkID, &head
+	r.ForkID = formatForkID(res.ForkID)
+	if classify != nil {
+		r.Fork = classify(res.ForkID)
+	}
+	if res.HasRange {
+		earliest, latest := res.EarliestBlock, res.LatestBlock
+		r.EarliestBlock, r.LatestBlock = &earliest, &latest
+		r.RangeUpdated = res.RangeUpdated
+	}
+	return r
+}
+
+// loadGenesis reads the genesis specification of a custom network.
+func loadGenesis(file string) (*params.ChainConfig, *types.Block, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	genesis := new(core.Genesis)
+	if err := json.NewDecoder(f).Decode(genesis); err != nil {
+		return nil, nil, fmt.Errorf("invalid genesis file: %v", err)
+	}
+	if genesis.Config == nil {
+		return nil, nil, errors.New("genesis has no chain config")
+	}
+	return genesis.Config, genesis.ToBlock(), nil
+}
+
+// parseClientName splits a devp2p client name like "Geth/v1.15.0-stable-abcdef/linux-amd64/go1.24"
+// into the client ("geth") and its version without build metadata ("v1.15.0").
+func parseClientName(name string) (client, version string) {
+	if name == "" {
+		return "", ""
+	}
+	parts := strings.Split(name, "/")
+	client = strings.ToLower(parts[0])
+	// Some clients put an instance name after the client name.
+	for _, p := range parts[1:] {
+		if strings.HasPrefix(p, "v") && len(p) > 1 && p[1] >= '0' && p[1] <= '9' {
+			version, _, _ = strings.Cut(p, "-")
+			version, _, _ = strings.Cut(version, "+")
+			break
+		}
+	}
+	return client, version
+}
+
+func formatForkID(id forkid.ID) string {
+	return fmt.Sprintf("%#x/%d", id.Hash, id.Next)
+}
+
+// forkReadiness classifies the fork ID of a node against the expected fork ID
+// of the network.
+func forkReadiness(id, want forkid.ID, filter forkid.Filter) string {
+	switch {
+	case filter(id) != nil:
+		return forkIncompatible
+	case id == want:
+		return forkReady
+	case id.Hash == want.Hash:
+		return forkUnscheduled
+	default:
+		return forkBehind
+	}
+}
+
+// makeNetworkReport aggregates the node reports.
+func makeNetworkReport(peers []nodeReport, network string, now time.Time) *networkReport {
+	var (
+		r         = &networkReport{Time: now.UTC(), Network: network, Nodes: len(peers), Peers: peers}
+		clients   = newCounter()
+		versions  = newCounter()
+		protocols = newCounter()
+		forkIDs   = newCounter()
+		readiness = newCounter()
+		history   = newCounter()
+	)
+	for _, p := range peers {
+		if p.Name == "" {
+			continue
+		}
+		r.Reachable++
+		clients.add(p.Client)
+		versions.add(p.Client + "/" + p.Version)
+		if p.Eth == 0 {
+			protocols.add("none")
+			continue
+		}
+		protocols.add(fmt.Sprintf("eth/%d", p.Eth))
+		if p.ForkID != "" {
+			forkIDs.add(p.ForkID)
+		}
+		if p.Fork != "" {
+			readiness.add(p.Fork)
+		}
+		if p.EarliestBlock != nil {
+			source := "status"
+			if p.RangeUpdated {
+				source = "update"
+			}
+			history.add(strconv.FormatUint(*p.EarliestBlock, 10) + "/" + source)
+		}
+	}
+	r.Clients = clients.entries()
+	r.ClientVersions = versions.entries()
+	r.Protocols = protocols.entries()
+	r.ForkIDs = forkIDs.entries()
+	r.Readiness = readiness.entries()
+	r.History = history.entries()
+	slices.SortFunc(r.Peers, func(a, b nodeReport) int { return bytes.Compare(a.ID[:], b.ID[:]) })
+	return r
+}
+
+func (r *networkReport) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", jsonIndent)
+	return enc.Encode(r)
+}
+
+// writeCSV writes the aggregate tables as CSV, one row per entry.
+func (r *networkReport) writeCSV(w io.Writer) error {
+	var (
+		cw     = csv.NewWriter(w)
+		tables = []struct {
+			name    string
+			entries []reportEntry
+		}{
+			{"clients", r.Clients},
+			{"clientVersions", r.ClientVersions},
+			{"protocols", r.Protocols},
+			{"forkIDs", r.ForkIDs},
+			{"readiness", r.Readiness},
+			{"history", r.History},
+		}
+	)
+	cw.Write([]string{"time", "network", "table", "key", "count", "share"})
+	ts := r.Time.Format(time.RFC3339)
+	cw.Write([]string{ts, r.Network, "nodes", "total", strconv.Itoa(r.Nodes), "1"})
+	cw.Write([]string{ts, r.Network, "nodes", "reachable", strconv.Itoa(r.Reachable), formatShare(r.Reachable, r.Nodes)})
+	for _, t := range tables {
+		for _, e := range t.entries {
+			cw.Write([]string{ts, r.Network, t.name, e.Key, strconv.Itoa(e.Count), strconv.FormatFloat(e.Share, 'f', 4, 64)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatShare(n, total int) string {
+	if total == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(float64(n)/float64(total), 'f', 4, 64)
+}
+
+func writeReportFile(file string, write func(io.Writer) error) error {
+	if file == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// counter counts occurrences of keys.
+type counter struct {
+	counts map[string]int
+	total  int
+}
+
+func newCounter() *counter {
+	return &counter{counts: make(map[string]int)}
+}
+
+func (c *counter) add(key string) {
+	if key == "" {
+		key = "unknown"
+	}
+	c.counts[key]++
+	c.total++
+}
+
+// entries returns the counted keys, most frequent first.
+func (c *counter) entries() []reportEntry {
+	result := make([]reportEntry, 0, len(c.counts))
+	for key, n := range c.counts {
+		result = append(result, reportEntry{Key: key, Count: n, Share: float64(n) / float64(c.total)})
+	}
+	slices.SortFunc(result, func(a, b reportEntry) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return result
+}
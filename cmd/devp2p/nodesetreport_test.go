@@ -0,0 +1,149 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestParseClientName(t *testing.T) {
+	tests := []struct {
+		name, client, version string
+	}{
+		{"Geth/v1.15.0-stable-1e5a7d9a/linux-amd64/go1.24.1", "geth", "v1.15.0"},
+		{"Geth/mynode/v1.14.12-stable/linux-amd64/go1.23.4", "geth", "v1.14.12"},
+		{"Nethermind/v1.31.0+a4b5c6d7/linux-x64/dotnet9.0.2", "nethermind", "v1.31.0"},
+		{"erigon/v3.0.0/linux-amd64/go1.23.6", "erigon", "v3.0.0"},
+		{"besu", "besu", ""},
+		{"", "", ""},
+	}
+	for _, test := range tests {
+		client, version := parseClientName(test.name)
+		if client != test.client || version != test.version {
+			t.Errorf("%q: got (%q, %q), want (%q, %q)", test.name, client, version, test.client, test.version)
+		}
+	}
+}
+
+func TestForkReadiness(t *testing.T) {
+	var (
+		want   = forkid.ID{Hash: [4]byte{1}, Next: 100}
+		filter = func(id forkid.ID) error {
+			if id.Hash == [4]byte{9} {
+				return forkid.ErrLocalIncompatibleOrStale
+			}
+			return nil
+		}
+	)
+	tests := []struct {
+		id   forkid.ID
+		want string
+	}{
+		{forkid.ID{Hash: [4]byte{1}, Next: 100}, forkReady},
+		{forkid.ID{Hash: [4]byte{1}, Next: 0}, forkUnscheduled},
+		{forkid.ID{Hash: [4]byte{2}, Next: 50}, forkBehind},
+		{forkid.ID{Hash: [4]byte{9}, Next: 0}, forkIncompatible},
+	}
+	for _, test := range tests {
+		if have := forkReadiness(test.id, want, filter); have != test.want {
+			t.Errorf("%v: got %s, want %s", test.id, have, test.want)
+		}
+	}
+}
+
+func TestNetworkReport(t *testing.T) {
+	u64 := func(n uint64) *uint64 { return &n }
+	peers := []nodeReport{
+		{Name: "Geth/v1.15.0", Client: "geth", Version: "v1.15.0", Eth: 69, ForkID: "0x01/0", Fork: forkReady, EarliestBlock: u64(0)},
+		{Name: "Geth/v1.15.0", Client: "geth", Version: "v1.15.0", Eth: 69, ForkID: "0x01/0", Fork: forkReady, EarliestBlock: u64(1000)},
+		{Name: "Geth/v1.14.0", Client: "geth", Version: "v1.14.0", Eth: 68, ForkID: "0x02/0", Fork: forkBehind},
+		{Name: "reth/v1.3.0", Client: "reth", Version: "v1.3.0", Eth: 69, ForkID: "0x01/0", Fork: forkReady, EarliestBlock: u64(1000)},
+		{Name: "reth/v1.3.0", Client: "reth", Version: "v1.3.0", Eth: 69, ForkID: "0x01/0", Fork: forkReady, EarliestBlock: u64(1000), RangeUpdated: true},
+		{Error: "connection refused"},
+	}
+	r := makeNetworkReport(peers, "hoodi", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if r.Nodes != 6 || r.Reachable != 5 {
+		t.Fatalf("wrong node counts: nodes %d, reachable %d", r.Nodes, r.Reachable)
+	}
+	wantClients := []reportEntry{{"geth", 3, 0.6}, {"reth", 2, 0.4}}
+	if !reflect.DeepEqual(r.Clients, wantClients) {
+		t.Errorf("wrong clients: %v", r.Clients)
+	}
+	wantHistory := []reportEntry{{"1000/status", 2, 0.5}, {"0/status", 1, 0.25}, {"1000/update", 1, 0.25}}
+	if !reflect.DeepEqual(r.History, wantHistory) {
+		t.Errorf("wrong history: %v", r.History)
+	}
+	wantReadiness := []reportEntry{{forkReady, 4, 0.8}, {forkBehind, 1, 0.2}}
+	if !reflect.DeepEqual(r.Readiness, wantReadiness) {
+		t.Errorf("wrong readiness: %v", r.Readiness)
+	}
+
+	var buf bytes.Buffer
+	if err := r.writeCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "time,network,table,key,count,share" {
+		t.Errorf("wrong CSV header: %q", lines[0])
+	}
+	for _, want := range []string{
+		"2025-01-01T00:00:00Z,hoodi,nodes,reachable,5,0.8333",
+		"2025-01-01T00:00:00Z,hoodi,clientVersions,geth/v1.15.0,2,0.4000",
+		"2025-01-01T00:00:00Z,hoodi,protocols,eth/69,4,0.8000",
+		"2025-01-01T00:00:00Z,hoodi,history,1000/status,2,0.5000",
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("CSV output is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestLoadGenesis(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "genesis.json")
+	blob, err := json.Marshal(core.DefaultHoodiGenesisBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, blob, 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, genesis, err := loadGenesis(file)
+	if err != nil {
+		t.Fatalf("failed to load genesis: %v", err)
+	}
+	if genesis.Hash() != params.HoodiGenesisHash {
+		t.Errorf("wrong genesis hash: got %x, want %x", genesis.Hash(), params.HoodiGenesisHash)
+	}
+	wantConfig, wantGenesis, _ := ethNetwork("hoodi")
+	now := uint64(time.Now().Unix())
+	if have, want := forkid.NewID(config, genesis, math.MaxUint64, now), forkid.NewID(wantConfig, wantGenesis, math.MaxUint64, now); have != want {
+		t.Errorf("wrong fork ID: got %v, want %v", have, want)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/ethereum/go-ethereum/cmd/devp2p/internal/ethtest"
+	"github.com/ethereum/go-ethereum/p2p/capture"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	replayCommand = &cli.Command{
+		Name:  "replay",
+		Usage: "Inspects and replays peer traffic captures",
+		Description: `Captures are recorded by geth when started with --netcapture.
+Without a node argument, the messages of the capture are decoded and printed.
+When a node is given, the messages received from the captured peer are sent to
+the node, and the messages sent by the node in response are printed.`,
+		ArgsUsage: "<capture file> [node]",
+		Action:    replay,
+		Flags: []cli.Flag{
+			replayBriefFlag,
+			replayTimingFlag,
+			replayResponsesFlag,
+			replayLingerFlag,
+		},
+	}
+	replayBriefFlag = &cli.BoolFlag{
+		Name:  "brief",
+		Usage: "Prints a single line per message instead of the decoded content",
+	}
+	replayTimingFlag = &cli.BoolFlag{
+		Name:  "timing",
+		Usage: "Keeps the original delays between messages when replaying",
+	}
+	replayResponsesFlag = &cli.BoolFlag{
+		Name:  "responses",
+		Usage: "Also replays the responses sent by the captured peer",
+	}
+	replayLingerFlag = &cli.DurationFlag{
+		Name:  "linger",
+		Usage: "Time to wait for messages of the node after replaying",
+		Value: 5 * time.Second,
+	}
+)
+
+func replay(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need capture file as argument")
+	}
+	f, err := os.Open(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := capture.NewReader(f)
+	if err != nil {
+		return err
+	}
+	h := r.Header()
+	fmt.Printf("Peer %v (%s) %s, caps %v, captured %v\n", h.ID, h.Name, h.RemoteAddr, h.Caps, h.StartTime().UTC())
+
+	if ctx.NArg() < 2 {
+		return dumpCapture(r, ctx.Bool(replayBriefFlag.Name))
+	}
+	n, err := parseNode(ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	return ethtest.Replay(ethtest.ReplayConfig{
+		Dest:      n,
+		Timing:    ctx.Bool(replayTimingFlag.Name),
+		Responses: ctx.Bool(replayResponsesFlag.Name),
+		Linger:    ctx.Duration(replayLingerFlag.Name),
+		Out:       os.Stdout,
+	}, r)
+}
+
+// dumpCapture prints the decoded messages of a capture.
+func dumpCapture(r *capture.Reader, brief bool) error {
+	dump := spew.ConfigState{Indent: "  ", DisableCapacities: true, DisablePointerAddresses: true}
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		prefix := fmt.Sprintf("%-12v %v %s/%d", rec.Elapsed().Round(time.Millisecond), rec.Direction, rec.Protocol, rec.Version)
+		msg, err := ethtest.DecodeMessage(rec.Protocol, rec.Version, rec.Code, rec.Payload)
+		switch {
+		case err != nil:
+			fmt.Printf("%s code %d, %d bytes: %v\n", prefix, rec.Code, len(rec.Payload), err)
+		case brief:
+			fmt.Printf("%s %T, %d bytes\n", prefix, msg, len(rec.Payload))
+		default:
+			fmt.Printf("%s %T\n%s", prefix, msg, dump.Sdump(msg))
+		}
+	}
+}
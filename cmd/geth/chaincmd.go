@@ -47,6 +47,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/urfave/cli/v2"
 )
 
@@ -141,6 +142,23 @@ Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
 be gzipped.`,
+	}
+	exportStateDiffCommand = &cli.Command{
+		Action:    exportStateDiff,
+		Name:      "export-statediff",
+		Usage:     "Export the per-block state diffs of a block range into file",
+		ArgsUsage: "<filename> <blockNumFirst> <blockNumLast>",
+		Flags:     slices.Concat([]cli.Flag{utils.CacheFlag, stateDiffFormatFlag}, utils.DatabaseFlags),
+		Description: `
+The export-statediff command writes the state diff of every canonical block in
+the given range to the file, in block order. A diff contains the new nonce,
+balance and code hash of every modified account, the new values of modified
+storage slots and the accounts that were deleted. Every diff also carries the
+block hash and parent hash, allowing consumers to detect reorgs.
+
+The states of the exported blocks and their parents must be available, which
+typically requires an archive node for older ranges. The file will be appended
+if already existing. If the file ends with .gz, the output will be gzipped.`,
 	}
 	importHistoryCommand = &cli.Command{
 		Action:    importHistory,
@@ -242,6 +260,11 @@ var (
 		Name:  "server",
 		Usage: "era1 server URL",
 	}
+	stateDiffFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Output format of the state diffs (jsonl or rlp)",
+		Value: "jsonl",
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -281,7 +304,7 @@ func initGenesis(ctx *cli.Context) error {
 	chaindb := utils.MakeChainDatabase(ctx, stack, false)
 	defer chaindb.Close()
 
-	triedb := utils.MakeTrieDatabase(ctx, chaindb, ctx.Bool(utils.CachePreimagesFlag.Name), false, genesis.IsVerkle())
+	triedb := makeGenesisTrieDatabase(ctx, chaindb, genesis)
 	defer triedb.Close()
 
 	_, hash, compatErr, err := core.SetupGenesisBlockWithOverride(chaindb, triedb, genesis, &overrides)
@@ -296,6 +319,16 @@ func initGenesis(ctx *cli.Context) error {
 	return nil
 }
 
+// makeGenesisTrieDatabase constructs the trie database matching the state
+// tree type configured in the genesis.
+func makeGenesisTrieDatabase(ctx *cli.Context, disk ethdb.Database, genesis *core.Genesis) *triedb.Database {
+	preimages := ctx.Bool(utils.CachePreimagesFlag.Name)
+	if genesis.IsBinary() {
+		return utils.MakeBinaryTrieDatabase(ctx, disk, preimages, false)
+	}
+	return utils.MakeTrieDatabase(ctx, disk, preimages, false, genesis.IsVerkle())
+}
+
 func dumpGenesis(ctx *cli.Context) error {
 	// check if there is a testnet preset enabled
 	var genesis *core.Genesis
@@ -451,6 +484,35 @@ func exportChain(ctx *cli.Context) error {
 	return nil
 }
 
+func exportStateDiff(ctx *cli.Context) error {
+	if ctx.Args().Len() != 3 {
+		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
+	}
+	first, ferr := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	last, lerr := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
+	}
+	if first > last {
+		utils.Fatalf("Export error: first block %d larger than last block %d\n", first, last)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+
+	if head := chain.CurrentBlock(); last > head.Number.Uint64() {
+		utils.Fatalf("Export error: block number %d larger than head block %d\n", last, head.Number.Uint64())
+	}
+	start := time.Now()
+	if err := utils.ExportStateDiff(chain, ctx.Args().First(), ctx.String(stateDiffFormatFlag.Name), first, last); err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+	fmt.Printf("Export done in %v\n", time.Since(start))
+	return nil
+}
+
 func importHistory(ctx *cli.Context) error {
 	if ctx.Args().Len() != 1 {
 		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
@@ -18,6 +18,8 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -33,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -83,6 +86,8 @@ Remove blockchain and state databases`,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
 			dbInspectHistoryCmd,
+			dbStateAccessProfileCmd,
+			dbRepairStateCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -207,6 +212,40 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command queries the history of the account or storage slot within the specified block range",
 	}
+	dbStateAccessProfileCmd = &cli.Command{
+		Action: showStateAccessProfile,
+		Name:   "state-access-profile",
+		Usage:  "Show the most frequently accessed accounts and storage tries",
+		Flags: slices.Concat([]cli.Flag{
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "number of accounts and storage tries to display",
+				Value: 20,
+			},
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command displays the state access profile persisted by the node, either
+at shutdown or when the profiling was stopped via debug_stopStateAccessProfile. The
+profiling can be enabled with --state.accessprofile or debug_startStateAccessProfile.`,
+	}
+	dbRepairStateCmd = &cli.Command{
+		Action: repairState,
+		Name:   "repair-state",
+		Usage:  "Regenerate missing or corrupted trie nodes from the flat state",
+		Flags: slices.Concat([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "only report the damaged trie nodes without rewriting them",
+			},
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command regenerates the persisted state trie (all accounts and storages)
+from the flat state (state snapshot in hash mode, or the path database flat state
+in path mode), compares every node with the one stored in the database and rewrites
+the ones which are missing or corrupted. The flat state must be fully generated and
+aligned with the persisted state: in path mode its root is the persisted state root,
+a damaged root node included; in hash mode its root must be a persisted trie root.
+Nothing is written unless the regenerated state root matches the persisted one.
+Every rewritten node is reported.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -907,3 +946,141 @@ func inspectHistory(ctx *cli.Context) error {
 	}
 	return inspectStorage(triedb, start, end, address, slot, ctx.Bool("raw"))
 }
+
+func showStateAccessProfile(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	blob := rawdb.ReadStateAccessProfile(db)
+	if len(blob) == 0 {
+		return errors.New("no state access profile persisted")
+	}
+	var report state.AccessProfileReport
+	if err := json.Unmarshal(blob, &report); err != nil {
+		return err
+	}
+	sources := []string{
+		state.AccessSourceSnapshot,
+		state.AccessSourceDiff,
+		state.AccessSourceDirty,
+		state.AccessSourceClean,
+		state.AccessSourceDisk,
+		state.AccessSourceTrie,
+	}
+	render := func(title string, entries []*state.AccessProfileEntry) {
+		fmt.Printf("%s:\n", title)
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(slices.Concat([]string{"Address"}, sources, []string{"Writes", "Total"}))
+		for i, entry := range entries {
+			if i >= ctx.Int("limit") {
+				break
+			}
+			row := []string{entry.Address.Hex()}
+			for _, source := range sources {
+				row = append(row, strconv.FormatUint(entry.Reads[source], 10))
+			}
+			row = append(row, strconv.FormatUint(entry.Writes, 10), strconv.FormatUint(entry.Total, 10))
+			table.Append(row)
+		}
+		table.Render()
+	}
+	fmt.Printf("State access profile since %v\n\n", report.Since)
+	render("Accounts", report.Accounts)
+	render("Storage tries", report.Storages)
+	return nil
+}
+
+// persistedStateRoot returns the root of the persisted state the flat state is
+// aligned with, which the trie is repaired under.
+//
+// In path mode, the flat state is flushed along with the trie nodes of the disk
+// layer, so its root is the persisted state root. A root node not matching it
+// is damaged and will be rewritten. In hash mode, the state snapshot is flushed
+// independently of the tries, so its root must be a persisted trie root on the
+// canonical chain.
+func persistedStateRoot(db ethdb.Database, scheme string) (common.Hash, error) {
+	root := rawdb.ReadSnapshotRoot(db)
+	if root == (common.Hash{}) {
+		return common.Hash{}, errors.New("no flat state found in the database")
+	}
+	if scheme == rawdb.PathScheme {
+		if blob := rawdb.ReadAccountTrieNode(db, nil); len(blob) > 0 && crypto.Keccak256Hash(blob) != root {
+			log.Warn("Persisted trie root node is inconsistent with the state root", "root", root, "node", crypto.Keccak256Hash(blob))
+		}
+		return root, nil
+	}
+	if rawdb.HasLegacyTrieNode(db, root) {
+		return root, nil
+	}
+	// The flat state is not aligned with a persisted trie, report the newest
+	// persisted state root to help the user.
+	head := rawdb.ReadHeadHeader(db)
+	if head == nil {
+		return common.Hash{}, errors.New("no head header found in the database")
+	}
+	for number := head.Number.Uint64(); ; number-- {
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, number), number)
+		if header != nil && rawdb.HasLegacyTrieNode(db, header.Root) {
+			return common.Hash{}, fmt.Errorf("flat state root %x is not persisted, the persisted state root is %x (block %d)", root, header.Root, number)
+		}
+		if number == 0 {
+			break
+		}
+	}
+	return common.Hash{}, fmt.Errorf("flat state root %x is not persisted, no persisted state root found", root)
+}
+
+func repairState(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	dryRun := ctx.Bool("dry-run")
+	db := utils.MakeChainDatabase(ctx, stack, dryRun)
+	defer db.Close()
+
+	scheme := rawdb.ReadStateScheme(db)
+	if scheme == "" {
+		return errors.New("no state found in the database")
+	}
+	root, err := persistedStateRoot(db, scheme)
+	if err != nil {
+		return err
+	}
+	log.Info("Repairing the state", "scheme", scheme, "root", root, "dryrun", dryRun)
+
+	start := time.Now()
+	nodes, err := snapshot.RepairTrie(db, scheme, root, dryRun)
+	if err != nil {
+		log.Error("Failed to repair state", "root", root, "err", err)
+		return err
+	}
+	if len(nodes) == 0 {
+		log.Info("State is intact, nothing to repair", "root", root, "elapsed", common.PrettyDuration(time.Since(start)))
+		return nil
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Owner", "Path", "Hash", "Problem"})
+	for _, n := range nodes {
+		problem := "corrupted"
+		if n.Missing {
+			problem = "missing"
+		}
+		owner := "account trie"
+		if n.Owner != (common.Hash{}) {
+			owner = n.Owner.Hex()
+		}
+		table.Append([]string{owner, fmt.Sprintf("%x", n.Path), n.Hash.Hex(), problem})
+	}
+	table.Render()
+
+	if dryRun {
+		log.Info("Found damaged trie nodes", "root", root, "nodes", len(nodes), "elapsed", common.PrettyDuration(time.Since(start)))
+	} else {
+		log.Info("Rewritten damaged trie nodes", "root", root, "nodes", len(nodes), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
@@ -89,8 +89,11 @@ var (
 		utils.ChainHistoryFlag,
 		utils.LogHistoryFlag,
 		utils.LogNoHistoryFlag,
+		utils.AddressIndexFlag,
+		utils.AddressIndexInternalFlag,
 		utils.LogExportCheckpointsFlag,
 		utils.StateHistoryFlag,
+		utils.StateAccessProfileFlag,
 		utils.LightKDFFlag,
 		utils.EthRequiredBlocksFlag,
 		utils.LegacyWhitelistFlag, // deprecated
@@ -108,8 +111,13 @@ var (
 		utils.CryptoKZGFlag,
 		utils.ListenPortFlag,
 		utils.DiscoveryPortFlag,
+		utils.QUICPortFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
+		utils.BandwidthIngressFlag,
+		utils.BandwidthEgressFlag,
+		utils.BandwidthPeerIngressFlag,
+		utils.BandwidthPeerEgressFlag,
 		utils.MiningEnabledFlag, // deprecated
 		utils.MinerGasLimitFlag,
 		utils.MinerGasPriceFlag,
@@ -122,8 +130,11 @@ var (
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV4Flag,
 		utils.DiscoveryV5Flag,
+		utils.DiscoveryV5TopicsFlag,
 		utils.LegacyDiscoveryV5Flag, // deprecated
 		utils.NetrestrictFlag,
+		utils.AllowlistFlag,
+		utils.NetCaptureFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
 		utils.DNSDiscoveryFlag,
@@ -139,6 +150,8 @@ var (
 		utils.GpoPercentileFlag,
 		utils.GpoMaxGasPriceFlag,
 		utils.GpoIgnoreGasPriceFlag,
+		utils.GpoStrategyFlag,
+		utils.GpoInclusionBlocksFlag,
 		configFileFlag,
 		utils.LogDebugFlag,
 		utils.LogBacktraceAtFlag,
@@ -162,18 +175,27 @@ var (
 		utils.AuthPortFlag,
 		utils.AuthVirtualHostsFlag,
 		utils.JWTSecretFlag,
+		utils.AuthTLSCertFlag,
+		utils.AuthTLSKeyFlag,
+		utils.AuthTLSClientCAFlag,
 		utils.HTTPVirtualHostsFlag,
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
 		utils.GraphQLVirtualHostsFlag,
 		utils.HTTPApiFlag,
 		utils.HTTPPathPrefixFlag,
+		utils.HTTPTLSCertFlag,
+		utils.HTTPTLSKeyFlag,
+		utils.HTTPTLSClientCAFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
 		utils.WSPathPrefixFlag,
+		utils.WSTLSCertFlag,
+		utils.WSTLSKeyFlag,
+		utils.WSTLSClientCAFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 		utils.InsecureUnlockAllowedFlag,
@@ -183,6 +205,10 @@ var (
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCJobLimitFlag,
+		utils.RPCJobTimeoutFlag,
+		utils.RPCJobRetentionFlag,
+		utils.RPCCacheFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -213,6 +239,7 @@ func init() {
 		initCommand,
 		importCommand,
 		exportCommand,
+		exportStateDiffCommand,
 		importHistoryCommand,
 		exportHistoryCommand,
 		importPreimagesCommand,
@@ -22,6 +22,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -37,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -82,6 +84,9 @@ func StartNode(ctx *cli.Context, stack *node.Node, isConsole bool) {
 	if err := stack.Start(); err != nil {
 		Fatalf("Error starting protocol stack: %v", err)
 	}
+	if cfg := stack.Config(); cfg.HTTPTLS != nil || cfg.WSTLS != nil || cfg.AuthTLS != nil {
+		go reloadTLSOnHangup(stack)
+	}
 	go func() {
 		sigc := make(chan os.Signal, 1)
 		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
@@ -127,6 +132,22 @@ func StartNode(ctx *cli.Context, stack *node.Node, isConsole bool) {
 	}()
 }
 
+// reloadTLSOnHangup reloads the TLS credentials of the RPC endpoints whenever
+// the process receives SIGHUP.
+func reloadTLSOnHangup(stack *node.Node) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	defer signal.Stop(sigc)
+
+	for range sigc {
+		if err := stack.ReloadTLS(); err != nil {
+			log.Error("Failed to reload TLS credentials", "err", err)
+		} else {
+			log.Info("Reloaded TLS credentials")
+		}
+	}
+}
+
 func monitorFreeDiskSpace(sigc chan os.Signal, path string, freeDiskSpaceCritical uint64) {
 	if path == "" {
 		return
@@ -401,6 +422,72 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 	return nil
 }
 
+// ExportStateDiff exports the state diffs of the canonical blocks within the
+// specified range into the given file, one diff per block. The diffs are
+// either encoded as a stream of RLP items or as JSON lines, depending on the
+// requested format. Like ExportAppendChain, the file is appended to if data
+// already exists in it.
+func ExportStateDiff(blockchain *core.BlockChain, fn string, format string, first uint64, last uint64) error {
+	log.Info("Exporting state diffs", "file", fn, "format", format)
+
+	var encode func(w io.Writer, diff *state.StateDiff) error
+	switch format {
+	case "jsonl":
+		encode = func(w io.Writer, diff *state.StateDiff) error {
+			blob, err := json.Marshal(diff)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(append(blob, '\n'))
+			return err
+		}
+	case "rlp":
+		encode = func(w io.Writer, diff *state.StateDiff) error {
+			return rlp.Encode(w, diff)
+		}
+	default:
+		return fmt.Errorf("unknown state diff format %q", format)
+	}
+	// Open the file handle and potentially wrap with a gzip stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	buffered := bufio.NewWriter(writer)
+	defer buffered.Flush()
+
+	var (
+		start    = time.Now()
+		reported = time.Now()
+	)
+	for number := first; number <= last; number++ {
+		header := blockchain.GetHeaderByNumber(number)
+		if header == nil {
+			return fmt.Errorf("export failed on #%d: not found", number)
+		}
+		diff, err := blockchain.StateDiff(header)
+		if err != nil {
+			return fmt.Errorf("export failed on #%d: %v", number, err)
+		}
+		if err := encode(buffered, diff); err != nil {
+			return err
+		}
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Exporting state diffs", "exported", number-first+1, "number", number, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	log.Info("Exported state diffs", "file", fn, "blocks", last-first+1, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 // ExportHistory exports blockchain history into the specified directory,
 // following the Era format.
 func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) error {
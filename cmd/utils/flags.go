@@ -276,6 +276,11 @@ var (
 		Value:    ethconfig.Defaults.StateHistory,
 		Category: flags.StateCategory,
 	}
+	StateAccessProfileFlag = &cli.BoolFlag{
+		Name:     "state.accessprofile",
+		Usage:    "Enable profiling of the state reads and writes per account and storage trie",
+		Category: flags.StateCategory,
+	}
 	TransactionHistoryFlag = &cli.Uint64Flag{
 		Name:     "history.transactions",
 		Usage:    "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
@@ -299,6 +304,16 @@ var (
 		Usage:    "Do not maintain log search index",
 		Category: flags.StateCategory,
 	}
+	AddressIndexFlag = &cli.BoolFlag{
+		Name:     "history.addresses",
+		Usage:    "Maintain an index of transactions by sender and recipient address (same retention as the transactions index)",
+		Category: flags.StateCategory,
+	}
+	AddressIndexInternalFlag = &cli.BoolFlag{
+		Name:     "history.addresses.internal",
+		Usage:    "Also index addresses participating in internal calls (requires re-executing blocks on import)",
+		Category: flags.StateCategory,
+	}
 	LogExportCheckpointsFlag = &cli.StringFlag{
 		Name:     "history.logs.export",
 		Usage:    "Export checkpoints to file in go source file format",
@@ -608,6 +623,21 @@ var (
 		Usage:    "Path to a JWT secret to use for authenticated RPC endpoints",
 		Category: flags.APICategory,
 	}
+	AuthTLSCertFlag = &flags.DirectoryFlag{
+		Name:     "authrpc.tls.cert",
+		Usage:    "Path to the TLS certificate served by the authenticated RPC endpoints (reloaded on change or SIGHUP)",
+		Category: flags.APICategory,
+	}
+	AuthTLSKeyFlag = &flags.DirectoryFlag{
+		Name:     "authrpc.tls.key",
+		Usage:    "Path to the TLS private key of the authenticated RPC endpoints",
+		Category: flags.APICategory,
+	}
+	AuthTLSClientCAFlag = &flags.DirectoryFlag{
+		Name:     "authrpc.tls.clientca",
+		Usage:    "Path to the CA bundle verifying client certificates of the authenticated RPC endpoints (enables mutual TLS)",
+		Category: flags.APICategory,
+	}
 
 	// Logging and debug settings
 	EthStatsURLFlag = &cli.StringFlag{
@@ -681,6 +711,21 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	HTTPTLSCertFlag = &flags.DirectoryFlag{
+		Name:     "http.tls.cert",
+		Usage:    "Path to the TLS certificate served by the HTTP-RPC server (reloaded on change or SIGHUP)",
+		Category: flags.APICategory,
+	}
+	HTTPTLSKeyFlag = &flags.DirectoryFlag{
+		Name:     "http.tls.key",
+		Usage:    "Path to the TLS private key of the HTTP-RPC server",
+		Category: flags.APICategory,
+	}
+	HTTPTLSClientCAFlag = &flags.DirectoryFlag{
+		Name:     "http.tls.clientca",
+		Usage:    "Path to the CA bundle verifying client certificates of the HTTP-RPC server (enables mutual TLS)",
+		Category: flags.APICategory,
+	}
 	GraphQLEnabledFlag = &cli.BoolFlag{
 		Name:     "graphql",
 		Usage:    "Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.",
@@ -733,6 +778,21 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	WSTLSCertFlag = &flags.DirectoryFlag{
+		Name:     "ws.tls.cert",
+		Usage:    "Path to the TLS certificate served by the WS-RPC server (reloaded on change or SIGHUP)",
+		Category: flags.APICategory,
+	}
+	WSTLSKeyFlag = &flags.DirectoryFlag{
+		Name:     "ws.tls.key",
+		Usage:    "Path to the TLS private key of the WS-RPC server",
+		Category: flags.APICategory,
+	}
+	WSTLSClientCAFlag = &flags.DirectoryFlag{
+		Name:     "ws.tls.clientca",
+		Usage:    "Path to the CA bundle verifying client certificates of the WS-RPC server (enables mutual TLS)",
+		Category: flags.APICategory,
+	}
 	ExecFlag = &cli.StringFlag{
 		Name:     "exec",
 		Usage:    "Execute JavaScript statement",
@@ -760,6 +820,30 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCJobLimitFlag = &cli.IntFlag{
+		Name:     "rpc.job-limit",
+		Usage:    "Maximum number of concurrently running asynchronous jobs per RPC endpoint (0 = disabled)",
+		Value:    node.DefaultConfig.RPCJobLimit,
+		Category: flags.APICategory,
+	}
+	RPCJobTimeoutFlag = &cli.DurationFlag{
+		Name:     "rpc.job-timeout",
+		Usage:    "Maximum duration of an asynchronous RPC job",
+		Value:    node.DefaultConfig.RPCJobTimeout,
+		Category: flags.APICategory,
+	}
+	RPCJobRetentionFlag = &cli.DurationFlag{
+		Name:     "rpc.job-retention",
+		Usage:    "Duration the outcome of a finished asynchronous RPC job remains available",
+		Value:    node.DefaultConfig.RPCJobRetention,
+		Category: flags.APICategory,
+	}
+	RPCCacheFlag = &cli.IntFlag{
+		Name:     "rpc.cache",
+		Usage:    "Megabytes of memory allocated to caching immutable RPC results per endpoint (0 = disabled)",
+		Value:    node.DefaultConfig.RPCCacheSize,
+		Category: flags.APICategory,
+	}
 
 	// Network Settings
 	MaxPeersFlag = &cli.IntFlag{
@@ -774,6 +858,26 @@ var (
 		Value:    node.DefaultConfig.P2P.MaxPendingPeers,
 		Category: flags.NetworkingCategory,
 	}
+	BandwidthIngressFlag = &cli.IntFlag{
+		Name:     "bandwidth.ingress",
+		Usage:    "Maximum incoming p2p traffic of all peers in KB/s (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthEgressFlag = &cli.IntFlag{
+		Name:     "bandwidth.egress",
+		Usage:    "Maximum outgoing p2p traffic of all peers in KB/s (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthPeerIngressFlag = &cli.IntFlag{
+		Name:     "bandwidth.peeringress",
+		Usage:    "Maximum incoming p2p traffic of a single peer in KB/s (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthPeerEgressFlag = &cli.IntFlag{
+		Name:     "bandwidth.peeregress",
+		Usage:    "Maximum outgoing p2p traffic of a single peer in KB/s (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
 	ListenPortFlag = &cli.IntFlag{
 		Name:     "port",
 		Usage:    "Network listening port",
@@ -821,11 +925,26 @@ var (
 		Category: flags.NetworkingCategory,
 		Value:    true,
 	}
+	DiscoveryV5TopicsFlag = &cli.BoolFlag{
+		Name:     "discovery.v5.topics",
+		Usage:    "Enables the experimental V5 discovery topic advertisement protocol",
+		Category: flags.NetworkingCategory,
+	}
 	NetrestrictFlag = &cli.StringFlag{
 		Name:     "netrestrict",
 		Usage:    "Restricts network communication to the given IP networks (CIDR masks)",
 		Category: flags.NetworkingCategory,
 	}
+	NetCaptureFlag = &cli.StringFlag{
+		Name:     "netcapture",
+		Usage:    "Records the messages exchanged with each peer into capture files in the given directory (for debugging)",
+		Category: flags.NetworkingCategory,
+	}
+	AllowlistFlag = &cli.StringFlag{
+		Name:     "allowlist",
+		Usage:    "Restricts peer connections to the nodes listed in the given file (node IDs or enode URLs, one per line)",
+		Category: flags.NetworkingCategory,
+	}
 	DNSDiscoveryFlag = &cli.StringFlag{
 		Name:     "discovery.dns",
 		Usage:    "Sets DNS discovery entry points (use \"\" to disable DNS)",
@@ -837,6 +956,11 @@ var (
 		Value:    30303,
 		Category: flags.NetworkingCategory,
 	}
+	QUICPortFlag = &cli.IntFlag{
+		Name:     "quic.port",
+		Usage:    "Enables the experimental QUIC transport for P2P connections on the given UDP port",
+		Category: flags.NetworkingCategory,
+	}
 
 	// Console
 	JSpathFlag = &flags.DirectoryFlag{
@@ -877,6 +1001,18 @@ var (
 		Value:    ethconfig.Defaults.GPO.IgnorePrice.Int64(),
 		Category: flags.GasPriceCategory,
 	}
+	GpoStrategyFlag = &cli.StringFlag{
+		Name:     "gpo.strategy",
+		Usage:    "Priority fee estimation strategy (percentile, mempool)",
+		Value:    ethconfig.Defaults.GPO.Strategy,
+		Category: flags.GasPriceCategory,
+	}
+	GpoInclusionBlocksFlag = &cli.IntFlag{
+		Name:     "gpo.inclusionblocks",
+		Usage:    "Number of upcoming blocks the mempool strategy targets for inclusion",
+		Value:    ethconfig.Defaults.GPO.InclusionBlocks,
+		Category: flags.GasPriceCategory,
+	}
 
 	// Metrics flags
 	MetricsEnabledFlag = &cli.BoolFlag{
@@ -1125,6 +1261,9 @@ func setListenAddress(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(DiscoveryPortFlag.Name) {
 		cfg.DiscAddr = fmt.Sprintf(":%d", ctx.Int(DiscoveryPortFlag.Name))
 	}
+	if ctx.IsSet(QUICPortFlag.Name) {
+		cfg.QUICAddr = fmt.Sprintf(":%d", ctx.Int(QUICPortFlag.Name))
+	}
 }
 
 // setNAT creates a port mapper from command line flags.
@@ -1193,6 +1332,8 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(HTTPPathPrefixFlag.Name) {
 		cfg.HTTPPathPrefix = ctx.String(HTTPPathPrefixFlag.Name)
 	}
+	cfg.HTTPTLS = makeTLSConfig(ctx, cfg.HTTPTLS, HTTPTLSCertFlag, HTTPTLSKeyFlag, HTTPTLSClientCAFlag)
+	cfg.AuthTLS = makeTLSConfig(ctx, cfg.AuthTLS, AuthTLSCertFlag, AuthTLSKeyFlag, AuthTLSClientCAFlag)
 	if ctx.IsSet(AllowUnprotectedTxs.Name) {
 		cfg.AllowUnprotectedTxs = ctx.Bool(AllowUnprotectedTxs.Name)
 	}
@@ -1204,6 +1345,19 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(RPCJobLimitFlag.Name) {
+		cfg.RPCJobLimit = ctx.Int(RPCJobLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCJobTimeoutFlag.Name) {
+		cfg.RPCJobTimeout = ctx.Duration(RPCJobTimeoutFlag.Name)
+	}
+	if ctx.IsSet(RPCJobRetentionFlag.Name) {
+		cfg.RPCJobRetention = ctx.Duration(RPCJobRetentionFlag.Name)
+	}
+	if ctx.IsSet(RPCCacheFlag.Name) {
+		cfg.RPCCacheSize = ctx.Int(RPCCacheFlag.Name)
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1243,6 +1397,29 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(WSPathPrefixFlag.Name) {
 		cfg.WSPathPrefix = ctx.String(WSPathPrefixFlag.Name)
 	}
+	cfg.WSTLS = makeTLSConfig(ctx, cfg.WSTLS, WSTLSCertFlag, WSTLSKeyFlag, WSTLSClientCAFlag)
+}
+
+// makeTLSConfig applies the given TLS flags on top of the configured TLS
+// settings of an RPC endpoint, returning them unchanged if no flag is set.
+func makeTLSConfig(ctx *cli.Context, config *node.TLSConfig, cert, key, clientCA *flags.DirectoryFlag) *node.TLSConfig {
+	if !ctx.IsSet(cert.Name) && !ctx.IsSet(key.Name) && !ctx.IsSet(clientCA.Name) {
+		return config
+	}
+	updated := new(node.TLSConfig)
+	if config != nil {
+		*updated = *config
+	}
+	if ctx.IsSet(cert.Name) {
+		updated.CertFile = ctx.String(cert.Name)
+	}
+	if ctx.IsSet(key.Name) {
+		updated.KeyFile = ctx.String(key.Name)
+	}
+	if ctx.IsSet(clientCA.Name) {
+		updated.ClientCAFile = ctx.String(clientCA.Name)
+	}
+	return updated
 }
 
 // setIPC creates an IPC path configuration from the set command line flags,
@@ -1320,6 +1497,18 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(MaxPendingPeersFlag.Name) {
 		cfg.MaxPendingPeers = ctx.Int(MaxPendingPeersFlag.Name)
 	}
+	if ctx.IsSet(BandwidthIngressFlag.Name) {
+		cfg.Bandwidth.Ingress = ctx.Int(BandwidthIngressFlag.Name) * 1024
+	}
+	if ctx.IsSet(BandwidthEgressFlag.Name) {
+		cfg.Bandwidth.Egress = ctx.Int(BandwidthEgressFlag.Name) * 1024
+	}
+	if ctx.IsSet(BandwidthPeerIngressFlag.Name) {
+		cfg.Bandwidth.PeerIngress = ctx.Int(BandwidthPeerIngressFlag.Name) * 1024
+	}
+	if ctx.IsSet(BandwidthPeerEgressFlag.Name) {
+		cfg.Bandwidth.PeerEgress = ctx.Int(BandwidthPeerEgressFlag.Name) * 1024
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.NoDiscovery = true
 	}
@@ -1328,6 +1517,7 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	flags.CheckExclusive(ctx, DiscoveryV5Flag, NoDiscoverFlag)
 	cfg.DiscoveryV4 = ctx.Bool(DiscoveryV4Flag.Name)
 	cfg.DiscoveryV5 = ctx.Bool(DiscoveryV5Flag.Name)
+	cfg.DiscoveryV5Topics = ctx.Bool(DiscoveryV5TopicsFlag.Name)
 
 	if netrestrict := ctx.String(NetrestrictFlag.Name); netrestrict != "" {
 		list, err := netutil.ParseNetlist(netrestrict)
@@ -1336,6 +1526,12 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		}
 		cfg.NetRestrict = list
 	}
+	if ctx.IsSet(AllowlistFlag.Name) {
+		cfg.Allowlist = ctx.String(AllowlistFlag.Name)
+	}
+	if ctx.IsSet(NetCaptureFlag.Name) {
+		cfg.CaptureDir = ctx.String(NetCaptureFlag.Name)
+	}
 
 	if ctx.Bool(DeveloperFlag.Name) {
 		// --dev mode can't use p2p networking.
@@ -1452,6 +1648,12 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
 	if ctx.IsSet(GpoIgnoreGasPriceFlag.Name) {
 		cfg.IgnorePrice = big.NewInt(ctx.Int64(GpoIgnoreGasPriceFlag.Name))
 	}
+	if ctx.IsSet(GpoStrategyFlag.Name) {
+		cfg.Strategy = ctx.String(GpoStrategyFlag.Name)
+	}
+	if ctx.IsSet(GpoInclusionBlocksFlag.Name) {
+		cfg.InclusionBlocks = ctx.Int(GpoInclusionBlocksFlag.Name)
+	}
 }
 
 func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
@@ -1644,6 +1846,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(StateSchemeFlag.Name) {
 		cfg.StateScheme = ctx.String(StateSchemeFlag.Name)
 	}
+	if ctx.IsSet(StateAccessProfileFlag.Name) {
+		cfg.StateAccessProfile = ctx.Bool(StateAccessProfileFlag.Name)
+	}
 	// Parse transaction history flag, if user is still using legacy config
 	// file with 'TxLookupLimit' configured, copy the value to 'TransactionHistory'.
 	if cfg.TransactionHistory == ethconfig.Defaults.TransactionHistory && cfg.TxLookupLimit != ethconfig.Defaults.TxLookupLimit {
@@ -1668,6 +1873,13 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(LogNoHistoryFlag.Name) {
 		cfg.LogNoHistory = true
 	}
+	if ctx.IsSet(AddressIndexFlag.Name) {
+		cfg.AddressIndex = ctx.Bool(AddressIndexFlag.Name)
+	}
+	if ctx.IsSet(AddressIndexInternalFlag.Name) {
+		cfg.AddressIndex = true
+		cfg.AddressIndexInternal = ctx.Bool(AddressIndexInternalFlag.Name)
+	}
 	if ctx.IsSet(LogExportCheckpointsFlag.Name) {
 		cfg.LogExportCheckpoints = ctx.String(LogExportCheckpointsFlag.Name)
 	}
@@ -1852,6 +2064,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.VMTraceJsonConfig = ctx.String(VMTraceJsonConfigFlag.Name)
 		}
 	}
+	if cfg.AddressIndexInternal && cfg.VMTrace != "" {
+		Fatalf("--%s can't be used with --%s", AddressIndexInternalFlag.Name, VMTraceFlag.Name)
+	}
 }
 
 // MakeBeaconLightConfig constructs a beacon light client config based on the
@@ -2292,3 +2507,24 @@ func MakeTrieDatabase(ctx *cli.Context, disk ethdb.Database, preimage bool, read
 	}
 	return triedb.NewDatabase(disk, config)
 }
+
+// MakeBinaryTrieDatabase constructs a trie database holding the binary tree,
+// which is only supported in path mode.
+func MakeBinaryTrieDatabase(ctx *cli.Context, disk ethdb.Database, preimage bool, readOnly bool) *triedb.Database {
+	scheme, err := rawdb.ParseStateScheme(ctx.String(StateSchemeFlag.Name), disk)
+	if err != nil {
+		Fatalf("%v", err)
+	}
+	if scheme != rawdb.PathScheme {
+		Fatalf("Binary tree requires the path-based state scheme, have %q", scheme)
+	}
+	config := &triedb.Config{
+		Preimages: preimage,
+		IsBinary:  true,
+		PathDB:    pathdb.Defaults,
+	}
+	if readOnly {
+		config.PathDB = pathdb.ReadOnly
+	}
+	return triedb.NewDatabase(disk, config)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/pebble"
@@ -68,6 +69,15 @@ func BenchmarkInsertChain_ring1000_memdb(b *testing.B) {
 func BenchmarkInsertChain_ring1000_diskdb(b *testing.B) {
 	benchInsertChain(b, true, genTxRing(1000))
 }
+func BenchmarkInsertChain_storage_hash(b *testing.B) {
+	benchInsertChainStorage(b, rawdb.HashScheme, 200, 4)
+}
+func BenchmarkInsertChain_storage_path(b *testing.B) {
+	benchInsertChainStorage(b, rawdb.PathScheme, 200, 4)
+}
+func BenchmarkInsertChain_storage_path_wide(b *testing.B) {
+	benchInsertChainStorage(b, rawdb.PathScheme, 500, 1)
+}
 
 var (
 	// This is the content of the genesis block used by the benchmarks.
@@ -351,3 +361,63 @@ func benchReadChain(b *testing.B, full bool, count uint64) {
 		db.Close()
 	}
 }
+
+// benchStorageCode is a contract storing the current block number into the
+// slot specified by the first word of the calldata.
+var benchStorageCode = []byte{
+	byte(vm.NUMBER),
+	byte(vm.PUSH1), 0x00,
+	byte(vm.CALLDATALOAD),
+	byte(vm.SSTORE),
+	byte(vm.STOP),
+}
+
+// benchInsertChainStorage measures the insertion of blocks modifying the
+// storage of many contracts, stressing the state root computation and commit
+// with lots of storage tries changed in every block. The construction of the
+// pathdb diff layer from the committed node set is included in the timings,
+// but it is not parallelized by the commit pipeline.
+func benchInsertChainStorage(b *testing.B, scheme string, ncontracts int, nslots int) {
+	alloc := types.GenesisAlloc{benchRootAddr: {Balance: benchRootFunds}}
+	contracts := make([]common.Address, ncontracts)
+	for i := range contracts {
+		contracts[i] = common.BigToAddress(big.NewInt(int64(0x10000 + i)))
+		alloc[contracts[i]] = types.Account{Code: benchStorageCode, Balance: big.NewInt(0)}
+	}
+	gspec := &Genesis{
+		Config:   params.TestChainConfig,
+		GasLimit: 60_000_000,
+		Alloc:    alloc,
+	}
+	_, chain, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), b.N, func(i int, gen *BlockGen) {
+		signer := gen.Signer()
+		for _, contract := range contracts {
+			for j := 0; j < nslots; j++ {
+				// Touch fresh slots in every block to keep growing the tries
+				slot := common.BigToHash(big.NewInt(int64(i*nslots + j)))
+				tx, err := types.SignNewTx(benchRootKey, signer, &types.LegacyTx{
+					Nonce:    gen.TxNonce(benchRootAddr),
+					To:       &contract,
+					Gas:      100_000,
+					GasPrice: gen.header.BaseFee,
+					Data:     slot.Bytes(),
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+				gen.AddTx(tx)
+			}
+		}
+	})
+	chainman, err := NewBlockChain(rawdb.NewMemoryDatabase(), gspec, ethash.NewFaker(), DefaultConfig().WithStateScheme(scheme))
+	if err != nil {
+		b.Fatalf("failed to create chain: %v", err)
+	}
+	defer chainman.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	if i, err := chainman.InsertChain(chain); err != nil {
+		b.Fatalf("insert error (block %d): %v\n", i, err)
+	}
+}
@@ -18,6 +18,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -119,10 +120,11 @@ var (
 )
 
 const (
-	bodyCacheLimit     = 256
-	blockCacheLimit    = 256
-	receiptsCacheLimit = 32
-	txLookupCacheLimit = 1024
+	bodyCacheLimit      = 256
+	blockCacheLimit     = 256
+	receiptsCacheLimit  = 32
+	txLookupCacheLimit  = 1024
+	internalsCacheLimit = 256
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
@@ -193,6 +195,16 @@ type BlockChainConfig struct {
 	// If the value is zero, all transactions of the entire chain will be indexed.
 	// If the value is -1, indexing is disabled.
 	TxLookupLimit int64
+
+	// AddressIndex enables indexing transactions by sender and recipient
+	// address, maintained alongside the transaction indexes over the same
+	// block range. It requires transaction indexing to be enabled.
+	AddressIndex bool
+
+	// AddressIndexInternal additionally indexes the participants of internal
+	// calls. They are recorded during block execution, so blocks imported
+	// before enabling it are only indexed by sender and recipient.
+	AddressIndexInternal bool
 }
 
 // DefaultConfig returns the default config.
@@ -322,6 +334,10 @@ type BlockChain struct {
 	txLookupLock  sync.RWMutex
 	txLookupCache *lru.Cache[common.Hash, txLookup]
 
+	// internalsCache holds the internal call participants of recently executed
+	// blocks until they are written into the address-transaction index.
+	internalsCache *lru.Cache[common.Hash, [][]common.Address]
+
 	stopping      atomic.Bool // false if chain is running, true when stopped
 	procInterrupt atomic.Bool // interrupt signaler for block processing
 
@@ -347,7 +363,19 @@ func NewBlockChain(db ethdb.Database, genesis *Genesis, engine consensus.Engine,
 	if err != nil {
 		return nil, err
 	}
-	triedb := triedb.NewDatabase(db, cfg.triedbConfig(enableVerkle))
+	enableBinary, err := EnableBinaryTreeAtGenesis(db, genesis)
+	if err != nil {
+		return nil, err
+	}
+	if enableBinary && cfg.StateScheme != rawdb.PathScheme {
+		return nil, errors.New("binary tree requires the path-based state scheme")
+	}
+	if cfg.AddressIndexInternal && cfg.VmConfig.Tracer != nil {
+		return nil, errors.New("internal call indexing is not supported with a live tracer")
+	}
+	tdbConfig := cfg.triedbConfig(enableVerkle)
+	tdbConfig.IsBinary = enableBinary
+	triedb := triedb.NewDatabase(db, tdbConfig)
 
 	// Write the supplied genesis to the database if it has not been initialized
 	// yet. The corresponding chain config will be returned, either from the
@@ -366,19 +394,20 @@ func NewBlockChain(db ethdb.Database, genesis *Genesis, engine consensus.Engine,
 	log.Info("")
 
 	bc := &BlockChain{
-		chainConfig:   chainConfig,
-		cfg:           cfg,
-		db:            db,
-		triedb:        triedb,
-		triegc:        prque.New[int64, common.Hash](nil),
-		chainmu:       syncx.NewClosableMutex(),
-		bodyCache:     lru.NewCache[common.Hash, *types.Body](bodyCacheLimit),
-		bodyRLPCache:  lru.NewCache[common.Hash, rlp.RawValue](bodyCacheLimit),
-		receiptsCache: lru.NewCache[common.Hash, []*types.Receipt](receiptsCacheLimit),
-		blockCache:    lru.NewCache[common.Hash, *types.Block](blockCacheLimit),
-		txLookupCache: lru.NewCache[common.Hash, txLookup](txLookupCacheLimit),
-		engine:        engine,
-		logger:        cfg.VmConfig.Tracer,
+		chainConfig:    chainConfig,
+		cfg:            cfg,
+		db:             db,
+		triedb:         triedb,
+		triegc:         prque.New[int64, common.Hash](nil),
+		chainmu:        syncx.NewClosableMutex(),
+		bodyCache:      lru.NewCache[common.Hash, *types.Body](bodyCacheLimit),
+		bodyRLPCache:   lru.NewCache[common.Hash, rlp.RawValue](bodyCacheLimit),
+		receiptsCache:  lru.NewCache[common.Hash, []*types.Receipt](receiptsCacheLimit),
+		blockCache:     lru.NewCache[common.Hash, *types.Block](blockCacheLimit),
+		txLookupCache:  lru.NewCache[common.Hash, txLookup](txLookupCacheLimit),
+		internalsCache: lru.NewCache[common.Hash, [][]common.Address](internalsCacheLimit),
+		engine:         engine,
+		logger:         cfg.VmConfig.Tracer,
 	}
 	bc.hc, err = NewHeaderChain(db, chainConfig, engine, bc.insertStopped)
 	if err != nil {
@@ -394,6 +423,12 @@ func NewBlockChain(db ethdb.Database, genesis *Genesis, engine consensus.Engine,
 	if genesisHeader == nil {
 		return nil, ErrNoGenesis
 	}
+	// The binary tree can't enumerate the storage of an account, so deletion
+	// is only supported for the accounts without any committed storage, which
+	// EIP-6780 guarantees.
+	if enableBinary && !chainConfig.IsCancun(common.Big0, genesisHeader.Time) {
+		return nil, errors.New("binary tree requires cancun to be active at genesis")
+	}
 	bc.genesisBlock = types.NewBlockWithHeader(genesisHeader)
 
 	bc.currentBlock.Store(nil)
@@ -520,6 +555,10 @@ func NewBlockChain(db ethdb.Database, genesis *Genesis, engine consensus.Engine,
 	}
 
 	// Start tx indexer if it's enabled.
+	if bc.cfg.AddressIndex && bc.cfg.TxLookupLimit < 0 {
+		log.Warn("Address transaction index requires transaction indexing, disabling")
+		bc.cfg.AddressIndex = false
+	}
 	if bc.cfg.TxLookupLimit >= 0 {
 		bc.txIndexer = newTxIndexer(uint64(bc.cfg.TxLookupLimit), bc)
 	}
@@ -1170,6 +1209,13 @@ func (bc *BlockChain) Export(w io.Writer) error {
 
 // ExportN writes a subset of the active chain to the given writer.
 func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
+	return bc.ExportRange(context.Background(), w, first, last, nil)
+}
+
+// ExportRange writes a subset of the active chain to the given writer like
+// ExportN, aborting once ctx is cancelled. If progress is non-nil, it is invoked
+// with the number of blocks written so far after every block.
+func (bc *BlockChain) ExportRange(ctx context.Context, w io.Writer, first uint64, last uint64, progress func(exported uint64)) error {
 	if first > last {
 		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
 	}
@@ -1181,6 +1227,9 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 		reported   = time.Now()
 	)
 	for nr := first; nr <= last; nr++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		block := bc.GetBlockByNumber(nr)
 		if block == nil {
 			return fmt.Errorf("export failed on #%d: not found", nr)
@@ -1192,6 +1241,9 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 		if err := block.EncodeRLP(w); err != nil {
 			return err
 		}
+		if progress != nil {
+			progress(nr - first + 1)
+		}
 		if time.Since(reported) >= statsReportLimit {
 			log.Info("Exporting blocks", "exported", block.NumberU64()-first, "elapsed", common.PrettyDuration(time.Since(start)))
 			reported = time.Now()
@@ -1200,6 +1252,27 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 	return nil
 }
 
+// writeAddressTxEntries adds the address-transaction index entries of the given
+// block into the batch, including the internal call participants if they were
+// collected during execution.
+func (bc *BlockChain) writeAddressTxEntries(batch ethdb.KeyValueWriter, block *types.Block) {
+	internals, _ := bc.internalsCache.Get(block.Hash())
+	if internals != nil {
+		rawdb.WriteAddressTxInternals(batch, block.NumberU64(), block.Hash(), internals)
+	}
+	signer := types.MakeSigner(bc.chainConfig, block.Number(), block.Time())
+	rawdb.WriteAddressTxEntries(batch, rawdb.DeriveAddressTxEntries(signer, block.NumberU64(), block.Transactions(), internals))
+}
+
+// deleteAddressTxEntries adds the removal of the address-transaction index
+// entries of the given block into the batch.
+func (bc *BlockChain) deleteAddressTxEntries(batch ethdb.KeyValueWriter, block *types.Block) {
+	internals := rawdb.ReadAddressTxInternals(bc.db, block.NumberU64(), block.Hash())
+	signer := types.MakeSigner(bc.chainConfig, block.Number(), block.Time())
+	rawdb.DeleteAddressTxEntries(batch, rawdb.DeriveAddressTxEntries(signer, block.NumberU64(), block.Transactions(), internals))
+	rawdb.DeleteAddressTxInternals(batch, block.NumberU64(), block.Hash())
+}
+
 // writeHeadBlock injects a new head block into the current block chain. This method
 // assumes that the block is indeed a true head. It will also reset the head
 // header and the head snap sync block to this very same block if they are older
@@ -1213,6 +1286,9 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	rawdb.WriteHeadFastBlockHash(batch, block.Hash())
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
 	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	if bc.cfg.AddressIndex {
+		bc.writeAddressTxEntries(batch, block)
+	}
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
 	// Flush the whole batch into the disk, exit the node if failed
@@ -2039,14 +2115,27 @@ func (bc *BlockChain) processBlock(parentRoot common.Hash, block *types.Block, s
 		}()
 	}
 
+	// Collect the internal call participants for the address index if requested.
+	// Live tracers are rejected on startup in this mode, so the tracer slot is free.
+	var (
+		vmConfig  = bc.cfg.VmConfig
+		internals *internalCallTracer
+	)
+	if bc.cfg.AddressIndex && bc.cfg.AddressIndexInternal {
+		internals = new(internalCallTracer)
+		vmConfig.Tracer = internals.hooks()
+	}
 	// Process block using the parent state as reference point
 	pstart := time.Now()
-	res, err := bc.processor.Process(block, statedb, bc.cfg.VmConfig)
+	res, err := bc.processor.Process(block, statedb, vmConfig)
 	if err != nil {
 		bc.reportBlock(block, res, err)
 		return nil, err
 	}
 	ptime := time.Since(pstart)
+	if internals != nil && len(internals.txs) == len(block.Transactions()) {
+		bc.internalsCache.Add(block.Hash(), internals.txs)
+	}
 
 	vstart := time.Now()
 	if err := bc.validator.ValidateState(block, statedb, res, false); err != nil {
@@ -2433,6 +2522,7 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Header) error
 		}
 	}
 	// Undo old blocks in reverse order
+	addrBatch := bc.db.NewBatch()
 	for i := 0; i < len(oldChain); i++ {
 		// Collect all the deleted transactions
 		block := bc.GetBlock(oldChain[i].Hash(), oldChain[i].Number.Uint64())
@@ -2442,6 +2532,9 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Header) error
 		for _, tx := range block.Transactions() {
 			deletedTxs = append(deletedTxs, tx.Hash())
 		}
+		if bc.cfg.AddressIndex {
+			bc.deleteAddressTxEntries(addrBatch, block)
+		}
 		// Collect deleted logs and emit them for new integrations
 		if logs := bc.collectLogs(block, true); len(logs) > 0 {
 			// Emit revertals latest first, older then
@@ -2450,6 +2543,11 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Header) error
 			// TODO(karalabe): Hook into the reverse emission part
 		}
 	}
+	// The address index is keyed by block position, drop the entries of the
+	// old chain before the new chain overwrites them.
+	if err := addrBatch.Write(); err != nil {
+		log.Crit("Failed to delete address transaction indexes", "err", err)
+	}
 	// Apply new blocks in forward order
 	for i := len(newChain) - 1; i >= 1; i-- {
 		// Collect all the included transactions
@@ -19,6 +19,7 @@ package core
 import (
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -369,6 +370,45 @@ func (bc *BlockChain) TxIndexDone() bool {
 	return progress.Done()
 }
 
+// GetAddressTransactions retrieves at most limit address-transaction index
+// entries of the given address in ascending order, starting at the given block
+// number and transaction index and ending at the last block (inclusive). Blocks
+// below the index tail are not covered, entries left behind by chain reorgs
+// are skipped.
+func (bc *BlockChain) GetAddressTransactions(address common.Address, first uint64, index uint32, last uint64, limit int) ([]rawdb.AddressTxEntry, error) {
+	if !bc.cfg.AddressIndex {
+		return nil, errors.New("address transaction index is not enabled")
+	}
+	tail := rawdb.ReadAddressTxIndexTail(bc.db)
+	if tail == nil {
+		return nil, errors.New("address transaction index is not initialized")
+	}
+	if first < *tail {
+		first, index = *tail, 0
+	}
+	var entries []rawdb.AddressTxEntry
+	for len(entries) < limit {
+		want := limit - len(entries)
+		batch := rawdb.ReadAddressTxEntries(bc.db, address, first, index, last, want)
+		for _, entry := range batch {
+			if number := rawdb.ReadTxLookupEntry(bc.db, entry.TxHash); number != nil && *number == entry.BlockNumber {
+				entries = append(entries, entry)
+			}
+		}
+		if len(batch) < want {
+			break // no more entries in range
+		}
+		// Continue after the last retrieved entry
+		final := batch[len(batch)-1]
+		if final.TxIndex == math.MaxUint32 {
+			first, index = final.BlockNumber+1, 0
+		} else {
+			first, index = final.BlockNumber, final.TxIndex+1
+		}
+	}
+	return entries, nil
+}
+
 // HasState checks if state trie is fully present in the database or not.
 func (bc *BlockChain) HasState(hash common.Hash) bool {
 	_, err := bc.statedb.OpenTrie(hash)
@@ -423,6 +463,58 @@ func (bc *BlockChain) HistoricState(root common.Hash) (*state.StateDB, error) {
 	return state.New(root, state.NewHistoricDatabase(bc.db, bc.triedb))
 }
 
+// StateDiff returns the state mutations made by the given block. They are derived
+// from the state history if it's available, otherwise both the state of the block
+// and the state of its parent must be available. The diff of the genesis block
+// contains the entire genesis allocation.
+func (bc *BlockChain) StateDiff(header *types.Header) (*state.StateDiff, error) {
+	parentRoot := types.EmptyRootHash
+	if header.Number.Sign() > 0 {
+		parent := bc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+		if parent == nil {
+			return nil, fmt.Errorf("block %d has no parent", header.Number)
+		}
+		parentRoot = parent.Root
+	}
+	accounts, err := state.DiffStates(bc.triedb, parentRoot, header.Root)
+	if err != nil {
+		return nil, err
+	}
+	return &state.StateDiff{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash,
+		Root:       header.Root,
+		Accounts:   accounts,
+	}, nil
+}
+
+// RevertStateDiff returns the state mutations undoing the given block, for the
+// consumers of the state diffs to roll back a block removed by a reorg. In path
+// mode, the mutations are taken from the state history of the block. Otherwise,
+// both the state of the block and the state of its parent must be available.
+func (bc *BlockChain) RevertStateDiff(header *types.Header) (*state.StateDiff, error) {
+	if header.Number.Sign() == 0 {
+		return nil, errors.New("genesis block can't be reverted")
+	}
+	parent := bc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("block %d has no parent", header.Number)
+	}
+	accounts, err := state.RevertStates(bc.triedb, header.Root, parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	return &state.StateDiff{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash,
+		Root:       parent.Root,
+		Reverted:   true,
+		Accounts:   accounts,
+	}, nil
+}
+
 // Config retrieves the chain's fork configuration.
 func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
 
@@ -487,6 +579,20 @@ func (bc *BlockChain) HistoryPruningCutoff() (uint64, common.Hash) {
 	return pt.BlockNumber, pt.BlockHash
 }
 
+// EarliestAvailableBlock returns the number of the first block for which bodies
+// and receipts are available. Below the history pruning cutoff, history can still
+// be served from the era1 files present in the era directory.
+func (bc *BlockChain) EarliestAvailableBlock() uint64 {
+	cutoff, _ := bc.HistoryPruningCutoff()
+	if cutoff == 0 {
+		return 0
+	}
+	if store := rawdb.EraStore(bc.db); store != nil {
+		return store.HistoryStart(cutoff)
+	}
+	return cutoff
+}
+
 // TrieDB retrieves the low level trie database used for data storage.
 func (bc *BlockChain) TrieDB() *triedb.Database {
 	return bc.triedb
@@ -47,6 +47,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb/pebble"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/bintrie"
 	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
 )
@@ -4515,3 +4516,194 @@ func TestGetCanonicalReceipt(t *testing.T) {
 		}
 	}
 }
+
+// TestBinaryTreeChain tests that a chain storing its state in the binary tree
+// since genesis can be imported and reopened.
+func TestBinaryTreeChain(t *testing.T) {
+	var (
+		engine = beacon.New(ethash.NewFaker())
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(100000000000000000)
+
+		// The contract stores the block number into slot 1
+		contract = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		code     = []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0x01, byte(vm.SSTORE), byte(vm.STOP)}
+
+		config = *params.MergedTestChainConfig
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				address:  {Balance: funds},
+				contract: {Code: code, Storage: map[common.Hash]common.Hash{{0x2}: {0x2}}},
+			},
+		}
+		recipient = common.Address{0x02}
+	)
+	config.EnableBinaryTreeAtGenesis = true
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 5, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), recipient, big.NewInt(1000), params.TxGas, b.header.BaseFee, nil), types.HomesteadSigner{}, key)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(address), contract, big.NewInt(0), 50000, b.header.BaseFee, nil), types.HomesteadSigner{}, key)
+		b.AddTx(tx)
+	})
+	if _, err := NewBlockChain(rawdb.NewMemoryDatabase(), gspec, engine, DefaultConfig().WithStateScheme(rawdb.HashScheme)); err == nil {
+		t.Fatal("Binary tree accepted in hash mode")
+	}
+	legacy := *params.TestChainConfig
+	legacy.EnableBinaryTreeAtGenesis = true
+	if _, err := NewBlockChain(rawdb.NewMemoryDatabase(), &Genesis{Config: &legacy}, engine, DefaultConfig().WithStateScheme(rawdb.PathScheme)); err == nil {
+		t.Fatal("Binary tree accepted without cancun")
+	}
+	db := rawdb.NewMemoryDatabase()
+	chain, err := NewBlockChain(db, gspec, engine, DefaultConfig().WithStateScheme(rawdb.PathScheme))
+	if err != nil {
+		t.Fatalf("Failed to create tester chain: %v", err)
+	}
+	if !chain.TrieDB().IsBinary() {
+		t.Fatal("Binary tree not enabled")
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("Block %d: failed to insert into chain: %v", n, err)
+	}
+	check := func(chain *BlockChain) {
+		statedb, err := chain.State()
+		if err != nil {
+			t.Fatalf("Failed to open head state: %v", err)
+		}
+		if have := statedb.GetBalance(recipient); have.Uint64() != 5000 {
+			t.Fatalf("Recipient balance mismatch: have %v, want 5000", have)
+		}
+		if have := statedb.GetNonce(address); have != 10 {
+			t.Fatalf("Sender nonce mismatch: have %d, want 10", have)
+		}
+		if have := statedb.GetState(contract, common.Hash{0x2}); have != (common.Hash{0x2}) {
+			t.Fatalf("Genesis slot mismatch: have %x", have)
+		}
+		if have := statedb.GetState(contract, common.BigToHash(big.NewInt(1))); have != common.BigToHash(big.NewInt(5)) {
+			t.Fatalf("Stored slot mismatch: have %x", have)
+		}
+		if !bytes.Equal(statedb.GetCode(contract), code) {
+			t.Fatal("Contract code mismatch")
+		}
+	}
+	check(chain)
+	chain.Stop()
+
+	// Reopen the chain, the binary tree must be picked up from the stored config
+	chain, err = NewBlockChain(db, nil, engine, DefaultConfig().WithStateScheme(rawdb.PathScheme))
+	if err != nil {
+		t.Fatalf("Failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if !chain.TrieDB().IsBinary() {
+		t.Fatal("Binary tree not enabled after restart")
+	}
+	check(chain)
+}
+
+// TestBinaryTreeOverlayChain tests that a chain converting its state into the
+// binary tree since genesis reads the genesis state from the Merkle-Patricia
+// trie and writes all the changes into the binary tree.
+func TestBinaryTreeOverlayChain(t *testing.T) {
+	var (
+		engine = beacon.New(ethash.NewFaker())
+
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(100000000000000000)
+
+		// The contract stores the block number into slot 1
+		contract = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		code     = []byte{byte(vm.NUMBER), byte(vm.PUSH1), 0x01, byte(vm.SSTORE), byte(vm.STOP)}
+
+		config = *params.MergedTestChainConfig
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				address:  {Balance: funds},
+				contract: {Code: code, Storage: map[common.Hash]common.Hash{{0x2}: {0x2}}},
+			},
+		}
+		recipient = common.Address{0x02}
+	)
+	config.EnableBinaryTreeOverlayAtGenesis = true
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 5, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(address), recipient, big.NewInt(1000), params.TxGas, b.header.BaseFee, nil), types.HomesteadSigner{}, key)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(address), contract, big.NewInt(0), 50000, b.header.BaseFee, nil), types.HomesteadSigner{}, key)
+		b.AddTx(tx)
+	})
+	db := rawdb.NewMemoryDatabase()
+	chain, err := NewBlockChain(db, gspec, engine, DefaultConfig().WithStateScheme(rawdb.PathScheme))
+	if err != nil {
+		t.Fatalf("Failed to create tester chain: %v", err)
+	}
+	if !chain.TrieDB().IsBinary() {
+		t.Fatal("Binary tree not enabled")
+	}
+	// The genesis state is the Merkle-Patricia trie of the allocation
+	mpt := *params.MergedTestChainConfig
+	if have, want := chain.Genesis().Root(), (&Genesis{Config: &mpt, Alloc: gspec.Alloc}).ToBlock().Root(); have != want {
+		t.Fatalf("Genesis root mismatch: have %x, want %x", have, want)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("Block %d: failed to insert into chain: %v", n, err)
+	}
+	check := func(chain *BlockChain) {
+		statedb, err := chain.State()
+		if err != nil {
+			t.Fatalf("Failed to open head state: %v", err)
+		}
+		if have := statedb.GetBalance(recipient); have.Uint64() != 5000 {
+			t.Fatalf("Recipient balance mismatch: have %v, want 5000", have)
+		}
+		if have := statedb.GetNonce(address); have != 10 {
+			t.Fatalf("Sender nonce mismatch: have %d, want 10", have)
+		}
+		if have := statedb.GetState(contract, common.Hash{0x2}); have != (common.Hash{0x2}) {
+			t.Fatalf("Genesis slot mismatch: have %x", have)
+		}
+		if have := statedb.GetState(contract, common.BigToHash(big.NewInt(1))); have != common.BigToHash(big.NewInt(5)) {
+			t.Fatalf("Stored slot mismatch: have %x", have)
+		}
+		if !bytes.Equal(statedb.GetCode(contract), code) {
+			t.Fatal("Contract code mismatch")
+		}
+		// The changes are written into the binary tree, the untouched genesis
+		// state is only available in the base trie.
+		tr, err := bintrie.NewBinaryTrie(chain.CurrentBlock().Root, chain.TrieDB())
+		if err != nil {
+			t.Fatalf("Failed to open binary tree: %v", err)
+		}
+		if acc, _ := tr.GetAccount(recipient); acc == nil || acc.Balance.Uint64() != 5000 {
+			t.Fatalf("Recipient not written into the binary tree: %v", acc)
+		}
+		if val, _ := tr.GetStorage(contract, common.BigToHash(big.NewInt(1)).Bytes()); !bytes.Equal(val, []byte{5}) {
+			t.Fatalf("Stored slot not written into the binary tree: %x", val)
+		}
+		if val, _ := tr.GetStorage(contract, common.Hash{0x2}.Bytes()); len(val) != 0 {
+			t.Fatalf("Genesis slot copied into the binary tree: %x", val)
+		}
+	}
+	check(chain)
+	chain.Stop()
+
+	// Reopen the chain, the transition must be picked up from the stored config
+	chain, err = NewBlockChain(db, nil, engine, DefaultConfig().WithStateScheme(rawdb.PathScheme))
+	if err != nil {
+		t.Fatalf("Failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if !chain.TrieDB().IsBinary() {
+		t.Fatal("Binary tree not enabled after restart")
+	}
+	check(chain)
+}
@@ -124,7 +124,7 @@ func (b *BlockGen) addTx(bc *BlockChain, vmConfig vm.Config, tx *types.Transacti
 	}
 	// Merge the tx-local access event into the "block-local" one, in order to collect
 	// all values, so that the witness can be built.
-	if b.statedb.Database().TrieDB().IsVerkle() {
+	if b.statedb.AccessEvents() != nil {
 		b.statedb.AccessEvents().Merge(evm.AccessEvents)
 	}
 	b.txs = append(b.txs, tx)
@@ -426,8 +426,9 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		return block, b.receipts
 	}
 
-	// Forcibly use hash-based state scheme for retaining all nodes in disk.
-	triedb := triedb.NewDatabase(db, triedb.HashDefaults)
+	// Forcibly use hash-based state scheme for retaining all nodes in disk,
+	// unless the chain stores its state in the binary tree.
+	triedb := triedb.NewDatabase(db, generateTrieConfig(config))
 	defer triedb.Close()
 
 	for i := 0; i < n; i++ {
@@ -467,12 +468,22 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 	return cm.chain, cm.receipts
 }
 
+// generateTrieConfig returns the trie database config used for generating the
+// chain. The binary tree is only supported in path mode, which retains all the
+// nodes in disk as well since every generated block is committed.
+func generateTrieConfig(config *params.ChainConfig) *triedb.Config {
+	if config != nil && config.IsBinaryTreeGenesis() {
+		return triedb.BinaryDefaults
+	}
+	return triedb.HashDefaults
+}
+
 // GenerateChainWithGenesis is a wrapper of GenerateChain which will initialize
 // genesis block to database first according to the provided genesis specification
 // then generate chain on top.
 func GenerateChainWithGenesis(genesis *Genesis, engine consensus.Engine, n int, gen func(int, *BlockGen)) (ethdb.Database, []*types.Block, []types.Receipts) {
 	db := rawdb.NewMemoryDatabase()
-	triedb := triedb.NewDatabase(db, triedb.HashDefaults)
+	triedb := triedb.NewDatabase(db, generateTrieConfig(genesis.Config))
 	defer triedb.Close()
 	_, err := genesis.Commit(db, triedb)
 	if err != nil {
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/overlay"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
@@ -127,7 +128,7 @@ func ReadGenesis(db ethdb.Database) (*Genesis, error) {
 }
 
 // hashAlloc computes the state root according to the genesis specification.
-func hashAlloc(ga *types.GenesisAlloc, isVerkle bool) (common.Hash, error) {
+func hashAlloc(ga *types.GenesisAlloc, isVerkle bool, isBinary bool) (common.Hash, error) {
 	// If a genesis-time verkle trie is requested, create a trie config
 	// with the verkle trie enabled so that the tree can be initialized
 	// as such.
@@ -137,11 +138,13 @@ func hashAlloc(ga *types.GenesisAlloc, isVerkle bool) (common.Hash, error) {
 			PathDB:   pathdb.Defaults,
 			IsVerkle: true,
 		}
+	} else if isBinary {
+		config = triedb.BinaryDefaults
 	}
 	// Create an ephemeral in-memory database for computing hash,
 	// all the derived states will be discarded to not pollute disk.
 	emptyRoot := types.EmptyRootHash
-	if isVerkle {
+	if isVerkle || isBinary {
 		emptyRoot = types.EmptyVerkleHash
 	}
 	db := rawdb.NewMemoryDatabase()
@@ -166,7 +169,7 @@ func hashAlloc(ga *types.GenesisAlloc, isVerkle bool) (common.Hash, error) {
 // generated states will be persisted into the given database.
 func flushAlloc(ga *types.GenesisAlloc, triedb *triedb.Database) (common.Hash, error) {
 	emptyRoot := types.EmptyRootHash
-	if triedb.IsVerkle() {
+	if triedb.IsVerkle() || triedb.IsBinary() {
 		emptyRoot = types.EmptyVerkleHash
 	}
 	statedb, err := state.New(emptyRoot, state.NewDatabase(triedb, nil))
@@ -198,6 +201,27 @@ func flushAlloc(ga *types.GenesisAlloc, triedb *triedb.Database) (common.Hash, e
 	return root, nil
 }
 
+// flushOverlayAlloc persists the genesis allocation into the Merkle-Patricia
+// trie, which is frozen as the read-only base of the binary tree overlay, and
+// marks the genesis state as being in transition into the binary tree.
+func flushOverlayAlloc(ga *types.GenesisAlloc, db ethdb.Database) (common.Hash, error) {
+	// The base trie is never modified after genesis, so it is stored in the
+	// hash-based layout regardless of the state scheme of the binary tree,
+	// whose nodes live in a separate namespace.
+	base := triedb.NewDatabase(db, triedb.HashDefaults)
+	defer base.Close()
+
+	root, err := flushAlloc(ga, base)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	ts := &overlay.TransitionState{Started: true, BaseRoot: root}
+	if err := overlay.StoreTransitionState(db, root, ts); err != nil {
+		return common.Hash{}, err
+	}
+	return root, nil
+}
+
 func getGenesisState(db ethdb.Database, blockhash common.Hash) (alloc types.GenesisAlloc, err error) {
 	blob := rawdb.ReadGenesisStateSpec(db, blockhash)
 	if len(blob) != 0 {
@@ -449,9 +473,21 @@ func (g *Genesis) IsVerkle() bool {
 	return g.Config.IsVerkleGenesis()
 }
 
+// IsBinary indicates whether the state is stored in a binary tree since
+// genesis time.
+func (g *Genesis) IsBinary() bool {
+	return g.Config.IsBinaryTreeGenesis()
+}
+
+// IsBinaryOverlay indicates whether the genesis state is stored in the
+// Merkle-Patricia trie, serving as the base of the binary tree overlay.
+func (g *Genesis) IsBinaryOverlay() bool {
+	return g.Config.EnableBinaryTreeOverlayAtGenesis
+}
+
 // ToBlock returns the genesis block according to genesis specification.
 func (g *Genesis) ToBlock() *types.Block {
-	root, err := hashAlloc(&g.Alloc, g.IsVerkle())
+	root, err := hashAlloc(&g.Alloc, g.IsVerkle(), g.IsBinary() && !g.IsBinaryOverlay())
 	if err != nil {
 		panic(err)
 	}
@@ -539,7 +575,15 @@ func (g *Genesis) Commit(db ethdb.Database, triedb *triedb.Database) (*types.Blo
 		return nil, errors.New("can't start clique chain without signers")
 	}
 	// flush the data to disk and compute the state root
-	root, err := flushAlloc(&g.Alloc, triedb)
+	var (
+		root common.Hash
+		err  error
+	)
+	if g.IsBinaryOverlay() {
+		root, err = flushOverlayAlloc(&g.Alloc, db)
+	} else {
+		root, err = flushAlloc(&g.Alloc, triedb)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -595,6 +639,28 @@ func EnableVerkleAtGenesis(db ethdb.Database, genesis *Genesis) (bool, error) {
 	return false, nil
 }
 
+// EnableBinaryTreeAtGenesis indicates whether the state should be stored in the
+// binary tree since genesis, either directly or through the overlay. This is a
+// temporary solution only for testing the binary tree on development networks.
+//
+// In production networks (mainnet and public testnets), the binary tree is never
+// enabled at genesis, making this function irrelevant in those cases.
+func EnableBinaryTreeAtGenesis(db ethdb.Database, genesis *Genesis) (bool, error) {
+	if genesis != nil {
+		if genesis.Config == nil {
+			return false, errGenesisNoConfig
+		}
+		return genesis.Config.IsBinaryTreeGenesis(), nil
+	}
+	if ghash := rawdb.ReadCanonicalHash(db, 0); ghash != (common.Hash{}) {
+		chainCfg := rawdb.ReadChainConfig(db, ghash)
+		if chainCfg != nil {
+			return chainCfg.IsBinaryTreeGenesis(), nil
+		}
+	}
+	return false, nil
+}
+
 // DefaultGenesisBlock returns the Ethereum main net genesis block.
 func DefaultGenesisBlock() *Genesis {
 	return &Genesis{
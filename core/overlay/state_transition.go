@@ -60,6 +60,7 @@ func (ts *TransitionState) Copy() *TransitionState {
 		CurrentSlotHash:       ts.CurrentSlotHash,
 		CurrentPreimageOffset: ts.CurrentPreimageOffset,
 		StorageProcessed:      ts.StorageProcessed,
+		BaseRoot:              ts.BaseRoot,
 	}
 	if ts.CurrentAccountAddress != nil {
 		addr := *ts.CurrentAccountAddress
@@ -103,3 +104,13 @@ func LoadTransitionState(db ethdb.KeyValueReader, root common.Hash, isVerkle boo
 	}
 	return ts
 }
+
+// StoreTransitionState persists the transition state associated with the given
+// state root hash into the database.
+func StoreTransitionState(db ethdb.KeyValueWriter, root common.Hash, ts *TransitionState) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(ts); err != nil {
+		return err
+	}
+	return rawdb.WriteVerkleTransitionState(db, root, buf.Bytes())
+}
@@ -285,6 +285,33 @@ func DeleteTxIndexTail(db ethdb.KeyValueWriter) {
 	}
 }
 
+// ReadAddressTxIndexTail retrieves the number of oldest indexed block
+// whose transactions has been indexed by address.
+func ReadAddressTxIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(addressTxIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteAddressTxIndexTail stores the number of oldest block indexed by
+// address into database.
+func WriteAddressTxIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(addressTxIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the address transaction index tail", "err", err)
+	}
+}
+
+// DeleteAddressTxIndexTail deletes the number of oldest block indexed by
+// address from database.
+func DeleteAddressTxIndexTail(db ethdb.KeyValueWriter) {
+	if err := db.Delete(addressTxIndexTailKey); err != nil {
+		log.Crit("Failed to delete the address transaction index tail", "err", err)
+	}
+}
+
 // ReadHeaderRange returns the rlp-encoded headers, starting at 'number', and going
 // backwards towards genesis. This method assumes that the caller already has
 // placed a cap on count, to prevent DoS issues.
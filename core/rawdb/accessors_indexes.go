@@ -134,6 +134,182 @@ func DeleteAllTxLookupEntries(db ethdb.KeyValueStore, condition func(common.Hash
 	}
 }
 
+// Roles an address can take in a transaction, stored as a bitmap in the
+// address-transaction index.
+const (
+	AddressTxSender    = 1 << iota // Address is the sender of the transaction
+	AddressTxRecipient             // Address is the recipient or the created contract
+	AddressTxInternal              // Address takes part in an internal call
+)
+
+// AddressTxEntry is an entry of the address-transaction index, linking an
+// address with a transaction it took part in.
+type AddressTxEntry struct {
+	Address     common.Address
+	BlockNumber uint64
+	TxIndex     uint32
+	TxHash      common.Hash
+	Roles       uint8
+}
+
+// DeriveAddressTxEntries creates the address-transaction index entries for the
+// given transactions of a block. Internal call participants are optional, if
+// specified, the list has to contain an item for every transaction.
+func DeriveAddressTxEntries(signer types.Signer, number uint64, txs types.Transactions, internal [][]common.Address) []AddressTxEntry {
+	var entries []AddressTxEntry
+	for i, tx := range txs {
+		roles := make(map[common.Address]uint8)
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			log.Warn("Failed to derive transaction sender", "number", number, "index", i, "err", err)
+		} else {
+			roles[sender] |= AddressTxSender
+		}
+		if to := tx.To(); to != nil {
+			roles[*to] |= AddressTxRecipient
+		} else if err == nil {
+			roles[crypto.CreateAddress(sender, tx.Nonce())] |= AddressTxRecipient
+		}
+		if internal != nil {
+			for _, addr := range internal[i] {
+				roles[addr] |= AddressTxInternal
+			}
+		}
+		for addr, role := range roles {
+			entries = append(entries, AddressTxEntry{
+				Address:     addr,
+				BlockNumber: number,
+				TxIndex:     uint32(i),
+				TxHash:      tx.Hash(),
+				Roles:       role,
+			})
+		}
+	}
+	return entries
+}
+
+// WriteAddressTxEntries stores the given address-transaction index entries.
+func WriteAddressTxEntries(db ethdb.KeyValueWriter, entries []AddressTxEntry) {
+	for _, entry := range entries {
+		value := append(entry.TxHash.Bytes(), entry.Roles)
+		if err := db.Put(addressTxKey(entry.Address, entry.BlockNumber, entry.TxIndex), value); err != nil {
+			log.Crit("Failed to store address transaction entry", "err", err)
+		}
+	}
+}
+
+// DeleteAddressTxEntries removes the given address-transaction index entries.
+func DeleteAddressTxEntries(db ethdb.KeyValueWriter, entries []AddressTxEntry) {
+	for _, entry := range entries {
+		if err := db.Delete(addressTxKey(entry.Address, entry.BlockNumber, entry.TxIndex)); err != nil {
+			log.Crit("Failed to delete address transaction entry", "err", err)
+		}
+	}
+}
+
+// ReadAddressTxEntries retrieves at most limit index entries of the given
+// address in ascending order, starting at the given block number and transaction
+// index and ending at the last block (inclusive).
+func ReadAddressTxEntries(db ethdb.Iteratee, address common.Address, first uint64, index uint32, last uint64, limit int) []AddressTxEntry {
+	prefix := append(addressTxPrefix, address.Bytes()...)
+	start := addressTxKey(address, first, index)[len(prefix):]
+
+	it := db.NewIterator(prefix, start)
+	defer it.Release()
+
+	var entries []AddressTxEntry
+	for len(entries) < limit && it.Next() {
+		key, value := it.Key(), it.Value()
+		if len(key) != len(prefix)+12 || len(value) != common.HashLength+1 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > last {
+			break
+		}
+		entries = append(entries, AddressTxEntry{
+			Address:     address,
+			BlockNumber: number,
+			TxIndex:     binary.BigEndian.Uint32(key[len(prefix)+8:]),
+			TxHash:      common.BytesToHash(value[:common.HashLength]),
+			Roles:       value[common.HashLength],
+		})
+	}
+	return entries
+}
+
+// ReadAddressTxInternals retrieves the internal call participants of every
+// transaction in the given block, as recorded during block execution.
+func ReadAddressTxInternals(db ethdb.KeyValueReader, number uint64, hash common.Hash) [][]common.Address {
+	data, _ := db.Get(addressTxInternalKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var internal [][]common.Address
+	if err := rlp.DecodeBytes(data, &internal); err != nil {
+		log.Error("Invalid internal call participants RLP", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return internal
+}
+
+// WriteAddressTxInternals stores the internal call participants of every
+// transaction in the given block.
+func WriteAddressTxInternals(db ethdb.KeyValueWriter, number uint64, hash common.Hash, internal [][]common.Address) {
+	data, err := rlp.EncodeToBytes(internal)
+	if err != nil {
+		log.Crit("Failed to encode internal call participants", "err", err)
+	}
+	if err := db.Put(addressTxInternalKey(number, hash), data); err != nil {
+		log.Crit("Failed to store internal call participants", "err", err)
+	}
+}
+
+// DeleteAddressTxInternals removes the internal call participants of the
+// given block.
+func DeleteAddressTxInternals(db ethdb.KeyValueWriter, number uint64, hash common.Hash) {
+	if err := db.Delete(addressTxInternalKey(number, hash)); err != nil {
+		log.Crit("Failed to delete internal call participants", "err", err)
+	}
+}
+
+// DeleteAllAddressTxEntries purges all the address-transaction indexes in the
+// database, including the recorded internal call participants. If condition
+// is specified, only the entries whose block number satisfies the condition
+// are removed.
+func DeleteAllAddressTxEntries(db ethdb.KeyValueStore, condition func(uint64) bool) {
+	deleteAddressTxRange(db, addressTxPrefix, common.AddressLength, common.AddressLength+12, condition)
+	deleteAddressTxRange(db, addressTxInternalPrefix, 0, 8+common.HashLength, condition)
+}
+
+// deleteAddressTxRange deletes the address-transaction index items under the
+// given prefix whose block number, stored at the given offset after the prefix,
+// satisfies the condition.
+func deleteAddressTxRange(db ethdb.KeyValueStore, prefix []byte, offset int, length int, condition func(uint64) bool) {
+	iter := NewKeyLengthIterator(db.NewIterator(prefix, nil), len(prefix)+length)
+	defer iter.Release()
+
+	batch := db.NewBatch()
+	for iter.Next() {
+		number := binary.BigEndian.Uint64(iter.Key()[len(prefix)+offset:])
+		if condition == nil || condition(number) {
+			batch.Delete(iter.Key())
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete address transaction entries", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	if batch.ValueSize() > 0 {
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to delete address transaction entries", "err", err)
+		}
+		batch.Reset()
+	}
+}
+
 // findTxInBlockBody traverses the given RLP-encoded block body, searching for
 // the transaction specified by its hash.
 func findTxInBlockBody(blockbody rlp.RawValue, target common.Hash) (*types.Transaction, uint64, error) {
@@ -187,3 +187,16 @@ func WriteTransitionStatus(db ethdb.KeyValueWriter, data []byte) {
 		log.Crit("Failed to store the eth2 transition status", "err", err)
 	}
 }
+
+// ReadStateAccessProfile retrieves the last persisted state access profile.
+func ReadStateAccessProfile(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(stateAccessProfileKey)
+	return data
+}
+
+// WriteStateAccessProfile stores the state access profile to the database.
+func WriteStateAccessProfile(db ethdb.KeyValueWriter, data []byte) {
+	if err := db.Put(stateAccessProfileKey, data); err != nil {
+		log.Crit("Failed to store the state access profile", "err", err)
+	}
+}
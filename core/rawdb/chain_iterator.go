@@ -18,6 +18,7 @@ package rawdb
 
 import (
 	"encoding/binary"
+	"math/big"
 	"runtime"
 	"sync/atomic"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -94,6 +96,24 @@ type blockTxHashes struct {
 // received from interrupt channel, the iteration will be aborted and result
 // channel will be closed.
 func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool, interrupt chan struct{}) chan *blockTxHashes {
+	return iterateBodies(db, from, to, reverse, interrupt, func(number uint64, body *types.Body) *blockTxHashes {
+		var hashes []common.Hash
+		for _, tx := range body.Transactions {
+			hashes = append(hashes, tx.Hash())
+		}
+		return &blockTxHashes{
+			hashes: hashes,
+			number: number,
+		}
+	})
+}
+
+// iterateBodies iterates over the (canon) block bodies in the block number(s)
+// given, and yields the results of the process function on a channel. The
+// bodies are processed concurrently, so results might arrive out of order. If
+// there is a signal received from interrupt channel, the iteration will be
+// aborted and result channel will be closed.
+func iterateBodies[T any](db ethdb.Database, from uint64, to uint64, reverse bool, interrupt chan struct{}, derive func(uint64, *types.Body) T) chan T {
 	// One thread sequentially reads data from db
 	type numberRlp struct {
 		number uint64
@@ -107,8 +127,8 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 		threads = uint64(cpus)
 	}
 	var (
-		rlpCh    = make(chan *numberRlp, threads*2)     // we send raw rlp over this channel
-		hashesCh = make(chan *blockTxHashes, threads*2) // send hashes over hashesCh
+		rlpCh    = make(chan *numberRlp, threads*2) // we send raw rlp over this channel
+		resultCh = make(chan T, threads*2)          // send results over resultCh
 	)
 	// lookup runs in one instance
 	lookup := func() {
@@ -139,7 +159,7 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 		defer func() {
 			// Last processor closes the result channel
 			if nThreadsAlive.Add(-1) == 0 {
-				close(hashesCh)
+				close(resultCh)
 			}
 		}()
 		for data := range rlpCh {
@@ -148,17 +168,9 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 				log.Warn("Failed to decode block body", "block", data.number, "error", err)
 				return
 			}
-			var hashes []common.Hash
-			for _, tx := range body.Transactions {
-				hashes = append(hashes, tx.Hash())
-			}
-			result := &blockTxHashes{
-				hashes: hashes,
-				number: data.number,
-			}
 			// Feed the block to the aggregator, or abort on interrupt
 			select {
-			case hashesCh <- result:
+			case resultCh <- derive(data.number, &body):
 			case <-interrupt:
 				return
 			}
@@ -168,7 +180,7 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 	for i := 0; i < int(threads); i++ {
 		go process()
 	}
-	return hashesCh
+	return resultCh
 }
 
 // indexTransactions creates txlookup indices of the specified block range.
@@ -363,6 +375,166 @@ func unindexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, in
 	unindexTransactions(db, from, to, interrupt, hook, false)
 }
 
+type blockAddressTxs struct {
+	number  uint64
+	hash    common.Hash
+	entries []AddressTxEntry
+}
+
+// iterateAddressTransactions iterates over all transactions in the (canon) block
+// number(s) given, and yields their address index entries on a channel. Internal
+// call participants are included if they were recorded during block execution.
+func iterateAddressTransactions(db ethdb.Database, config *params.ChainConfig, from uint64, to uint64, reverse bool, interrupt chan struct{}) chan *blockAddressTxs {
+	return iterateBodies(db, from, to, reverse, interrupt, func(number uint64, body *types.Body) *blockAddressTxs {
+		var (
+			hash   = ReadCanonicalHash(db, number)
+			time   uint64
+			header = ReadHeader(db, hash, number)
+		)
+		if header != nil {
+			time = header.Time
+		}
+		signer := types.MakeSigner(config, new(big.Int).SetUint64(number), time)
+		return &blockAddressTxs{
+			number:  number,
+			hash:    hash,
+			entries: DeriveAddressTxEntries(signer, number, body.Transactions, ReadAddressTxInternals(db, number, hash)),
+		}
+	})
+}
+
+// IndexAddressTransactions creates the address-transaction indices of the specified
+// block range. The from is included while to is excluded.
+//
+// Like IndexTransactions, the canonical chain is iterated in reverse order, so
+// that the index tail can be written periodically and an interrupted procedure
+// can be resumed.
+func IndexAddressTransactions(db ethdb.Database, config *params.ChainConfig, from uint64, to uint64, interrupt chan struct{}, report bool) {
+	// short circuit for invalid range
+	if from >= to {
+		return
+	}
+	var (
+		entriesCh = iterateAddressTransactions(db, config, from, to, true, interrupt)
+		batch     = db.NewBatch()
+		start     = time.Now()
+		logged    = start.Add(-7 * time.Second)
+
+		// Since we iterate in reverse, we expect the first number to come
+		// in to be [to-1].
+		lastNum         = to
+		queue           = prque.New[int64, *blockAddressTxs](nil)
+		blocks, entries = 0, 0 // for stats reporting
+	)
+	for delivery := range entriesCh {
+		queue.Push(delivery, int64(delivery.number))
+		for !queue.Empty() {
+			// If the next available item is gapped, return
+			if _, priority := queue.Peek(); priority != int64(lastNum-1) {
+				break
+			}
+			delivery := queue.PopItem()
+			lastNum = delivery.number
+			WriteAddressTxEntries(batch, delivery.entries)
+			blocks++
+			entries += len(delivery.entries)
+
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				WriteAddressTxIndexTail(batch, lastNum) // Also write the tail here
+				if err := batch.Write(); err != nil {
+					log.Crit("Failed writing batch to db", "error", err)
+					return
+				}
+				batch.Reset()
+			}
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Indexing transactions by address", "blocks", blocks, "entries", entries, "tail", lastNum, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+	}
+	WriteAddressTxIndexTail(batch, lastNum)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed writing batch to db", "error", err)
+		return
+	}
+	logger := log.Debug
+	if report {
+		logger = log.Info
+	}
+	select {
+	case <-interrupt:
+		logger("Address transaction indexing interrupted", "blocks", blocks, "entries", entries, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
+	default:
+		logger("Indexed transactions by address", "blocks", blocks, "entries", entries, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// UnindexAddressTransactions removes the address-transaction indices of the
+// specified block range. The from is included while to is excluded.
+func UnindexAddressTransactions(db ethdb.Database, config *params.ChainConfig, from uint64, to uint64, interrupt chan struct{}, report bool) {
+	// short circuit for invalid range
+	if from >= to {
+		return
+	}
+	var (
+		entriesCh = iterateAddressTransactions(db, config, from, to, false, interrupt)
+		batch     = db.NewBatch()
+		start     = time.Now()
+		logged    = start.Add(-7 * time.Second)
+
+		// we expect the first number to come in to be [from].
+		nextNum         = from
+		queue           = prque.New[int64, *blockAddressTxs](nil)
+		blocks, entries = 0, 0 // for stats reporting
+	)
+	for delivery := range entriesCh {
+		queue.Push(delivery, -int64(delivery.number))
+		for !queue.Empty() {
+			// If the next available item is gapped, return
+			if _, priority := queue.Peek(); -priority != int64(nextNum) {
+				break
+			}
+			delivery := queue.PopItem()
+			nextNum = delivery.number + 1
+			DeleteAddressTxEntries(batch, delivery.entries)
+			DeleteAddressTxInternals(batch, delivery.number, delivery.hash)
+			blocks++
+			entries += len(delivery.entries)
+
+			// A batch counts the size of deletion as '1', so we need to flush more
+			// often than that.
+			if blocks%1000 == 0 {
+				WriteAddressTxIndexTail(batch, nextNum)
+				if err := batch.Write(); err != nil {
+					log.Crit("Failed writing batch to db", "error", err)
+					return
+				}
+				batch.Reset()
+			}
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Unindexing transactions by address", "blocks", blocks, "entries", entries, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+	}
+	WriteAddressTxIndexTail(batch, nextNum)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed writing batch to db", "error", err)
+		return
+	}
+	logger := log.Debug
+	if report {
+		logger = log.Info
+	}
+	select {
+	case <-interrupt:
+		logger("Address transaction unindexing interrupted", "blocks", blocks, "entries", entries, "tail", to, "elapsed", common.PrettyDuration(time.Since(start)))
+	default:
+		logger("Unindexed transactions by address", "blocks", blocks, "entries", entries, "tail", to, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
 // PruneTransactionIndex removes all tx index entries below a certain block number.
 func PruneTransactionIndex(db ethdb.Database, pruneBlock uint64) {
 	tail := ReadTxIndexTail(db)
@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"math"
 	"math/big"
 	"reflect"
 	"sort"
@@ -25,7 +26,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func TestChainIterator(t *testing.T) {
@@ -251,3 +254,63 @@ func TestPruneTransactionIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestIndexAddressTransactions(t *testing.T) {
+	var (
+		chainDB  = NewMemoryDatabase()
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		to       = common.BytesToAddress([]byte{0x11})
+		internal = common.BytesToAddress([]byte{0x22})
+		signer   = types.LatestSigner(params.TestChainConfig)
+	)
+	// Write a chain with a single transaction in each block, with an internal
+	// call recorded in block 3.
+	genesis := types.NewBlock(&types.Header{Number: big.NewInt(0)}, nil, nil, newTestHasher())
+	WriteBlock(chainDB, genesis)
+	WriteCanonicalHash(chainDB, genesis.Hash(), 0)
+	for i := uint64(1); i <= 10; i++ {
+		tx := types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: i, GasPrice: big.NewInt(1), Gas: 21000, To: &to})
+		block := types.NewBlock(&types.Header{Number: new(big.Int).SetUint64(i)}, &types.Body{Transactions: types.Transactions{tx}}, nil, newTestHasher())
+		WriteBlock(chainDB, block)
+		WriteCanonicalHash(chainDB, block.Hash(), i)
+		if i == 3 {
+			WriteAddressTxInternals(chainDB, i, block.Hash(), [][]common.Address{{internal}})
+		}
+	}
+	verify := func(addr common.Address, want int, roles uint8, tail uint64) {
+		t.Helper()
+		entries := ReadAddressTxEntries(chainDB, addr, 0, 0, math.MaxUint64, 100)
+		if len(entries) != want {
+			t.Fatalf("Address %x: entry count mismatch, want %d, got %d", addr, want, len(entries))
+		}
+		for _, entry := range entries {
+			if entry.Roles != roles {
+				t.Fatalf("Address %x: role mismatch, want %d, got %d", addr, roles, entry.Roles)
+			}
+		}
+		if number := ReadAddressTxIndexTail(chainDB); number == nil || *number != tail {
+			t.Fatalf("Address transaction tail mismatch")
+		}
+	}
+	IndexAddressTransactions(chainDB, params.TestChainConfig, 5, 11, nil, false)
+	verify(sender, 6, AddressTxSender, 5)
+	verify(to, 6, AddressTxRecipient, 5)
+	verify(internal, 0, AddressTxInternal, 5)
+
+	IndexAddressTransactions(chainDB, params.TestChainConfig, 0, 5, nil, false)
+	verify(sender, 10, AddressTxSender, 0)
+	verify(internal, 1, AddressTxInternal, 0)
+
+	// Check pagination from an intermediate position
+	entries := ReadAddressTxEntries(chainDB, sender, 4, 0, 7, 100)
+	if len(entries) != 4 || entries[0].BlockNumber != 4 || entries[3].BlockNumber != 7 {
+		t.Fatalf("Unexpected entries in range: %v", entries)
+	}
+	UnindexAddressTransactions(chainDB, params.TestChainConfig, 0, 5, nil, false)
+	verify(sender, 6, AddressTxSender, 5)
+	verify(internal, 0, AddressTxInternal, 5)
+
+	DeleteAllAddressTxEntries(chainDB, func(number uint64) bool { return number < 8 })
+	verify(sender, 3, AddressTxSender, 5)
+}
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb/eradb"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
@@ -81,6 +82,19 @@ func (frdb *freezerdb) Freeze() error {
 	return nil
 }
 
+// EraStore returns the era1 history backend of the database, or nil if the
+// database doesn't have one. Database wrappers can provide the backend of the
+// wrapped database by implementing an EraStore method.
+func EraStore(db ethdb.Database) *eradb.Store {
+	switch db := db.(type) {
+	case *freezerdb:
+		return db.eradb
+	case interface{ EraStore() *eradb.Store }:
+		return db.EraStore()
+	}
+	return nil
+}
+
 // nofreezedb is a database wrapper that disables freezer data retrievals.
 type nofreezedb struct {
 	ethdb.KeyValueStore
@@ -407,6 +421,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		storageTries       stat
 		codes              stat
 		txLookups          stat
+		addressTxs         stat
 		accountSnaps       stat
 		storageSnaps       stat
 		preimages          stat
@@ -468,6 +483,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			codes.Add(size)
 		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
 			txLookups.Add(size)
+		case bytes.HasPrefix(key, addressTxPrefix) && len(key) == (len(addressTxPrefix)+common.AddressLength+12):
+			addressTxs.Add(size)
+		case bytes.HasPrefix(key, addressTxInternalPrefix) && len(key) == (len(addressTxInternalPrefix)+8+common.HashLength):
+			addressTxs.Add(size)
 		case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
 			accountSnaps.Add(size)
 		case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
@@ -547,6 +566,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
 		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
 		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
+		{"Key-Value store", "Address transaction index", addressTxs.Size(), addressTxs.Count()},
 		{"Key-Value store", "Log index filter-map rows", filterMapRows.Size(), filterMapRows.Count()},
 		{"Key-Value store", "Log index last-block-of-map", filterMapLastBlock.Size(), filterMapLastBlock.Count()},
 		{"Key-Value store", "Log index block-lv", filterMapBlockLV.Size(), filterMapBlockLV.Count()},
@@ -601,10 +621,11 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 var knownMetadataKeys = [][]byte{
 	databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, headFinalizedBlockKey,
 	lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
-	snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
+	snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, addressTxIndexTailKey, fastTxLookupLimitKey,
 	uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
 	persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
 	filterMapsRangeKey, headStateHistoryIndexKey, VerkleTransitionStatePrefix,
+	stateAccessProfileKey,
 }
 
 // printChainMetadata prints out chain metadata to stderr.
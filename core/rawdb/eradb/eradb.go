@@ -22,7 +22,11 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common/lru"
@@ -46,6 +50,8 @@ type Store struct {
 	lru     lru.BasicLRU[uint64, *fileCacheEntry]
 	opening map[uint64]*fileCacheEntry
 	closing bool
+	epochs  []uint64 // Epochs present in the directory, as of the last refresh
+	listed  bool     // Whether the directory has been listed yet
 }
 
 type fileCacheEntry struct {
@@ -76,6 +82,99 @@ func New(datadir string) (*Store, error) {
 	return db, nil
 }
 
+// Dir returns the directory of the store.
+func (db *Store) Dir() string {
+	return db.datadir
+}
+
+// eraFileRE matches era1 file names, capturing the epoch.
+var eraFileRE = regexp.MustCompile(`^[^-]+-([0-9]{5,})-[0-9a-f]+\.era1$`)
+
+// Epochs returns the epochs for which an era1 file is present in the store
+// directory, in ascending order.
+func (db *Store) Epochs() ([]uint64, error) {
+	entries, err := os.ReadDir(db.datadir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var epochs []uint64
+	for _, entry := range entries {
+		m := eraFileRE.FindStringSubmatch(entry.Name())
+		if m == nil || entry.IsDir() {
+			continue
+		}
+		epoch, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		epochs = append(epochs, epoch)
+	}
+	slices.Sort(epochs)
+	return epochs, nil
+}
+
+// Refresh updates the list of the era1 files present in the store directory,
+// which HistoryStart is based on. It needs to be called after adding files to
+// the directory.
+func (db *Store) Refresh() error {
+	epochs, err := db.Epochs()
+	if err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.epochs, db.listed = epochs, true
+	return nil
+}
+
+// HistoryStart returns the first block of the contiguous range of era1 files
+// which ends at block number end (exclusive). If the file containing block end-1
+// is not present, end is returned.
+//
+// The directory is only listed once, the files added later are considered after
+// calling Refresh.
+func (db *Store) HistoryStart(end uint64) uint64 {
+	if end == 0 {
+		return 0
+	}
+	db.mu.Lock()
+	listed := db.listed
+	db.mu.Unlock()
+
+	if !listed {
+		if err := db.Refresh(); err != nil {
+			log.Warn("Failed to list era1 files", "dir", db.datadir, "err", err)
+			return end
+		}
+	}
+	db.mu.Lock()
+	epochs := db.epochs
+	db.mu.Unlock()
+
+	var (
+		start = end
+		next  = (end - 1) / uint64(era.MaxEra1Size)
+	)
+	for i := len(epochs) - 1; i >= 0; i-- {
+		if epochs[i] > next {
+			continue
+		}
+		if epochs[i] < next {
+			break
+		}
+		start = next * uint64(era.MaxEra1Size)
+		if next == 0 {
+			break
+		}
+		next--
+	}
+	return start
+}
+
 // Close closes all open era1 files in the cache.
 func (db *Store) Close() {
 	db.mu.Lock()
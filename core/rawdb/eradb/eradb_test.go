@@ -17,10 +17,13 @@
 package eradb
 
 import (
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -101,3 +104,42 @@ func TestEraDatabaseConcurrentOpenClose(t *testing.T) {
 	}()
 	wg.Wait()
 }
+
+func TestEraHistoryStart(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"mainnet-00000-5ec1ffb8.era1",
+		"mainnet-00002-5cb8a8a2.era1",
+		"mainnet-00003-d8b8a40b.era1",
+		"mainnet-00004-00000000.era1.tmp",
+		"checksums.txt",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	db, err := New(dir)
+	require.NoError(t, err)
+	defer db.Close()
+
+	epochs, err := db.Epochs()
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0, 2, 3}, epochs)
+
+	size := uint64(era.MaxEra1Size)
+	tests := []struct{ end, want uint64 }{
+		{0, 0},
+		{1, 0},
+		{size, 0},
+		{size + 1, size + 1}, // epoch 1 is missing
+		{3 * size, 2 * size},
+		{4*size - 100, 2 * size},
+		{4*size + 1, 4*size + 1},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, db.HistoryStart(test.end), "end %d", test.end)
+	}
+	// Added files are only considered after a refresh.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mainnet-00001-0a1b2c3d.era1"), nil, 0644))
+	assert.Equal(t, 2*size, db.HistoryStart(3*size+1))
+	require.NoError(t, db.Refresh())
+	assert.Equal(t, uint64(0), db.HistoryStart(3*size+1))
+}
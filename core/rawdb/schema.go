@@ -83,6 +83,10 @@ var (
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
+	// addressTxIndexTailKey tracks the oldest block whose transactions have been
+	// indexed by address.
+	addressTxIndexTailKey = []byte("AddressTransactionIndexTail")
+
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	// This flag is deprecated, it's kept to avoid reporting errors when inspect
 	// database.
@@ -100,6 +104,9 @@ var (
 	// snapSyncStatusFlagKey flags that status of snap sync.
 	snapSyncStatusFlagKey = []byte("SnapSyncStatus")
 
+	// stateAccessProfileKey tracks the last persisted state access profile.
+	stateAccessProfileKey = []byte("StateAccessProfile")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td (deprecated)
@@ -155,6 +162,10 @@ var (
 	// old log index
 	bloomBitsMetaPrefix = []byte("iB")
 
+	// address-transaction index
+	addressTxPrefix         = []byte("iA") // addressTxPrefix + address + num (uint64 big endian) + tx index (uint32 big endian) -> tx hash + roles
+	addressTxInternalPrefix = []byte("iI") // addressTxInternalPrefix + num (uint64 big endian) + hash -> internal call participants
+
 	preimageCounter     = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitsCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 	preimageMissCounter = metrics.NewRegisteredCounter("db/preimage/miss", nil)
@@ -213,6 +224,21 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// addressTxKey = addressTxPrefix + address + num (uint64 big endian) + tx index (uint32 big endian)
+func addressTxKey(address common.Address, number uint64, index uint32) []byte {
+	key := make([]byte, len(addressTxPrefix)+common.AddressLength+12)
+	copy(key, addressTxPrefix)
+	copy(key[len(addressTxPrefix):], address.Bytes())
+	binary.BigEndian.PutUint64(key[len(addressTxPrefix)+common.AddressLength:], number)
+	binary.BigEndian.PutUint32(key[len(addressTxPrefix)+common.AddressLength+8:], index)
+	return key
+}
+
+// addressTxInternalKey = addressTxInternalPrefix + num (uint64 big endian) + hash
+func addressTxInternalKey(number uint64, hash common.Hash) []byte {
+	return append(append(addressTxInternalPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
@@ -0,0 +1,274 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+)
+
+// The sources where state data can be served from.
+const (
+	AccessSourceSnapshot = "snapshot" // state snapshot, hash scheme only
+	AccessSourceDiff     = "diff"     // pathdb in-memory diff layers
+	AccessSourceDirty    = "dirty"    // pathdb dirty buffer of the disk layer
+	AccessSourceClean    = "clean"    // pathdb clean state cache
+	AccessSourceDisk     = "disk"     // pathdb persistent flat state
+	AccessSourceTrie     = "trie"     // trie traversal
+)
+
+// accessProfiler is the active profiler, nil if state access profiling is
+// disabled. It's a process-wide switch, similar to the metrics system, as the
+// state access is spread across many short-lived readers.
+var accessProfiler atomic.Pointer[AccessProfiler]
+
+// EnableAccessProfiler turns on the state access profiling and returns the
+// active profiler. If profiling is already enabled, the existing profiler is
+// returned with all the collected statistics retained.
+func EnableAccessProfiler() *AccessProfiler {
+	p := newAccessProfiler()
+	if !accessProfiler.CompareAndSwap(nil, p) {
+		return accessProfiler.Load()
+	}
+	return p
+}
+
+// DisableAccessProfiler turns off the state access profiling, discarding all
+// the collected statistics.
+func DisableAccessProfiler() {
+	accessProfiler.Store(nil)
+}
+
+// ActiveAccessProfiler returns the active profiler, or nil if state access
+// profiling is disabled.
+func ActiveAccessProfiler() *AccessProfiler {
+	return accessProfiler.Load()
+}
+
+// accessSources lists the sources of the state reads, in the order their
+// counters are kept in.
+var accessSources = [...]string{
+	AccessSourceSnapshot,
+	AccessSourceDiff,
+	AccessSourceDirty,
+	AccessSourceClean,
+	AccessSourceDisk,
+	AccessSourceTrie,
+}
+
+// accessSourceIndex returns the counter index of the given read source.
+func accessSourceIndex(source string) int {
+	for i, s := range accessSources {
+		if s == source {
+			return i
+		}
+	}
+	return 0 // unreachable, all sources are listed
+}
+
+const (
+	// accessProfileLimit is the maximum number of accounts tracked by the
+	// profiler, taking roughly 32MB of memory. Once it's reached, the least
+	// recently accessed accounts are evicted along with their statistics, so
+	// the frequently accessed ones are retained while rarely accessed accounts
+	// may be under-counted.
+	accessProfileLimit = 1 << 17
+
+	// accessProfileShards is the number of independently locked partitions of
+	// the tracked accounts, reducing the lock contention on the state read path.
+	accessProfileShards = 16
+)
+
+// accessStats tracks the access statistics of a single account, including the
+// access of the account itself and the storage trie belonging to it.
+type accessStats struct {
+	accountReads  [len(accessSources)]uint64
+	accountWrites uint64
+	storageReads  [len(accessSources)]uint64
+	storageWrites uint64
+}
+
+// accessShard is a partition of the accounts tracked by the profiler.
+type accessShard struct {
+	stats lru.BasicLRU[common.Address, *accessStats]
+	lock  sync.Mutex
+}
+
+// AccessProfiler collects the number of reads and writes per account and per
+// storage trie, along with the source where the reads are served from. At most
+// accessProfileLimit accounts are tracked, evicting the least recently accessed
+// ones.
+//
+// AccessProfiler is safe for concurrent use.
+type AccessProfiler struct {
+	shards [accessProfileShards]accessShard
+	start  time.Time
+	lock   sync.Mutex // Protects the start time
+}
+
+func newAccessProfiler() *AccessProfiler {
+	p := &AccessProfiler{start: time.Now()}
+	for i := range p.shards {
+		p.shards[i].stats = lru.NewBasicLRU[common.Address, *accessStats](accessProfileLimit / accessProfileShards)
+	}
+	return p
+}
+
+// shard returns the partition tracking the given account.
+func (p *AccessProfiler) shard(addr common.Address) *accessShard {
+	return &p.shards[addr[common.AddressLength-1]%accessProfileShards]
+}
+
+// entry returns the statistics of the given account, creating it if it's not
+// yet tracked. The caller must hold the lock of the shard.
+func (s *accessShard) entry(addr common.Address) *accessStats {
+	stats, ok := s.stats.Get(addr)
+	if !ok {
+		stats = new(accessStats)
+		s.stats.Add(addr, stats)
+	}
+	return stats
+}
+
+// accountRead records an account read served by the given source.
+func (p *AccessProfiler) accountRead(addr common.Address, source string) {
+	s := p.shard(addr)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.entry(addr).accountReads[accessSourceIndex(source)]++
+}
+
+// storageRead records a storage slot read served by the given source.
+func (p *AccessProfiler) storageRead(addr common.Address, source string) {
+	s := p.shard(addr)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.entry(addr).storageReads[accessSourceIndex(source)]++
+}
+
+// recordWrites records the account and storage mutations in a state update.
+func (p *AccessProfiler) recordWrites(update *stateUpdate) {
+	for addr := range update.accountsOrigin {
+		s := p.shard(addr)
+		s.lock.Lock()
+		s.entry(addr).accountWrites++
+		s.lock.Unlock()
+	}
+	for addr, slots := range update.storagesOrigin {
+		s := p.shard(addr)
+		s.lock.Lock()
+		s.entry(addr).storageWrites += uint64(len(slots))
+		s.lock.Unlock()
+	}
+}
+
+// Reset discards all the statistics collected so far.
+func (p *AccessProfiler) Reset() {
+	for i := range p.shards {
+		s := &p.shards[i]
+		s.lock.Lock()
+		s.stats.Purge()
+		s.lock.Unlock()
+	}
+	p.lock.Lock()
+	p.start = time.Now()
+	p.lock.Unlock()
+}
+
+// AccessProfileEntry is the access statistics of a single account or storage
+// trie in the report.
+type AccessProfileEntry struct {
+	Address common.Address    `json:"address"`
+	Reads   map[string]uint64 `json:"reads"`
+	Writes  uint64            `json:"writes"`
+	Total   uint64            `json:"total"`
+}
+
+// AccessProfileReport contains the most frequently accessed accounts and
+// storage tries since the profiling was started or last reset.
+type AccessProfileReport struct {
+	Since    time.Time             `json:"since"`
+	Accounts []*AccessProfileEntry `json:"accounts"`
+	Storages []*AccessProfileEntry `json:"storages"`
+}
+
+// Report returns the top n accounts and storage tries, ranked by the total
+// number of accesses. A non-positive n returns all tracked entries.
+func (p *AccessProfiler) Report(n int) *AccessProfileReport {
+	p.lock.Lock()
+	report := &AccessProfileReport{Since: p.start}
+	p.lock.Unlock()
+
+	for i := range p.shards {
+		s := &p.shards[i]
+		s.lock.Lock()
+		for _, addr := range s.stats.Keys() {
+			stats, _ := s.stats.Peek(addr)
+			if entry := newAccessProfileEntry(addr, &stats.accountReads, stats.accountWrites); entry.Total > 0 {
+				report.Accounts = append(report.Accounts, entry)
+			}
+			if entry := newAccessProfileEntry(addr, &stats.storageReads, stats.storageWrites); entry.Total > 0 {
+				report.Storages = append(report.Storages, entry)
+			}
+		}
+		s.lock.Unlock()
+	}
+	report.Accounts = topAccessProfileEntries(report.Accounts, n)
+	report.Storages = topAccessProfileEntries(report.Storages, n)
+	return report
+}
+
+func newAccessProfileEntry(addr common.Address, reads *[len(accessSources)]uint64, writes uint64) *AccessProfileEntry {
+	entry := &AccessProfileEntry{
+		Address: addr,
+		Reads:   make(map[string]uint64),
+		Writes:  writes,
+		Total:   writes,
+	}
+	for i, count := range reads {
+		if count > 0 {
+			entry.Reads[accessSources[i]] = count
+			entry.Total += count
+		}
+	}
+	return entry
+}
+
+// topAccessProfileEntries sorts the entries by the total number of accesses in
+// descending order and truncates the list to n items.
+func topAccessProfileEntries(entries []*AccessProfileEntry, n int) []*AccessProfileEntry {
+	slices.SortFunc(entries, func(a, b *AccessProfileEntry) int {
+		if a.Total != b.Total {
+			if a.Total > b.Total {
+				return -1
+			}
+			return 1
+		}
+		return a.Address.Cmp(b.Address)
+	})
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+func TestAccessProfiler(t *testing.T) {
+	profiler := EnableAccessProfiler()
+	defer DisableAccessProfiler()
+
+	if EnableAccessProfiler() != profiler {
+		t.Fatal("Expected the active profiler to be reused")
+	}
+	var (
+		tdb   = triedb.NewDatabase(rawdb.NewMemoryDatabase(), &triedb.Config{PathDB: pathdb.Defaults})
+		sdb   = NewDatabase(tdb, nil)
+		hot   = common.HexToAddress("0xaaaa")
+		cold  = common.HexToAddress("0xbbbb")
+		state = func(root common.Hash) *StateDB {
+			s, err := New(root, sdb)
+			if err != nil {
+				t.Fatalf("Failed to open state: %v", err)
+			}
+			return s
+		}
+	)
+	s := state(types.EmptyRootHash)
+	s.SetBalance(hot, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+	s.SetState(hot, common.HexToHash("0x01"), common.HexToHash("0x01"))
+	s.SetState(hot, common.HexToHash("0x02"), common.HexToHash("0x02"))
+	s.SetBalance(cold, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+	root, err := s.Commit(1, false, false)
+	if err != nil {
+		t.Fatalf("Failed to commit state: %v", err)
+	}
+	// Read the state back through fresh state objects, the data is expected
+	// to be served by the in-memory diff layer.
+	for i := 0; i < 3; i++ {
+		s = state(root)
+		s.GetBalance(hot)
+		s.GetState(hot, common.HexToHash("0x01"))
+	}
+	report := profiler.Report(1)
+	if len(report.Accounts) != 1 || len(report.Storages) != 1 {
+		t.Fatalf("Unexpected report size: %d accounts, %d storages", len(report.Accounts), len(report.Storages))
+	}
+	if report.Accounts[0].Address != hot {
+		t.Fatalf("Unexpected hottest account, want %x, got %x", hot, report.Accounts[0].Address)
+	}
+	if reads := report.Accounts[0].Reads[AccessSourceDiff]; reads != 3 {
+		t.Fatalf("Unexpected number of account reads from diff layer, want 3, got %d", reads)
+	}
+	if writes := report.Accounts[0].Writes; writes != 1 {
+		t.Fatalf("Unexpected number of account writes, want 1, got %d", writes)
+	}
+	storage := report.Storages[0]
+	if storage.Address != hot || storage.Reads[AccessSourceDiff] != 3 || storage.Writes != 2 {
+		t.Fatalf("Unexpected storage stats: %+v", storage)
+	}
+	// Ensure the statistics are dropped after reset
+	profiler.Reset()
+	if report := profiler.Report(0); len(report.Accounts) != 0 || len(report.Storages) != 0 {
+		t.Fatalf("Unexpected report after reset: %+v", report)
+	}
+}
+
+// Tests that the number of tracked accounts is capped, retaining the accounts
+// which are accessed frequently.
+func TestAccessProfilerLimit(t *testing.T) {
+	var (
+		p   = newAccessProfiler()
+		hot = common.HexToAddress("0xaaaa")
+	)
+	for i := 0; i < 2*accessProfileLimit; i++ {
+		p.accountRead(common.BigToAddress(big.NewInt(int64(i)+1<<32)), AccessSourceDisk)
+		if i%1024 == 0 {
+			p.accountRead(hot, AccessSourceClean)
+		}
+	}
+	report := p.Report(0)
+	if len(report.Accounts) > accessProfileLimit {
+		t.Fatalf("Too many tracked accounts: %d, limit %d", len(report.Accounts), accessProfileLimit)
+	}
+	if report.Accounts[0].Address != hot {
+		t.Fatalf("Unexpected hottest account, want %x, got %x", hot, report.Accounts[0].Address)
+	}
+}
@@ -28,9 +28,11 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/bintrie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/utils"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/database"
 )
 
 const (
@@ -193,7 +195,10 @@ func (db *CachingDB) Reader(stateRoot common.Hash) (Reader, error) {
 	// This reader offers improved performance but is optional and only
 	// partially useful if the snapshot data in path database is not
 	// fully generated.
-	if db.TrieDB().Scheme() == rawdb.PathScheme {
+	//
+	// The flat states only hold the binary overlay during the transition
+	// into the binary tree, the base trie must be consulted for the rest.
+	if db.TrieDB().Scheme() == rawdb.PathScheme && !inBinaryTransition(stateRoot, db.triedb) {
 		reader, err := db.triedb.StateReader(stateRoot)
 		if err == nil {
 			readers = append(readers, newFlatReader(reader))
@@ -228,6 +233,9 @@ func (db *CachingDB) ReadersWithCacheStats(stateRoot common.Hash) (ReaderWithSta
 
 // OpenTrie opens the main account trie at a specific root hash.
 func (db *CachingDB) OpenTrie(root common.Hash) (Trie, error) {
+	if db.triedb.IsBinary() {
+		return openBinaryTrie(root, db.triedb)
+	}
 	if db.triedb.IsVerkle() {
 		ts := overlay.LoadTransitionState(db.TrieDB().Disk(), root, db.triedb.IsVerkle())
 		if ts.InTransition() {
@@ -244,9 +252,69 @@ func (db *CachingDB) OpenTrie(root common.Hash) (Trie, error) {
 	return tr, nil
 }
 
+// openBinaryTrie opens the binary tree at a specific root hash. If the state
+// is still being converted from the Merkle-Patricia trie, the transition tree
+// is opened instead, which falls back to the frozen base trie for reads.
+func openBinaryTrie(root common.Hash, db *triedb.Database) (Trie, error) {
+	ts := overlay.LoadTransitionState(db.Disk(), root, true)
+	if !ts.InTransition() {
+		return bintrie.NewBinaryTrie(root, db)
+	}
+	// The transition starts from the base trie itself, with an empty overlay.
+	overlayRoot := root
+	if root == ts.BaseRoot {
+		overlayRoot = types.EmptyVerkleHash
+	}
+	tr, err := bintrie.NewBinaryTrie(overlayRoot, db)
+	if err != nil {
+		return nil, err
+	}
+	basedb := &mptNodeDatabase{disk: db.Disk()}
+	base, err := trie.NewStateTrie(trie.StateTrieID(ts.BaseRoot), basedb)
+	if err != nil {
+		return nil, err
+	}
+	return bintrie.NewTransitionTrie(base, tr, basedb), nil
+}
+
+// inBinaryTransition reports whether the state with the given root is still
+// being converted from the Merkle-Patricia trie into the binary tree.
+func inBinaryTransition(root common.Hash, db *triedb.Database) bool {
+	return db.IsBinary() && overlay.LoadTransitionState(db.Disk(), root, true).InTransition()
+}
+
+// mptNodeDatabase provides read access to the frozen Merkle-Patricia trie
+// persisted in the key-value store, which serves as the read-only base during
+// the transition into the binary tree. Both the path-based and hash-based
+// node layouts are supported.
+type mptNodeDatabase struct {
+	disk ethdb.KeyValueReader
+}
+
+// NodeReader implements database.NodeDatabase, the persisted trie is the only
+// accessible state.
+func (db *mptNodeDatabase) NodeReader(root common.Hash) (database.NodeReader, error) {
+	return db, nil
+}
+
+// Node implements database.NodeReader, retrieving the trie node with the given
+// path and hash from the key-value store.
+func (db *mptNodeDatabase) Node(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	var blob []byte
+	if owner == (common.Hash{}) {
+		blob = rawdb.ReadAccountTrieNode(db.disk, path)
+	} else {
+		blob = rawdb.ReadStorageTrieNode(db.disk, owner, path)
+	}
+	if len(blob) > 0 && crypto.Keccak256Hash(blob) == hash {
+		return blob, nil
+	}
+	return rawdb.ReadLegacyTrieNode(db.disk, hash), nil
+}
+
 // OpenStorageTrie opens the storage trie of an account.
 func (db *CachingDB) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self Trie) (Trie, error) {
-	if db.triedb.IsVerkle() {
+	if db.triedb.IsVerkle() || db.triedb.IsBinary() {
 		return self, nil
 	}
 	tr, err := trie.NewStateTrie(trie.StorageTrieID(stateRoot, crypto.Keccak256Hash(address.Bytes()), root), db.triedb)
@@ -294,6 +362,10 @@ func mustCopyTrie(t Trie) Trie {
 		return t.Copy()
 	case *trie.VerkleTrie:
 		return t.Copy()
+	case *bintrie.BinaryTrie:
+		return t.Copy()
+	case *bintrie.TransitionTrie:
+		return t.Copy()
 	default:
 		panic(fmt.Errorf("unknown trie type %T", t))
 	}
@@ -164,7 +164,7 @@ func newFlatReader(reader database.StateReader) *flatReader {
 //
 // The returned account might be nil if it's not existent.
 func (r *flatReader) Account(addr common.Address) (*types.StateAccount, error) {
-	account, err := r.reader.Account(crypto.Keccak256Hash(addr.Bytes()))
+	account, err := r.account(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -194,9 +194,7 @@ func (r *flatReader) Account(addr common.Address) (*types.StateAccount, error) {
 //
 // The returned storage slot might be empty if it's not existent.
 func (r *flatReader) Storage(addr common.Address, key common.Hash) (common.Hash, error) {
-	addrHash := crypto.Keccak256Hash(addr.Bytes())
-	slotHash := crypto.Keccak256Hash(key.Bytes())
-	ret, err := r.reader.Storage(addrHash, slotHash)
+	ret, err := r.storage(addr, key)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -214,6 +212,64 @@ func (r *flatReader) Storage(addr common.Address, key common.Hash) (common.Hash,
 	return value, nil
 }
 
+// locatedStateReader is implemented by the state readers which are capable of
+// reporting where the state data is served from, e.g. the path database.
+type locatedStateReader interface {
+	AccountWithLocation(hash common.Hash) (*types.SlimAccount, string, error)
+	StorageWithLocation(accountHash, storageHash common.Hash) ([]byte, string, error)
+}
+
+// account retrieves the account in the slim format from the underlying reader,
+// recording the access if the state access profiling is enabled.
+func (r *flatReader) account(addr common.Address) (*types.SlimAccount, error) {
+	hash := crypto.Keccak256Hash(addr.Bytes())
+
+	profiler := accessProfiler.Load()
+	if profiler == nil {
+		return r.reader.Account(hash)
+	}
+	var (
+		account *types.SlimAccount
+		source  = AccessSourceSnapshot
+		err     error
+	)
+	if located, ok := r.reader.(locatedStateReader); ok {
+		account, source, err = located.AccountWithLocation(hash)
+	} else {
+		account, err = r.reader.Account(hash)
+	}
+	if err == nil {
+		profiler.accountRead(addr, source)
+	}
+	return account, err
+}
+
+// storage retrieves the RLP-encoded storage slot from the underlying reader,
+// recording the access if the state access profiling is enabled.
+func (r *flatReader) storage(addr common.Address, key common.Hash) ([]byte, error) {
+	addrHash := crypto.Keccak256Hash(addr.Bytes())
+	slotHash := crypto.Keccak256Hash(key.Bytes())
+
+	profiler := accessProfiler.Load()
+	if profiler == nil {
+		return r.reader.Storage(addrHash, slotHash)
+	}
+	var (
+		blob   []byte
+		source = AccessSourceSnapshot
+		err    error
+	)
+	if located, ok := r.reader.(locatedStateReader); ok {
+		blob, source, err = located.StorageWithLocation(addrHash, slotHash)
+	} else {
+		blob, err = r.reader.Storage(addrHash, slotHash)
+	}
+	if err == nil {
+		profiler.storageRead(addr, source)
+	}
+	return blob, err
+}
+
 // trieReader implements the StateReader interface, providing functions to access
 // state from the referenced trie.
 //
@@ -238,7 +294,9 @@ func newTrieReader(root common.Hash, db *triedb.Database, cache *utils.PointCach
 		tr  Trie
 		err error
 	)
-	if !db.IsVerkle() {
+	if db.IsBinary() {
+		tr, err = openBinaryTrie(root, db)
+	} else if !db.IsVerkle() {
 		tr, err = trie.NewStateTrie(trie.StateTrieID(root), db)
 	} else {
 		// TODO @gballet determine the trie type (verkle or overlay) by transition state
@@ -278,7 +336,13 @@ func (r *trieReader) Account(addr common.Address) (*types.StateAccount, error) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
-	return r.account(addr)
+	account, err := r.account(addr)
+	if err == nil {
+		if profiler := accessProfiler.Load(); profiler != nil {
+			profiler.accountRead(addr, AccessSourceTrie)
+		}
+	}
+	return account, err
 }
 
 // Storage implements StateReader, retrieving the storage slot specified by the
@@ -295,7 +359,7 @@ func (r *trieReader) Storage(addr common.Address, key common.Hash) (common.Hash,
 		found bool
 		value common.Hash
 	)
-	if r.db.IsVerkle() {
+	if r.db.IsVerkle() || r.db.IsBinary() {
 		tr = r.mainTrie
 	} else {
 		tr, found = r.subTries[addr]
@@ -323,6 +387,9 @@ func (r *trieReader) Storage(addr common.Address, key common.Hash) (common.Hash,
 	if err != nil {
 		return common.Hash{}, err
 	}
+	if profiler := accessProfiler.Load(); profiler != nil {
+		profiler.storageRead(addr, AccessSourceTrie)
+	}
 	value.SetBytes(ret)
 	return value, nil
 }
@@ -0,0 +1,188 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// RepairedNode is a trie node which was found to be missing or corrupted in
+// the database, and has been regenerated from the flat state.
+type RepairedNode struct {
+	Owner   common.Hash // Owner of the trie, zero for the account trie
+	Path    []byte      // Path of the node within the trie
+	Hash    common.Hash // Hash of the regenerated node
+	Missing bool        // Whether the node was absent, otherwise it was corrupted
+}
+
+// trieRepairer compares the trie nodes regenerated from the flat state with
+// the ones persisted in the database. Without a batch, the discrepancies are
+// collected for reporting, otherwise the regenerated nodes are written into the
+// batch, which is flushed whenever it grows large enough.
+type trieRepairer struct {
+	db     ethdb.KeyValueReader
+	scheme string
+	batch  ethdb.Batch              // batch for rewriting the nodes, nil if only checking
+	nodes  []*RepairedNode          // damaged nodes found, if only checking
+	seen   map[common.Hash]struct{} // deduplicates shared nodes in hash mode
+	err    error                    // first error encountered while flushing the batch
+	lock   sync.Mutex
+}
+
+// check compares the regenerated node with the persisted one, tracking it or
+// rewriting it if the persisted one is absent or doesn't match.
+func (r *trieRepairer) check(owner common.Hash, path []byte, hash common.Hash, blob []byte) {
+	var stored []byte
+	switch r.scheme {
+	case rawdb.HashScheme:
+		stored = rawdb.ReadLegacyTrieNode(r.db, hash)
+	case rawdb.PathScheme:
+		if owner == (common.Hash{}) {
+			stored = rawdb.ReadAccountTrieNode(r.db, path)
+		} else {
+			stored = rawdb.ReadStorageTrieNode(r.db, owner, path)
+		}
+	}
+	if bytes.Equal(stored, blob) {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.scheme == rawdb.HashScheme {
+		if _, ok := r.seen[hash]; ok {
+			return
+		}
+		r.seen[hash] = struct{}{}
+	}
+	if r.batch == nil {
+		r.nodes = append(r.nodes, &RepairedNode{
+			Owner:   owner,
+			Path:    common.CopyBytes(path),
+			Hash:    hash,
+			Missing: len(stored) == 0,
+		})
+		return
+	}
+	rawdb.WriteTrieNode(r.batch, owner, path, hash, blob, r.scheme)
+	if r.batch.ValueSize() > ethdb.IdealBatchSize {
+		if err := r.batch.Write(); err != nil && r.err == nil {
+			r.err = err
+		}
+		r.batch.Reset()
+	}
+}
+
+// generate implements trieGeneratorFn, regenerating the trie with the leaves
+// from the flat state and checking every produced node against the database.
+func (r *trieRepairer) generate(db ethdb.KeyValueWriter, scheme string, owner common.Hash, in chan trieKV, out chan common.Hash) {
+	t := trie.NewStackTrie(func(path []byte, hash common.Hash, blob []byte) {
+		r.check(owner, path, hash, blob)
+	})
+	for leaf := range in {
+		t.Update(leaf.key[:], leaf.value)
+	}
+	out <- t.Hash()
+}
+
+// run regenerates the whole state trie from the flat state, checking all the
+// nodes against the database, and verifies the regenerated root.
+func (r *trieRepairer) run(db ethdb.KeyValueStore, root common.Hash) error {
+	base := &diskLayer{diskdb: db, root: root}
+	acctIt := base.AccountIterator(common.Hash{})
+	defer acctIt.Release()
+
+	got, err := generateTrieRoot(nil, r.scheme, acctIt, common.Hash{}, r.generate, func(_ ethdb.KeyValueWriter, accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error) {
+		storageIt := base.StorageIterator(accountHash, common.Hash{})
+		defer storageIt.Release()
+
+		return generateTrieRoot(nil, r.scheme, storageIt, accountHash, r.generate, nil, stat, false)
+	}, newGenerateStats(), true)
+	if err != nil {
+		return err
+	}
+	if err := acctIt.Error(); err != nil {
+		return err
+	}
+	if got != root {
+		return fmt.Errorf("state root hash mismatch: got %x, want %x", got, root)
+	}
+	return nil
+}
+
+// RepairTrie regenerates the whole state trie (the account trie along with all
+// the storage tries) from the persisted flat state, detecting the trie nodes
+// which are missing or corrupted in the database and rewriting them. The flat
+// state must be fully generated and correspond to the given state root. It is
+// applicable to both the legacy state snapshot and the path database, as they
+// share the same flat state layout on disk.
+//
+// The trie is regenerated twice: first to verify the flat state against the
+// expected root and find the damaged nodes, then to rewrite them. This way a
+// corrupted flat state won't be propagated into the tries, without holding the
+// regenerated nodes in memory. If dryRun is set, the discrepancies are only
+// reported without being fixed.
+func RepairTrie(db ethdb.KeyValueStore, scheme string, root common.Hash, dryRun bool) ([]*RepairedNode, error) {
+	if scheme != rawdb.HashScheme && scheme != rawdb.PathScheme {
+		return nil, fmt.Errorf("unsupported state scheme %q", scheme)
+	}
+	// Ensure the flat state is complete and matches the requested state
+	blob := rawdb.ReadSnapshotGenerator(db)
+	if len(blob) == 0 {
+		return nil, errors.New("missing flat state generator")
+	}
+	var generator journalGenerator
+	if err := rlp.DecodeBytes(blob, &generator); err != nil {
+		return nil, fmt.Errorf("failed to decode flat state generator: %v", err)
+	}
+	if !generator.Done {
+		return nil, ErrNotConstructed
+	}
+	if flatRoot := rawdb.ReadSnapshotRoot(db); flatRoot != root {
+		return nil, fmt.Errorf("flat state root mismatch: have %x, want %x", flatRoot, root)
+	}
+	checker := &trieRepairer{db: db, scheme: scheme, seen: make(map[common.Hash]struct{})}
+	if err := checker.run(db, root); err != nil {
+		return nil, err
+	}
+	if dryRun || len(checker.nodes) == 0 {
+		return checker.nodes, nil
+	}
+	// The regenerated state is verified, rewrite the broken nodes
+	writer := &trieRepairer{db: db, scheme: scheme, batch: db.NewBatch(), seen: make(map[common.Hash]struct{})}
+	if err := writer.run(db, root); err != nil {
+		return nil, err
+	}
+	if writer.err != nil {
+		return nil, writer.err
+	}
+	if err := writer.batch.Write(); err != nil {
+		return nil, err
+	}
+	log.Info("Repaired state trie", "root", root, "nodes", len(checker.nodes))
+	return checker.nodes, nil
+}
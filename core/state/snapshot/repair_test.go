@@ -0,0 +1,114 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+)
+
+func TestRepairTrie(t *testing.T) {
+	testRepairTrie(t, rawdb.HashScheme)
+	testRepairTrie(t, rawdb.PathScheme)
+}
+
+func testRepairTrie(t *testing.T, scheme string) {
+	var (
+		acc1   = hashData([]byte("acc-1"))
+		acc3   = hashData([]byte("acc-3"))
+		helper = newHelper(scheme)
+		keys   = []string{"key-1", "key-2", "key-3"}
+		vals   = []string{"val-1", "val-2", "val-3"}
+	)
+	stRoot := helper.makeStorageTrie("acc-1", keys, vals, true)
+	helper.addAccount("acc-1", &types.StateAccount{Balance: uint256.NewInt(1), Root: stRoot, CodeHash: types.EmptyCodeHash.Bytes()})
+	helper.addAccount("acc-2", &types.StateAccount{Balance: uint256.NewInt(2), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()})
+	helper.makeStorageTrie("acc-3", keys, vals, true)
+	helper.addAccount("acc-3", &types.StateAccount{Balance: uint256.NewInt(3), Root: stRoot, CodeHash: types.EmptyCodeHash.Bytes()})
+	helper.addSnapStorage("acc-1", keys, vals)
+	helper.addSnapStorage("acc-3", keys, vals)
+	root := helper.Commit()
+
+	// Mark the flat state as complete and aligned with the trie
+	blob, _ := rlp.EncodeToBytes(journalGenerator{Done: true})
+	rawdb.WriteSnapshotGenerator(helper.diskdb, blob)
+	rawdb.WriteSnapshotRoot(helper.diskdb, root)
+
+	nodes, err := RepairTrie(helper.diskdb, scheme, root, false)
+	if err != nil {
+		t.Fatalf("Failed to check healthy state: %v", err)
+	}
+	if len(nodes) != 0 {
+		t.Fatalf("Unexpected repairs in healthy state: %d", len(nodes))
+	}
+	healthy := rawdb.ReadTrieNode(helper.diskdb, acc1, nil, stRoot, scheme)
+
+	// Drop the storage root of account one and corrupt the one of account three.
+	// In hash mode the storage tries are shared, so only one of them applies.
+	rawdb.DeleteTrieNode(helper.diskdb, acc1, nil, stRoot, scheme)
+	rawdb.WriteTrieNode(helper.diskdb, acc3, nil, stRoot, []byte{0xde, 0xad}, scheme)
+
+	nodes, err = RepairTrie(helper.diskdb, scheme, root, true)
+	if err != nil {
+		t.Fatalf("Failed to check damaged state: %v", err)
+	}
+	want := 2
+	if scheme == rawdb.HashScheme {
+		want = 1
+	}
+	if len(nodes) != want {
+		t.Fatalf("Unexpected number of damaged nodes, want %d, got %d", want, len(nodes))
+	}
+	if bytes.Equal(rawdb.ReadTrieNode(helper.diskdb, acc1, nil, stRoot, scheme), healthy) {
+		t.Fatal("Dry run should not rewrite any node")
+	}
+	if _, err := RepairTrie(helper.diskdb, scheme, root, false); err != nil {
+		t.Fatalf("Failed to repair state: %v", err)
+	}
+	for _, owner := range []common.Hash{acc1, acc3} {
+		if !bytes.Equal(rawdb.ReadTrieNode(helper.diskdb, owner, nil, stRoot, scheme), healthy) {
+			t.Fatalf("Storage trie root of %x is not repaired", owner)
+		}
+	}
+	if nodes, _ = RepairTrie(helper.diskdb, scheme, root, false); len(nodes) != 0 {
+		t.Fatalf("Unexpected repairs after fixing: %d", len(nodes))
+	}
+	// Corrupt the account trie root node, ensure it's rewritten
+	healthyRoot := rawdb.ReadTrieNode(helper.diskdb, common.Hash{}, nil, root, scheme)
+	rawdb.WriteTrieNode(helper.diskdb, common.Hash{}, nil, root, []byte{0xde, 0xad}, scheme)
+	if nodes, err = RepairTrie(helper.diskdb, scheme, root, false); err != nil || len(nodes) != 1 {
+		t.Fatalf("Failed to repair the root node: %d nodes, %v", len(nodes), err)
+	}
+	if !bytes.Equal(rawdb.ReadTrieNode(helper.diskdb, common.Hash{}, nil, root, scheme), healthyRoot) {
+		t.Fatal("Account trie root node is not repaired")
+	}
+	// Corrupt the flat state, ensure nothing is written
+	rawdb.DeleteTrieNode(helper.diskdb, acc1, nil, stRoot, scheme)
+	rawdb.WriteStorageSnapshot(helper.diskdb, acc1, hashData([]byte("key-1")), []byte("val-x"))
+	if _, err := RepairTrie(helper.diskdb, scheme, root, false); err == nil {
+		t.Fatal("Expected failure with corrupted flat state")
+	}
+	if rawdb.HasTrieNode(helper.diskdb, acc1, nil, stRoot, scheme) {
+		t.Fatal("Trie node rewritten from corrupted flat state")
+	}
+}
@@ -124,8 +124,14 @@ func (s *stateObject) touch() {
 // subsequent reads to expand the same trie instead of reloading from disk.
 func (s *stateObject) getTrie() (Trie, error) {
 	if s.trie == nil {
-		// Assumes the primary account trie is already loaded
-		tr, err := s.db.db.OpenStorageTrie(s.db.originalRoot, s.address, s.data.Root, s.db.trie)
+		// Assumes the primary account trie is already loaded. Only the tries
+		// of verkle and binary mode contain the storage, don't touch it for MPT
+		// as it may be swapped concurrently by the account updates.
+		var self Trie
+		if s.db.db.TrieDB().IsVerkle() || s.db.db.TrieDB().IsBinary() {
+			self = s.db.trie
+		}
+		tr, err := s.db.db.OpenStorageTrie(s.db.originalRoot, s.address, s.data.Root, self)
 		if err != nil {
 			return nil, err
 		}
@@ -143,8 +149,9 @@ func (s *stateObject) getTrie() (Trie, error) {
 // from the prefetcher.
 func (s *stateObject) getPrefetchedTrie() Trie {
 	// If there's nothing to meaningfully return, let the user figure it out by
-	// pulling the trie from disk.
-	if (s.data.Root == types.EmptyRootHash && !s.db.db.TrieDB().IsVerkle()) || s.db.prefetcher == nil {
+	// pulling the trie from disk. In verkle mode the storage slots live in the
+	// shared main trie, which must not be swapped out for a prefetched copy.
+	if s.data.Root == types.EmptyRootHash || s.db.db.TrieDB().IsVerkle() || s.db.db.TrieDB().IsBinary() || s.db.prefetcher == nil {
 		return nil
 	}
 	// Attempt to retrieve the trie from the prefetcher
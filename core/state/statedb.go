@@ -21,12 +21,14 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"runtime"
 	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/overlay"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/stateless"
@@ -36,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/bintrie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/utils"
 	"github.com/holiman/uint256"
@@ -566,8 +569,13 @@ func (s *StateDB) GetTransientState(addr common.Address, key common.Hash) common
 
 // updateStateObject writes the given object to the trie.
 func (s *StateDB) updateStateObject(obj *stateObject) {
-	// Encode the account and update the account trie
-	if err := s.trie.UpdateAccount(obj.Address(), &obj.data, len(obj.code)); err != nil {
+	// Encode the account and update the account trie. The code size is part of
+	// the account in verkle mode, resolve it even if the code was not loaded.
+	codeLen := len(obj.code)
+	if s.db.TrieDB().IsVerkle() || s.db.TrieDB().IsBinary() {
+		codeLen = obj.CodeSize()
+	}
+	if err := s.trie.UpdateAccount(obj.Address(), &obj.data, codeLen); err != nil {
 		s.setError(fmt.Errorf("updateStateObject (%x) error: %v", obj.Address(), err))
 	}
 	if obj.dirtyCode {
@@ -815,11 +823,20 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// Process all storage updates concurrently. The state object update root
 	// method will internally call a blocking trie fetch from the prefetcher,
 	// so there's no need to explicitly wait for the prefetchers to finish.
+	//
+	// The updated objects are streamed back as soon as their storage roots are
+	// resolved, so the account trie is mutated in a pipelined fashion instead of
+	// waiting for the slowest storage trie.
 	var (
 		start   = time.Now()
 		workers errgroup.Group
+		shared  = s.db.TrieDB().IsVerkle() || s.db.TrieDB().IsBinary()
+		updated = make(chan *stateObject, len(s.mutations))
+
+		usedAddrs    []common.Address
+		deletedAddrs []common.Address
 	)
-	if s.db.TrieDB().IsVerkle() {
+	if shared {
 		// Whilst MPT storage tries are independent, Verkle has one single trie
 		// for all the accounts and all the storage slots merged together. The
 		// former can thus be simply parallelized, but updating the latter will
@@ -828,12 +845,19 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 		workers.SetLimit(1)
 	}
 	for addr, op := range s.mutations {
-		if op.applied || op.isDelete() {
+		if op.applied {
+			continue
+		}
+		op.applied = true
+		usedAddrs = append(usedAddrs, addr) // Copy needed for closure
+
+		if op.isDelete() {
+			deletedAddrs = append(deletedAddrs, addr)
 			continue
 		}
 		obj := s.stateObjects[addr] // closure for the task runner below
 		workers.Go(func() error {
-			if s.db.TrieDB().IsVerkle() {
+			if shared {
 				obj.updateTrie()
 			} else {
 				obj.updateRoot()
@@ -844,13 +868,19 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 					s.witness.AddState(obj.trie.Witness())
 				}
 			}
+			updated <- obj
 			return nil
 		})
 	}
+	storageDone := make(chan time.Time, 1)
+	go func() {
+		workers.Wait()
+		storageDone <- time.Now()
+	}()
 	// If witness building is enabled, gather all the read-only accesses.
 	// Skip witness collection in Verkle mode, they will be gathered
 	// together at the end.
-	if s.witness != nil && !s.db.TrieDB().IsVerkle() {
+	if s.witness != nil && !shared {
 		// Pull in anything that has been accessed before destruction
 		for _, obj := range s.stateObjectsDestruct {
 			// Skip any objects that haven't touched their storage
@@ -880,9 +910,12 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 			}
 		}
 	}
-	workers.Wait()
-	s.StorageUpdates += time.Since(start)
-
+	// In Verkle mode the storage slots are written into the shared main trie,
+	// so the account updates can only be applied after all of them are done.
+	var storageEnd time.Time
+	if shared {
+		storageEnd = <-storageDone
+	}
 	// Now we're about to start to write changes to the trie. The trie is so far
 	// _untouched_. We can check with the prefetcher, if it can give us a trie
 	// which has the same root, but also has some content loaded into it.
@@ -890,8 +923,7 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// Don't check prefetcher if verkle trie has been used. In the context of verkle,
 	// only a single trie is used for state hashing. Replacing a non-nil verkle tree
 	// here could result in losing uncommitted changes from storage.
-	start = time.Now()
-	if s.prefetcher != nil {
+	if s.prefetcher != nil && !shared {
 		if trie := s.prefetcher.trie(common.Hash{}, s.originalRoot); trie == nil {
 			log.Error("Failed to retrieve account pre-fetcher trie")
 		} else {
@@ -908,29 +940,21 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// If the self-destruct is handled first, then `P` would be left with only one child, thus collapsed
 	// into a shortnode. This requires `B` to be resolved from disk.
 	// Whereas if the created node is handled first, then the collapse is avoided, and `B` is not resolved.
-	var (
-		usedAddrs    []common.Address
-		deletedAddrs []common.Address
-	)
-	for addr, op := range s.mutations {
-		if op.applied {
-			continue
-		}
-		op.applied = true
-
-		if op.isDelete() {
-			deletedAddrs = append(deletedAddrs, addr)
-		} else {
-			s.updateStateObject(s.stateObjects[addr])
-			s.AccountUpdated += 1
-		}
-		usedAddrs = append(usedAddrs, addr) // Copy needed for closure
+	for range len(usedAddrs) - len(deletedAddrs) {
+		s.updateStateObject(<-updated)
+		s.AccountUpdated += 1
 	}
 	for _, deletedAddr := range deletedAddrs {
 		s.deleteStateObject(deletedAddr)
 		s.AccountDeleted += 1
 	}
-	s.AccountUpdates += time.Since(start)
+	// The account updates are overlapped with the storage updates, only the
+	// time spent after all storage roots are resolved is attributed to them.
+	if storageEnd.IsZero() {
+		storageEnd = <-storageDone
+	}
+	s.StorageUpdates += storageEnd.Sub(start)
+	s.AccountUpdates += time.Since(storageEnd)
 
 	if s.prefetcher != nil {
 		s.prefetcher.used(common.Hash{}, s.originalRoot, usedAddrs, nil)
@@ -1081,10 +1105,15 @@ func (s *StateDB) deleteStorage(addr common.Address, addrHash common.Hash, root
 // with their values be tracked as original value.
 // In case (d), **original** account along with its storages should be deleted,
 // with their values be tracked as original value.
-func (s *StateDB) handleDestruction(noStorageWiping bool) (map[common.Hash]*accountDelete, []*trienode.NodeSet, error) {
+//
+// The storage wiping of the destructed accounts is scheduled on the given
+// workers, merging the trie node changes with the given function. The returned
+// channels are closed once the storage of the corresponding account is wiped
+// and its trie node changes are handed to the merge function.
+func (s *StateDB) handleDestruction(noStorageWiping bool, workers *errgroup.Group, merge func(*trienode.NodeSet) error) (map[common.Hash]*accountDelete, map[common.Address]chan struct{}, error) {
 	var (
-		nodes   []*trienode.NodeSet
 		deletes = make(map[common.Hash]*accountDelete)
+		wiped   = make(map[common.Address]chan struct{})
 	)
 	for addr, prevObj := range s.stateObjectsDestruct {
 		prev := prevObj.origin
@@ -1106,24 +1135,32 @@ func (s *StateDB) handleDestruction(noStorageWiping bool) (map[common.Hash]*acco
 		deletes[addrHash] = op
 
 		// Short circuit if the origin storage was empty.
-		if prev.Root == types.EmptyRootHash || s.db.TrieDB().IsVerkle() {
+		if prev.Root == types.EmptyRootHash || s.db.TrieDB().IsVerkle() || s.db.TrieDB().IsBinary() {
 			continue
 		}
 		if noStorageWiping {
 			return nil, nil, fmt.Errorf("unexpected storage wiping, %x", addr)
 		}
-		// Remove storage slots belonging to the account.
-		storages, storagesOrigin, set, err := s.deleteStorage(addr, addrHash, prev.Root)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to delete storage, err: %w", err)
-		}
-		op.storages = storages
-		op.storagesOrigin = storagesOrigin
+		// Remove storage slots belonging to the account. The storage tries
+		// are independent from each other, delete them concurrently.
+		done := make(chan struct{})
+		wiped[addr] = done
+
+		workers.Go(func() error {
+			defer close(done)
 
-		// Aggregate the associated trie node changes.
-		nodes = append(nodes, set)
+			storages, storagesOrigin, set, err := s.deleteStorage(addr, addrHash, prev.Root)
+			if err != nil {
+				return fmt.Errorf("failed to delete storage, err: %w", err)
+			}
+			op.storages = storages
+			op.storagesOrigin = storagesOrigin
+
+			// Aggregate the associated trie node changes.
+			return merge(set)
+		})
 	}
-	return deletes, nodes, nil
+	return deletes, wiped, nil
 }
 
 // GetTrie returns the account trie.
@@ -1152,26 +1189,38 @@ func (s *StateDB) commit(deleteEmptyObjects bool, noStorageWiping bool) (*stateU
 		storageTrieNodesUpdated int
 		storageTrieNodesDeleted int
 
-		lock    sync.Mutex                                               // protect two maps below
+		lock    sync.Mutex                                               // protect the map below
 		nodes   = trienode.NewMergedNodeSet()                            // aggregated trie nodes
 		updates = make(map[common.Hash]*accountUpdate, len(s.mutations)) // aggregated account updates
 
-		// merge aggregates the dirty trie nodes into the global set.
-		//
-		// Given that some accounts may be destroyed and then recreated within
-		// the same block, it's possible that a node set with the same owner
-		// may already exists. In such cases, these two sets are combined, with
-		// the later one overwriting the previous one if any nodes are modified
-		// or deleted in both sets.
-		//
-		// merge run concurrently across  all the state objects and account trie.
+		// sets streams the dirty trie nodes of the committed tries to the
+		// merger below. It's large enough to never block the committers.
+		sets   = make(chan *trienode.NodeSet, len(s.mutations)+len(s.stateObjectsDestruct)+1)
+		merged = make(chan error, 1)
+
+		// merge hands the dirty trie nodes over for aggregation into the global
+		// set. It runs concurrently across all the state objects and account trie.
 		merge = func(set *trienode.NodeSet) error {
-			if set == nil {
-				return nil
+			if set != nil {
+				sets <- set
+			}
+			return nil
+		}
+	)
+	// Aggregate the node sets into the global set as they are produced, in
+	// parallel with the trie commits still running.
+	//
+	// Given that some accounts may be destroyed and then recreated within
+	// the same block, it's possible that a node set with the same owner
+	// may already exists. In such cases, these two sets are combined, with
+	// the later one overwriting the previous one if any nodes are modified
+	// or deleted in both sets. The sets are merged in the order they are sent.
+	go func() {
+		var err error
+		for set := range sets {
+			if err != nil {
+				continue
 			}
-			lock.Lock()
-			defer lock.Unlock()
-
 			updates, deletes := set.Size()
 			if set.Owner == (common.Hash{}) {
 				accountTrieNodesUpdated += updates
@@ -1180,32 +1229,33 @@ func (s *StateDB) commit(deleteEmptyObjects bool, noStorageWiping bool) (*stateU
 				storageTrieNodesUpdated += updates
 				storageTrieNodesDeleted += deletes
 			}
-			return nodes.Merge(set)
+			err = nodes.Merge(set)
 		}
-	)
-	// Given that some accounts could be destroyed and then recreated within
-	// the same block, account deletions must be processed first. This ensures
-	// that the storage trie nodes deleted during destruction and recreated
-	// during subsequent resurrection can be combined correctly.
-	deletes, delNodes, err := s.handleDestruction(noStorageWiping)
-	if err != nil {
-		return nil, err
-	}
-	for _, set := range delNodes {
-		if err := merge(set); err != nil {
-			return nil, err
-		}
-	}
-	// Handle all state updates afterwards, concurrently to one another to shave
-	// off some milliseconds from the commit operation. Also accumulate the code
-	// writes to run in parallel with the computations.
+		merged <- err
+	}()
+	// Handle all state updates concurrently to one another to shave off some
+	// milliseconds from the commit operation. Also accumulate the code writes
+	// to run in parallel with the computations.
 	var (
 		start   = time.Now()
 		root    common.Hash
 		workers errgroup.Group
+
+		// wait waits for the scheduled work and the aggregation of its trie
+		// node changes to finish.
+		wait = func() error {
+			err := workers.Wait()
+			close(sets)
+			if mergeErr := <-merged; err == nil {
+				err = mergeErr
+			}
+			return err
+		}
 	)
+	workers.SetLimit(runtime.NumCPU())
 	// Schedule the account trie first since that will be the biggest, so give
-	// it the most time to crunch.
+	// it the most time to crunch. The account trie is independent from the
+	// storage wiping below, so it can be committed in the meantime.
 	//
 	// TODO(karalabe): This account trie commit is *very* heavy. 5-6ms at chain
 	// heads, which seems excessive given that it doesn't do hashing, it just
@@ -1224,6 +1274,15 @@ func (s *StateDB) commit(deleteEmptyObjects bool, noStorageWiping bool) (*stateU
 		s.AccountCommits = time.Since(start)
 		return nil
 	})
+	// Given that some accounts could be destroyed and then recreated within
+	// the same block, account deletions must be processed first. This ensures
+	// that the storage trie nodes deleted during destruction and recreated
+	// during subsequent resurrection can be combined correctly.
+	deletes, wiped, err := s.handleDestruction(noStorageWiping, &workers, merge)
+	if err != nil {
+		wait()
+		return nil, err
+	}
 	// Schedule each of the storage tries that need to be updated, so they can
 	// run concurrently to one another.
 	//
@@ -1231,6 +1290,7 @@ func (s *StateDB) commit(deleteEmptyObjects bool, noStorageWiping bool) (*stateU
 	// same time as all the storage commits combined, so we could maybe only have
 	// 2 threads in total. But that kind of depends on the account commit being
 	// more expensive than it should be, so let's fix that and revisit this todo.
+	var codes []contractCode
 	for addr, op := range s.mutations {
 		if op.isDelete() {
 			continue
@@ -1238,15 +1298,25 @@ func (s *StateDB) commit(deleteEmptyObjects bool, noStorageWiping bool) (*stateU
 		// Write any contract code associated with the state object
 		obj := s.stateObjects[addr]
 		if obj == nil {
+			wait()
 			return nil, errors.New("missing state object")
 		}
+		if obj.dirtyCode {
+			codes = append(codes, contractCode{hash: common.BytesToHash(obj.CodeHash()), blob: obj.code})
+		}
 		// Run the storage updates concurrently to one another
+		wipe := wiped[addr]
 		workers.Go(func() error {
 			// Write any storage changes in the state object to its storage trie
 			update, set, err := obj.commit()
 			if err != nil {
 				return err
 			}
+			// Merge the changes of a resurrected account after the wiping of
+			// its previous storage was handed over, so that they take precedence.
+			if wipe != nil {
+				<-wipe
+			}
 			if err := merge(set); err != nil {
 				return err
 			}
@@ -1257,8 +1327,19 @@ func (s *StateDB) commit(deleteEmptyObjects bool, noStorageWiping bool) (*stateU
 			return nil
 		})
 	}
+	// Persist the dirty contract codes alongside the trie commits, they must be
+	// available before the state update is handed to the trie database.
+	if db := s.db.TrieDB().Disk(); db != nil && len(codes) > 0 {
+		workers.Go(func() error {
+			batch := db.NewBatch()
+			for _, code := range codes {
+				rawdb.WriteCode(batch, code.hash, code.blob)
+			}
+			return batch.Write()
+		})
+	}
 	// Wait for everything to finish and update the metrics
-	if err := workers.Wait(); err != nil {
+	if err := wait(); err != nil {
 		return nil, err
 	}
 	accountReadMeters.Mark(int64(s.AccountLoaded))
@@ -1282,7 +1363,12 @@ func (s *StateDB) commit(deleteEmptyObjects bool, noStorageWiping bool) (*stateU
 	s.mutations = make(map[common.Address]*mutation)
 	s.stateObjectsDestruct = make(map[common.Address]*stateObject)
 
+	// The first overlay of the transition into the binary tree is applied on
+	// top of the empty binary tree, rather than the base trie.
 	origin := s.originalRoot
+	if tr, ok := s.trie.(*bintrie.TransitionTrie); ok && origin == tr.Base().Hash() {
+		origin = types.EmptyVerkleHash
+	}
 	s.originalRoot = root
 
 	return newStateUpdate(noStorageWiping, origin, root, deletes, updates, nodes), nil
@@ -1295,13 +1381,14 @@ func (s *StateDB) commitAndFlush(block uint64, deleteEmptyObjects bool, noStorag
 	if err != nil {
 		return nil, err
 	}
-	// Commit dirty contract code if any exists
-	if db := s.db.TrieDB().Disk(); db != nil && len(ret.codes) > 0 {
-		batch := db.NewBatch()
-		for _, code := range ret.codes {
-			rawdb.WriteCode(batch, code.hash, code.blob)
-		}
-		if err := batch.Write(); err != nil {
+	if profiler := accessProfiler.Load(); profiler != nil {
+		profiler.recordWrites(ret)
+	}
+	// Carry the transition into the binary tree over to the new state, it must
+	// be available before the state is accessible.
+	if tr, ok := s.trie.(*bintrie.TransitionTrie); ok {
+		ts := &overlay.TransitionState{Started: true, BaseRoot: tr.Base().Hash()}
+		if err := overlay.StoreTransitionState(s.db.TrieDB().Disk(), ret.root, ts); err != nil {
 			return nil, err
 		}
 	}
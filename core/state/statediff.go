@@ -0,0 +1,463 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+//go:generate go run github.com/fjl/gencodec -type StateDiff -field-override stateDiffMarshaling -out gen_statediff_json.go
+//go:generate go run github.com/fjl/gencodec -type AccountDiff -field-override accountDiffMarshaling -out gen_accountdiff_json.go
+
+// StateDiff is the complete set of state mutations made by a single block,
+// expressed as the post-state values of everything that changed. The block
+// and parent hashes are included so that consumers replicating the state can
+// detect reorgs by checking that each diff links onto the last one applied.
+//
+// A reverted diff undoes the block when it's removed from the canonical chain:
+// it holds the values of the parent state for everything the block changed, and
+// the Root is the parent state root.
+type StateDiff struct {
+	Number     uint64         `json:"number"`
+	Hash       common.Hash    `json:"hash"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Root       common.Hash    `json:"stateRoot"`
+	Reverted   bool           `json:"reverted,omitempty" rlp:"-"`
+	Accounts   []*AccountDiff `json:"accounts"`
+}
+
+type stateDiffMarshaling struct {
+	Number hexutil.Uint64
+}
+
+// AccountDiff represents an account which was created, modified or deleted.
+// For deleted accounts, Destructed is set and all other fields are zero; the
+// consumer is expected to drop the whole account including its storage.
+type AccountDiff struct {
+	AddressHash common.Hash     `json:"addressHash"`
+	Address     *common.Address `json:"address,omitempty" rlp:"nil"` // Only present if the preimage is known
+	Destructed  bool            `json:"destructed,omitempty"`
+	Nonce       uint64          `json:"nonce"`
+	Balance     *uint256.Int    `json:"balance"`
+	CodeHash    common.Hash     `json:"codeHash"`
+	Code        []byte          `json:"code,omitempty"` // Only present if the code was changed
+	StorageRoot common.Hash     `json:"storageRoot"`
+	Storage     []*SlotDiff     `json:"storage,omitempty"`
+}
+
+type accountDiffMarshaling struct {
+	Nonce   hexutil.Uint64
+	Balance *hexutil.U256
+	Code    hexutil.Bytes
+}
+
+// SlotDiff represents a storage slot which was modified. A zero value means
+// the slot was cleared.
+type SlotDiff struct {
+	KeyHash common.Hash  `json:"keyHash"`
+	Key     *common.Hash `json:"key,omitempty" rlp:"nil"` // Only present if the preimage is known
+	Value   common.Hash  `json:"value"`
+}
+
+// leafDiff contains the leaves which differ between two tries. Updated holds
+// the new values of leaves which were created or modified, while deleted holds
+// the keys of leaves which no longer exist. The original values of modified
+// leaves are tracked too, for resolving the nested storage diffs.
+type leafDiff struct {
+	updated  []leaf
+	deleted  []common.Hash
+	original map[common.Hash][]byte
+}
+
+type leaf struct {
+	key   common.Hash
+	value []byte
+}
+
+// diffTries collects the leaf-level differences between two tries by walking
+// the subtries which are not shared between them, in both directions.
+func diffTries(db *triedb.Database, oldID, newID *trie.ID) (*leafDiff, error) {
+	oldTrie, err := trie.New(oldID, db)
+	if err != nil {
+		return nil, err
+	}
+	newTrie, err := trie.New(newID, db)
+	if err != nil {
+		return nil, err
+	}
+	diff := &leafDiff{original: make(map[common.Hash][]byte)}
+
+	// Collect the created and modified leaves, iterating the nodes which are
+	// present in the new trie but not in the old one.
+	oldIt, err := oldTrie.NodeIterator(nil)
+	if err != nil {
+		return nil, err
+	}
+	newIt, err := newTrie.NodeIterator(nil)
+	if err != nil {
+		return nil, err
+	}
+	it, _ := trie.NewDifferenceIterator(oldIt, newIt)
+	iter := trie.NewIterator(it)
+	updated := make(map[common.Hash]struct{})
+	for iter.Next() {
+		key := common.BytesToHash(iter.Key)
+		updated[key] = struct{}{}
+		diff.updated = append(diff.updated, leaf{key: key, value: common.CopyBytes(iter.Value)})
+	}
+	if iter.Err != nil {
+		return nil, iter.Err
+	}
+	// Collect the deleted leaves and the original values of the modified ones,
+	// iterating the nodes which are present in the old trie but not in the new.
+	if oldIt, err = oldTrie.NodeIterator(nil); err != nil {
+		return nil, err
+	}
+	if newIt, err = newTrie.NodeIterator(nil); err != nil {
+		return nil, err
+	}
+	it, _ = trie.NewDifferenceIterator(newIt, oldIt)
+	iter = trie.NewIterator(it)
+	for iter.Next() {
+		key := common.BytesToHash(iter.Key)
+		if _, ok := updated[key]; ok {
+			diff.original[key] = common.CopyBytes(iter.Value)
+		} else {
+			diff.deleted = append(diff.deleted, key)
+		}
+	}
+	if iter.Err != nil {
+		return nil, iter.Err
+	}
+	return diff, nil
+}
+
+// DiffStates computes the state mutations required to transition from the
+// parent state to the child state. Accounts are returned in the order of their
+// hashes, with deleted accounts following the updated ones.
+//
+// If the path-based scheme is used and the state history of the child state
+// has been persisted, the mutations are derived from the history. Otherwise,
+// both states must be available in the trie database and are compared with
+// each other.
+func DiffStates(db *triedb.Database, parentRoot, root common.Hash) ([]*AccountDiff, error) {
+	if db.IsVerkle() || db.IsBinary() {
+		return nil, errors.New("state diff is not supported for verkle or binary tree")
+	}
+	if db.Scheme() == rawdb.PathScheme {
+		change, err := db.StateChange(root)
+		if err == nil && change.Parent == parentRoot {
+			accounts, err := diffHistory(db, change)
+			if err == nil {
+				return accounts, nil
+			}
+			log.Debug("Failed to diff states by history", "root", root, "err", err)
+		}
+	}
+	return diffTrieStates(db, parentRoot, root)
+}
+
+// RevertStates computes the state mutations undoing the transition from the
+// parent state to the child state, i.e. the values of the parent state for
+// everything the transition changed. Accounts are ordered like in DiffStates.
+//
+// If the path-based scheme is used and the state history of the child state
+// has been persisted, the mutations are the original values recorded in the
+// history. Otherwise, both states are compared in reverse.
+func RevertStates(db *triedb.Database, root, parentRoot common.Hash) ([]*AccountDiff, error) {
+	if db.IsVerkle() || db.IsBinary() {
+		return nil, errors.New("state diff is not supported for verkle or binary tree")
+	}
+	if db.Scheme() == rawdb.PathScheme {
+		change, err := db.StateChange(root)
+		if err == nil && change.Parent == parentRoot {
+			accounts, err := revertHistory(db, change)
+			if err == nil {
+				return accounts, nil
+			}
+			log.Debug("Failed to revert states by history", "root", root, "err", err)
+		}
+	}
+	return diffTrieStates(db, root, parentRoot)
+}
+
+// diffReader returns a reader of the given state, which is either one of the
+// live states or a historic state resolvable through the state history index.
+func diffReader(db *triedb.Database, root common.Hash) (StateReader, error) {
+	if reader, err := db.StateReader(root); err == nil {
+		return newFlatReader(reader), nil
+	}
+	reader, err := db.HistoricReader(root)
+	if err != nil {
+		return nil, err
+	}
+	return newHistoricReader(reader), nil
+}
+
+// diffHistory computes the state mutations recorded in the state history. The
+// history only holds the original values of the mutated states, the new values
+// are read from the child state.
+func diffHistory(db *triedb.Database, change *pathdb.StateChange) ([]*AccountDiff, error) {
+	reader, err := diffReader(db, change.Root)
+	if err != nil {
+		return nil, err
+	}
+	var updated, deleted []*AccountDiff
+	for addr, blob := range change.Accounts {
+		origin := types.NewEmptyStateAccount()
+		if len(blob) != 0 {
+			if origin, err = types.FullAccount(blob); err != nil {
+				return nil, fmt.Errorf("invalid account %x in history: %v", addr, err)
+			}
+		}
+		account, err := reader.Account(addr)
+		if err != nil {
+			return nil, err
+		}
+		entry := &AccountDiff{
+			AddressHash: crypto.Keccak256Hash(addr.Bytes()),
+			Address:     &addr,
+		}
+		if account == nil {
+			if len(blob) == 0 {
+				continue // created and destructed within the block
+			}
+			entry.Destructed = true
+			entry.Balance = new(uint256.Int)
+			deleted = append(deleted, entry)
+			continue
+		}
+		entry.Nonce = account.Nonce
+		entry.Balance = account.Balance
+		entry.CodeHash = common.BytesToHash(account.CodeHash)
+		entry.StorageRoot = account.Root
+
+		if !bytes.Equal(origin.CodeHash, account.CodeHash) && !bytes.Equal(account.CodeHash, types.EmptyCodeHash.Bytes()) {
+			entry.Code = rawdb.ReadCode(db.Disk(), entry.CodeHash)
+			if len(entry.Code) == 0 {
+				return nil, fmt.Errorf("missing code %x of account %x", entry.CodeHash, addr)
+			}
+		}
+		for key, prev := range change.Storages[addr] {
+			value, err := reader.Storage(addr, key)
+			if err != nil {
+				return nil, err
+			}
+			var original common.Hash
+			if len(prev) != 0 {
+				_, content, _, err := rlp.Split(prev)
+				if err != nil {
+					return nil, fmt.Errorf("invalid slot %x of account %x in history: %v", key, addr, err)
+				}
+				original.SetBytes(content)
+			}
+			if original == value {
+				continue
+			}
+			entry.Storage = append(entry.Storage, &SlotDiff{
+				KeyHash: crypto.Keccak256Hash(key.Bytes()),
+				Key:     &key,
+				Value:   value,
+			})
+		}
+		slices.SortFunc(entry.Storage, func(a, b *SlotDiff) int {
+			return a.KeyHash.Cmp(b.KeyHash)
+		})
+		updated = append(updated, entry)
+	}
+	byHash := func(a, b *AccountDiff) int {
+		return a.AddressHash.Cmp(b.AddressHash)
+	}
+	slices.SortFunc(updated, byHash)
+	slices.SortFunc(deleted, byHash)
+	return append(updated, deleted...), nil
+}
+
+// revertHistory computes the state mutations undoing a transition from the
+// original values recorded in its state history. The child state is only read
+// for skipping accounts which existed in neither state, and for deciding whether
+// the code has to be restored.
+func revertHistory(db *triedb.Database, change *pathdb.StateChange) ([]*AccountDiff, error) {
+	reader, err := diffReader(db, change.Root)
+	if err != nil {
+		return nil, err
+	}
+	var updated, deleted []*AccountDiff
+	for addr, blob := range change.Accounts {
+		account, err := reader.Account(addr)
+		if err != nil {
+			return nil, err
+		}
+		entry := &AccountDiff{
+			AddressHash: crypto.Keccak256Hash(addr.Bytes()),
+			Address:     &addr,
+		}
+		if len(blob) == 0 {
+			if account == nil {
+				continue // created and destructed within the block
+			}
+			entry.Destructed = true
+			entry.Balance = new(uint256.Int)
+			deleted = append(deleted, entry)
+			continue
+		}
+		origin, err := types.FullAccount(blob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account %x in history: %v", addr, err)
+		}
+		entry.Nonce = origin.Nonce
+		entry.Balance = origin.Balance
+		entry.CodeHash = common.BytesToHash(origin.CodeHash)
+		entry.StorageRoot = origin.Root
+
+		if (account == nil || !bytes.Equal(origin.CodeHash, account.CodeHash)) && !bytes.Equal(origin.CodeHash, types.EmptyCodeHash.Bytes()) {
+			entry.Code = rawdb.ReadCode(db.Disk(), entry.CodeHash)
+			if len(entry.Code) == 0 {
+				return nil, fmt.Errorf("missing code %x of account %x", entry.CodeHash, addr)
+			}
+		}
+		for key, prev := range change.Storages[addr] {
+			var original common.Hash
+			if len(prev) != 0 {
+				_, content, _, err := rlp.Split(prev)
+				if err != nil {
+					return nil, fmt.Errorf("invalid slot %x of account %x in history: %v", key, addr, err)
+				}
+				original.SetBytes(content)
+			}
+			entry.Storage = append(entry.Storage, &SlotDiff{
+				KeyHash: crypto.Keccak256Hash(key.Bytes()),
+				Key:     &key,
+				Value:   original,
+			})
+		}
+		slices.SortFunc(entry.Storage, func(a, b *SlotDiff) int {
+			return a.KeyHash.Cmp(b.KeyHash)
+		})
+		updated = append(updated, entry)
+	}
+	byHash := func(a, b *AccountDiff) int {
+		return a.AddressHash.Cmp(b.AddressHash)
+	}
+	slices.SortFunc(updated, byHash)
+	slices.SortFunc(deleted, byHash)
+	return append(updated, deleted...), nil
+}
+
+// diffTrieStates computes the state mutations by comparing the tries of the
+// parent and child states.
+func diffTrieStates(db *triedb.Database, parentRoot, root common.Hash) ([]*AccountDiff, error) {
+	// Preimages are resolved through a secure trie, which is the only one that
+	// has access to the preimage store. A missing preimage is not an error, the
+	// hashed keys are always reported.
+	keyTrie, err := trie.NewStateTrie(trie.StateTrieID(root), db)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := diffTries(db, trie.StateTrieID(parentRoot), trie.StateTrieID(root))
+	if err != nil {
+		return nil, err
+	}
+	var accounts []*AccountDiff
+	for _, item := range diff.updated {
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(item.value, &account); err != nil {
+			return nil, fmt.Errorf("invalid account %x: %v", item.key, err)
+		}
+		entry := &AccountDiff{
+			AddressHash: item.key,
+			Nonce:       account.Nonce,
+			Balance:     account.Balance,
+			CodeHash:    common.BytesToHash(account.CodeHash),
+			StorageRoot: account.Root,
+		}
+		if preimage := keyTrie.GetKey(item.key.Bytes()); preimage != nil {
+			addr := common.BytesToAddress(preimage)
+			entry.Address = &addr
+		}
+		// Resolve the original account for deciding which parts of it changed.
+		// The account is regarded as empty if it didn't exist before.
+		origin := types.NewEmptyStateAccount()
+		if blob, ok := diff.original[item.key]; ok {
+			if err := rlp.DecodeBytes(blob, origin); err != nil {
+				return nil, fmt.Errorf("invalid account %x: %v", item.key, err)
+			}
+		}
+		if !bytes.Equal(origin.CodeHash, account.CodeHash) && !bytes.Equal(account.CodeHash, types.EmptyCodeHash.Bytes()) {
+			entry.Code = rawdb.ReadCode(db.Disk(), entry.CodeHash)
+			if len(entry.Code) == 0 {
+				return nil, fmt.Errorf("missing code %x of account %x", entry.CodeHash, item.key)
+			}
+		}
+		if origin.Root != account.Root {
+			slots, err := diffTries(db, trie.StorageTrieID(parentRoot, item.key, origin.Root), trie.StorageTrieID(root, item.key, account.Root))
+			if err != nil {
+				return nil, err
+			}
+			for _, slot := range slots.updated {
+				_, content, _, err := rlp.Split(slot.value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid slot %x of account %x: %v", slot.key, item.key, err)
+				}
+				entry.Storage = append(entry.Storage, newSlotDiff(keyTrie, slot.key, common.BytesToHash(content)))
+			}
+			for _, key := range slots.deleted {
+				entry.Storage = append(entry.Storage, newSlotDiff(keyTrie, key, common.Hash{}))
+			}
+		}
+		accounts = append(accounts, entry)
+	}
+	for _, key := range diff.deleted {
+		entry := &AccountDiff{
+			AddressHash: key,
+			Destructed:  true,
+			Balance:     new(uint256.Int),
+		}
+		if preimage := keyTrie.GetKey(key.Bytes()); preimage != nil {
+			addr := common.BytesToAddress(preimage)
+			entry.Address = &addr
+		}
+		accounts = append(accounts, entry)
+	}
+	return accounts, nil
+}
+
+// newSlotDiff constructs a slot diff, resolving the preimage of the slot key
+// if it's available.
+func newSlotDiff(keyTrie *trie.StateTrie, keyHash common.Hash, value common.Hash) *SlotDiff {
+	slot := &SlotDiff{KeyHash: keyHash, Value: value}
+	if preimage := keyTrie.GetKey(keyHash.Bytes()); preimage != nil {
+		key := common.BytesToHash(preimage)
+		slot.Key = &key
+	}
+	return slot
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+func TestDiffStates(t *testing.T) {
+	testDiffStates(t, triedb.NewDatabase(rawdb.NewMemoryDatabase(), &triedb.Config{Preimages: true}), false)
+}
+
+// TestDiffStatesHistory tests deriving the state diff from the state history,
+// after the tries of the parent state have been overwritten on disk.
+func TestDiffStatesHistory(t *testing.T) {
+	disk, err := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{Ancient: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := *pathdb.Defaults
+	config.EnableStateIndexing = true
+	tdb := triedb.NewDatabase(disk, &triedb.Config{PathDB: &config})
+	defer tdb.Close()
+	testDiffStates(t, tdb, true)
+}
+
+func testDiffStates(t *testing.T, tdb *triedb.Database, flush bool) {
+	var (
+		sdb   = NewDatabase(tdb, nil)
+		addr1 = common.HexToAddress("0x01")
+		addr2 = common.HexToAddress("0x02")
+		addr3 = common.HexToAddress("0x03")
+		code  = []byte{0x60, 0x00}
+		slot1 = common.HexToHash("0x01")
+		slot2 = common.HexToHash("0x02")
+		slot3 = common.HexToHash("0x03")
+	)
+	// Construct the parent state
+	state, _ := New(types.EmptyRootHash, sdb)
+	state.SetBalance(addr1, uint256.NewInt(10), tracing.BalanceChangeUnspecified)
+	state.SetCode(addr2, code)
+	state.SetState(addr2, slot1, common.HexToHash("0x11"))
+	state.SetState(addr2, slot2, common.HexToHash("0x22"))
+	state.SetBalance(addr3, uint256.NewInt(5), tracing.BalanceChangeUnspecified)
+	parent, err := state.Commit(0, false, true)
+	if err != nil {
+		t.Fatalf("Failed to commit parent state: %v", err)
+	}
+	// Mutate a few things in the child state
+	state, _ = New(parent, sdb)
+	state.SetBalance(addr1, uint256.NewInt(20), tracing.BalanceChangeUnspecified)
+	state.SetState(addr2, slot1, common.Hash{})
+	state.SetState(addr2, slot3, common.HexToHash("0x33"))
+	state.SelfDestruct(addr3)
+	root, err := state.Commit(1, true, true)
+	if err != nil {
+		t.Fatalf("Failed to commit child state: %v", err)
+	}
+	if flush {
+		if err := tdb.Commit(root, false); err != nil {
+			t.Fatalf("Failed to flush states: %v", err)
+		}
+		if _, err := tdb.StateChange(root); err != nil {
+			t.Fatalf("Missing state history: %v", err)
+		}
+		if _, err := diffTrieStates(tdb, parent, root); err == nil {
+			t.Fatal("Parent state unexpectedly available")
+		}
+		// Wait for the histories to be indexed, the parent state is only
+		// accessible as historic state.
+		for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+			_, err := tdb.HistoricReader(parent)
+			if left, _ := tdb.IndexProgress(); err == nil && left == 0 {
+				break
+			}
+			if time.Since(start) > 5*time.Second {
+				t.Fatal("State histories not indexed")
+			}
+		}
+	}
+	diff, err := DiffStates(tdb, parent, root)
+	if err != nil {
+		t.Fatalf("Failed to diff states: %v", err)
+	}
+	got := make(map[common.Address]*AccountDiff)
+	for _, account := range diff {
+		if account.Address == nil {
+			t.Fatalf("Missing preimage for %x", account.AddressHash)
+		}
+		if account.AddressHash != crypto.Keccak256Hash(account.Address.Bytes()) {
+			t.Fatalf("Mismatching address hash for %x", account.Address)
+		}
+		got[*account.Address] = account
+	}
+	if len(got) != 3 {
+		t.Fatalf("Unexpected number of modified accounts, want 3, got %d", len(got))
+	}
+	if got[addr1].Balance.Uint64() != 20 || got[addr1].Destructed || got[addr1].Storage != nil || got[addr1].Code != nil {
+		t.Fatalf("Unexpected diff for balance change: %+v", got[addr1])
+	}
+	if !got[addr3].Destructed {
+		t.Fatalf("Expected account to be destructed: %+v", got[addr3])
+	}
+	if got[addr2].Code != nil {
+		t.Fatal("Unchanged code should not be reported")
+	}
+	slots := make(map[common.Hash]common.Hash)
+	for _, slot := range got[addr2].Storage {
+		if slot.Key == nil {
+			t.Fatalf("Missing preimage for slot %x", slot.KeyHash)
+		}
+		slots[*slot.Key] = slot.Value
+	}
+	want := map[common.Hash]common.Hash{
+		slot1: {},
+		slot3: common.HexToHash("0x33"),
+	}
+	if len(slots) != len(want) {
+		t.Fatalf("Unexpected number of modified slots, want %d, got %d", len(want), len(slots))
+	}
+	for key, value := range want {
+		if slots[key] != value {
+			t.Fatalf("Unexpected slot %x value, want %x, got %x", key, value, slots[key])
+		}
+	}
+	// Ensure the revert diff restores the parent values
+	diff, err = RevertStates(tdb, root, parent)
+	if err != nil {
+		t.Fatalf("Failed to revert states: %v", err)
+	}
+	got = make(map[common.Address]*AccountDiff)
+	for _, account := range diff {
+		if account.Address == nil {
+			t.Fatalf("Missing preimage for %x", account.AddressHash)
+		}
+		got[*account.Address] = account
+	}
+	if len(got) != 3 {
+		t.Fatalf("Unexpected number of reverted accounts, want 3, got %d", len(got))
+	}
+	if got[addr1].Balance.Uint64() != 10 || got[addr1].Destructed {
+		t.Fatalf("Unexpected revert of balance change: %+v", got[addr1])
+	}
+	if got[addr3].Destructed || got[addr3].Balance.Uint64() != 5 {
+		t.Fatalf("Unexpected revert of destructed account: %+v", got[addr3])
+	}
+	slots = make(map[common.Hash]common.Hash)
+	for _, slot := range got[addr2].Storage {
+		if slot.Key == nil {
+			t.Fatalf("Missing preimage for slot %x", slot.KeyHash)
+		}
+		slots[*slot.Key] = slot.Value
+	}
+	want = map[common.Hash]common.Hash{
+		slot1: common.HexToHash("0x11"),
+		slot3: {},
+	}
+	if len(slots) != len(want) {
+		t.Fatalf("Unexpected number of reverted slots, want %d, got %d", len(want), len(slots))
+	}
+	for key, value := range want {
+		if slots[key] != value {
+			t.Fatalf("Unexpected reverted slot %x value, want %x, got %x", key, value, slots[key])
+		}
+	}
+	// Ensure the initial state reports the deployed code
+	diff, err = DiffStates(tdb, types.EmptyRootHash, parent)
+	if err != nil {
+		t.Fatalf("Failed to diff states: %v", err)
+	}
+	for _, account := range diff {
+		if *account.Address == addr2 && !bytes.Equal(account.Code, code) {
+			t.Fatalf("Unexpected code, want %x, got %x", code, account.Code)
+		}
+	}
+	// Ensure the diff survives an RLP roundtrip
+	blob, err := rlp.EncodeToBytes(&StateDiff{Number: 1, Root: root, Accounts: diff})
+	if err != nil {
+		t.Fatalf("Failed to encode diff: %v", err)
+	}
+	var dec StateDiff
+	if err := rlp.DecodeBytes(blob, &dec); err != nil {
+		t.Fatalf("Failed to decode diff: %v", err)
+	}
+	if len(dec.Accounts) != len(diff) {
+		t.Fatalf("Unexpected number of decoded accounts, want %d, got %d", len(diff), len(dec.Accounts))
+	}
+}
+
+func TestStateDiffJSON(t *testing.T) {
+	addr := common.HexToAddress("0x01")
+	diff := &StateDiff{
+		Number: 10,
+		Accounts: []*AccountDiff{{
+			Address: &addr,
+			Nonce:   5,
+			Balance: uint256.NewInt(1000),
+			Code:    []byte{0x60},
+		}},
+	}
+	blob, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatalf("Failed to encode diff: %v", err)
+	}
+	var fields struct {
+		Number   string `json:"number"`
+		Accounts []struct {
+			Nonce   string `json:"nonce"`
+			Balance string `json:"balance"`
+		} `json:"accounts"`
+	}
+	if err := json.Unmarshal(blob, &fields); err != nil {
+		t.Fatalf("Failed to decode fields: %v", err)
+	}
+	if fields.Number != "0xa" || fields.Accounts[0].Nonce != "0x5" || fields.Accounts[0].Balance != "0x3e8" {
+		t.Fatalf("Unexpected encoding: %s", blob)
+	}
+	var dec StateDiff
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("Failed to decode diff: %v", err)
+	}
+	if !reflect.DeepEqual(diff, &dec) {
+		t.Fatalf("Unexpected decoded diff: %s", blob)
+	}
+}
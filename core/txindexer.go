@@ -18,12 +18,16 @@ package core
 
 import (
 	"fmt"
+	"math/big"
 	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // TxIndexProgress is the struct describing the progress for transaction indexing.
@@ -58,6 +62,11 @@ type txIndexer struct {
 	// progress queries.
 	tail atomic.Pointer[uint64]
 
+	// addresses denotes whether transactions are also indexed by sender and
+	// recipient address, according to the same limit and cutoff.
+	addresses bool
+	config    *params.ChainConfig
+
 	// cutoff denotes the block number before which the chain segment should
 	// be pruned and not available locally.
 	cutoff uint64
@@ -66,15 +75,20 @@ type txIndexer struct {
 	closed chan struct{}
 }
 
+// indexFunc creates or removes the indexes of the specified block range.
+type indexFunc func(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, report bool)
+
 // newTxIndexer initializes the transaction indexer.
 func newTxIndexer(limit uint64, chain *BlockChain) *txIndexer {
 	cutoff, _ := chain.HistoryPruningCutoff()
 	indexer := &txIndexer{
-		limit:  limit,
-		cutoff: cutoff,
-		db:     chain.db,
-		term:   make(chan chan struct{}),
-		closed: make(chan struct{}),
+		limit:     limit,
+		addresses: chain.cfg.AddressIndex,
+		config:    chain.chainConfig,
+		cutoff:    cutoff,
+		db:        chain.db,
+		term:      make(chan chan struct{}),
+		closed:    make(chan struct{}),
 	}
 	indexer.head.Store(indexer.resolveHead())
 	indexer.tail.Store(rawdb.ReadTxIndexTail(chain.db))
@@ -91,7 +105,7 @@ func newTxIndexer(limit uint64, chain *BlockChain) *txIndexer {
 	} else {
 		msg = fmt.Sprintf("last %d blocks", limit)
 	}
-	log.Info("Initialized transaction indexer", "range", msg)
+	log.Info("Initialized transaction indexer", "range", msg, "addresses", indexer.addresses)
 
 	return indexer
 }
@@ -110,10 +124,32 @@ func (indexer *txIndexer) run(head uint64, stop chan struct{}, done chan struct{
 	if head == 0 || head < indexer.cutoff {
 		return
 	}
+	indexer.runIndex(head, rawdb.ReadTxIndexTail(indexer.db), rawdb.IndexTransactions, rawdb.UnindexTransactions, stop)
+
+	// Maintain the address indexes over the same range, unless interrupted.
+	if !indexer.addresses {
+		return
+	}
+	select {
+	case <-stop:
+		return
+	default:
+	}
+	index := func(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, report bool) {
+		rawdb.IndexAddressTransactions(db, indexer.config, from, to, interrupt, report)
+	}
+	unindex := func(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, report bool) {
+		rawdb.UnindexAddressTransactions(db, indexer.config, from, to, interrupt, report)
+	}
+	indexer.runIndex(head, rawdb.ReadAddressTxIndexTail(indexer.db), index, unindex, stop)
+}
+
+// runIndex moves the range of an index with the given tail to the range
+// expected by the configured limit and the latest chain head.
+func (indexer *txIndexer) runIndex(head uint64, tail *uint64, index indexFunc, unindex indexFunc, stop chan struct{}) {
 	// The tail flag is not existent, it means the node is just initialized
 	// and all blocks in the chain (part of them may from ancient store) are
 	// not indexed yet, index the chain according to the configured limit.
-	tail := rawdb.ReadTxIndexTail(indexer.db)
 	if tail == nil {
 		// Determine the first block for transaction indexing, taking the
 		// configured cutoff point into account.
@@ -122,7 +158,7 @@ func (indexer *txIndexer) run(head uint64, stop chan struct{}, done chan struct{
 			from = head - indexer.limit + 1
 		}
 		from = max(from, indexer.cutoff)
-		rawdb.IndexTransactions(indexer.db, from, head+1, stop, true)
+		index(indexer.db, from, head+1, stop, true)
 		return
 	}
 	// The tail flag is existent (which means indexes in [tail, head] should be
@@ -130,7 +166,7 @@ func (indexer *txIndexer) run(head uint64, stop chan struct{}, done chan struct{
 	if indexer.limit == 0 || head < indexer.limit {
 		if *tail > 0 {
 			from := max(uint64(0), indexer.cutoff)
-			rawdb.IndexTransactions(indexer.db, from, *tail, stop, true)
+			index(indexer.db, from, *tail, stop, true)
 		}
 		return
 	}
@@ -140,10 +176,10 @@ func (indexer *txIndexer) run(head uint64, stop chan struct{}, done chan struct{
 	from = max(from, indexer.cutoff)
 	if from < *tail {
 		// Reindex a part of missing indices and rewind index tail to HEAD-limit
-		rawdb.IndexTransactions(indexer.db, from, *tail, stop, true)
+		index(indexer.db, from, *tail, stop, true)
 	} else {
 		// Unindex a part of stale indices and forward index tail to HEAD-limit
-		rawdb.UnindexTransactions(indexer.db, *tail, from, stop, false)
+		unindex(indexer.db, *tail, from, stop, false)
 	}
 }
 
@@ -211,6 +247,32 @@ func (indexer *txIndexer) repair(head uint64) {
 	}
 }
 
+// repairAddresses ensures that the address-transaction indexes are in a valid
+// state, applying the same rules as repair. The indexes are purged entirely
+// if address indexing is disabled.
+func (indexer *txIndexer) repairAddresses(head uint64) {
+	tail := rawdb.ReadAddressTxIndexTail(indexer.db)
+	if tail == nil {
+		return
+	}
+	if !indexer.addresses || *tail > head || head < indexer.cutoff {
+		// A crash may occur between the two delete operations,
+		// potentially leaving dangling indexes in the database.
+		// However, this is considered acceptable.
+		rawdb.DeleteAddressTxIndexTail(indexer.db)
+		rawdb.DeleteAllAddressTxEntries(indexer.db, nil)
+		log.Warn("Purge address transaction indexes", "enabled", indexer.addresses, "head", head, "tail", *tail, "cutoff", indexer.cutoff)
+		return
+	}
+	if *tail < indexer.cutoff {
+		rawdb.WriteAddressTxIndexTail(indexer.db, indexer.cutoff)
+		rawdb.DeleteAllAddressTxEntries(indexer.db, func(number uint64) bool {
+			return number < indexer.cutoff
+		})
+		log.Warn("Purge address transaction indexes below cutoff", "tail", *tail, "cutoff", indexer.cutoff)
+	}
+}
+
 // resolveHead resolves the block number of the current chain head.
 func (indexer *txIndexer) resolveHead() uint64 {
 	headBlockHash := rawdb.ReadHeadBlockHash(indexer.db)
@@ -241,6 +303,7 @@ func (indexer *txIndexer) loop(chain *BlockChain) {
 	// Validate the transaction indexes and repair if necessary
 	head := indexer.head.Load()
 	indexer.repair(head)
+	indexer.repairAddresses(head)
 
 	// Launch the initial processing if chain is not empty (head != genesis).
 	// This step is useful in these scenarios that chain has no progress.
@@ -331,3 +394,38 @@ func (indexer *txIndexer) close() {
 	case <-indexer.closed:
 	}
 }
+
+// internalCallTracer collects the addresses taking part in the internal calls
+// of every transaction in a block, for the address-transaction index.
+type internalCallTracer struct {
+	txs  [][]common.Address
+	seen map[common.Address]struct{}
+	inTx bool
+}
+
+// hooks returns the tracing hooks feeding the collector.
+func (t *internalCallTracer) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: func(vm *tracing.VMContext, tx *types.Transaction, from common.Address) {
+			t.txs = append(t.txs, nil)
+			t.seen = make(map[common.Address]struct{})
+			t.inTx = true
+		},
+		OnTxEnd: func(receipt *types.Receipt, err error) {
+			t.inTx = false
+		},
+		OnEnter: func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+			// The top level call is covered by the sender and recipient entries,
+			// system calls are outside of transactions.
+			if depth == 0 || !t.inTx {
+				return
+			}
+			for _, addr := range []common.Address{from, to} {
+				if _, ok := t.seen[addr]; !ok {
+					t.seen[addr] = struct{}{}
+					t.txs[len(t.txs)-1] = append(t.txs[len(t.txs)-1], addr)
+				}
+			}
+		},
+	}
+}
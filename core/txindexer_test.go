@@ -17,12 +17,15 @@
 package core
 
 import (
+	"math"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -446,3 +449,95 @@ func TestTxIndexerReport(t *testing.T) {
 		db.Close()
 	}
 }
+
+// TestAddressTxIndex tests that transactions are indexed by sender, recipient
+// and internal call participants during block import.
+func TestAddressTxIndex(t *testing.T) {
+	var (
+		testBankKey, _  = crypto.GenerateKey()
+		testBankAddress = crypto.PubkeyToAddress(testBankKey.PublicKey)
+		testBankFunds   = big.NewInt(1000000000000000000)
+		recipient       = common.HexToAddress("0xdeadbeef")
+		caller          = common.HexToAddress("0xcc")
+		callee          = common.HexToAddress("0xbb")
+
+		// CALL(gas, 0xbb, 0, 0, 0, 0, 0)
+		code = append(append([]byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x73}, callee.Bytes()...), 0x5a, 0xf1, 0x00)
+
+		gspec = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				testBankAddress: {Balance: testBankFunds},
+				caller:          {Balance: common.Big0, Code: code},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = ethash.NewFaker()
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 10, func(i int, gen *BlockGen) {
+		to, gas := recipient, params.TxGas
+		if i%2 == 1 {
+			to, gas = caller, 100000
+		}
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), to, big.NewInt(1000), gas, big.NewInt(10*params.InitialBaseFee), nil), signer, testBankKey)
+		gen.AddTx(tx)
+	})
+	cfg := DefaultConfig()
+	cfg.TxLookupLimit = 0
+	cfg.AddressIndex = true
+	cfg.AddressIndexInternal = true
+
+	// Internal call indexing takes the tracer slot, live tracers are rejected.
+	traced := *cfg
+	traced.VmConfig.Tracer = &tracing.Hooks{}
+	if _, err := NewBlockChain(rawdb.NewMemoryDatabase(), gspec, engine, &traced); err == nil {
+		t.Fatal("Live tracer accepted with internal call indexing")
+	}
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), gspec, engine, cfg)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("Failed to insert chain: %v", err)
+	}
+	for i := 0; rawdb.ReadAddressTxIndexTail(chain.db) == nil; i++ {
+		if i == 100 {
+			t.Fatal("Address transaction index not initialized")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, test := range []struct {
+		address common.Address
+		count   int
+		roles   uint8
+	}{
+		{testBankAddress, 10, rawdb.AddressTxSender},
+		{recipient, 5, rawdb.AddressTxRecipient},
+		{caller, 5, rawdb.AddressTxRecipient | rawdb.AddressTxInternal},
+		{callee, 5, rawdb.AddressTxInternal},
+	} {
+		entries, err := chain.GetAddressTransactions(test.address, 0, 0, math.MaxUint64, 100)
+		if err != nil {
+			t.Fatalf("Failed to retrieve entries of %x: %v", test.address, err)
+		}
+		if len(entries) != test.count {
+			t.Fatalf("Address %x: entry count mismatch, want %d, got %d", test.address, test.count, len(entries))
+		}
+		for _, entry := range entries {
+			if entry.Roles != test.roles {
+				t.Fatalf("Address %x: role mismatch, want %d, got %d", test.address, test.roles, entry.Roles)
+			}
+			if tx := blocks[entry.BlockNumber-1].Transactions()[entry.TxIndex]; tx.Hash() != entry.TxHash {
+				t.Fatalf("Address %x: transaction mismatch at #%d", test.address, entry.BlockNumber)
+			}
+		}
+	}
+	// Continue from an intermediate position
+	entries, _ := chain.GetAddressTransactions(testBankAddress, 4, 0, 8, 3)
+	if len(entries) != 3 || entries[0].BlockNumber != 4 || entries[2].BlockNumber != 6 {
+		t.Fatalf("Unexpected page: %v", entries)
+	}
+}
@@ -18,6 +18,7 @@ package eth
 
 import (
 	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -27,8 +28,13 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// exportProgressInterval is the number of blocks after which the progress of a
+// chain export running as an RPC job is updated.
+const exportProgressInterval = 1000
+
 // AdminAPI is the collection of Ethereum full node related APIs for node
 // administration.
 type AdminAPI struct {
@@ -40,9 +46,21 @@ func NewAdminAPI(eth *Ethereum) *AdminAPI {
 	return &AdminAPI{eth: eth}
 }
 
+// BackfillHistory restores the pruned history of blocks first..last, storing it
+// as era1 files in the era directory. The source is either "peers", to retrieve
+// the history from the network, or the URL of an era1 file server. It returns
+// the number of era1 files added.
+func (api *AdminAPI) BackfillHistory(ctx context.Context, first, last uint64, source string) (int, error) {
+	if source == "" {
+		source = "peers"
+	}
+	return api.eth.backfill.backfill(ctx, first, last, source)
+}
+
 // ExportChain exports the current blockchain into a local file,
-// or a range of blocks if first and last are non-nil.
-func (api *AdminAPI) ExportChain(file string, first *uint64, last *uint64) (bool, error) {
+// or a range of blocks if first and last are non-nil. When running as an
+// asynchronous RPC job, the number of exported blocks is reported as progress.
+func (api *AdminAPI) ExportChain(ctx context.Context, file string, first *uint64, last *uint64) (bool, error) {
 	if first == nil && last != nil {
 		return false, errors.New("last cannot be specified without first")
 	}
@@ -50,6 +68,10 @@ func (api *AdminAPI) ExportChain(file string, first *uint64, last *uint64) (bool
 		head := api.eth.BlockChain().CurrentHeader().Number.Uint64()
 		last = &head
 	}
+	if first == nil {
+		genesis, head := uint64(0), api.eth.BlockChain().CurrentBlock().Number.Uint64()
+		first, last = &genesis, &head
+	}
 	if _, err := os.Stat(file); err == nil {
 		// File already exists. Allowing overwrite could be a DoS vector,
 		// since the 'file' may point to arbitrary paths on the drive.
@@ -68,12 +90,17 @@ func (api *AdminAPI) ExportChain(file string, first *uint64, last *uint64) (bool
 		defer writer.(*gzip.Writer).Close()
 	}
 
-	// Export the blockchain
-	if first != nil {
-		if err := api.eth.BlockChain().ExportN(writer, *first, *last); err != nil {
-			return false, err
+	// Export the blockchain, reporting the progress to the job if any
+	var progress func(uint64)
+	if job := rpc.JobFromContext(ctx); job != nil && *first <= *last {
+		total := *last - *first + 1
+		progress = func(exported uint64) {
+			if exported%exportProgressInterval == 0 || exported == total {
+				job.SetProgress(exported, total)
+			}
 		}
-	} else if err := api.eth.BlockChain().Export(writer); err != nil {
+	}
+	if err := api.eth.BlockChain().ExportRange(ctx, writer, *first, *last, progress); err != nil {
 		return false, err
 	}
 	return true, nil
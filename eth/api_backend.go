@@ -379,6 +379,12 @@ func (b *EthAPIBackend) TxIndexDone() bool {
 	return b.eth.blockchain.TxIndexDone()
 }
 
+// GetAddressTransactions retrieves the address-transaction index entries of the
+// given address in the specified range.
+func (b *EthAPIBackend) GetAddressTransactions(ctx context.Context, address common.Address, first uint64, index uint32, last uint64, limit int) ([]rawdb.AddressTxEntry, error) {
+	return b.eth.blockchain.GetAddressTransactions(address, first, index, last, limit)
+}
+
 func (b *EthAPIBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return b.eth.txPool.PoolNonce(addr), nil
 }
@@ -424,6 +430,10 @@ func (b *EthAPIBackend) FeeHistory(ctx context.Context, blockCount uint64, lastB
 	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
 }
 
+func (b *EthAPIBackend) FeeEstimates(ctx context.Context) (*gasprice.FeeEstimates, error) {
+	return b.gpo.FeeEstimates(ctx)
+}
+
 func (b *EthAPIBackend) BlobBaseFee(ctx context.Context) *big.Int {
 	if excess := b.CurrentHeader().ExcessBlobGas; excess != nil {
 		return eip4844.CalcBlobFee(b.ChainConfig(), b.CurrentHeader())
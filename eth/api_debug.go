@@ -18,18 +18,24 @@ package eth
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/protocols/snap"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
@@ -356,6 +362,215 @@ func (api *DebugAPI) getModifiedAccounts(startHeader, endHeader *types.Header) (
 	return dirty, nil
 }
 
+// StateDiffMaxBlocks is the maximum number of blocks whose state diffs can be
+// retrieved in a single request.
+const StateDiffMaxBlocks = 128
+
+// GetStateDiffByNumber returns the state diffs of the canonical blocks within
+// the specified range, both ends included. Each diff contains the new values
+// of the accounts and storage slots which were changed by the block, as well
+// as the accounts which were deleted.
+//
+// With one parameter, returns the state diff of the specified block.
+func (api *DebugAPI) GetStateDiffByNumber(startNum uint64, endNum *uint64) ([]*state.StateDiff, error) {
+	last := startNum
+	if endNum != nil {
+		last = *endNum
+	}
+	if startNum > last {
+		return nil, fmt.Errorf("start block (%d) must not be greater than end block (%d)", startNum, last)
+	}
+	if last-startNum >= StateDiffMaxBlocks {
+		return nil, fmt.Errorf("block range too large, maximum %d blocks", StateDiffMaxBlocks)
+	}
+	var diffs []*state.StateDiff
+	for number := startNum; number <= last; number++ {
+		header := api.eth.blockchain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("block %d not found", number)
+		}
+		diff, err := api.eth.blockchain.StateDiff(header)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// GetStateDiffByHash returns the state diff of the specified block. The block
+// doesn't have to be canonical, which allows consumers to retrieve the diffs
+// of the blocks on a side chain, as long as their states are still available.
+func (api *DebugAPI) GetStateDiffByHash(hash common.Hash) (*state.StateDiff, error) {
+	header := api.eth.blockchain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, fmt.Errorf("block %x not found", hash)
+	}
+	return api.eth.blockchain.StateDiff(header)
+}
+
+// StateDiffs creates a subscription that fires the state diff of every block
+// added to the canonical chain. If blocks are removed by a reorg, the reverted
+// diffs undoing them are sent first, newest block first, followed by the diffs
+// of the new canonical blocks in ascending order.
+//
+// Consumers should check that every diff links onto the last one applied: a
+// gap means some diffs could not be computed and the replica must be resynced.
+func (api *DebugAPI) StateDiffs(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	var (
+		chain   = api.eth.blockchain
+		rpcSub  = notifier.CreateSubscription()
+		heads   = make(chan core.ChainHeadEvent, 16)
+		headSub = chain.SubscribeChainHeadEvent(heads)
+	)
+	go func() {
+		defer headSub.Unsubscribe()
+
+		last := chain.CurrentBlock()
+		for {
+			select {
+			case ev := <-heads:
+				diffs, err := stateDiffsBetween(chain, last, ev.Header)
+				if err != nil {
+					log.Warn("Failed to compute state diffs", "number", ev.Header.Number, "hash", ev.Header.Hash(), "err", err)
+				}
+				for _, diff := range diffs {
+					notifier.Notify(rpcSub.ID, diff)
+				}
+				last = ev.Header
+			case <-rpcSub.Err():
+				return
+			case <-headSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// stateDiffsBetween returns the state diffs transitioning the chain from one
+// head to another: the reverted diffs of the blocks which are only on the old
+// chain, followed by the diffs of the blocks which are only on the new one.
+func stateDiffsBetween(chain *core.BlockChain, from, to *types.Header) ([]*state.StateDiff, error) {
+	var reverted, applied []*types.Header
+	for from.Hash() != to.Hash() {
+		if len(reverted)+len(applied) >= StateDiffMaxBlocks {
+			return nil, fmt.Errorf("too many blocks between %d and %d, maximum %d blocks", from.Number, to.Number, StateDiffMaxBlocks)
+		}
+		if from.Number.Cmp(to.Number) >= 0 {
+			if from.Number.Sign() == 0 {
+				return nil, errors.New("no common ancestor")
+			}
+			reverted = append(reverted, from)
+			if from = chain.GetHeader(from.ParentHash, from.Number.Uint64()-1); from == nil {
+				return nil, fmt.Errorf("block %d has no parent", reverted[len(reverted)-1].Number)
+			}
+		} else {
+			applied = append(applied, to)
+			if to = chain.GetHeader(to.ParentHash, to.Number.Uint64()-1); to == nil {
+				return nil, fmt.Errorf("block %d has no parent", applied[len(applied)-1].Number)
+			}
+		}
+	}
+	diffs := make([]*state.StateDiff, 0, len(reverted)+len(applied))
+	for _, header := range reverted {
+		diff, err := chain.RevertStateDiff(header)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	for i := len(applied) - 1; i >= 0; i-- {
+		diff, err := chain.StateDiff(applied[i])
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+const (
+	// accessProfilePersistLimit is the number of the most frequently accessed
+	// accounts and storage tries persisted from the state access profile. It
+	// also caps the entries returned by debug_stateAccessProfile.
+	accessProfilePersistLimit = 1024
+
+	// accessProfileDefaultLimit is the number of entries returned by
+	// debug_stateAccessProfile if no limit is given.
+	accessProfileDefaultLimit = 20
+)
+
+// persistAccessProfile stores the top entries of the state access profile into
+// the database, allowing them to be inspected offline with `geth db`.
+func persistAccessProfile(db ethdb.KeyValueWriter, profiler *state.AccessProfiler) {
+	blob, err := json.Marshal(profiler.Report(accessProfilePersistLimit))
+	if err != nil {
+		log.Error("Failed to encode state access profile", "err", err)
+		return
+	}
+	rawdb.WriteStateAccessProfile(db, blob)
+}
+
+// StartStateAccessProfile turns on the profiling of the state access, counting
+// the reads and writes per account and storage trie, along with the sources the
+// reads are served from. It's a no-op if the profiling is already running.
+func (api *DebugAPI) StartStateAccessProfile() {
+	state.EnableAccessProfiler()
+}
+
+// StopStateAccessProfile turns off the profiling of the state access. The top
+// entries collected so far are persisted in the database.
+func (api *DebugAPI) StopStateAccessProfile() error {
+	profiler := state.ActiveAccessProfiler()
+	if profiler == nil {
+		return errors.New("state access profiling is not running")
+	}
+	persistAccessProfile(api.eth.ChainDb(), profiler)
+	state.DisableAccessProfiler()
+	return nil
+}
+
+// ResetStateAccessProfile discards all the statistics of the state access
+// collected so far, without stopping the profiling.
+func (api *DebugAPI) ResetStateAccessProfile() error {
+	profiler := state.ActiveAccessProfiler()
+	if profiler == nil {
+		return errors.New("state access profiling is not running")
+	}
+	profiler.Reset()
+	return nil
+}
+
+// StateAccessProfile returns the most frequently accessed accounts and storage
+// tries since the profiling was started or last reset. The number of returned
+// entries is capped by the optional limit, defaulting to 20 if the limit is
+// omitted or zero, and at most 1024.
+//
+// The profiler tracks at most 131072 accounts. Beyond that, the least recently
+// accessed accounts are evicted with their statistics, so the counts of rarely
+// accessed accounts are approximate while the hot ones are kept accurate.
+func (api *DebugAPI) StateAccessProfile(limit *int) (*state.AccessProfileReport, error) {
+	profiler := state.ActiveAccessProfiler()
+	if profiler == nil {
+		return nil, errors.New("state access profiling is not running")
+	}
+	n := accessProfileDefaultLimit
+	if limit != nil {
+		switch {
+		case *limit < 0:
+			return nil, errors.New("negative limit")
+		case *limit > 0:
+			n = min(*limit, accessProfilePersistLimit)
+		}
+	}
+	return profiler.Report(n), nil
+}
+
 // GetAccessibleState returns the first number where the node has accessible
 // state on disk. Note this being the post-state of that block and the pre-state
 // of the next block.
@@ -443,3 +658,76 @@ func (api *DebugAPI) GetTrieFlushInterval() (string, error) {
 	}
 	return api.eth.blockchain.GetTrieFlushInterval().String(), nil
 }
+
+// snapSyncer returns the snap syncer, or an error if the node is not running
+// snap sync.
+func (api *DebugAPI) snapSyncer() (*snap.Syncer, error) {
+	if api.eth.SyncMode() != ethconfig.SnapSync {
+		return nil, errors.New("snap sync is not active")
+	}
+	syncer := api.eth.Downloader().SnapSyncer
+	if syncer == nil {
+		return nil, errors.New("snap sync is not active")
+	}
+	return syncer, nil
+}
+
+// SnapSyncStatus returns the detailed status of the snap syncer: the progress of
+// the account range tasks, the sizes of the request and heal queues, the
+// throughput of the peers and the estimated time left.
+func (api *DebugAPI) SnapSyncStatus() (*snap.SyncStatus, error) {
+	syncer, err := api.snapSyncer()
+	if err != nil {
+		return nil, err
+	}
+	return syncer.Status(), nil
+}
+
+// PauseSnapSync suspends sending snap sync requests to peers, until resumed.
+func (api *DebugAPI) PauseSnapSync() error {
+	syncer, err := api.snapSyncer()
+	if err != nil {
+		return err
+	}
+	syncer.Pause()
+	return nil
+}
+
+// ResumeSnapSync continues a paused snap sync.
+func (api *DebugAPI) ResumeSnapSync() error {
+	syncer, err := api.snapSyncer()
+	if err != nil {
+		return err
+	}
+	syncer.Resume()
+	return nil
+}
+
+// BanSnapPeer prevents a peer from serving snap sync data. The peer stays
+// connected and the ban is kept until UnbanSnapPeer is called.
+func (api *DebugAPI) BanSnapPeer(id string) error {
+	nodeID, err := enode.ParseID(id)
+	if err != nil {
+		return fmt.Errorf("invalid peer id: %v", err)
+	}
+	syncer, err := api.snapSyncer()
+	if err != nil {
+		return err
+	}
+	syncer.BanPeer(nodeID.String())
+	return nil
+}
+
+// UnbanSnapPeer allows a banned peer to serve snap sync data again. It returns
+// false if the peer was not banned.
+func (api *DebugAPI) UnbanSnapPeer(id string) (bool, error) {
+	nodeID, err := enode.ParseID(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid peer id: %v", err)
+	}
+	syncer, err := api.snapSyncer()
+	if err != nil {
+		return false, err
+	}
+	return syncer.UnbanPeer(nodeID.String()), nil
+}
@@ -335,3 +335,170 @@ func TestGetModifiedAccounts(t *testing.T) {
 		}
 	})
 }
+
+func TestGetStateDiff(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	genBlocks := 2
+	signer := types.HomesteadSigner{}
+	blockChain := newTestBlockChain(t, genBlocks, genesis, func(i int, b *core.BlockGen) {
+		// Transfer from account[0] to account[1]
+		tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    uint64(i),
+			To:       &accounts[1].addr,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: b.BaseFee(),
+			Data:     nil}),
+			signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	defer blockChain.Stop()
+
+	// Create a debug API instance.
+	api := NewDebugAPI(&Ethereum{blockchain: blockChain})
+
+	last := uint64(genBlocks)
+	diffs, err := api.GetStateDiffByNumber(1, &last)
+	assert.NoError(t, err)
+	assert.Len(t, diffs, genBlocks)
+	for i, diff := range diffs {
+		header := blockChain.GetHeaderByNumber(uint64(i + 1))
+		assert.Equal(t, header.Hash(), diff.Hash)
+		assert.Equal(t, header.ParentHash, diff.ParentHash)
+		assert.Len(t, diff.Accounts, 3) // sender, recipient and coinbase
+
+		for _, account := range diff.Accounts {
+			if account.Address == nil {
+				t.Fatalf("account %x without preimage", account.AddressHash)
+			}
+			if *account.Address == accounts[0].addr && account.Nonce != uint64(i+1) {
+				t.Fatalf("unexpected sender nonce, want %d, got %d", i+1, account.Nonce)
+			}
+			if *account.Address == accounts[1].addr && account.Balance.Uint64() != uint64(1000*(i+1)) {
+				t.Fatalf("unexpected recipient balance, want %d, got %d", 1000*(i+1), account.Balance.Uint64())
+			}
+		}
+		byHash, err := api.GetStateDiffByHash(header.Hash())
+		assert.NoError(t, err)
+		assert.Equal(t, diff, byHash)
+	}
+	// Ensure oversized ranges are rejected
+	last = StateDiffMaxBlocks
+	_, err = api.GetStateDiffByNumber(0, &last)
+	assert.Error(t, err)
+}
+
+func TestStateDiffsReorg(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	signer := types.HomesteadSigner{}
+	transfer := func(value int64) func(i int, b *core.BlockGen) {
+		return func(i int, b *core.BlockGen) {
+			tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    b.TxNonce(accounts[0].addr),
+				To:       &accounts[1].addr,
+				Value:    big.NewInt(value),
+				Gas:      params.TxGas,
+				GasPrice: b.BaseFee(),
+			}), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	}
+	chain := newTestBlockChain(t, 3, genesis, transfer(1000))
+	defer chain.Stop()
+
+	// Replace the last two blocks with a longer fork
+	oldHead := chain.CurrentBlock()
+	fork, _ := core.GenerateChain(genesis.Config, chain.GetBlockByNumber(1), ethash.NewFaker(), chain.StateCache().TrieDB().Disk(), 3, transfer(7))
+	if n, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("block %d: failed to insert fork: %v", n, err)
+	}
+	newHead := chain.CurrentBlock()
+	if newHead.Hash() != fork[2].Hash() {
+		t.Fatal("fork is not canonical")
+	}
+	diffs, err := stateDiffsBetween(chain, oldHead, newHead)
+	if err != nil {
+		t.Fatalf("failed to compute diffs: %v", err)
+	}
+	if len(diffs) != 5 {
+		t.Fatalf("unexpected number of diffs, want 5, got %d", len(diffs))
+	}
+	// The old blocks are reverted newest first, restoring the parent states
+	for i, number := range []uint64{3, 2} {
+		diff := diffs[i]
+		if !diff.Reverted || diff.Number != number {
+			t.Fatalf("diff %d: want reverted block %d, got %d (reverted %v)", i, number, diff.Number, diff.Reverted)
+		}
+		if parent := chain.GetHeaderByHash(diff.ParentHash); diff.Root != parent.Root {
+			t.Fatalf("diff %d: want parent root %x, got %x", i, parent.Root, diff.Root)
+		}
+		for _, account := range diff.Accounts {
+			if *account.Address == accounts[1].addr && account.Balance.Uint64() != 1000*(number-1) {
+				t.Fatalf("diff %d: unexpected recipient balance, want %d, got %d", i, 1000*(number-1), account.Balance.Uint64())
+			}
+		}
+	}
+	// The new blocks are applied in ascending order, linking onto each other
+	prev := chain.GetHeaderByNumber(1).Hash()
+	for i, diff := range diffs[2:] {
+		if diff.Reverted || diff.ParentHash != prev {
+			t.Fatalf("diff %d: block %d doesn't link onto %x", i+2, diff.Number, prev)
+		}
+		prev = diff.Hash
+	}
+	if prev != newHead.Hash() {
+		t.Fatal("last diff is not the new head")
+	}
+}
+
+func TestStateAccessProfileLimit(t *testing.T) {
+	api := NewDebugAPI(&Ethereum{})
+	if _, err := api.StateAccessProfile(nil); err == nil {
+		t.Fatal("profile returned while profiling is not running")
+	}
+	api.StartStateAccessProfile()
+	defer state.DisableAccessProfiler()
+
+	sdb := state.NewDatabase(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil), nil)
+	statedb, _ := state.New(types.EmptyRootHash, sdb)
+	for i := 0; i < 30; i++ {
+		statedb.GetBalance(common.BigToAddress(big.NewInt(int64(i + 1))))
+	}
+	for i, tc := range []struct {
+		limit *int
+		want  int
+	}{
+		{nil, accessProfileDefaultLimit},
+		{new(int), accessProfileDefaultLimit},
+		{func() *int { n := 25; return &n }(), 25},
+	} {
+		report, err := api.StateAccessProfile(tc.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Accounts) != tc.want {
+			t.Errorf("case %d: wrong number of accounts: have %d, want %d", i, len(report.Accounts), tc.want)
+		}
+	}
+	negative := -1
+	if _, err := api.StateAccessProfile(&negative); err == nil {
+		t.Fatal("negative limit accepted")
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/filtermaps"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/state/pruner"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
@@ -97,9 +98,10 @@ type Ethereum struct {
 	localTxTracker *locals.TxTracker
 	blockchain     *core.BlockChain
 
-	handler *handler
-	discmix *enode.FairMix
-	dropper *dropper
+	handler  *handler
+	discmix  *enode.FairMix
+	dropper  *dropper
+	backfill *historyBackfiller
 
 	// DB interfaces
 	chainDb ethdb.Database // Block chain database
@@ -222,17 +224,19 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	var (
 		options = &core.BlockChainConfig{
-			TrieCleanLimit:   config.TrieCleanCache,
-			NoPrefetch:       config.NoPrefetch,
-			TrieDirtyLimit:   config.TrieDirtyCache,
-			ArchiveMode:      config.NoPruning,
-			TrieTimeLimit:    config.TrieTimeout,
-			SnapshotLimit:    config.SnapshotCache,
-			Preimages:        config.Preimages,
-			StateHistory:     config.StateHistory,
-			StateScheme:      scheme,
-			ChainHistoryMode: config.HistoryMode,
-			TxLookupLimit:    int64(min(config.TransactionHistory, math.MaxInt64)),
+			TrieCleanLimit:       config.TrieCleanCache,
+			NoPrefetch:           config.NoPrefetch,
+			TrieDirtyLimit:       config.TrieDirtyCache,
+			ArchiveMode:          config.NoPruning,
+			TrieTimeLimit:        config.TrieTimeout,
+			SnapshotLimit:        config.SnapshotCache,
+			Preimages:            config.Preimages,
+			StateHistory:         config.StateHistory,
+			StateScheme:          scheme,
+			ChainHistoryMode:     config.HistoryMode,
+			TxLookupLimit:        int64(min(config.TransactionHistory, math.MaxInt64)),
+			AddressIndex:         config.AddressIndex,
+			AddressIndexInternal: config.AddressIndexInternal,
 			VmConfig: vm.Config{
 				EnablePreimageRecording: config.EnablePreimageRecording,
 			},
@@ -243,6 +247,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			TrieJournalDirectory: stack.ResolvePath("triedb"),
 		}
 	)
+	if config.StateAccessProfile {
+		state.EnableAccessProfiler()
+	}
 	if config.VMTrace != "" {
 		traceConfig := json.RawMessage("{}")
 		if config.VMTraceJsonConfig != "" {
@@ -332,6 +339,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 
 	eth.dropper = newDropper(eth.p2pServer.MaxDialedConns(), eth.p2pServer.MaxInboundConns())
+	eth.backfill = newHistoryBackfiller(eth.blockchain, chainDb, eth.handler.peers)
 
 	eth.miner = miner.New(eth, config.Miner, eth.engine)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
@@ -574,6 +582,11 @@ func (s *Ethereum) Stop() error {
 	s.blockchain.Stop()
 	s.engine.Close()
 
+	// Persist the state access profile if it's being collected, allowing it
+	// to be inspected offline.
+	if profiler := state.ActiveAccessProfiler(); profiler != nil {
+		persistAccessProfile(s.chainDb, profiler)
+	}
 	// Clean shutdown marker as the last thing before closing db
 	s.shutdownTracker.Stop()
 
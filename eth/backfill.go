@@ -0,0 +1,427 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/rawdb/eradb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/internal/era/eradl"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	backfillBatchSize      = 128              // Number of blocks to request from a peer at once
+	backfillRequestTimeout = 10 * time.Second // Time allowance for a peer to answer a request
+)
+
+var (
+	errNoEraStore       = errors.New("database has no era1 directory")
+	errHistoryNotPruned = errors.New("chain history is not pruned")
+	errNoHistoryPeers   = errors.New("no peers available to serve history")
+	errBackfillRunning  = errors.New("history backfill already in progress")
+)
+
+// historyBackfiller restores pruned chain history. Instead of writing to the
+// database, the history is stored as era1 files into the era directory of the
+// database, where it is picked up to serve peers and RPC requests.
+//
+// The era1 files are either downloaded from an era server, or built from block
+// bodies and receipts retrieved from peers, which are verified against the local
+// headers.
+type historyBackfiller struct {
+	chain   *core.BlockChain
+	peers   *peerSet
+	store   *eradb.Store
+	network string
+
+	running sync.Mutex
+	failed  map[string]bool // peers that failed a request during the current run
+}
+
+func newHistoryBackfiller(chain *core.BlockChain, db ethdb.Database, peers *peerSet) *historyBackfiller {
+	network := "unknown"
+	if name, ok := params.NetworkNames[chain.Config().ChainID.String()]; ok {
+		network = name
+	}
+	return &historyBackfiller{
+		chain:   chain,
+		peers:   peers,
+		store:   rawdb.EraStore(db),
+		network: network,
+	}
+}
+
+// backfill restores the history of blocks first..last. The source is either
+// "peers" or the URL of an era server. It returns the number of era1 files
+// added to the era directory.
+func (b *historyBackfiller) backfill(ctx context.Context, first, last uint64, source string) (int, error) {
+	if b.store == nil {
+		return 0, errNoEraStore
+	}
+	if !b.running.TryLock() {
+		return 0, errBackfillRunning
+	}
+	defer b.running.Unlock()
+
+	cutoff, _ := b.chain.HistoryPruningCutoff()
+	if cutoff == 0 {
+		return 0, errHistoryNotPruned
+	}
+	last = min(last, cutoff-1)
+	if first > last {
+		return 0, fmt.Errorf("range is not below the history pruning cutoff %d", cutoff)
+	}
+	var loader *eradl.Loader
+	if source != "peers" {
+		var err error
+		if loader, err = eradl.New(source, b.network); err != nil {
+			return 0, err
+		}
+	}
+	if err := os.MkdirAll(b.store.Dir(), 0755); err != nil {
+		return 0, err
+	}
+	epochs, err := b.store.Epochs()
+	if err != nil {
+		return 0, err
+	}
+	have := make(map[uint64]bool, len(epochs))
+	for _, epoch := range epochs {
+		have[epoch] = true
+	}
+	// Make the added files visible to the history range of the chain, also
+	// if the backfill fails halfway.
+	defer func() {
+		if err := b.store.Refresh(); err != nil {
+			log.Warn("Failed to list era1 files", "dir", b.store.Dir(), "err", err)
+		}
+	}()
+
+	var (
+		size   = uint64(era.MaxEra1Size)
+		td     = new(big.Int)
+		tdNext uint64 // number of the block td must be summed up to
+		added  int
+	)
+	b.failed = make(map[string]bool)
+	for epoch := first / size; epoch <= last/size; epoch++ {
+		if have[epoch] {
+			continue
+		}
+		start, end := epoch*size, min((epoch+1)*size, cutoff)-1
+		if loader != nil {
+			log.Info("Downloading era1 file", "epoch", epoch)
+			err = loader.DownloadEpochRange(epoch, epoch, b.store.Dir())
+		} else {
+			log.Info("Backfilling history from peers", "epoch", epoch, "first", start, "last", end)
+			if err = b.sumDifficulty(ctx, td, tdNext, start); err == nil {
+				err = b.fillFromPeers(ctx, epoch, start, end, td)
+				tdNext = end + 1
+			}
+		}
+		if err != nil {
+			return added, fmt.Errorf("backfill of epoch %d failed: %w", epoch, err)
+		}
+		added++
+	}
+	log.Info("History backfill completed", "files", added, "earliest", b.chain.EarliestAvailableBlock())
+	return added, nil
+}
+
+// sumDifficulty adds the difficulty of the blocks from..to-1 to td.
+func (b *historyBackfiller) sumDifficulty(ctx context.Context, td *big.Int, from, to uint64) error {
+	reported := time.Now()
+	for n := from; n < to; n++ {
+		header := b.chain.GetHeaderByNumber(n)
+		if header == nil {
+			return fmt.Errorf("missing header %d", n)
+		}
+		td.Add(td, header.Difficulty)
+		if time.Since(reported) >= 8*time.Second {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			log.Info("Computing total difficulty", "number", n, "target", to)
+			reported = time.Now()
+		}
+	}
+	return nil
+}
+
+// fillFromPeers creates the era1 file of an epoch from data retrieved from peers.
+// The total difficulty td is updated to include the blocks of the epoch.
+func (b *historyBackfiller) fillFromPeers(ctx context.Context, epoch, start, end uint64, td *big.Int) (err error) {
+	tmp := filepath.Join(b.store.Dir(), fmt.Sprintf("%s-%05d.era1.tmp", b.network, epoch))
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+		if err != nil {
+			os.Remove(tmp)
+		}
+	}()
+
+	var (
+		w        = era.NewBuilder(f)
+		reported = time.Now()
+	)
+	for n := start; n <= end; {
+		headers := make([]*types.Header, 0, backfillBatchSize)
+		for ; n <= end && len(headers) < backfillBatchSize; n++ {
+			header := b.chain.GetHeaderByNumber(n)
+			if header == nil {
+				return fmt.Errorf("missing header %d", n)
+			}
+			headers = append(headers, header)
+		}
+		bodies, err := b.fetchBodies(ctx, headers)
+		if err != nil {
+			return err
+		}
+		receipts, err := b.fetchReceipts(ctx, headers, bodies)
+		if err != nil {
+			return err
+		}
+		for i, header := range headers {
+			td.Add(td, header.Difficulty)
+			block := types.NewBlockWithHeader(header).WithBody(*bodies[i])
+			if err := w.Add(block, receipts[i], new(big.Int).Set(td)); err != nil {
+				return err
+			}
+		}
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Backfilling history from peers", "epoch", epoch, "number", n-1, "last", end)
+			reported = time.Now()
+		}
+	}
+	root, err := w.Finalize()
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(b.store.Dir(), era.Filename(b.network, int(epoch), root)))
+}
+
+// fetchBodies retrieves the block bodies of the given headers.
+func (b *historyBackfiller) fetchBodies(ctx context.Context, headers []*types.Header) ([]*types.Body, error) {
+	var (
+		bodies = make([]*types.Body, len(headers))
+		need   []int
+	)
+	for i, header := range headers {
+		if header.TxHash == types.EmptyTxsHash && header.UncleHash == types.EmptyUncleHash {
+			bodies[i] = new(types.Body)
+		} else {
+			need = append(need, i)
+		}
+	}
+	for len(need) > 0 {
+		hashes := make([]common.Hash, len(need))
+		for i, idx := range need {
+			hashes[i] = headers[idx].Hash()
+		}
+		res, peer, err := b.request(ctx, headers[need[0]].Number.Uint64(), func(p *ethPeer, sink chan *eth.Response) (*eth.Request, error) {
+			return p.RequestBodies(hashes, sink)
+		})
+		if err != nil {
+			return nil, err
+		}
+		var (
+			delivered = *res.Res.(*eth.BlockBodiesResponse)
+			hashsets  = res.Meta.([][]common.Hash) // {txs hashes, uncle hashes, withdrawal hashes}
+		)
+		if len(delivered) > len(need) {
+			b.fail(peer, res, errors.New("too many block bodies"))
+			continue
+		}
+		for i, body := range delivered {
+			header := headers[need[i]]
+			if hashsets[0][i] != header.TxHash || hashsets[1][i] != header.UncleHash {
+				err = errors.New("invalid block body")
+				break
+			}
+			bodies[need[i]] = &types.Body{Transactions: body.Transactions, Uncles: body.Uncles}
+		}
+		if err != nil || len(delivered) == 0 {
+			b.fail(peer, res, err)
+			continue
+		}
+		res.Done <- nil
+		need = need[len(delivered):]
+	}
+	return bodies, nil
+}
+
+// fetchReceipts retrieves the receipts of the given blocks.
+func (b *historyBackfiller) fetchReceipts(ctx context.Context, headers []*types.Header, bodies []*types.Body) ([]types.Receipts, error) {
+	var (
+		receipts = make([]types.Receipts, len(headers))
+		need     []int
+	)
+	for i, header := range headers {
+		if header.ReceiptHash == types.EmptyReceiptsHash {
+			receipts[i] = types.Receipts{}
+		} else {
+			need = append(need, i)
+		}
+	}
+	for len(need) > 0 {
+		hashes := make([]common.Hash, len(need))
+		for i, idx := range need {
+			hashes[i] = headers[idx].Hash()
+		}
+		res, peer, err := b.request(ctx, headers[need[0]].Number.Uint64(), func(p *ethPeer, sink chan *eth.Response) (*eth.Request, error) {
+			return p.RequestReceipts(hashes, sink)
+		})
+		if err != nil {
+			return nil, err
+		}
+		var (
+			delivered = *res.Res.(*eth.ReceiptsRLPResponse)
+			rhashes   = res.Meta.([]common.Hash)
+		)
+		if len(delivered) > len(need) {
+			b.fail(peer, res, errors.New("too many receipts"))
+			continue
+		}
+		for i, data := range delivered {
+			idx := need[i]
+			if rhashes[i] != headers[idx].ReceiptHash {
+				err = errors.New("invalid receipts")
+				break
+			}
+			if receipts[idx], err = decodeStorageReceipts(data, bodies[idx].Transactions); err != nil {
+				break
+			}
+		}
+		if err != nil || len(delivered) == 0 {
+			b.fail(peer, res, err)
+			continue
+		}
+		res.Done <- nil
+		need = need[len(delivered):]
+	}
+	return receipts, nil
+}
+
+// decodeStorageReceipts converts receipts in storage encoding back into full
+// consensus receipts, using the transactions of the block.
+func decodeStorageReceipts(data []byte, txs []*types.Transaction) (types.Receipts, error) {
+	var stored []*types.ReceiptForStorage
+	if err := rlp.DecodeBytes(data, &stored); err != nil {
+		return nil, err
+	}
+	if len(stored) != len(txs) {
+		return nil, fmt.Errorf("receipt count %d != transaction count %d", len(stored), len(txs))
+	}
+	receipts := make(types.Receipts, len(stored))
+	for i, r := range stored {
+		receipts[i] = (*types.Receipt)(r)
+		receipts[i].Type = txs[i].Type()
+		receipts[i].Bloom = types.CreateBloom(receipts[i])
+	}
+	return receipts, nil
+}
+
+// request sends a request to a peer which may have the history of the given
+// block. If the peer doesn't answer in time, the request is retried with
+// another peer.
+func (b *historyBackfiller) request(ctx context.Context, number uint64, send func(*ethPeer, chan *eth.Response) (*eth.Request, error)) (*eth.Response, *ethPeer, error) {
+	for {
+		peer := b.pickPeer(number)
+		if peer == nil {
+			return nil, nil, errNoHistoryPeers
+		}
+		sink := make(chan *eth.Response)
+		req, err := send(peer, sink)
+		if err != nil {
+			b.failed[peer.ID()] = true
+			continue
+		}
+		timeout := time.NewTimer(backfillRequestTimeout)
+		select {
+		case res := <-sink:
+			timeout.Stop()
+			req.Close()
+			return res, peer, nil
+		case <-timeout.C:
+			peer.Log().Debug("History backfill request timed out", "number", number)
+			b.failed[peer.ID()] = true
+			req.Close()
+		case <-ctx.Done():
+			timeout.Stop()
+			req.Close()
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// fail excludes a peer from the current backfill run after it did not deliver
+// the requested data. If err is non-nil, the peer delivered invalid data and is
+// disconnected.
+func (b *historyBackfiller) fail(peer *ethPeer, res *eth.Response, err error) {
+	b.failed[peer.ID()] = true
+	if err != nil {
+		peer.Log().Debug("Invalid history delivered", "err", err)
+	}
+	res.Done <- err
+}
+
+// pickPeer selects a random peer which announced that it has the history of
+// the given block. Peers on eth/68 don't announce their range, they are tried
+// as well.
+func (b *historyBackfiller) pickPeer(number uint64) *ethPeer {
+	b.peers.lock.RLock()
+	defer b.peers.lock.RUnlock()
+
+	var candidates []*ethPeer
+	for id, p := range b.peers.peers {
+		if b.failed[id] {
+			continue
+		}
+		if br := p.BlockRange(); br != nil && br.EarliestBlock > number {
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rand.IntN(len(candidates))]
+}
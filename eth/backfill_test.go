@@ -0,0 +1,287 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/history"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// This test checks that receipts in storage encoding, as delivered by peers,
+// are restored into the consensus receipts stored in era1 files.
+func TestDecodeStorageReceipts(t *testing.T) {
+	var (
+		txs = []*types.Transaction{
+			types.NewTx(&types.LegacyTx{Nonce: 0}),
+			types.NewTx(&types.DynamicFeeTx{Nonce: 1}),
+		}
+		receipts = types.Receipts{
+			{
+				Type:              types.LegacyTxType,
+				Status:            types.ReceiptStatusSuccessful,
+				CumulativeGasUsed: 21000,
+				Logs:              []*types.Log{{Address: common.Address{1}, Topics: []common.Hash{{2}}, Data: []byte{3}}},
+			},
+			{
+				Type:              types.DynamicFeeTxType,
+				Status:            types.ReceiptStatusFailed,
+				CumulativeGasUsed: 50000,
+				Logs:              []*types.Log{},
+			},
+		}
+	)
+	for _, r := range receipts {
+		r.Bloom = types.CreateBloom(r)
+	}
+	want := types.DeriveSha(receipts, trie.NewStackTrie(nil))
+
+	stored := types.EncodeBlockReceiptLists([]types.Receipts{receipts})[0]
+	have, err := decodeStorageReceipts(stored, txs)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if hash := types.DeriveSha(have, trie.NewStackTrie(nil)); hash != want {
+		t.Fatalf("wrong receipts root %x, want %x", hash, want)
+	}
+	if _, err := decodeStorageReceipts(stored, txs[:1]); err == nil {
+		t.Fatal("no error for receipt count mismatch")
+	}
+}
+
+// This test checks that pruned history is restored from a peer into era1 files,
+// and that the new history start is announced to peers afterwards.
+func TestBackfillFromPeers(t *testing.T) {
+	var (
+		engine = ethash.NewFaker()
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{testAddr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+		size   = uint64(era.MaxEra1Size)
+		cutoff = size + 64
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, engine, int(cutoff)+16, func(i int, b *core.BlockGen) {
+		// Only some blocks have transactions, most bodies and receipts are
+		// empty and never requested.
+		if i%1000 != 0 && i != int(size)+10 {
+			return
+		}
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(testAddr), common.Address{1}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, testKey)
+		b.AddTx(tx)
+	})
+	// Register the history pruning point of the test chain.
+	ghash := gspec.ToBlock().Hash()
+	history.PrunePoints[ghash] = &history.PrunePoint{
+		BlockNumber: cutoff,
+		BlockHash:   blocks[cutoff-1].Hash(),
+	}
+	defer delete(history.PrunePoints, ghash)
+
+	// Create the serving node with the full history, and the node with pruned
+	// history and an era directory. The bodies below the cutoff are imported by
+	// the pruned node as well, but the backfiller only retrieves them from peers.
+	server := newBackfillTestHandler(t, rawdb.NewMemoryDatabase(), gspec, core.DefaultConfig(), blocks[:len(blocks)-1])
+	defer server.close()
+
+	db, err := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{Ancient: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	config := core.DefaultConfig()
+	config.ChainHistoryMode = history.KeepPostMerge
+	client := newBackfillTestHandler(t, db, gspec, config, blocks[:len(blocks)-1])
+	defer client.close()
+
+	if earliest := client.chain.EarliestAvailableBlock(); earliest != cutoff {
+		t.Fatalf("wrong earliest block before backfill: %d, want %d", earliest, cutoff)
+	}
+	clientPeer := connectBackfillPeers(t, server, client)
+
+	// Backfill the second epoch first, which requires summing up the difficulty
+	// of the first epoch from the local headers, then the remaining history.
+	backfiller := newHistoryBackfiller(client.chain, client.db, client.handler.peers)
+	if n, err := backfiller.backfill(context.Background(), size, cutoff, "peers"); err != nil || n != 1 {
+		t.Fatalf("backfill of epoch 1 failed: files %d, err %v", n, err)
+	}
+	if earliest := client.chain.EarliestAvailableBlock(); earliest != size {
+		t.Fatalf("wrong earliest block after epoch 1: %d, want %d", earliest, size)
+	}
+	if n, err := backfiller.backfill(context.Background(), 0, cutoff, "peers"); err != nil || n != 1 {
+		t.Fatalf("backfill of epoch 0 failed: files %d, err %v", n, err)
+	}
+	if earliest := client.chain.EarliestAvailableBlock(); earliest != 0 {
+		t.Fatalf("wrong earliest block after backfill: %d, want 0", earliest)
+	}
+	checkBackfilledEra(t, backfiller.store.Dir(), append([]*types.Block{server.chain.Genesis()}, blocks[:cutoff-1]...))
+
+	// The next head block should announce the restored history.
+	if _, err := server.chain.InsertChain(blocks[len(blocks)-1:]); err != nil {
+		t.Fatalf("failed to insert head block: %v", err)
+	}
+	if _, err := client.chain.InsertChain(blocks[len(blocks)-1:]); err != nil {
+		t.Fatalf("failed to insert head block: %v", err)
+	}
+	want := eth.BlockRangeUpdatePacket{EarliestBlock: 0, LatestBlock: uint64(len(blocks)), LatestBlockHash: blocks[len(blocks)-1].Hash()}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		br := server.handler.peers.peer(clientPeer.ID()).BlockRange()
+		if *br == want && client.handler.blockRange.currentRange() == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("wrong range received by peer: %+v, want %+v", *br, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newBackfillTestHandler creates a handler on a chain with the given blocks.
+func newBackfillTestHandler(t *testing.T, db ethdb.Database, gspec *core.Genesis, config *core.BlockChainConfig, blocks []*types.Block) *testHandler {
+	chain, err := core.NewBlockChain(db, gspec, ethash.NewFaker(), config)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert: %v", n, err)
+	}
+	txpool := newTestTxPool()
+	handler, err := newHandler(&handlerConfig{
+		Database:   db,
+		Chain:      chain,
+		TxPool:     txpool,
+		Network:    1,
+		Sync:       ethconfig.FullSync,
+		BloomCache: 1,
+	})
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	handler.Start(1000)
+	return &testHandler{db: db, chain: chain, txpool: txpool, handler: handler}
+}
+
+// connectBackfillPeers connects two handlers over eth/69, returning the peer of
+// the client as seen by the server.
+func connectBackfillPeers(t *testing.T, server, client *testHandler) *eth.Peer {
+	serverPipe, clientPipe := p2p.MsgPipe()
+	t.Cleanup(func() {
+		serverPipe.Close()
+		clientPipe.Close()
+	})
+	var (
+		serverPeer = eth.NewPeer(eth.ETH69, p2p.NewPeerPipe(enode.ID{1}, "", nil, clientPipe), clientPipe, client.txpool)
+		clientPeer = eth.NewPeer(eth.ETH69, p2p.NewPeerPipe(enode.ID{2}, "", nil, serverPipe), serverPipe, server.txpool)
+	)
+	t.Cleanup(serverPeer.Close)
+	t.Cleanup(clientPeer.Close)
+
+	go client.handler.runEthPeer(serverPeer, func(peer *eth.Peer) error {
+		return eth.Handle((*ethHandler)(client.handler), peer)
+	})
+	go server.handler.runEthPeer(clientPeer, func(peer *eth.Peer) error {
+		return eth.Handle((*ethHandler)(server.handler), peer)
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	for client.handler.peers.peer(serverPeer.ID()) == nil || server.handler.peers.peer(clientPeer.ID()) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("peers didn't connect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return clientPeer
+}
+
+// checkBackfilledEra verifies that the era1 files in dir contain the given
+// blocks starting at genesis, along with their receipts and total difficulty.
+func checkBackfilledEra(t *testing.T, dir string, blocks []*types.Block) {
+	files, err := era.ReadDir(dir, "mainnet")
+	if err != nil {
+		t.Fatalf("failed to list era1 files: %v", err)
+	}
+	if want := (len(blocks) + era.MaxEra1Size - 1) / era.MaxEra1Size; len(files) != want {
+		t.Fatalf("wrong number of era1 files: %d, want %d", len(files), want)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(tmp) != 0 {
+		t.Fatalf("leftover temporary files: %v", tmp)
+	}
+	var (
+		td     = new(big.Int)
+		number uint64
+	)
+	for _, file := range files {
+		e, err := era.Open(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", file, err)
+		}
+		it, err := era.NewIterator(e)
+		if err != nil {
+			t.Fatalf("failed to iterate %s: %v", file, err)
+		}
+		for it.Next() {
+			block, receipts, err := it.BlockAndReceipts()
+			if err != nil {
+				t.Fatalf("block %d: %v", it.Number(), err)
+			}
+			want := blocks[number]
+			if block.Hash() != want.Hash() {
+				t.Fatalf("block %d: wrong hash %x, want %x", number, block.Hash(), want.Hash())
+			}
+			if block.Transactions().Len() != want.Transactions().Len() {
+				t.Fatalf("block %d: wrong transaction count %d, want %d", number, block.Transactions().Len(), want.Transactions().Len())
+			}
+			if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != want.ReceiptHash() {
+				t.Fatalf("block %d: wrong receipts root %x, want %x", number, hash, want.ReceiptHash())
+			}
+			td.Add(td, want.Difficulty())
+			have, err := it.TotalDifficulty()
+			if err != nil {
+				t.Fatalf("block %d: %v", number, err)
+			}
+			if have.Cmp(td) != 0 {
+				t.Fatalf("block %d: wrong total difficulty %v, want %v", number, have, td)
+			}
+			number++
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("failed to iterate %s: %v", file, err)
+		}
+		e.Close()
+	}
+	if number != uint64(len(blocks)) {
+		t.Fatalf("wrong number of blocks: %d, want %d", number, len(blocks))
+	}
+}
@@ -175,10 +175,16 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 	c.feeRecipientLock.Unlock()
 
 	// Reset to CurrentBlock in case of the chain was rewound
-	if header := c.eth.BlockChain().CurrentBlock(); c.curForkchoiceState.HeadBlockHash != header.Hash() {
+	header := c.eth.BlockChain().CurrentBlock()
+	if c.curForkchoiceState.HeadBlockHash != header.Hash() {
 		finalizedHash := c.finalizedBlockHash(header.Number.Uint64())
 		c.setCurrentState(header.Hash(), *finalizedHash)
 	}
+	// The chain might have been advanced by blocks sealed elsewhere and synced
+	// over the network, make sure the new block is not older than its parent.
+	if timestamp <= header.Time {
+		timestamp = header.Time + 1
+	}
 
 	// Because transaction insertion, block insertion, and block production will
 	// happen without any timing delay between them in simulator mode and the
@@ -51,8 +51,9 @@ var (
 
 // dropper monitors the state of the peer pool and makes changes as follows:
 //   - during sync the Downloader handles peer connections, so dropper is disabled
-//   - if not syncing and the peer count is close to the limit, it drops peers
-//     randomly every peerDropInterval to make space for new peers
+//   - if not syncing and the peer count is close to the limit, it drops the peer
+//     with the lowest reputation score every peerDropInterval to make space for
+//     new peers
 //   - peers are dropped separately from the inboud pool and from the dialed pool
 type dropper struct {
 	maxDialPeers    int // maximum number of dialed peers
@@ -103,8 +104,9 @@ func (cm *dropper) Stop() {
 	cm.wg.Wait()
 }
 
-// dropRandomPeer selects one of the peers randomly and drops it from the peer pool.
-func (cm *dropper) dropRandomPeer() bool {
+// dropWorstPeer selects the droppable peer with the lowest reputation score and
+// drops it from the peer pool.
+func (cm *dropper) dropWorstPeer() bool {
 	peers := cm.peersFunc()
 	var numInbound int
 	for _, p := range peers {
@@ -125,10 +127,9 @@ func (cm *dropper) dropRandomPeer() bool {
 	}
 
 	droppable := slices.DeleteFunc(peers, selectDoNotDrop)
-	if len(droppable) > 0 {
-		p := droppable[mrand.Intn(len(droppable))]
-		log.Debug("Dropping random peer", "inbound", p.Inbound(),
-			"id", p.ID(), "duration", common.PrettyDuration(p.Lifetime()), "peercountbefore", len(peers))
+	if p := selectWorstPeer(droppable); p != nil {
+		log.Debug("Dropping worst peer", "inbound", p.Inbound(), "id", p.ID(), "score", p.Score(),
+			"duration", common.PrettyDuration(p.Lifetime()), "peercountbefore", len(peers))
 		p.Disconnect(p2p.DiscUselessPeer)
 		if p.Inbound() {
 			droppedInbound.Mark(1)
@@ -140,6 +141,31 @@ func (cm *dropper) dropRandomPeer() bool {
 	return false
 }
 
+// selectWorstPeer returns the peer with the lowest reputation score, or nil if
+// there are no peers. Ties are broken randomly, so that peers without any score
+// yet are still churned.
+func selectWorstPeer(peers []*p2p.Peer) *p2p.Peer {
+	var (
+		worst *p2p.Peer
+		score float64
+		ties  int
+	)
+	for _, p := range peers {
+		s := p.Score()
+		switch {
+		case worst == nil || s < score:
+			worst, score, ties = p, s, 1
+		case s == score:
+			// Reservoir sampling among the equally scored peers
+			ties++
+			if mrand.Intn(ties) == 0 {
+				worst = p
+			}
+		}
+	}
+	return worst
+}
+
 // randomDuration generates a random duration between min and max.
 func randomDuration(min, max time.Duration) time.Duration {
 	if min > max {
@@ -155,9 +181,9 @@ func (cm *dropper) loop() {
 	for {
 		select {
 		case <-cm.peerDropTimer.C:
-			// Drop a random peer if we are not syncing and the peer count is close to the limit.
+			// Drop the worst peer if we are not syncing and the peer count is close to the limit.
 			if !cm.syncingFunc() {
-				cm.dropRandomPeer()
+				cm.dropWorstPeer()
 			}
 			cm.peerDropTimer.Reset(randomDuration(peerDropIntervalMin, peerDropIntervalMax))
 		case <-cm.shutdownCh:
@@ -0,0 +1,56 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Tests that the dropper selects the peer with the lowest reputation score and
+// breaks ties between equally scored peers randomly.
+func TestSelectWorstPeer(t *testing.T) {
+	if p := selectWorstPeer(nil); p != nil {
+		t.Fatalf("selected peer from empty set: %v", p)
+	}
+	var peers []*p2p.Peer
+	for i := 0; i < 4; i++ {
+		peers = append(peers, p2p.NewPeer(enode.ID{byte(i)}, "test", nil))
+	}
+	peers[0].Report(p2p.ScoreUseful)
+	peers[1].Report(p2p.ScoreTimeout)
+	peers[2].Report(p2p.ScoreInvalid)
+	peers[3].Report(p2p.ScoreUseful)
+
+	if p := selectWorstPeer(peers); p != peers[2] {
+		t.Fatalf("wrong peer selected: have %v, want %v", p.ID(), peers[2].ID())
+	}
+	// Check that ties between peers without any score yet are broken randomly.
+	fresh := []*p2p.Peer{
+		p2p.NewPeer(enode.ID{0x10}, "test", nil),
+		p2p.NewPeer(enode.ID{0x11}, "test", nil),
+	}
+	selected := make(map[enode.ID]bool)
+	for i := 0; i < 100; i++ {
+		selected[selectWorstPeer(append(fresh, peers[0])).ID()] = true
+	}
+	if len(selected) != 2 || !selected[fresh[0].ID()] || !selected[fresh[1].ID()] {
+		t.Fatalf("wrong tie breaking, selected peers: %v", selected)
+	}
+}
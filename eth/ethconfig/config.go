@@ -45,6 +45,8 @@ var FullNodeGPO = gasprice.Config{
 	MaxBlockHistory:  1024,
 	MaxPrice:         gasprice.DefaultMaxPrice,
 	IgnorePrice:      gasprice.DefaultIgnorePrice,
+	Strategy:         gasprice.StrategyPercentile,
+	InclusionBlocks:  gasprice.DefaultInclusionBlocks,
 }
 
 // Defaults contains default settings for use on the Ethereum main net.
@@ -102,6 +104,8 @@ type Config struct {
 	TransactionHistory   uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	LogHistory           uint64 `toml:",omitempty"` // The maximum number of blocks from head where a log search index is maintained.
 	LogNoHistory         bool   `toml:",omitempty"` // No log search index is maintained.
+	AddressIndex         bool   `toml:",omitempty"` // Maintain an address to transaction index alongside the tx index.
+	AddressIndexInternal bool   `toml:",omitempty"` // Include internal call participants in the address index.
 	LogExportCheckpoints string // export log index checkpoints to file
 	StateHistory         uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
 
@@ -128,6 +132,9 @@ type Config struct {
 	SnapshotCache  int
 	Preimages      bool
 
+	// Enables profiling of the state access per account and storage trie
+	StateAccessProfile bool `toml:",omitempty"`
+
 	// This is the number of blocks for which logs will be cached in the filter system.
 	FilterLogCacheSize int
 
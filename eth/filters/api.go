@@ -262,28 +262,35 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+func (api *FilterAPI) Logs(ctx context.Context, crit ExtendedFilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
+	if crit.paginated() {
+		return nil, errPaginationUnsupported
+	}
+	if err := crit.validate(); err != nil {
+		return nil, err
+	}
 
 	var (
 		rpcSub      = notifier.CreateSubscription()
 		matchedLogs = make(chan []*types.Log)
 	)
 
-	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit), matchedLogs)
+	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit.FilterCriteria), matchedLogs)
 	if err != nil {
 		return nil, err
 	}
+	match := crit.match()
 
 	go func() {
 		defer logsSub.Unsubscribe()
 		for {
 			select {
 			case logs := <-matchedLogs:
-				for _, log := range logs {
+				for _, log := range match.filter(logs) {
 					notifier.Notify(rpcSub.ID, &log)
 				}
 			case <-rpcSub.Err(): // client send an unsubscribe request
@@ -343,14 +350,56 @@ func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 }
 
 // GetLogs returns logs matching the given argument that are stored within the state.
+//
+// Queries running as asynchronous jobs report the logs as partial results, so
+// that they count once against the job's result size limit, and return no logs
+// themselves.
 func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
-	if len(crit.Topics) > maxTopics {
-		return nil, errExceedMaxTopics
+	filter, err := api.logsFilter(&ExtendedFilterCriteria{FilterCriteria: crit})
+	if err != nil {
+		return nil, err
+	}
+	if job := rpc.JobFromContext(ctx); job != nil {
+		if err := filter.jobLogs(ctx, job); err != nil {
+			return nil, err
+		}
+		return []*types.Log{}, nil
+	}
+	// Run the filter and return all the logs
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return returnLogs(logs), err
+}
+
+// GetLogsPage returns a single page of logs matching the given extended criteria,
+// starting at the criteria's cursor. The returned cursor can be used to request
+// the next page and is omitted once the filtered range is exhausted. A page may
+// hold fewer logs than the limit if its search budget is used up before the end
+// of the range, the cursor then points to the first block not searched yet.
+func (api *FilterAPI) GetLogsPage(ctx context.Context, crit ExtendedFilterCriteria) (*LogsPage, error) {
+	filter, err := api.logsFilter(&crit)
+	if err != nil {
+		return nil, err
 	}
-	if len(crit.Addresses) > maxAddresses {
-		return nil, errExceedMaxAddresses
+	limit := defaultPageSize
+	if crit.Limit != 0 {
+		limit = int(crit.Limit)
 	}
+	logs, cursor, err := filter.LogsPage(ctx, crit.Cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &LogsPage{Logs: returnLogs(logs), Cursor: cursor}, nil
+}
 
+// logsFilter validates the criteria of a log query and constructs the filter
+// executing it.
+func (api *FilterAPI) logsFilter(crit *ExtendedFilterCriteria) (*Filter, error) {
+	if err := crit.validate(); err != nil {
+		return nil, err
+	}
 	var filter *Filter
 	if crit.BlockHash != nil {
 		if crit.FromBlock != nil || crit.ToBlock != nil {
@@ -379,13 +428,8 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 		// Construct the range filter
 		filter = api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
 	}
-
-	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return returnLogs(logs), err
+	filter.extended = crit.match()
+	return filter, nil
 }
 
 // UninstallFilter removes the filter with the given filter id.
@@ -540,84 +584,104 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 	}
 
 	args.Addresses = []common.Address{}
-
 	if raw.Addresses != nil {
-		// raw.Address can contain a single address or an array of addresses
-		switch rawAddr := raw.Addresses.(type) {
-		case []interface{}:
-			if len(rawAddr) > maxAddresses {
-				return errExceedMaxAddresses
-			}
-			for i, addr := range rawAddr {
-				if strAddr, ok := addr.(string); ok {
-					addr, err := decodeAddress(strAddr)
-					if err != nil {
-						return fmt.Errorf("invalid address at index %d: %v", i, err)
-					}
-					args.Addresses = append(args.Addresses, addr)
-				} else {
-					return fmt.Errorf("non-string address at index %d", i)
-				}
-			}
-		case string:
-			addr, err := decodeAddress(rawAddr)
-			if err != nil {
-				return fmt.Errorf("invalid address: %v", err)
-			}
-			args.Addresses = []common.Address{addr}
-		default:
-			return errors.New("invalid addresses in query")
+		addresses, err := decodeAddresses(raw.Addresses)
+		if err != nil {
+			return err
 		}
+		args.Addresses = addresses
 	}
 	if len(raw.Topics) > maxTopics {
 		return errExceedMaxTopics
 	}
+	topics, err := decodeTopicList(raw.Topics)
+	if err != nil {
+		return err
+	}
+	args.Topics = topics
 
-	// topics is an array consisting of strings and/or arrays of strings.
-	// JSON null values are converted to common.Hash{} and ignored by the filter manager.
-	if len(raw.Topics) > 0 {
-		args.Topics = make([][]common.Hash, len(raw.Topics))
-		for i, t := range raw.Topics {
-			switch topic := t.(type) {
-			case nil:
-				// ignore topic when matching logs
+	return nil
+}
 
-			case string:
-				// match specific topic
-				top, err := decodeTopic(topic)
+// decodeAddresses decodes an address criteria, which can either be a single
+// address or an array of addresses.
+func decodeAddresses(raw interface{}) ([]common.Address, error) {
+	switch rawAddr := raw.(type) {
+	case []interface{}:
+		if len(rawAddr) > maxAddresses {
+			return nil, errExceedMaxAddresses
+		}
+		addresses := make([]common.Address, 0, len(rawAddr))
+		for i, addr := range rawAddr {
+			if strAddr, ok := addr.(string); ok {
+				addr, err := decodeAddress(strAddr)
 				if err != nil {
-					return err
+					return nil, fmt.Errorf("invalid address at index %d: %v", i, err)
 				}
-				args.Topics[i] = []common.Hash{top}
+				addresses = append(addresses, addr)
+			} else {
+				return nil, fmt.Errorf("non-string address at index %d", i)
+			}
+		}
+		return addresses, nil
+	case string:
+		addr, err := decodeAddress(rawAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address: %v", err)
+		}
+		return []common.Address{addr}, nil
+	default:
+		return nil, errors.New("invalid addresses in query")
+	}
+}
 
-			case []interface{}:
-				// or case e.g. [null, "topic0", "topic1"]
-				if len(topic) > maxSubTopics {
-					return errExceedMaxTopics
+// decodeTopicList decodes a positional list of topic criteria. The list is an
+// array consisting of strings and/or arrays of strings. JSON null values are
+// converted to wildcards and ignored by the filter manager.
+func decodeTopicList(raw []interface{}) ([][]common.Hash, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	topics := make([][]common.Hash, len(raw))
+	for i, t := range raw {
+		switch topic := t.(type) {
+		case nil:
+			// ignore topic when matching logs
+
+		case string:
+			// match specific topic
+			top, err := decodeTopic(topic)
+			if err != nil {
+				return nil, err
+			}
+			topics[i] = []common.Hash{top}
+
+		case []interface{}:
+			// or case e.g. [null, "topic0", "topic1"]
+			if len(topic) > maxSubTopics {
+				return nil, errExceedMaxTopics
+			}
+			for _, rawTopic := range topic {
+				if rawTopic == nil {
+					// null component, match all
+					topics[i] = nil
+					break
 				}
-				for _, rawTopic := range topic {
-					if rawTopic == nil {
-						// null component, match all
-						args.Topics[i] = nil
-						break
-					}
-					if topic, ok := rawTopic.(string); ok {
-						parsed, err := decodeTopic(topic)
-						if err != nil {
-							return err
-						}
-						args.Topics[i] = append(args.Topics[i], parsed)
-					} else {
-						return errInvalidTopic
+				if topic, ok := rawTopic.(string); ok {
+					parsed, err := decodeTopic(topic)
+					if err != nil {
+						return nil, err
 					}
+					topics[i] = append(topics[i], parsed)
+				} else {
+					return nil, errInvalidTopic
 				}
-			default:
-				return errInvalidTopic
 			}
+		default:
+			return nil, errInvalidTopic
 		}
 	}
-
-	return nil
+	return topics, nil
 }
 
 func decodeAddress(s string) (common.Address, error) {
@@ -0,0 +1,387 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	errExceedMaxDataWords    = errors.New("exceed max data words")
+	errExceedMaxPageSize     = errors.New("exceed max page size")
+	errPaginationUnsupported = errors.New("limit and cursor are not supported by subscriptions")
+)
+
+const (
+	// The maximum number of 32-byte data words that can be constrained
+	maxDataWords = 64
+	// The number of logs returned in a page if no limit is specified
+	defaultPageSize = 1000
+	// The maximum number of logs returned in a single page
+	maxPageSize = 10000
+
+	// The initial number of blocks searched at once while filling a page. The
+	// window grows while no logs are found, so that sparse results are found in
+	// few rounds, and is sized to the density of the logs found otherwise, so
+	// that dense results don't fill the memory.
+	initialPageWindow = 64
+	// The maximum number of blocks searched for a single page. If the page is
+	// not filled within these blocks, it is returned with fewer logs than the
+	// limit and the cursor points to the next block to search.
+	maxPageBlocks = 1 << 16
+
+	// The number of blocks searched at once by log queries running as
+	// asynchronous RPC jobs, each reported as a progress step.
+	jobWindow = 1 << 14
+)
+
+// LogCursor identifies a position in the chain's log stream. It is used as the
+// continuation token of paginated log queries and points to the first log that
+// has not been returned yet.
+type LogCursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	LogIndex    hexutil.Uint   `json:"logIndex"`
+}
+
+// before reports whether the given log is positioned before the cursor.
+func (c *LogCursor) before(log *types.Log) bool {
+	if log.BlockNumber != uint64(c.BlockNumber) {
+		return log.BlockNumber < uint64(c.BlockNumber)
+	}
+	return log.Index < uint(c.LogIndex)
+}
+
+// LogsPage is a single page of results of a paginated log query.
+type LogsPage struct {
+	Logs   []*types.Log `json:"logs"`
+	Cursor *LogCursor   `json:"cursor"` // Position to continue from, nil if the range is exhausted
+}
+
+// ExtendedFilterCriteria is a FilterCriteria with additional matching rules that
+// are not covered by the log index: constraints on the 32-byte words of the log
+// data, excluded addresses and topics, as well as pagination.
+//
+// The positive address and topic criteria are evaluated on top of the log index,
+// the remaining rules are applied on the candidate logs.
+type ExtendedFilterCriteria struct {
+	FilterCriteria
+
+	// Data restricts the 32-byte words of the log data, positionally and with
+	// the same OR semantics as Topics.
+	Data [][]common.Hash
+
+	// ExcludeAddresses lists contract addresses whose logs are dropped.
+	ExcludeAddresses []common.Address
+
+	// ExcludeTopics drops every log which has any of the listed topics at the
+	// corresponding position.
+	ExcludeTopics [][]common.Hash
+
+	Limit  uint64     // Maximum number of logs in a page, zero means default
+	Cursor *LogCursor // Position to continue a previous page from
+}
+
+// UnmarshalJSON sets *args fields with given data.
+func (args *ExtendedFilterCriteria) UnmarshalJSON(data []byte) error {
+	type input struct {
+		Data             []interface{}   `json:"data"`
+		ExcludeAddresses interface{}     `json:"excludeAddresses"`
+		ExcludeTopics    []interface{}   `json:"excludeTopics"`
+		Limit            *hexutil.Uint64 `json:"limit"`
+		Cursor           *LogCursor      `json:"cursor"`
+	}
+	if err := args.FilterCriteria.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	var raw input
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw.Data) > maxDataWords {
+		return errExceedMaxDataWords
+	}
+	words, err := decodeTopicList(raw.Data)
+	if err != nil {
+		return err
+	}
+	args.Data = words
+
+	if raw.ExcludeAddresses != nil {
+		addresses, err := decodeAddresses(raw.ExcludeAddresses)
+		if err != nil {
+			return err
+		}
+		args.ExcludeAddresses = addresses
+	}
+	if len(raw.ExcludeTopics) > maxTopics {
+		return errExceedMaxTopics
+	}
+	topics, err := decodeTopicList(raw.ExcludeTopics)
+	if err != nil {
+		return err
+	}
+	args.ExcludeTopics = topics
+
+	if raw.Limit != nil {
+		args.Limit = uint64(*raw.Limit)
+	}
+	args.Cursor = raw.Cursor
+	return nil
+}
+
+// validate checks the criteria against the query limits.
+func (crit *ExtendedFilterCriteria) validate() error {
+	if len(crit.Topics) > maxTopics || len(crit.ExcludeTopics) > maxTopics {
+		return errExceedMaxTopics
+	}
+	if len(crit.Addresses) > maxAddresses || len(crit.ExcludeAddresses) > maxAddresses {
+		return errExceedMaxAddresses
+	}
+	if len(crit.Data) > maxDataWords {
+		return errExceedMaxDataWords
+	}
+	if crit.Limit > maxPageSize {
+		return errExceedMaxPageSize
+	}
+	return nil
+}
+
+// paginated reports whether the criteria requests a paginated result.
+func (crit *ExtendedFilterCriteria) paginated() bool {
+	return crit.Limit != 0 || crit.Cursor != nil
+}
+
+// match returns the non-indexed part of the criteria, or nil if there is none.
+func (crit *ExtendedFilterCriteria) match() *extendedMatch {
+	m := &extendedMatch{
+		data:             crit.Data,
+		excludeAddresses: crit.ExcludeAddresses,
+		excludeTopics:    crit.ExcludeTopics,
+	}
+	if len(m.data) == 0 && len(m.excludeAddresses) == 0 && len(m.excludeTopics) == 0 {
+		return nil
+	}
+	return m
+}
+
+// extendedMatch holds the filter rules which cannot be looked up in the log
+// index and are checked on the logs directly.
+type extendedMatch struct {
+	data             [][]common.Hash
+	excludeAddresses []common.Address
+	excludeTopics    [][]common.Hash
+}
+
+// matches reports whether the log satisfies all rules.
+func (m *extendedMatch) matches(log *types.Log) bool {
+	if slices.Contains(m.excludeAddresses, log.Address) {
+		return false
+	}
+	for i, sub := range m.excludeTopics {
+		if i < len(log.Topics) && slices.Contains(sub, log.Topics[i]) {
+			return false
+		}
+	}
+	// If more data words are filtered than the log contains, skip.
+	for i, sub := range m.data {
+		if len(sub) == 0 {
+			continue // empty rule set == wildcard
+		}
+		if (i+1)*common.HashLength > len(log.Data) {
+			return false
+		}
+		word := common.BytesToHash(log.Data[i*common.HashLength : (i+1)*common.HashLength])
+		if !slices.Contains(sub, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns the logs satisfying all rules. A nil matcher accepts everything.
+func (m *extendedMatch) filter(logs []*types.Log) []*types.Log {
+	if m == nil {
+		return logs
+	}
+	var ret []*types.Log
+	for _, log := range logs {
+		if m.matches(log) {
+			ret = append(ret, log)
+		}
+	}
+	return ret
+}
+
+// LogsPage retrieves at most limit logs matching the filter, starting from the
+// cursor position. If more matching logs exist in the filtered range, it also
+// returns the cursor of the first log not included in the page.
+//
+// Range searches are executed in block windows adapted to the density of the
+// matches, so that a page is filled without searching the whole range if enough
+// matches are found early, and without holding many more logs than the page.
+// At most maxPageBlocks blocks are searched per page: if the page is not filled
+// by then, the logs found so far are returned with a cursor pointing to the
+// first block that was not searched.
+func (f *Filter) LogsPage(ctx context.Context, cursor *LogCursor, limit int) ([]*types.Log, *LogCursor, error) {
+	var logs []*types.Log
+	collect := func(found []*types.Log) {
+		for _, log := range found {
+			// The logs are in chain order, one more than the page is enough to
+			// know where the next page starts.
+			if len(logs) > limit {
+				return
+			}
+			if cursor == nil || !cursor.before(log) {
+				logs = append(logs, log)
+			}
+		}
+	}
+	if f.block != nil {
+		found, err := f.Logs(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		collect(found)
+	} else {
+		// Pin the head so that the page boundaries do not move while searching.
+		begin, end, err := f.pinnedRange(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cursor != nil && uint64(cursor.BlockNumber) > begin {
+			begin = uint64(cursor.BlockNumber)
+		}
+		var searched uint64
+		for window := uint64(initialPageWindow); begin <= end && len(logs) <= limit; {
+			if searched == maxPageBlocks {
+				// The search budget of the page is used up, continue from the
+				// first unsearched block on the next page.
+				next := &LogCursor{BlockNumber: hexutil.Uint64(begin)}
+				return logs, next, nil
+			}
+			window = min(window, maxPageBlocks-searched)
+			last := end
+			if end-begin >= window {
+				last = begin + window - 1
+			}
+			found, err := f.rangeLogs(ctx, begin, last)
+			if err != nil {
+				return nil, nil, err
+			}
+			collect(found)
+			if last == end {
+				break
+			}
+			searched += last - begin + 1
+			begin = last + 1
+			window = nextPageWindow(window, searched, len(logs), limit)
+		}
+	}
+	if len(logs) <= limit {
+		return logs, nil, nil
+	}
+	next := &LogCursor{
+		BlockNumber: hexutil.Uint64(logs[limit].BlockNumber),
+		LogIndex:    hexutil.Uint(logs[limit].Index),
+	}
+	return logs[:limit], next, nil
+}
+
+// nextPageWindow returns the number of blocks to search next while filling a
+// page, after finding the given number of logs in the blocks searched so far.
+// Without matches the window doubles, otherwise it is sized to the blocks that
+// are expected to hold the missing logs, growing at most twofold.
+func nextPageWindow(window, searched uint64, found, limit int) uint64 {
+	if found == 0 {
+		return min(window*2, maxPageBlocks)
+	}
+	missing := uint64(limit + 1 - found)
+	return max(1, min(missing*searched/uint64(found), window*2, maxPageBlocks))
+}
+
+// pinnedRange resolves the block range of a range filter, replacing the latest
+// block by the current head.
+func (f *Filter) pinnedRange(ctx context.Context) (uint64, uint64, error) {
+	begin, end, err := f.resolveRange(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if begin == math.MaxUint64 || end == math.MaxUint64 {
+		head := f.sys.backend.CurrentHeader().Number.Uint64()
+		if begin == math.MaxUint64 {
+			begin = head
+		}
+		if end == math.MaxUint64 {
+			end = head
+		}
+	}
+	return begin, end, nil
+}
+
+// jobLogs retrieves all logs matching the filter like Logs, for queries running
+// as an asynchronous RPC job. The logs are only reported to the job as partial
+// results, so that they count once against the job's result size limit. Range
+// searches are split into fixed windows, each reported to the job as progress.
+func (f *Filter) jobLogs(ctx context.Context, job *rpc.Job) error {
+	if f.block != nil {
+		logs, err := f.Logs(ctx)
+		if err != nil {
+			return err
+		}
+		if len(logs) > 0 {
+			if err := job.AddPartial(logs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	first, end, err := f.pinnedRange(ctx)
+	if err != nil || first > end {
+		return err
+	}
+	for begin := first; ; begin += jobWindow {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		last := end
+		if end-begin >= jobWindow {
+			last = begin + jobWindow - 1
+		}
+		found, err := f.rangeLogs(ctx, begin, last)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			if err := job.AddPartial(found); err != nil {
+				return err
+			}
+		}
+		job.SetProgress(last-first+1, end-first+1)
+		if last == end {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/filtermaps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+func TestUnmarshalJSONExtendedFilterCriteria(t *testing.T) {
+	var (
+		address0 = common.HexToAddress("70c87d191324e6712a591f304b4eedef6ad9bb9d")
+		topic0   = common.HexToHash("3ac225168df54212a25c1c01fd35bebfea408fdac2e31ddd6f80a4bbf9a5f1ca")
+		word0    = common.HexToHash("01")
+		word1    = common.HexToHash("02")
+	)
+	var crit ExtendedFilterCriteria
+	vector := fmt.Sprintf(`{"fromBlock":"0x1","address":"%s","data":[null,["%s","%s"]],"excludeAddresses":"%s","excludeTopics":["%s"],"limit":"0x10","cursor":{"blockNumber":"0x5","logIndex":"0x2"}}`,
+		address0.Hex(), word0.Hex(), word1.Hex(), address0.Hex(), topic0.Hex())
+	if err := json.Unmarshal([]byte(vector), &crit); err != nil {
+		t.Fatal(err)
+	}
+	if crit.FromBlock.Int64() != 1 || len(crit.Addresses) != 1 || crit.Addresses[0] != address0 {
+		t.Fatalf("base criteria not decoded: %+v", crit.FilterCriteria)
+	}
+	if len(crit.Data) != 2 || crit.Data[0] != nil || len(crit.Data[1]) != 2 || crit.Data[1][0] != word0 || crit.Data[1][1] != word1 {
+		t.Fatalf("wrong data criteria: %v", crit.Data)
+	}
+	if len(crit.ExcludeAddresses) != 1 || crit.ExcludeAddresses[0] != address0 {
+		t.Fatalf("wrong excluded addresses: %v", crit.ExcludeAddresses)
+	}
+	if len(crit.ExcludeTopics) != 1 || crit.ExcludeTopics[0][0] != topic0 {
+		t.Fatalf("wrong excluded topics: %v", crit.ExcludeTopics)
+	}
+	if crit.Limit != 16 || crit.Cursor == nil || crit.Cursor.BlockNumber != 5 || crit.Cursor.LogIndex != 2 {
+		t.Fatalf("wrong pagination: limit %d cursor %+v", crit.Limit, crit.Cursor)
+	}
+
+	vector = fmt.Sprintf(`{"data":[%s"%s"]}`, strings.Repeat(`null,`, maxDataWords), word0.Hex())
+	if err := json.Unmarshal([]byte(vector), &crit); err != errExceedMaxDataWords {
+		t.Fatalf("expected %v, got %v", errExceedMaxDataWords, err)
+	}
+}
+
+func TestExtendedLogs(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		sys     = NewFilterSystem(backend, Config{})
+		api     = NewFilterAPI(sys)
+		addr1   = common.BytesToAddress([]byte("jeff"))
+		addr2   = common.BytesToAddress([]byte("ethereum"))
+		topic1  = common.BytesToHash([]byte("topic1"))
+		topic2  = common.BytesToHash([]byte("topic2"))
+
+		gspec = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	defer db.Close()
+
+	// Every 50th block contains two logs with the block number as data word.
+	word := func(n int) common.Hash { return common.BigToHash(big.NewInt(int64(n))) }
+	_, chain, receipts := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 3000, func(i int, gen *core.BlockGen) {
+		if i%50 != 0 {
+			return
+		}
+		receipt := types.NewReceipt(nil, false, 0)
+		receipt.Logs = []*types.Log{
+			{Address: addr1, Topics: []common.Hash{topic1}, Data: append(word(i).Bytes(), word(1).Bytes()...)},
+			{Address: addr2, Topics: []common.Hash{topic2}, Data: word(i).Bytes()},
+		}
+		receipt.Bloom = types.CreateBloom(receipt)
+		gen.AddUncheckedReceipt(receipt)
+		gen.AddUncheckedTx(types.NewTransaction(999, common.HexToAddress("0x999"), big.NewInt(999), 999, gen.BaseFee(), nil))
+	})
+	gspec.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	backend.startFilterMaps(0, false, filtermaps.DefaultParams)
+	defer backend.stopFilterMaps()
+
+	var (
+		ctx      = context.Background()
+		earliest = big.NewInt(0)
+		latest   = big.NewInt(rpc.LatestBlockNumber.Int64())
+	)
+	for i, tc := range []struct {
+		crit ExtendedFilterCriteria
+		want int
+	}{
+		// data word matching across addresses
+		{ExtendedFilterCriteria{FilterCriteria: FilterCriteria{FromBlock: earliest, ToBlock: latest}, Data: [][]common.Hash{{word(100), word(2500)}}}, 4},
+		// data word matching combined with indexed criteria
+		{ExtendedFilterCriteria{FilterCriteria: FilterCriteria{FromBlock: earliest, ToBlock: latest, Addresses: []common.Address{addr1}}, Data: [][]common.Hash{{word(100), word(2500)}}}, 2},
+		// wildcard data word followed by a constrained one
+		{ExtendedFilterCriteria{FilterCriteria: FilterCriteria{FromBlock: earliest, ToBlock: latest}, Data: [][]common.Hash{nil, {word(1)}}}, 60},
+		// excluded address without positive criteria
+		{ExtendedFilterCriteria{FilterCriteria: FilterCriteria{FromBlock: earliest, ToBlock: latest}, ExcludeAddresses: []common.Address{addr2}}, 60},
+		// excluded topic
+		{ExtendedFilterCriteria{FilterCriteria: FilterCriteria{FromBlock: earliest, ToBlock: latest, Addresses: []common.Address{addr1, addr2}}, ExcludeTopics: [][]common.Hash{{topic1}}}, 60},
+	} {
+		page, err := api.GetLogsPage(ctx, tc.crit)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if len(page.Logs) != tc.want || page.Cursor != nil {
+			t.Errorf("case %d: wrong number of logs: have %d, want %d", i, len(page.Logs), tc.want)
+		}
+	}
+
+	// Page through all logs and check that the cursor continues seamlessly.
+	crit := ExtendedFilterCriteria{
+		FilterCriteria: FilterCriteria{FromBlock: earliest, ToBlock: latest, Addresses: []common.Address{addr1, addr2}},
+		Limit:          25,
+	}
+	var (
+		all   []*types.Log
+		pages int
+	)
+	for {
+		page, err := api.GetLogsPage(ctx, crit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		all = append(all, page.Logs...)
+		if page.Cursor == nil {
+			break
+		}
+		if len(page.Logs) != int(crit.Limit) {
+			t.Fatalf("page %d: incomplete page with cursor: %d logs", pages, len(page.Logs))
+		}
+		crit.Cursor = page.Cursor
+	}
+	if pages != 5 || len(all) != 120 {
+		t.Fatalf("wrong pagination result: %d pages, %d logs", pages, len(all))
+	}
+	for i, log := range all {
+		if want := uint64(i/2*50 + 1); log.BlockNumber != want || log.Index != uint(i%2) {
+			t.Fatalf("log %d: wrong position %d/%d, want %d/%d", i, log.BlockNumber, log.Index, want, i%2)
+		}
+	}
+
+	// Run the query as an asynchronous job and check its progress reporting.
+	server := rpc.NewServer()
+	defer server.Stop()
+	server.SetJobLimits(1, 0, 0)
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var id rpc.ID
+	query := map[string]any{"fromBlock": "earliest", "toBlock": "latest", "address": []common.Address{addr1, addr2}}
+	if err := client.Call(&id, "rpc_startJob", "eth_getLogs", []any{query}); err != nil {
+		t.Fatal(err)
+	}
+	var status rpc.JobStatus
+	for {
+		if err := client.Call(&status, "rpc_jobStatus", id); err != nil {
+			t.Fatal(err)
+		}
+		if status.State != rpc.JobRunning {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status.State != rpc.JobDone {
+		t.Fatalf("job failed: %s %v", status.State, status.Error)
+	}
+	var (
+		result  []*types.Log
+		partial []*types.Log
+	)
+	if err := json.Unmarshal(status.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	for _, enc := range status.Partial {
+		var logs []*types.Log
+		if err := json.Unmarshal(enc, &logs); err != nil {
+			t.Fatal(err)
+		}
+		partial = append(partial, logs...)
+	}
+	if len(result) != 0 || len(partial) != 120 {
+		t.Fatalf("wrong job result: %d logs, %d partial", len(result), len(partial))
+	}
+	if status.Progress.Done != status.Progress.Total || status.Progress.Total != uint64(len(chain)+1) {
+		t.Fatalf("wrong job progress: %+v", status.Progress)
+	}
+}
+
+func TestNextPageWindow(t *testing.T) {
+	for i, tc := range []struct {
+		window, searched uint64
+		found, limit     int
+		want             uint64
+	}{
+		// no matches yet, the window doubles up to the maximum
+		{64, 64, 0, 100, 128},
+		{maxPageBlocks, 1 << 24, 0, 100, maxPageBlocks},
+		// sparse matches, the window grows at most twofold
+		{64, 64, 1, 100, 128},
+		// dense matches, the window shrinks to the expected missing logs
+		{64, 64, 64, 100, 37},
+		{64, 64, 6400, 10000, 36},
+		{64, 128, 1000, 1000, 1},
+	} {
+		if have := nextPageWindow(tc.window, tc.searched, tc.found, tc.limit); have != tc.want {
+			t.Errorf("case %d: wrong window: have %d, want %d", i, have, tc.want)
+		}
+	}
+}
@@ -38,6 +38,7 @@ type Filter struct {
 
 	addresses []common.Address
 	topics    [][]common.Hash
+	extended  *extendedMatch // Additional non-indexed criteria, nil if unused
 
 	block      *common.Hash // Block hash if filtering a single block
 	begin, end int64        // Range interval if filtering multiple blocks
@@ -93,9 +94,19 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		return f.blockLogs(ctx, header)
 	}
 
+	begin, end, err := f.resolveRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.rangeLogs(ctx, begin, end)
+}
+
+// resolveRange converts the special begin/end block numbers of a range filter
+// into actual block numbers. The latest block is resolved to MaxUint64.
+func (f *Filter) resolveRange(ctx context.Context) (uint64, uint64, error) {
 	// Disallow pending logs.
 	if f.begin == rpc.PendingBlockNumber.Int64() || f.end == rpc.PendingBlockNumber.Int64() {
-		return nil, errPendingLogsUnsupported
+		return 0, 0, errPendingLogsUnsupported
 	}
 
 	resolveSpecial := func(number int64) (uint64, error) {
@@ -136,13 +147,13 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	// range query need to resolve the special begin/end block number
 	begin, err := resolveSpecial(f.begin)
 	if err != nil {
-		return nil, err
+		return 0, 0, err
 	}
 	end, err := resolveSpecial(f.end)
 	if err != nil {
-		return nil, err
+		return 0, 0, err
 	}
-	return f.rangeLogs(ctx, begin, end)
+	return begin, end, nil
 }
 
 const (
@@ -410,7 +421,7 @@ func (f *Filter) rangeLogs(ctx context.Context, firstBlock, lastBlock uint64) ([
 func (f *Filter) indexedLogs(ctx context.Context, mb filtermaps.MatcherBackend, begin, end uint64) ([]*types.Log, error) {
 	start := time.Now()
 	potentialMatches, err := filtermaps.GetPotentialMatches(ctx, mb, begin, end, f.addresses, f.topics)
-	matches := filterLogs(potentialMatches, nil, nil, f.addresses, f.topics)
+	matches := f.extended.filter(filterLogs(potentialMatches, nil, nil, f.addresses, f.topics))
 	log.Trace("Performed indexed log search", "begin", begin, "end", end, "true matches", len(matches), "false positives", len(potentialMatches)-len(matches), "elapsed", common.PrettyDuration(time.Since(start)))
 	return matches, err
 }
@@ -466,7 +477,7 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*typ
 	if err != nil {
 		return nil, err
 	}
-	logs := filterLogs(cached.logs, nil, nil, f.addresses, f.topics)
+	logs := f.extended.filter(filterLogs(cached.logs, nil, nil, f.addresses, f.topics))
 	if len(logs) == 0 {
 		return nil, nil
 	}
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/internal/ethapi/override"
@@ -52,11 +53,28 @@ type Options struct {
 // run successfully with the provided context options. It returns an error if the
 // transaction would always revert, or if there are unexpected failures.
 func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uint64) (uint64, []byte, error) {
+	gas, _, revert, err := estimate(ctx, call, opts, gasCap)
+	if err != nil {
+		return 0, revert, err
+	}
+	return gas, nil, nil
+}
+
+// estimate implements Estimate, additionally returning the number of executions
+// performed. If the transaction fails even with the highest allowable gas limit,
+// that limit is returned along with the error.
+func estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uint64) (uint64, int, []byte, error) {
 	// Binary search the gas limit, as it may need to be higher than the amount used
 	var (
 		lo uint64 // lowest-known gas limit where tx execution fails
 		hi uint64 // lowest-known gas limit where tx execution succeeds
+
+		executions int
 	)
+	try := func(gasLimit uint64) (bool, *core.ExecutionResult, error) {
+		executions++
+		return execute(ctx, call, opts, gasLimit)
+	}
 	// Determine the highest gas limit can be used during the estimation.
 	hi = opts.Header.GasLimit
 	if call.GasLimit >= params.TxGas {
@@ -95,7 +113,7 @@ func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uin
 		available := balance
 		if call.Value != nil {
 			if call.Value.Cmp(available) >= 0 {
-				return 0, nil, core.ErrInsufficientFundsForTransfer
+				return 0, 0, nil, core.ErrInsufficientFundsForTransfer
 			}
 			available.Sub(available, call.Value)
 		}
@@ -105,7 +123,7 @@ func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uin
 			blobBalanceUsage.Mul(blobBalanceUsage, blobGasPerBlob)
 			blobBalanceUsage.Mul(blobBalanceUsage, call.BlobGasFeeCap)
 			if blobBalanceUsage.Cmp(available) >= 0 {
-				return 0, nil, core.ErrInsufficientFunds
+				return 0, 0, nil, core.ErrInsufficientFunds
 			}
 			available.Sub(available, blobBalanceUsage)
 		}
@@ -133,23 +151,23 @@ func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uin
 	// unused access list items). Ever so slightly wasteful, but safer overall.
 	if len(call.Data) == 0 {
 		if call.To != nil && opts.State.GetCodeSize(*call.To) == 0 {
-			failed, _, err := execute(ctx, call, opts, params.TxGas)
+			failed, _, err := try(params.TxGas)
 			if !failed && err == nil {
-				return params.TxGas, nil, nil
+				return params.TxGas, executions, nil, nil
 			}
 		}
 	}
 	// We first execute the transaction at the highest allowable gas limit, since if this fails we
 	// can return error immediately.
-	failed, result, err := execute(ctx, call, opts, hi)
+	failed, result, err := try(hi)
 	if err != nil {
-		return 0, nil, err
+		return 0, executions, nil, err
 	}
 	if failed {
 		if result != nil && !errors.Is(result.Err, vm.ErrOutOfGas) {
-			return 0, result.Revert(), result.Err
+			return hi, executions, result.Revert(), result.Err
 		}
-		return 0, nil, fmt.Errorf("gas required exceeds allowance (%d)", hi)
+		return hi, executions, nil, fmt.Errorf("gas required exceeds allowance (%d)", hi)
 	}
 	// For almost any transaction, the gas consumed by the unconstrained execution
 	// above lower-bounds the gas limit required for it to succeed. One exception
@@ -163,12 +181,12 @@ func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uin
 	// check that gas amount and use as a limit for the binary search.
 	optimisticGasLimit := (result.MaxUsedGas + params.CallStipend) * 64 / 63
 	if optimisticGasLimit < hi {
-		failed, _, err = execute(ctx, call, opts, optimisticGasLimit)
+		failed, _, err = try(optimisticGasLimit)
 		if err != nil {
 			// This should not happen under normal conditions since if we make it this far the
 			// transaction had run without error at least once before.
 			log.Error("Execution error in estimate gas", "err", err)
-			return 0, nil, err
+			return 0, executions, nil, err
 		}
 		if failed {
 			lo = optimisticGasLimit
@@ -194,12 +212,12 @@ func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uin
 			// range here is skewed to favor the low side.
 			mid = lo * 2
 		}
-		failed, _, err = execute(ctx, call, opts, mid)
+		failed, _, err = try(mid)
 		if err != nil {
 			// This should not happen under normal conditions since if we make it this far the
 			// transaction had run without error at least once before.
 			log.Error("Execution error in estimate gas", "err", err)
-			return 0, nil, err
+			return 0, executions, nil, err
 		}
 		if failed {
 			lo = mid
@@ -207,7 +225,7 @@ func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uin
 			hi = mid
 		}
 	}
-	return hi, nil, nil
+	return hi, executions, nil, nil
 }
 
 // execute is a helper that executes the transaction under a given gas limit and
@@ -221,7 +239,7 @@ func execute(ctx context.Context, call *core.Message, opts *Options, gasLimit ui
 
 	// Execute the call and separate execution faults caused by a lack of gas or
 	// other non-fixable conditions
-	result, err := run(ctx, call, opts)
+	result, err := run(ctx, call, opts, nil)
 	if err != nil {
 		if errors.Is(err, core.ErrIntrinsicGas) {
 			return true, nil, nil // Special case, raise gas limit
@@ -235,8 +253,8 @@ func execute(ctx context.Context, call *core.Message, opts *Options, gasLimit ui
 }
 
 // run assembles the EVM as defined by the consensus rules and runs the requested
-// call invocation.
-func run(ctx context.Context, call *core.Message, opts *Options) (*core.ExecutionResult, error) {
+// call invocation, reporting the execution to the tracer if one is given.
+func run(ctx context.Context, call *core.Message, opts *Options, tracer *tracing.Hooks) (*core.ExecutionResult, error) {
 	// Assemble the call and the call context
 	var (
 		evmContext = core.NewEVMBlockContext(opts.Header, opts.Chain, nil)
@@ -255,7 +273,7 @@ func run(ctx context.Context, call *core.Message, opts *Options) (*core.Executio
 	if call.BlobGasFeeCap != nil && call.BlobGasFeeCap.BitLen() == 0 {
 		evmContext.BlobBaseFee = new(big.Int)
 	}
-	evm := vm.NewEVM(evmContext, dirtyState, opts.Config, vm.Config{NoBaseFee: true})
+	evm := vm.NewEVM(evmContext, dirtyState, opts.Config, vm.Config{NoBaseFee: true, Tracer: tracer})
 
 	// Monitor the outer context and interrupt the EVM upon cancellation. To avoid
 	// a dangling goroutine until the outer estimation finishes, create an internal
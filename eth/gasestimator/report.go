@@ -0,0 +1,212 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasestimator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Frame is a call frame entered while executing the estimated transaction.
+type Frame struct {
+	Depth   int            // Call depth, 0 for the transaction itself
+	Type    vm.OpCode      // Opcode which entered the frame (CALL for the transaction)
+	From    common.Address // Caller of the frame
+	To      common.Address // Callee of the frame, the new contract for creations
+	Gas     uint64         // Gas made available to the frame
+	GasUsed uint64         // Gas consumed by the frame, including its subcalls
+	Capped  bool           // Whether the 63/64 rule withheld gas requested by the caller
+	Error   error          // Error the frame terminated with, if any
+	Revert  []byte         // Revert data returned by the frame, if it reverted
+}
+
+// Report explains how a gas estimate was obtained and how the transaction uses
+// gas when executed with the estimated gas limit.
+type Report struct {
+	Gas        uint64 // Estimated gas limit, or the highest allowance tried on failure
+	Iterations int    // Number of executions performed by the search
+	UsedGas    uint64 // Gas used by the final execution after refunds
+	Refund     uint64 // Gas refunded at the end of the final execution
+
+	Frames     []*Frame // Call frames of the final execution in the order they were entered
+	Bottleneck *Frame   // Frame with the least spare gas among those capped by the 63/64 rule
+
+	// BlockContext lists the block context opcodes read during the final
+	// execution, which may change the gas used once included in another block.
+	BlockContext []vm.OpCode
+
+	Err    error  // Reason the estimation failed, nil on success
+	Revert []byte // Revert data of the transaction if it reverted
+}
+
+// blockContextOps are the opcodes reading block context, which differs between
+// the estimation and the eventual inclusion of the transaction.
+var blockContextOps = map[vm.OpCode]bool{
+	vm.BLOCKHASH:   true,
+	vm.COINBASE:    true,
+	vm.TIMESTAMP:   true,
+	vm.NUMBER:      true,
+	vm.PREVRANDAO:  true,
+	vm.GASLIMIT:    true,
+	vm.BASEFEE:     true,
+	vm.BLOBBASEFEE: true,
+}
+
+// frameTracer records the call frames and block context accesses of a single
+// execution.
+type frameTracer struct {
+	frames    []*Frame
+	stack     []*Frame // Frames currently executing
+	requested uint64   // Gas requested by the last call opcode
+	context   []vm.OpCode
+	seen      map[vm.OpCode]bool
+}
+
+func newFrameTracer() *frameTracer {
+	return &frameTracer{seen: make(map[vm.OpCode]bool)}
+}
+
+func (t *frameTracer) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnEnter:  t.onEnter,
+		OnExit:   t.onExit,
+		OnOpcode: t.onOpcode,
+	}
+}
+
+func (t *frameTracer) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	opcode := vm.OpCode(op)
+	switch opcode {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		// The requested gas is the topmost stack item of all call variants.
+		stack := scope.StackData()
+		if len(stack) == 0 {
+			return
+		}
+		requested := stack[len(stack)-1]
+		if requested.IsUint64() {
+			t.requested = requested.Uint64()
+		} else {
+			t.requested = ^uint64(0)
+		}
+	case vm.CREATE, vm.CREATE2:
+		// Creations always request all the gas available.
+		t.requested = ^uint64(0)
+	default:
+		if blockContextOps[opcode] && !t.seen[opcode] {
+			t.seen[opcode] = true
+			t.context = append(t.context, opcode)
+		}
+	}
+}
+
+func (t *frameTracer) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	frame := &Frame{
+		Depth: depth,
+		Type:  vm.OpCode(typ),
+		From:  from,
+		To:    to,
+		Gas:   gas,
+	}
+	if depth > 0 {
+		// Value transfers receive a stipend on top of the forwarded gas.
+		forwarded := gas
+		if value != nil && value.Sign() != 0 && (frame.Type == vm.CALL || frame.Type == vm.CALLCODE) && forwarded >= params.CallStipend {
+			forwarded -= params.CallStipend
+		}
+		frame.Capped = t.requested > forwarded
+	}
+	t.requested = 0
+	t.frames = append(t.frames, frame)
+	t.stack = append(t.stack, frame)
+}
+
+func (t *frameTracer) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if len(t.stack) == 0 {
+		return
+	}
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+
+	frame.GasUsed = gasUsed
+	frame.Error = err
+	if reverted && errors.Is(err, vm.ErrExecutionReverted) {
+		frame.Revert = common.CopyBytes(output)
+	}
+}
+
+// bottleneck returns the frame capped by the 63/64 rule with the least gas to
+// spare, which is the first one to run out of gas when lowering the gas limit.
+func (t *frameTracer) bottleneck() *Frame {
+	var found *Frame
+	for _, frame := range t.frames {
+		if !frame.Capped || frame.GasUsed > frame.Gas {
+			continue
+		}
+		if found == nil || frame.Gas-frame.GasUsed < found.Gas-found.GasUsed {
+			found = frame
+		}
+	}
+	return found
+}
+
+// EstimateDetailed estimates the gas limit of the transaction like Estimate,
+// and explains the result by tracing the execution with the estimated limit.
+// If the transaction fails regardless of the gas limit, the execution with the
+// highest allowance is traced instead and the failure reported in the result.
+// An error is only returned if the transaction could not be executed at all.
+func EstimateDetailed(ctx context.Context, call *core.Message, opts *Options, gasCap uint64) (*Report, error) {
+	gas, iterations, revert, err := estimate(ctx, call, opts, gasCap)
+	if gas == 0 {
+		return nil, err
+	}
+	report := &Report{
+		Gas:        gas,
+		Iterations: iterations,
+		Err:        err,
+		Revert:     revert,
+	}
+	// Trace the execution with the resulting gas limit.
+	defer func(gas uint64) { call.GasLimit = gas }(call.GasLimit)
+	call.GasLimit = gas
+
+	tracer := newFrameTracer()
+	result, runErr := run(ctx, call, opts, tracer.hooks())
+	if runErr != nil {
+		if err != nil {
+			// Executions failing before entering the EVM, e.g. due to the
+			// intrinsic gas exceeding the allowance, have nothing to explain.
+			return report, nil
+		}
+		return nil, runErr
+	}
+	report.UsedGas = result.UsedGas
+	if result.MaxUsedGas > result.UsedGas {
+		report.Refund = result.MaxUsedGas - result.UsedGas
+	}
+	report.Frames = tracer.frames
+	report.Bottleneck = tracer.bottleneck()
+	report.BlockContext = tracer.context
+	return report, nil
+}
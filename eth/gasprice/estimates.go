@@ -0,0 +1,260 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// Target number of blocks until inclusion of the fee tiers.
+	fastTierBlocks     = 1
+	standardTierBlocks = 3
+	slowTierBlocks     = 10
+
+	// blobFeeForecastBlocks is the number of upcoming blocks the blob base fee
+	// is predicted for.
+	blobFeeForecastBlocks = 5
+
+	// defaultBlockTime is the block time in seconds assumed when the recent
+	// blocks do not allow to measure it.
+	defaultBlockTime = 12
+)
+
+// FeeTier is a fee suggestion targeting inclusion within a number of blocks.
+type FeeTier struct {
+	Blocks               uint64   // Number of blocks the transaction is expected to be included within
+	Seconds              uint64   // Expected time until inclusion, derived from the recent block time
+	MaxPriorityFeePerGas *big.Int // Suggested tip, or gas price before London
+	MaxFeePerGas         *big.Int // Suggested fee cap tolerating a doubling base fee
+}
+
+// FeeEstimates contains fee suggestions for transactions to be included on top
+// of the current chain head.
+type FeeEstimates struct {
+	BlockNumber uint64     // Head block the estimates are based on
+	BaseFee     *big.Int   // Base fee of the next block, nil before London
+	BlobBaseFee []*big.Int // Predicted blob base fees of the upcoming blocks, nil before Cancun
+
+	Slow     FeeTier
+	Standard FeeTier
+	Fast     FeeTier
+}
+
+// FeeEstimates returns slow, standard and fast fee tiers for transactions to be
+// included on top of the current head, along with the blob base fee trend. The
+// estimates are computed once per chain head.
+//
+// The tiers are derived from the lowest tip that made it into each of the recent
+// blocks: a tip which would have been included in one out of k recent blocks is
+// expected to be included within k blocks. With the mempool strategy, the tiers
+// also outbid the pending transactions filling their k blocks, in line with the
+// suggested tip. Blob base fees are predicted by extrapolating the excess blob
+// gas with the recent average blob gas usage.
+func (oracle *Oracle) FeeEstimates(ctx context.Context) (*FeeEstimates, error) {
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		if err == nil {
+			err = errors.New("chain head not found")
+		}
+		return nil, err
+	}
+	headHash := head.Hash()
+
+	// If the estimates of the head are still available, return them.
+	oracle.cacheLock.RLock()
+	lastHead, last := oracle.lastEstimatesHead, oracle.lastEstimates
+	oracle.cacheLock.RUnlock()
+	if last != nil && headHash == lastHead {
+		return last.copy(), nil
+	}
+	oracle.fetchLock.Lock()
+	defer oracle.fetchLock.Unlock()
+
+	// Try checking the cache again, maybe the last fetch computed what we need
+	oracle.cacheLock.RLock()
+	lastHead, last = oracle.lastEstimatesHead, oracle.lastEstimates
+	oracle.cacheLock.RUnlock()
+	if last != nil && headHash == lastHead {
+		return last.copy(), nil
+	}
+	estimates, err := oracle.estimateFees(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	oracle.cacheLock.Lock()
+	oracle.lastEstimatesHead = headHash
+	oracle.lastEstimates = estimates
+	oracle.cacheLock.Unlock()
+
+	return estimates.copy(), nil
+}
+
+// estimateFees computes the fee estimates on top of the given head.
+func (oracle *Oracle) estimateFees(ctx context.Context, head *types.Header) (*FeeEstimates, error) {
+	var (
+		config = oracle.backend.ChainConfig()
+		number = head.Number.Uint64()
+		blocks = min(uint64(oracle.checkBlocks), number)
+		result = make(chan results, blocks)
+		quit   = make(chan struct{})
+		tips   = make([]*big.Int, 0, blocks)
+	)
+	defer close(quit)
+
+	for n := number; n > number-blocks; n-- {
+		go oracle.getBlockValues(ctx, n, 1, oracle.ignorePrice, result, quit)
+	}
+	for range blocks {
+		res := <-result
+		if res.err != nil {
+			return nil, res.err
+		}
+		// Any tip would have been accepted by blocks without competing
+		// transactions.
+		if len(res.values) == 0 {
+			res.values = []*big.Int{oracle.ignorePrice}
+		}
+		tips = append(tips, res.values[0])
+	}
+	slices.SortFunc(tips, func(a, b *big.Int) int { return a.Cmp(b) })
+
+	// Measure the recent block time and blob gas usage.
+	var (
+		blockTime   uint64 = defaultBlockTime
+		blobGasUsed uint64
+	)
+	if blocks > 0 {
+		oldest := head
+		for n := number; n > number-blocks; n-- {
+			header, err := oracle.backend.HeaderByNumber(ctx, rpc.BlockNumber(n))
+			if header == nil {
+				if err == nil {
+					err = fmt.Errorf("header %d not found", n)
+				}
+				return nil, err
+			}
+			if header.BlobGasUsed != nil {
+				blobGasUsed += *header.BlobGasUsed
+			}
+			oldest = header
+		}
+		blobGasUsed /= blocks
+		if span := number - oldest.Number.Uint64(); span > 0 && head.Time > oldest.Time {
+			blockTime = max((head.Time-oldest.Time)/span, 1)
+		}
+	}
+	estimates := &FeeEstimates{
+		BlockNumber: number,
+		BaseFee:     nextBaseFee(config, head),
+	}
+	oracle.cacheLock.RLock()
+	lastPrice := oracle.lastPrice
+	oracle.cacheLock.RUnlock()
+
+	// The mempool strategy raises the tips above the pending transactions
+	// competing for the same blocks.
+	var poolTxs types.Transactions
+	mempool, _ := oracle.strategy.(*mempoolStrategy)
+	if mempool != nil {
+		txs, err := mempool.pool.GetPoolTransactions()
+		if err != nil {
+			return nil, err
+		}
+		poolTxs = txs
+	}
+	tier := func(target uint64) FeeTier {
+		tip := lastPrice
+		if len(tips) > 0 {
+			tip = tips[(uint64(len(tips))+target-1)/target-1]
+		}
+		if mempool != nil {
+			if bid := mempool.poolTip(poolTxs, head, target); bid != nil && bid.Cmp(tip) > 0 {
+				tip = bid
+			}
+		}
+		if tip.Cmp(oracle.maxPrice) > 0 {
+			tip = oracle.maxPrice
+		}
+		feeCap := new(big.Int).Set(tip)
+		if estimates.BaseFee != nil {
+			feeCap.Add(feeCap, new(big.Int).Lsh(estimates.BaseFee, 1))
+		}
+		return FeeTier{
+			Blocks:               target,
+			Seconds:              target * blockTime,
+			MaxPriorityFeePerGas: new(big.Int).Set(tip),
+			MaxFeePerGas:         feeCap,
+		}
+	}
+	estimates.Slow = tier(slowTierBlocks)
+	estimates.Standard = tier(standardTierBlocks)
+	estimates.Fast = tier(fastTierBlocks)
+
+	// Extrapolate the blob base fee over synthetic headers. The first one is
+	// exact, as it only depends on the head.
+	if head.ExcessBlobGas != nil {
+		parent := head
+		for range blobFeeForecastBlocks {
+			header := &types.Header{
+				Number:      new(big.Int).Add(parent.Number, big.NewInt(1)),
+				Time:        parent.Time + blockTime,
+				BaseFee:     estimates.BaseFee,
+				BlobGasUsed: &blobGasUsed,
+			}
+			if !config.IsCancun(header.Number, header.Time) {
+				break
+			}
+			excess := eip4844.CalcExcessBlobGas(config, parent, header.Time)
+			header.ExcessBlobGas = &excess
+			estimates.BlobBaseFee = append(estimates.BlobBaseFee, eip4844.CalcBlobFee(config, header))
+			parent = header
+		}
+	}
+	return estimates, nil
+}
+
+// copy returns a deep copy of the estimates.
+func (e *FeeEstimates) copy() *FeeEstimates {
+	cpy := *e
+	if e.BaseFee != nil {
+		cpy.BaseFee = new(big.Int).Set(e.BaseFee)
+	}
+	if e.BlobBaseFee != nil {
+		cpy.BlobBaseFee = make([]*big.Int, len(e.BlobBaseFee))
+		for i, fee := range e.BlobBaseFee {
+			cpy.BlobBaseFee[i] = new(big.Int).Set(fee)
+		}
+	}
+	cpy.Slow, cpy.Standard, cpy.Fast = e.Slow.copy(), e.Standard.copy(), e.Fast.copy()
+	return &cpy
+}
+
+// copy returns a deep copy of the tier.
+func (t FeeTier) copy() FeeTier {
+	t.MaxPriorityFeePerGas = new(big.Int).Set(t.MaxPriorityFeePerGas)
+	t.MaxFeePerGas = new(big.Int).Set(t.MaxFeePerGas)
+	return t
+}
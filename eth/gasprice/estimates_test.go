@@ -0,0 +1,153 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestFeeEstimates(t *testing.T) {
+	backend := newTestBackend(t, big.NewInt(0), big.NewInt(28), false)
+	defer backend.teardown()
+
+	oracle := NewOracle(backend, Config{Blocks: 5, Percentile: 60}, big.NewInt(params.GWei))
+	estimates, err := oracle.FeeEstimates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		config  = backend.ChainConfig()
+		head    = backend.chain.GetHeaderByNumber(testHead)
+		baseFee = eip1559.CalcBaseFee(config, head)
+	)
+	if estimates.BlockNumber != testHead || estimates.BaseFee.Cmp(baseFee) != 0 {
+		t.Fatalf("wrong head values: number %d, base fee %v, want %d, %v", estimates.BlockNumber, estimates.BaseFee, testHead, baseFee)
+	}
+	// The lowest tips of blocks 28 to 32 are 28 to 32 gwei, with a block time
+	// of 10 seconds.
+	for _, tc := range []struct {
+		name   string
+		tier   FeeTier
+		blocks uint64
+		tip    int64
+	}{
+		{"slow", estimates.Slow, slowTierBlocks, 28},
+		{"standard", estimates.Standard, standardTierBlocks, 29},
+		{"fast", estimates.Fast, fastTierBlocks, 32},
+	} {
+		tip := big.NewInt(tc.tip * params.GWei)
+		feeCap := new(big.Int).Add(tip, new(big.Int).Mul(baseFee, big.NewInt(2)))
+		if tc.tier.Blocks != tc.blocks || tc.tier.Seconds != tc.blocks*10 {
+			t.Errorf("%s: wrong target: %d blocks, %d seconds", tc.name, tc.tier.Blocks, tc.tier.Seconds)
+		}
+		if tc.tier.MaxPriorityFeePerGas.Cmp(tip) != 0 || tc.tier.MaxFeePerGas.Cmp(feeCap) != 0 {
+			t.Errorf("%s: wrong fees: tip %v, fee cap %v, want %v, %v", tc.name, tc.tier.MaxPriorityFeePerGas, tc.tier.MaxFeePerGas, tip, feeCap)
+		}
+	}
+	// The blob base fee of the next block is exact, later ones follow the
+	// recent blob gas usage of six blobs per block, which exceeds the target.
+	if len(estimates.BlobBaseFee) != blobFeeForecastBlocks {
+		t.Fatalf("wrong number of blob fee predictions: %d", len(estimates.BlobBaseFee))
+	}
+	excess := eip4844.CalcExcessBlobGas(config, head, head.Time+10)
+	next := eip4844.CalcBlobFee(config, &types.Header{Number: big.NewInt(testHead + 1), Time: head.Time + 10, ExcessBlobGas: &excess})
+	if estimates.BlobBaseFee[0].Cmp(next) != 0 {
+		t.Fatalf("wrong next blob base fee: have %v, want %v", estimates.BlobBaseFee[0], next)
+	}
+	for i := 1; i < len(estimates.BlobBaseFee); i++ {
+		if estimates.BlobBaseFee[i].Cmp(estimates.BlobBaseFee[i-1]) < 0 {
+			t.Fatalf("blob base fee prediction %d decreases: %v", i, estimates.BlobBaseFee)
+		}
+	}
+	if estimates.BlobBaseFee[blobFeeForecastBlocks-1].Cmp(next) <= 0 {
+		t.Fatalf("blob base fee predictions do not increase: %v", estimates.BlobBaseFee)
+	}
+}
+
+func TestFeeEstimatesMempool(t *testing.T) {
+	backend := &poolTestBackend{testBackend: newTestBackend(t, big.NewInt(0), big.NewInt(28), false)}
+	defer backend.teardown()
+
+	// The pool fills the next block, but not the next three.
+	head := backend.chain.GetHeaderByNumber(testHead)
+	backend.txs = types.Transactions{
+		newPoolTx(100, 1000, head.GasLimit),
+		newPoolTx(50, 1000, 21000),
+	}
+	config := Config{Blocks: 5, Percentile: 60, Strategy: StrategyMempool, InclusionBlocks: 1}
+	oracle := NewOracle(backend, config, big.NewInt(params.GWei))
+	estimates, err := oracle.FeeEstimates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		tier FeeTier
+		tip  *big.Int
+	}{
+		{"slow", estimates.Slow, big.NewInt(28 * params.GWei)},
+		{"standard", estimates.Standard, big.NewInt(29 * params.GWei)},
+		{"fast", estimates.Fast, big.NewInt(50*params.GWei + 1)},
+	} {
+		if tc.tier.MaxPriorityFeePerGas.Cmp(tc.tip) != 0 {
+			t.Errorf("%s: wrong tip %v, want %v", tc.name, tc.tier.MaxPriorityFeePerGas, tc.tip)
+		}
+	}
+	// The tier targeting the inclusion blocks of the strategy agrees with the
+	// suggested tip.
+	tip, err := oracle.SuggestTipCap(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tip.Cmp(estimates.Fast.MaxPriorityFeePerGas) != 0 {
+		t.Errorf("fast tier %v differs from suggested tip %v", estimates.Fast.MaxPriorityFeePerGas, tip)
+	}
+}
+
+func TestFeeEstimatesCache(t *testing.T) {
+	backend := &poolTestBackend{testBackend: newTestBackend(t, big.NewInt(0), big.NewInt(28), false)}
+	defer backend.teardown()
+
+	oracle := NewOracle(backend, Config{Blocks: 5, Percentile: 60, Strategy: StrategyMempool}, big.NewInt(params.GWei))
+	first, err := oracle.FeeEstimates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := first.Fast.MaxPriorityFeePerGas.Int64()
+	first.Fast.MaxPriorityFeePerGas.SetInt64(0)
+
+	// The estimates are reused while the head doesn't change, even if the pool
+	// content does, and modifying them doesn't affect the cache.
+	backend.txs = types.Transactions{newPoolTx(500, 1000, 100_000_000)}
+	second, err := oracle.FeeEstimates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := second.Fast.MaxPriorityFeePerGas.Int64(); have != want {
+		t.Fatalf("cached estimates changed: have %d, want %d", have, want)
+	}
+	if oracle.lastEstimatesHead != backend.chain.GetHeaderByNumber(testHead).Hash() {
+		t.Fatalf("estimates cached for wrong head %x", oracle.lastEstimatesHead)
+	}
+}
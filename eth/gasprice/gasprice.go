@@ -47,6 +47,8 @@ type Config struct {
 	MaxBlockHistory  uint64
 	MaxPrice         *big.Int `toml:",omitempty"`
 	IgnorePrice      *big.Int `toml:",omitempty"`
+	Strategy         string   `toml:",omitempty"` // Tip estimation strategy, percentile if empty
+	InclusionBlocks  int      `toml:",omitempty"` // Blocks the mempool strategy aims for inclusion within
 }
 
 // OracleBackend includes all necessary background APIs for oracle.
@@ -67,9 +69,13 @@ type Oracle struct {
 	lastPrice   *big.Int
 	maxPrice    *big.Int
 	ignorePrice *big.Int
+	strategy    Strategy
 	cacheLock   sync.RWMutex
 	fetchLock   sync.Mutex
 
+	lastEstimatesHead common.Hash   // Head the cached fee estimates are based on
+	lastEstimates     *FeeEstimates // Fee estimates on top of lastEstimatesHead
+
 	checkBlocks, percentile           int
 	maxHeaderHistory, maxBlockHistory uint64
 
@@ -138,7 +144,7 @@ func NewOracle(backend OracleBackend, params Config, startPrice *big.Int) *Oracl
 		}()
 	}
 
-	return &Oracle{
+	oracle := &Oracle{
 		backend:          backend,
 		lastPrice:        startPrice,
 		maxPrice:         maxPrice,
@@ -149,6 +155,8 @@ func NewOracle(backend OracleBackend, params Config, startPrice *big.Int) *Oracl
 		maxBlockHistory:  maxBlockHistory,
 		historyCache:     cache,
 	}
+	oracle.strategy = newStrategy(oracle, params)
+	return oracle
 }
 
 // SuggestTipCap returns a tip cap so that newly created transaction can have a
@@ -178,6 +186,25 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	if headHash == lastHead {
 		return new(big.Int).Set(lastPrice), nil
 	}
+	price, err := oracle.strategy.SuggestTipCap(ctx, head, lastPrice)
+	if err != nil {
+		return new(big.Int).Set(lastPrice), err
+	}
+	if price.Cmp(oracle.maxPrice) > 0 {
+		price = new(big.Int).Set(oracle.maxPrice)
+	}
+	oracle.cacheLock.Lock()
+	oracle.lastHead = headHash
+	oracle.lastPrice = price
+	oracle.cacheLock.Unlock()
+
+	return new(big.Int).Set(price), nil
+}
+
+// recentTipCap returns the configured percentile of the lowest tips paid by the
+// transactions included in the recent blocks. If the blocks carry no meaningful
+// transactions, the previous suggestion is retained.
+func (oracle *Oracle) recentTipCap(ctx context.Context, head *types.Header, lastPrice *big.Int) (*big.Int, error) {
 	var (
 		sent, exp int
 		number    = head.Number.Uint64()
@@ -195,7 +222,7 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 		res := <-result
 		if res.err != nil {
 			close(quit)
-			return new(big.Int).Set(lastPrice), res.err
+			return nil, res.err
 		}
 		exp--
 		// Nothing returned. There are two special cases here:
@@ -221,15 +248,7 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 		slices.SortFunc(results, func(a, b *big.Int) int { return a.Cmp(b) })
 		price = results[(len(results)-1)*oracle.percentile/100]
 	}
-	if price.Cmp(oracle.maxPrice) > 0 {
-		price = new(big.Int).Set(oracle.maxPrice)
-	}
-	oracle.cacheLock.Lock()
-	oracle.lastHead = headHash
-	oracle.lastPrice = price
-	oracle.cacheLock.Unlock()
-
-	return new(big.Int).Set(price), nil
+	return price, nil
 }
 
 type results struct {
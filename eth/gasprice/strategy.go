@@ -0,0 +1,162 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tip estimation strategies selectable through Config.Strategy.
+const (
+	StrategyPercentile = "percentile" // percentile of the tips paid in recent blocks
+	StrategyMempool    = "mempool"    // recent blocks combined with the pending pool content
+)
+
+// DefaultInclusionBlocks is the number of upcoming blocks the mempool strategy
+// aims for a transaction to be included within.
+const DefaultInclusionBlocks = 3
+
+// Strategy estimates the priority fee a transaction has to pay to be included
+// in the upcoming blocks. The oracle invokes it at most once per chain head,
+// caches the result and caps it at the configured maximum price.
+type Strategy interface {
+	// SuggestTipCap returns the suggested tip on top of the given head. The
+	// previous suggestion is passed as a fallback for when no data is available.
+	SuggestTipCap(ctx context.Context, head *types.Header, lastPrice *big.Int) (*big.Int, error)
+}
+
+// PoolBackend is implemented by oracle backends with access to the transaction
+// pool, which is required by the mempool strategy.
+type PoolBackend interface {
+	GetPoolTransactions() (types.Transactions, error)
+}
+
+// newStrategy creates the tip estimation strategy selected by the config,
+// falling back to the percentile strategy if it is unknown or unsupported by
+// the backend.
+func newStrategy(oracle *Oracle, config Config) Strategy {
+	switch config.Strategy {
+	case "", StrategyPercentile:
+	case StrategyMempool:
+		pool, ok := oracle.backend.(PoolBackend)
+		if !ok {
+			log.Warn("Gasprice oracle backend has no transaction pool access", "strategy", config.Strategy, "updated", StrategyPercentile)
+			break
+		}
+		blocks := config.InclusionBlocks
+		if blocks < 1 {
+			blocks = DefaultInclusionBlocks
+			log.Warn("Sanitizing invalid gasprice oracle inclusion blocks", "provided", config.InclusionBlocks, "updated", blocks)
+		}
+		return &mempoolStrategy{oracle: oracle, pool: pool, blocks: uint64(blocks)}
+	default:
+		log.Warn("Sanitizing invalid gasprice oracle strategy", "provided", config.Strategy, "updated", StrategyPercentile)
+	}
+	return &percentileStrategy{oracle: oracle}
+}
+
+// percentileStrategy suggests the configured percentile of the lowest tips
+// paid in the recent blocks.
+type percentileStrategy struct {
+	oracle *Oracle
+}
+
+func (s *percentileStrategy) SuggestTipCap(ctx context.Context, head *types.Header, lastPrice *big.Int) (*big.Int, error) {
+	return s.oracle.recentTipCap(ctx, head, lastPrice)
+}
+
+// mempoolStrategy predicts the tip needed for inclusion within the next few
+// blocks from the pending transactions competing for them. Block producers
+// fill blocks by effective tip, so a transaction outbidding the pool content
+// that fits into the target blocks is expected to be included in time. The
+// recent blocks estimate serves as a lower bound, which covers an empty pool
+// as well as transactions arriving before the target blocks are produced.
+type mempoolStrategy struct {
+	oracle *Oracle
+	pool   PoolBackend
+	blocks uint64
+}
+
+func (s *mempoolStrategy) SuggestTipCap(ctx context.Context, head *types.Header, lastPrice *big.Int) (*big.Int, error) {
+	price, err := s.oracle.recentTipCap(ctx, head, lastPrice)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := s.pool.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	if tip := s.poolTip(txs, head, s.blocks); tip != nil && tip.Cmp(price) > 0 {
+		price = tip
+	}
+	return price, nil
+}
+
+// poolTip returns the tip needed to outbid the pool transactions which fill the
+// given number of blocks on top of head, or nil if the pool doesn't fill them.
+func (s *mempoolStrategy) poolTip(txs types.Transactions, head *types.Header, blocks uint64) *big.Int {
+	return inclusionTip(txs, nextBaseFee(s.oracle.backend.ChainConfig(), head), blocks*head.GasLimit, s.oracle.ignorePrice)
+}
+
+// nextBaseFee returns the base fee of the block following head, or nil if the
+// London fork is not active at that block.
+func nextBaseFee(config *params.ChainConfig, head *types.Header) *big.Int {
+	if !config.IsLondon(new(big.Int).Add(head.Number, common.Big1)) {
+		return nil
+	}
+	return eip1559.CalcBaseFee(config, head)
+}
+
+// inclusionTip returns the tip needed to outbid the transactions which fill the
+// given amount of gas when ordered by effective tip. Transactions that cannot
+// pay the base fee, or that pay less than ignoreUnder, do not compete for block
+// space. Nil is returned if the pool content does not fill the capacity.
+func inclusionTip(txs types.Transactions, baseFee *big.Int, capacity uint64, ignoreUnder *big.Int) *big.Int {
+	type bid struct {
+		tip *big.Int
+		gas uint64
+	}
+	bids := make([]bid, 0, len(txs))
+	for _, tx := range txs {
+		if baseFee != nil && tx.GasFeeCapIntCmp(baseFee) < 0 {
+			continue
+		}
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil || (ignoreUnder != nil && tip.Cmp(ignoreUnder) < 0) {
+			continue
+		}
+		bids = append(bids, bid{tip, tx.Gas()})
+	}
+	slices.SortFunc(bids, func(a, b bid) int { return b.tip.Cmp(a.tip) })
+
+	var used uint64
+	for _, b := range bids {
+		used += b.gas
+		if used > capacity {
+			return new(big.Int).Add(b.tip, common.Big1)
+		}
+	}
+	return nil
+}
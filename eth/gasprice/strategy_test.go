@@ -0,0 +1,117 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// poolTestBackend extends the test backend with transaction pool access.
+type poolTestBackend struct {
+	*testBackend
+	txs types.Transactions
+}
+
+func (b *poolTestBackend) GetPoolTransactions() (types.Transactions, error) {
+	return b.txs, nil
+}
+
+func newPoolTx(tip, feeCap int64, gas uint64) *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		To:        &common.Address{},
+		Gas:       gas,
+		GasTipCap: big.NewInt(tip * params.GWei),
+		GasFeeCap: big.NewInt(feeCap * params.GWei),
+	})
+}
+
+func TestInclusionTip(t *testing.T) {
+	var (
+		baseFee = big.NewInt(10 * params.GWei)
+		ignore  = big.NewInt(2 * params.Wei)
+		txs     = types.Transactions{
+			newPoolTx(5, 100, 40000),
+			newPoolTx(50, 20, 40000),  // effective tip of 10 gwei
+			newPoolTx(80, 5, 1000000), // cannot pay the base fee
+			newPoolTx(20, 100, 40000),
+		}
+	)
+	for i, tc := range []struct {
+		capacity uint64
+		want     *big.Int
+	}{
+		{30000, big.NewInt(20*params.GWei + 1)},
+		{50000, big.NewInt(10*params.GWei + 1)},
+		{100000, big.NewInt(5*params.GWei + 1)},
+		{120000, nil}, // pool does not fill the capacity
+	} {
+		have := inclusionTip(txs, baseFee, tc.capacity, ignore)
+		if (have == nil) != (tc.want == nil) || (have != nil && have.Cmp(tc.want) != 0) {
+			t.Errorf("case %d: wrong inclusion tip: have %v, want %v", i, have, tc.want)
+		}
+	}
+}
+
+func TestMempoolStrategy(t *testing.T) {
+	backend := &poolTestBackend{testBackend: newTestBackend(t, big.NewInt(0), nil, false)}
+	defer backend.teardown()
+
+	config := Config{
+		Blocks:          3,
+		Percentile:      60,
+		Strategy:        StrategyMempool,
+		InclusionBlocks: 1,
+	}
+	head := backend.chain.GetHeaderByNumber(testHead)
+
+	// An empty pool leaves the recent blocks estimate in effect.
+	oracle := NewOracle(backend, config, big.NewInt(params.GWei))
+	if _, ok := oracle.strategy.(*mempoolStrategy); !ok {
+		t.Fatalf("wrong strategy: %T", oracle.strategy)
+	}
+	got, err := oracle.SuggestTipCap(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(30 * params.GWei); got.Cmp(want) != 0 {
+		t.Fatalf("wrong tip without pool content: have %v, want %v", got, want)
+	}
+	// A pool filling the next block raises the suggestion above its content.
+	backend.txs = types.Transactions{
+		newPoolTx(100, 1000, head.GasLimit),
+		newPoolTx(50, 1000, 21000),
+	}
+	oracle = NewOracle(backend, config, big.NewInt(params.GWei))
+	got, err = oracle.SuggestTipCap(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(50*params.GWei + 1); got.Cmp(want) != 0 {
+		t.Fatalf("wrong tip with pool content: have %v, want %v", got, want)
+	}
+	// Backends without pool access fall back to the percentile strategy.
+	oracle = NewOracle(backend.testBackend, config, big.NewInt(params.GWei))
+	if _, ok := oracle.strategy.(*percentileStrategy); !ok {
+		t.Fatalf("wrong fallback strategy: %T", oracle.strategy)
+	}
+}
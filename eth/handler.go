@@ -227,6 +227,30 @@ func (h *handler) protoTracker() {
 	}
 }
 
+// requestTimeoutLoop lowers the reputation score of peers failing to respond to
+// `eth` or `snap` requests in time.
+func (h *handler) requestTimeoutLoop() {
+	defer h.wg.Done()
+
+	timeouts := make(chan string, 16)
+	ethSub := eth.SubscribeRequestTimeouts(timeouts)
+	defer ethSub.Unsubscribe()
+	snapSub := snap.SubscribeRequestTimeouts(timeouts)
+	defer snapSub.Unsubscribe()
+
+	for {
+		select {
+		case id := <-timeouts:
+			if p := h.peers.peer(id); p != nil {
+				p.Log().Debug("Peer failed to respond in time")
+				p.Report(p2p.ScoreTimeout)
+			}
+		case <-h.quitSync:
+			return
+		}
+	}
+}
+
 // incHandlers signals to increment the number of active handlers if not
 // quitting.
 func (h *handler) incHandlers() bool {
@@ -442,6 +466,10 @@ func (h *handler) Start(maxPeers int) {
 	// start peer handler tracker
 	h.wg.Add(1)
 	go h.protoTracker()
+
+	// penalize peers failing to respond to requests
+	h.wg.Add(1)
+	go h.requestTimeoutLoop()
 }
 
 func (h *handler) Stop() {
@@ -666,7 +694,7 @@ func (h *handler) broadcastBlockRange(state *blockRangeState) {
 
 // update assigns the values of the next block range update from the chain.
 func (st *blockRangeState) update(chain *core.BlockChain, latest *types.Header) {
-	earliest, _ := chain.HistoryPruningCutoff()
+	earliest := chain.EarliestAvailableBlock()
 	st.next.Store(&eth.BlockRangeUpdatePacket{
 		EarliestBlock:   min(latest.Number.Uint64(), earliest),
 		LatestBlock:     latest.Number.Uint64(),
@@ -676,12 +704,14 @@ func (st *blockRangeState) update(chain *core.BlockChain, latest *types.Header)
 
 // shouldSend decides whether it is time to send a block range update. We don't want to
 // send these updates constantly, so they will usually only be sent every 32 blocks.
-// However, there is a special case: if the range would move back, i.e. due to SetHead, we
-// want to send it immediately.
+// However, there are special cases: if the range would move back, i.e. due to SetHead,
+// or when the earliest block changes because history was backfilled, we want to send it
+// immediately.
 func (st *blockRangeState) shouldSend() bool {
 	next := st.next.Load()
 	return next.LatestBlock < st.prev.LatestBlock ||
-		next.LatestBlock-st.prev.LatestBlock >= 32
+		next.LatestBlock-st.prev.LatestBlock >= 32 ||
+		next.EarliestBlock != st.prev.EarliestBlock
 }
 
 func (st *blockRangeState) stop() {
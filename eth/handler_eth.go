@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
@@ -66,6 +67,7 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 				return errors.New("disallowed broadcast blob transaction")
 			}
 		}
+		h.reportUsefulTxs(peer, *packet)
 		return h.txFetcher.Enqueue(peer.ID(), *packet, false)
 
 	case *eth.PooledTransactionsResponse:
@@ -82,9 +84,21 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 				}
 			}
 		}
+		h.reportUsefulTxs(peer, *packet)
 		return h.txFetcher.Enqueue(peer.ID(), *packet, true)
 
 	default:
 		return fmt.Errorf("unexpected eth packet type: %T", packet)
 	}
 }
+
+// reportUsefulTxs raises the reputation score of the peer if it delivered any
+// transaction not yet known to the local pool.
+func (h *ethHandler) reportUsefulTxs(peer *eth.Peer, txs []*types.Transaction) {
+	for _, tx := range txs {
+		if !h.txpool.Has(tx.Hash()) {
+			peer.Report(p2p.ScoreUseful)
+			return
+		}
+	}
+}
@@ -244,6 +244,11 @@ func (p *Peer) dispatcher() {
 				res.Time = res.recv.Sub(res.Req.Sent)
 				resOp.fail <- nil
 
+				// Feed the response time into the reputation of the peer. The
+				// number of items is not known here, but only the latency is
+				// scored anyway.
+				p.ReportResponse(res.code, res.Time, 1)
+
 				// Stop tracking the request, the response dispatcher will deliver
 				delete(pending, res.id)
 			}
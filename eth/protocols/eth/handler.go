@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -119,13 +120,28 @@ func MakeProtocols(backend Backend, network uint64, disc enode.Iterator) []p2p.P
 			PeerInfo: func(id enode.ID) interface{} {
 				return backend.PeerInfo(id)
 			},
-			DialCandidates: disc,
-			Attributes:     []enr.Entry{currentENREntry(backend.Chain())},
+			DialCandidates:  disc,
+			Attributes:      []enr.Entry{currentENREntry(backend.Chain())},
+			MessagePriority: messagePriority,
 		})
 	}
 	return protocols
 }
 
+// messagePriority classifies the `eth` messages into traffic classes: block
+// propagation and the handshake go first, followed by the serving of chain
+// data, and transaction gossip last.
+func messagePriority(code uint64) p2p.Priority {
+	switch code {
+	case StatusMsg, NewBlockHashesMsg, NewBlockMsg, BlockRangeUpdateMsg:
+		return p2p.PriorityHigh
+	case TransactionsMsg, NewPooledTransactionHashesMsg, GetPooledTransactionsMsg, PooledTransactionsMsg:
+		return p2p.PriorityLow
+	default:
+		return p2p.PriorityNormal
+	}
+}
+
 // NodeInfo represents a short summary of the `eth` sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct {
@@ -197,12 +213,19 @@ var eth69 = map[uint64]msgHandler{
 
 // handleMessage is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
-func handleMessage(backend Backend, peer *Peer) error {
+func handleMessage(backend Backend, peer *Peer) (err error) {
 	// Read the next message from the remote peer, and ensure it's fully consumed
 	msg, err := peer.rw.ReadMsg()
 	if err != nil {
 		return err
 	}
+	// Any failure past this point is caused by the contents of the message,
+	// penalize the reputation of the peer for it.
+	defer func() {
+		if err != nil && !errors.Is(err, p2p.ErrShuttingDown) {
+			peer.Report(p2p.ScoreInvalid)
+		}
+	}()
 	if msg.Size > maxMessageSize {
 		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
 	}
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/tracker"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -34,6 +35,12 @@ import (
 // requestTracker is a singleton tracker for eth/66 and newer request times.
 var requestTracker = tracker.New(ProtocolName, 5*time.Minute)
 
+// SubscribeRequestTimeouts subscribes to the identifiers of peers failing to
+// respond to an `eth` request in time.
+func SubscribeRequestTimeouts(ch chan<- string) event.Subscription {
+	return requestTracker.SubscribeTimeouts(ch)
+}
+
 func handleGetBlockHeaders(backend Backend, msg Decoder, peer *Peer) error {
 	// Decode the complex header query
 	var query GetBlockHeadersPacket
@@ -521,7 +528,9 @@ func handlePooledTransactions(backend Backend, msg Decoder, peer *Peer) error {
 		}
 		peer.markTransaction(tx.Hash())
 	}
-	requestTracker.Fulfil(peer.id, peer.version, PooledTransactionsMsg, txs.RequestId)
+	if elapsed := requestTracker.Fulfil(peer.id, peer.version, PooledTransactionsMsg, txs.RequestId); elapsed > 0 {
+		peer.ReportResponse(PooledTransactionsMsg, elapsed, len(txs.PooledTransactionsResponse))
+	}
 
 	return backend.Handle(peer, &txs.PooledTransactionsResponse)
 }
@@ -18,6 +18,7 @@ package snap
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"time"
 
@@ -123,12 +124,19 @@ func Handle(backend Backend, peer *Peer) error {
 // HandleMessage is invoked whenever an inbound message is received from a
 // remote peer on the `snap` protocol. The remote connection is torn down upon
 // returning any error.
-func HandleMessage(backend Backend, peer *Peer) error {
+func HandleMessage(backend Backend, peer *Peer) (err error) {
 	// Read the next message from the remote peer, and ensure it's fully consumed
 	msg, err := peer.rw.ReadMsg()
 	if err != nil {
 		return err
 	}
+	// Any failure past this point is caused by the contents of the message,
+	// penalize the reputation of the peer for it.
+	defer func() {
+		if err != nil && !errors.Is(err, p2p.ErrShuttingDown) && peer.Peer != nil {
+			peer.Report(p2p.ScoreInvalid)
+		}
+	}()
 	if msg.Size > maxMessageSize {
 		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
 	}
@@ -176,7 +184,7 @@ func HandleMessage(backend Backend, peer *Peer) error {
 				return fmt.Errorf("accounts not monotonically increasing: #%d [%x] vs #%d [%x]", i-1, res.Accounts[i-1].Hash[:], i, res.Accounts[i].Hash[:])
 			}
 		}
-		requestTracker.Fulfil(peer.id, peer.version, AccountRangeMsg, res.ID)
+		fulfilRequest(peer, AccountRangeMsg, res.ID, len(res.Accounts))
 
 		return backend.Handle(peer, res)
 
@@ -210,7 +218,7 @@ func HandleMessage(backend Backend, peer *Peer) error {
 				}
 			}
 		}
-		requestTracker.Fulfil(peer.id, peer.version, StorageRangesMsg, res.ID)
+		fulfilRequest(peer, StorageRangesMsg, res.ID, len(res.Slots))
 
 		return backend.Handle(peer, res)
 
@@ -235,7 +243,7 @@ func HandleMessage(backend Backend, peer *Peer) error {
 		if err := msg.Decode(res); err != nil {
 			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 		}
-		requestTracker.Fulfil(peer.id, peer.version, ByteCodesMsg, res.ID)
+		fulfilRequest(peer, ByteCodesMsg, res.ID, len(res.Codes))
 
 		return backend.Handle(peer, res)
 
@@ -262,7 +270,7 @@ func HandleMessage(backend Backend, peer *Peer) error {
 		if err := msg.Decode(res); err != nil {
 			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 		}
-		requestTracker.Fulfil(peer.id, peer.version, TrieNodesMsg, res.ID)
+		fulfilRequest(peer, TrieNodesMsg, res.ID, len(res.Nodes))
 
 		return backend.Handle(peer, res)
 
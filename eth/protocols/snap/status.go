@@ -0,0 +1,277 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Phases of a snap sync cycle reported in SyncStatus.
+const (
+	SyncPhaseIdle     = "idle"     // No sync cycle is running
+	SyncPhaseSnapshot = "snapshot" // Account and storage ranges are being downloaded
+	SyncPhaseHealing  = "healing"  // State trie is being healed
+)
+
+// SyncStatus is a detailed snapshot of the state of the syncer, meant for
+// diagnosing a running sync.
+type SyncStatus struct {
+	Root   common.Hash `json:"root"`   // State root being synced
+	Phase  string      `json:"phase"`  // Current phase of the sync cycle
+	Paused bool        `json:"paused"` // Whether request assignment is suspended
+
+	// Estimated progress of the snapshot download phase. The ETA is zero if
+	// not enough data was downloaded yet to estimate it.
+	Progress   float64 `json:"progress"`
+	ETASeconds uint64  `json:"etaSeconds"`
+
+	Tasks  []SyncTaskStatus `json:"tasks"`  // Account range tasks left
+	Queues SyncQueueStatus  `json:"queues"` // Sizes of the request and heal queues
+	Peers  []SyncPeerStatus `json:"peers"`  // Peers known to the syncer
+}
+
+// SyncTaskStatus is the status of an account range task.
+type SyncTaskStatus struct {
+	Next         common.Hash `json:"next"`         // Next account to sync in this interval
+	Last         common.Hash `json:"last"`         // Last account to sync in this interval
+	Progress     float64     `json:"progress"`     // Filled fraction of the interval
+	StorageTasks int         `json:"storageTasks"` // Number of large contracts being chunked
+	Pending      bool        `json:"pending"`      // Whether a request is in flight for the task
+}
+
+// SyncQueueStatus contains the number of in-flight requests and the sizes of
+// the heal queues.
+type SyncQueueStatus struct {
+	AccountRequests      int `json:"accountRequests"`
+	StorageRequests      int `json:"storageRequests"`
+	BytecodeRequests     int `json:"bytecodeRequests"`
+	TrienodeHealRequests int `json:"trienodeHealRequests"`
+	BytecodeHealRequests int `json:"bytecodeHealRequests"`
+
+	TrienodeHealTasks    int     `json:"trienodeHealTasks"`    // Trie nodes scheduled for retrieval
+	BytecodeHealTasks    int     `json:"bytecodeHealTasks"`    // Bytecodes scheduled for retrieval
+	HealPending          int     `json:"healPending"`          // Items pending in the state scheduler
+	TrienodeHealPend     uint64  `json:"trienodeHealPend"`     // Trie nodes waiting to be processed
+	TrienodeHealThrottle float64 `json:"trienodeHealThrottle"` // Divisor of the trie node request size
+}
+
+// SyncPeerStatus is the status of a snap peer.
+type SyncPeerStatus struct {
+	ID        string `json:"id"`
+	Stateless bool   `json:"stateless"` // Failed to deliver state data in this cycle
+	Banned    bool   `json:"banned"`    // Excluded from serving sync data by the user
+
+	// Estimated number of items per second the peer can deliver, by request type.
+	Throughput map[string]int `json:"throughput"`
+}
+
+// peerThroughputKinds are the request types reported in SyncPeerStatus.
+var peerThroughputKinds = map[string]uint64{
+	"accounts":  AccountRangeMsg,
+	"storage":   StorageRangesMsg,
+	"bytecodes": ByteCodesMsg,
+	"trienodes": TrieNodesMsg,
+}
+
+// Status returns a detailed report of the state of the syncer. The task and
+// queue information is updated by the sync loop, so it reflects the state of
+// the last cycle once the sync is finished.
+func (s *Syncer) Status() *SyncStatus {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	status := *s.extStatus
+	status.Paused = s.paused.Load()
+
+	ids := make([]string, 0, len(s.peers)+len(s.bannedPeers))
+	for id := range s.peers {
+		ids = append(ids, id)
+	}
+	for id := range s.bannedPeers {
+		if _, ok := s.peers[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	status.Peers = make([]SyncPeerStatus, 0, len(ids))
+	for _, id := range ids {
+		peer := SyncPeerStatus{ID: id}
+		if _, ok := s.statelessPeers[id]; ok {
+			peer.Stateless = true
+		}
+		if _, ok := s.bannedPeers[id]; ok {
+			peer.Banned = true
+		}
+		if _, ok := s.peers[id]; ok {
+			peer.Throughput = make(map[string]int, len(peerThroughputKinds))
+			for name, kind := range peerThroughputKinds {
+				peer.Throughput[name] = s.rates.Capacity(id, kind, time.Second)
+			}
+		}
+		status.Peers = append(status.Peers, peer)
+	}
+	return &status
+}
+
+// syncStatus assembles the task and queue parts of the sync status. It must be
+// called from the sync loop, with the lock held.
+func (s *Syncer) syncStatus() *SyncStatus {
+	status := &SyncStatus{
+		Root:  s.root,
+		Phase: SyncPhaseSnapshot,
+		Tasks: make([]SyncTaskStatus, 0, len(s.tasks)),
+		Queues: SyncQueueStatus{
+			AccountRequests:      len(s.accountReqs),
+			StorageRequests:      len(s.storageReqs),
+			BytecodeRequests:     len(s.bytecodeReqs),
+			TrienodeHealRequests: len(s.trienodeHealReqs),
+			BytecodeHealRequests: len(s.bytecodeHealReqs),
+			TrienodeHealPend:     s.trienodeHealPend.Load(),
+			TrienodeHealThrottle: s.trienodeHealThrottle,
+		},
+	}
+	if len(s.tasks) == 0 {
+		status.Phase = SyncPhaseHealing
+	}
+	if s.healer != nil {
+		status.Queues.TrienodeHealTasks = len(s.healer.trieTasks)
+		status.Queues.BytecodeHealTasks = len(s.healer.codeTasks)
+		status.Queues.HealPending = s.healer.scheduler.Pending()
+	}
+	// Tasks are created by splitting the hash space evenly, so the filled
+	// part of each can be estimated from the remaining gap.
+	span := new(big.Float).SetInt(new(big.Int).Div(hashSpace, big.NewInt(int64(accountConcurrency))))
+	for _, task := range s.tasks {
+		gap := new(big.Float).SetInt(new(big.Int).Sub(task.Last.Big(), task.Next.Big()))
+		left, _ := new(big.Float).Quo(gap, span).Float64()
+
+		status.Tasks = append(status.Tasks, SyncTaskStatus{
+			Next:         task.Next,
+			Last:         task.Last,
+			Progress:     max(0, 1-left),
+			StorageTasks: len(task.SubTasks),
+			Pending:      task.req != nil,
+		})
+	}
+	if progress, eta, ok := s.estimateSyncProgress(); ok {
+		status.Progress = progress
+		status.ETASeconds = uint64(max(0, eta) / time.Second)
+	} else if len(s.tasks) == 0 {
+		status.Progress = 1
+	}
+	return status
+}
+
+// estimateSyncProgress estimates the downloaded fraction of the state and the
+// remaining time of the snapshot download phase, extrapolating from the filled
+// part of the account hash space. It returns false if there is not enough
+// progress yet to make a meaningful estimate.
+func (s *Syncer) estimateSyncProgress() (float64, time.Duration, bool) {
+	synced := s.accountBytes + s.bytecodeBytes + s.storageBytes
+	if synced == 0 {
+		return 0, 0, false
+	}
+	accountGaps := new(big.Int)
+	for _, task := range s.tasks {
+		accountGaps.Add(accountGaps, new(big.Int).Sub(task.Last.Big(), task.Next.Big()))
+	}
+	accountFills := new(big.Int).Sub(hashSpace, accountGaps)
+	if accountFills.BitLen() == 0 {
+		return 0, 0, false
+	}
+	estBytes := float64(new(big.Int).Div(
+		new(big.Int).Mul(new(big.Int).SetUint64(uint64(synced)), hashSpace),
+		accountFills,
+	).Uint64())
+	if estBytes < 1.0 {
+		return 0, 0, false
+	}
+	// Cap the estimated state size using the synced size to avoid negative values
+	if estBytes < float64(synced) {
+		estBytes = float64(synced)
+	}
+	elapsed := time.Since(s.startTime)
+	estTime := elapsed / time.Duration(synced) * time.Duration(estBytes)
+
+	return float64(synced) / estBytes, estTime - elapsed, true
+}
+
+// Pause suspends the assignment of new requests to peers. Requests already in
+// flight are still processed. The syncer stays paused across sync cycles until
+// Resume is called.
+func (s *Syncer) Pause() {
+	if !s.paused.Swap(true) {
+		log.Info("Snap sync paused")
+	}
+}
+
+// Resume continues assigning requests after a Pause.
+func (s *Syncer) Resume() {
+	if s.paused.Swap(false) {
+		log.Info("Snap sync resumed")
+		s.notify()
+	}
+}
+
+// BanPeer excludes a peer from serving sync data. Requests already sent to the
+// peer are not cancelled. The ban survives the peer reconnecting.
+func (s *Syncer) BanPeer(id string) {
+	s.lock.Lock()
+	s.bannedPeers[id] = struct{}{}
+	s.lock.Unlock()
+
+	log.Info("Banned snap sync peer", "id", id)
+}
+
+// UnbanPeer allows a previously banned peer to serve sync data again. It returns
+// false if the peer was not banned.
+func (s *Syncer) UnbanPeer(id string) bool {
+	s.lock.Lock()
+	_, ok := s.bannedPeers[id]
+	delete(s.bannedPeers, id)
+	s.lock.Unlock()
+
+	if ok {
+		log.Info("Unbanned snap sync peer", "id", id)
+		s.notify()
+	}
+	return ok
+}
+
+// excludedPeer reports whether requests must not be assigned to a peer. It must
+// be called with the lock held.
+func (s *Syncer) excludedPeer(id string) bool {
+	if _, ok := s.statelessPeers[id]; ok {
+		return true
+	}
+	_, ok := s.bannedPeers[id]
+	return ok
+}
+
+// notify wakes up the sync loop to reschedule tasks.
+func (s *Syncer) notify() {
+	select {
+	case s.update <- struct{}{}:
+	default:
+	}
+}
@@ -458,6 +458,7 @@ type Syncer struct {
 
 	// Request tracking during syncing phase
 	statelessPeers map[string]struct{} // Peers that failed to deliver state data
+	bannedPeers    map[string]struct{} // Peers excluded from serving sync data by the user
 	accountIdlers  map[string]struct{} // Peers that aren't serving account requests
 	bytecodeIdlers map[string]struct{} // Peers that aren't serving bytecode requests
 	storageIdlers  map[string]struct{} // Peers that aren't serving storage requests
@@ -474,6 +475,8 @@ type Syncer struct {
 	storageBytes   common.StorageSize // Number of storage trie bytes persisted to disk
 
 	extProgress *SyncProgress // progress that can be exposed to external caller.
+	extStatus   *SyncStatus   // detailed status that can be exposed to external caller.
+	paused      atomic.Bool   // Flag whether the assignment of new requests is suspended
 
 	// Request tracking during healing phase
 	trienodeHealIdlers map[string]struct{} // Peers that aren't serving trie node requests
@@ -524,6 +527,8 @@ func NewSyncer(db ethdb.KeyValueStore, scheme string) *Syncer {
 		rates:    msgrate.NewTrackers(log.New("proto", "snap")),
 		update:   make(chan struct{}, 1),
 
+		bannedPeers: make(map[string]struct{}),
+
 		accountIdlers:  make(map[string]struct{}),
 		storageIdlers:  make(map[string]struct{}),
 		bytecodeIdlers: make(map[string]struct{}),
@@ -541,6 +546,7 @@ func NewSyncer(db ethdb.KeyValueStore, scheme string) *Syncer {
 		stateWriter:          db.NewBatch(),
 
 		extProgress: new(SyncProgress),
+		extStatus:   &SyncStatus{Phase: SyncPhaseIdle},
 	}
 }
 
@@ -656,6 +662,7 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 		s.bytecodeReqs = make(map[uint64]*bytecodeRequest)
 		s.trienodeHealReqs = make(map[uint64]*trienodeHealRequest)
 		s.bytecodeHealReqs = make(map[uint64]*bytecodeHealRequest)
+		s.extStatus.Phase = SyncPhaseIdle
 		s.lock.Unlock()
 	}()
 	// Keep scheduling sync tasks
@@ -697,11 +704,14 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 			}
 			return nil
 		}
-		// Assign all the data retrieval tasks to any free peers
-		s.assignAccountTasks(accountResps, accountReqFails, cancel)
-		s.assignBytecodeTasks(bytecodeResps, bytecodeReqFails, cancel)
-		s.assignStorageTasks(storageResps, storageReqFails, cancel)
-
+		// Assign all the data retrieval tasks to any free peers, unless the
+		// sync was paused by the user
+		paused := s.paused.Load()
+		if !paused {
+			s.assignAccountTasks(accountResps, accountReqFails, cancel)
+			s.assignBytecodeTasks(bytecodeResps, bytecodeReqFails, cancel)
+			s.assignStorageTasks(storageResps, storageReqFails, cancel)
+		}
 		if len(s.tasks) == 0 {
 			// State sync phase completed, record the elapsed time in metrics.
 			// Note: the initial state sync runs only once, regardless of whether
@@ -714,8 +724,10 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 			if s.healStartTime.IsZero() {
 				s.healStartTime = time.Now()
 			}
-			s.assignTrienodeHealTasks(trienodeHealResps, trienodeHealReqFails, cancel)
-			s.assignBytecodeHealTasks(bytecodeHealResps, bytecodeHealReqFails, cancel)
+			if !paused {
+				s.assignTrienodeHealTasks(trienodeHealResps, trienodeHealReqFails, cancel)
+				s.assignBytecodeHealTasks(bytecodeHealResps, bytecodeHealReqFails, cancel)
+			}
 		}
 		// Update sync progress
 		s.lock.Lock()
@@ -731,6 +743,7 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 			BytecodeHealSynced: s.bytecodeHealSynced,
 			BytecodeHealBytes:  s.bytecodeHealBytes,
 		}
+		s.extStatus = s.syncStatus()
 		s.lock.Unlock()
 		// Wait for something to happen
 		select {
@@ -1028,7 +1041,7 @@ func (s *Syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 	}
 	targetTTL := s.rates.TargetTimeout()
 	for id := range s.accountIdlers {
-		if _, ok := s.statelessPeers[id]; ok {
+		if s.excludedPeer(id) {
 			continue
 		}
 		idlers.ids = append(idlers.ids, id)
@@ -1125,7 +1138,7 @@ func (s *Syncer) assignBytecodeTasks(success chan *bytecodeResponse, fail chan *
 	}
 	targetTTL := s.rates.TargetTimeout()
 	for id := range s.bytecodeIdlers {
-		if _, ok := s.statelessPeers[id]; ok {
+		if s.excludedPeer(id) {
 			continue
 		}
 		idlers.ids = append(idlers.ids, id)
@@ -1228,7 +1241,7 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 	}
 	targetTTL := s.rates.TargetTimeout()
 	for id := range s.storageIdlers {
-		if _, ok := s.statelessPeers[id]; ok {
+		if s.excludedPeer(id) {
 			continue
 		}
 		idlers.ids = append(idlers.ids, id)
@@ -1385,7 +1398,7 @@ func (s *Syncer) assignTrienodeHealTasks(success chan *trienodeHealResponse, fai
 	}
 	targetTTL := s.rates.TargetTimeout()
 	for id := range s.trienodeHealIdlers {
-		if _, ok := s.statelessPeers[id]; ok {
+		if s.excludedPeer(id) {
 			continue
 		}
 		idlers.ids = append(idlers.ids, id)
@@ -1513,7 +1526,7 @@ func (s *Syncer) assignBytecodeHealTasks(success chan *bytecodeHealResponse, fai
 	}
 	targetTTL := s.rates.TargetTimeout()
 	for id := range s.bytecodeHealIdlers {
-		if _, ok := s.statelessPeers[id]; ok {
+		if s.excludedPeer(id) {
 			continue
 		}
 		idlers.ids = append(idlers.ids, id)
@@ -3110,43 +3123,22 @@ func (s *Syncer) reportSyncProgress(force bool) {
 		return
 	}
 	// Don't report anything until we have a meaningful progress
-	synced := s.accountBytes + s.bytecodeBytes + s.storageBytes
-	if synced == 0 {
-		return
-	}
-	accountGaps := new(big.Int)
-	for _, task := range s.tasks {
-		accountGaps.Add(accountGaps, new(big.Int).Sub(task.Last.Big(), task.Next.Big()))
-	}
-	accountFills := new(big.Int).Sub(hashSpace, accountGaps)
-	if accountFills.BitLen() == 0 {
+	progress, eta, ok := s.estimateSyncProgress()
+	if !ok {
 		return
 	}
 	s.logTime = time.Now()
-	estBytes := float64(new(big.Int).Div(
-		new(big.Int).Mul(new(big.Int).SetUint64(uint64(synced)), hashSpace),
-		accountFills,
-	).Uint64())
-	// Don't report anything until we have a meaningful progress
-	if estBytes < 1.0 {
-		return
-	}
-	// Cap the estimated state size using the synced size to avoid negative values
-	if estBytes < float64(synced) {
-		estBytes = float64(synced)
-	}
-	elapsed := time.Since(s.startTime)
-	estTime := elapsed / time.Duration(synced) * time.Duration(estBytes)
 
 	// Create a mega progress report
 	var (
-		progress = fmt.Sprintf("%.2f%%", float64(synced)*100/estBytes)
+		synced   = s.accountBytes + s.bytecodeBytes + s.storageBytes
+		percent  = fmt.Sprintf("%.2f%%", progress*100)
 		accounts = fmt.Sprintf("%v@%v", log.FormatLogfmtUint64(s.accountSynced), s.accountBytes.TerminalString())
 		storage  = fmt.Sprintf("%v@%v", log.FormatLogfmtUint64(s.storageSynced), s.storageBytes.TerminalString())
 		bytecode = fmt.Sprintf("%v@%v", log.FormatLogfmtUint64(s.bytecodeSynced), s.bytecodeBytes.TerminalString())
 	)
-	log.Info("Syncing: state download in progress", "synced", progress, "state", synced,
-		"accounts", accounts, "slots", storage, "codes", bytecode, "eta", common.PrettyDuration(estTime-elapsed))
+	log.Info("Syncing: state download in progress", "synced", percent, "state", synced,
+		"accounts", accounts, "slots", storage, "codes", bytecode, "eta", common.PrettyDuration(eta))
 }
 
 // reportHealProgress calculates various status reports and provides it to the user.
@@ -1964,3 +1964,118 @@ func newDbConfig(scheme string) *triedb.Config {
 	}
 	return &triedb.Config{PathDB: &pathdb.Config{SnapshotNoBuild: true}}
 }
+
+// TestSyncPauseResume tests that a paused sync doesn't request any data, and
+// completes after being resumed.
+func TestSyncPauseResume(t *testing.T) {
+	t.Parallel()
+
+	var (
+		once   sync.Once
+		cancel = make(chan struct{})
+		term   = func() {
+			once.Do(func() {
+				close(cancel)
+			})
+		}
+	)
+	nodeScheme, sourceAccountTrie, elems := makeAccountTrieNoStorage(100, rawdb.HashScheme)
+
+	source := newTestPeer("source", t, term)
+	source.accountTrie = sourceAccountTrie.Copy()
+	source.accountValues = elems
+
+	syncer := setupSyncer(nodeScheme, source)
+	syncer.Pause()
+
+	done := make(chan error, 1)
+	go func() { done <- syncer.Sync(sourceAccountTrie.Hash(), cancel) }()
+
+	// Wait for the sync loop to publish its status.
+	deadline := time.Now().Add(5 * time.Second)
+	status := syncer.Status()
+	for status.Phase != SyncPhaseSnapshot && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		status = syncer.Status()
+	}
+	if status.Phase != SyncPhaseSnapshot {
+		t.Fatalf("wrong phase: have %q, want %q", status.Phase, SyncPhaseSnapshot)
+	}
+	if !status.Paused {
+		t.Fatal("status not paused")
+	}
+	if len(status.Tasks) != accountConcurrency {
+		t.Fatalf("wrong number of tasks: have %d, want %d", len(status.Tasks), accountConcurrency)
+	}
+	for i, task := range status.Tasks {
+		if task.Pending {
+			t.Fatalf("task %d has a pending request while paused", i)
+		}
+	}
+	if status.Queues.AccountRequests != 0 {
+		t.Fatalf("account requests sent while paused: %d", status.Queues.AccountRequests)
+	}
+	if len(status.Peers) != 1 || status.Peers[0].ID != "source" {
+		t.Fatalf("wrong peers: %+v", status.Peers)
+	}
+	syncer.Resume()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("sync did not complete after resume")
+	}
+	verifyTrie(rawdb.HashScheme, syncer.db, sourceAccountTrie.Hash(), t)
+
+	if status := syncer.Status(); status.Phase != SyncPhaseIdle || status.Paused {
+		t.Fatalf("wrong status after sync: phase %q, paused %v", status.Phase, status.Paused)
+	}
+}
+
+// TestSyncBannedPeer tests that banned peers are not asked for data.
+func TestSyncBannedPeer(t *testing.T) {
+	t.Parallel()
+
+	var (
+		once   sync.Once
+		cancel = make(chan struct{})
+		term   = func() {
+			once.Do(func() {
+				close(cancel)
+			})
+		}
+	)
+	nodeScheme, sourceAccountTrie, elems := makeAccountTrieNoStorage(100, rawdb.HashScheme)
+
+	mkSource := func(name string) *testPeer {
+		source := newTestPeer(name, t, term)
+		source.accountTrie = sourceAccountTrie.Copy()
+		source.accountValues = elems
+		return source
+	}
+	good, banned := mkSource("good"), mkSource("banned")
+	syncer := setupSyncer(nodeScheme, good, banned)
+	syncer.BanPeer("banned")
+
+	if err := syncer.Sync(sourceAccountTrie.Hash(), cancel); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	verifyTrie(rawdb.HashScheme, syncer.db, sourceAccountTrie.Hash(), t)
+
+	if banned.nAccountRequests+banned.nTrienodeRequests != 0 {
+		t.Fatalf("banned peer was sent requests: %s", banned.Stats())
+	}
+	if good.nAccountRequests == 0 {
+		t.Fatal("good peer was not sent any requests")
+	}
+	status := syncer.Status()
+	if len(status.Peers) != 2 || status.Peers[0].ID != "banned" || !status.Peers[0].Banned || status.Peers[1].Banned {
+		t.Fatalf("wrong peer status: %+v", status.Peers)
+	}
+	if !syncer.UnbanPeer("banned") || syncer.UnbanPeer("banned") {
+		t.Fatal("wrong unban result")
+	}
+}
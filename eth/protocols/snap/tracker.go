@@ -19,8 +19,23 @@ package snap
 import (
 	"time"
 
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/p2p/tracker"
 )
 
 // requestTracker is a singleton tracker for request times.
 var requestTracker = tracker.New(ProtocolName, time.Minute)
+
+// SubscribeRequestTimeouts subscribes to the identifiers of peers failing to
+// respond to a `snap` request in time.
+func SubscribeRequestTimeouts(ch chan<- string) event.Subscription {
+	return requestTracker.SubscribeTimeouts(ch)
+}
+
+// fulfilRequest marks a tracked request as serviced and feeds the response time
+// into the reputation score of the peer.
+func fulfilRequest(peer *Peer, code uint64, id uint64, items int) {
+	if elapsed := requestTracker.Fulfil(peer.id, peer.version, code, id); elapsed > 0 && peer.Peer != nil {
+		peer.ReportResponse(code, elapsed, items)
+	}
+}
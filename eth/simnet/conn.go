@@ -0,0 +1,224 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simnet
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// segment is a chunk of data written into a virtual link, to be delivered to
+// the reading side at a given time.
+type segment struct {
+	data []byte
+	at   time.Time
+}
+
+// stream is one direction of a virtual connection. Written data is queued up
+// and released to the reader once the simulated link latency has passed.
+type stream struct {
+	net      *Network
+	from, to enode.ID
+
+	lock     sync.Mutex
+	queue    []segment     // Segments in flight, ordered by delivery time
+	buf      []byte        // Delivered but not yet read data
+	closed   bool          // Whether the writing side closed the stream
+	deadline time.Time     // Read deadline, zero if none
+	wake     chan struct{} // Notification channel for reader wakeups
+}
+
+func newStream(net *Network, from, to enode.ID) *stream {
+	return &stream{
+		net:  net,
+		from: from,
+		to:   to,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// notify wakes up the reader to re-evaluate the stream state.
+func (s *stream) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// write queues up a copy of the data for delivery.
+func (s *stream) write(p []byte) (int, error) {
+	delay := s.net.delay(s.from, s.to)
+
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	at := time.Now().Add(delay)
+	if n := len(s.queue); n > 0 && at.Before(s.queue[n-1].at) {
+		at = s.queue[n-1].at // streams deliver in order
+	}
+	s.queue = append(s.queue, segment{data: append([]byte{}, p...), at: at})
+	s.lock.Unlock()
+
+	s.notify()
+	return len(p), nil
+}
+
+// read blocks until data is delivered, the stream is closed or the deadline
+// expires.
+func (s *stream) read(p []byte) (int, error) {
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		s.lock.Lock()
+		now := time.Now()
+		if len(s.buf) == 0 && len(s.queue) > 0 && !s.queue[0].at.After(now) {
+			s.buf, s.queue = s.queue[0].data, s.queue[1:]
+		}
+		if len(s.buf) > 0 {
+			n := copy(p, s.buf)
+			s.buf = s.buf[n:]
+			s.lock.Unlock()
+			return n, nil
+		}
+		if s.closed && len(s.queue) == 0 {
+			s.lock.Unlock()
+			return 0, io.EOF
+		}
+		if !s.deadline.IsZero() && !s.deadline.After(now) {
+			s.lock.Unlock()
+			return 0, os.ErrDeadlineExceeded
+		}
+		// Nothing to deliver yet, sleep until the next event
+		var wait time.Duration = -1
+		if len(s.queue) > 0 {
+			wait = s.queue[0].at.Sub(now)
+		}
+		if !s.deadline.IsZero() {
+			if d := s.deadline.Sub(now); wait < 0 || d < wait {
+				wait = d
+			}
+		}
+		s.lock.Unlock()
+
+		if wait < 0 {
+			<-s.wake
+			continue
+		}
+		if timer == nil {
+			timer = time.NewTimer(wait)
+		} else {
+			timer.Reset(wait)
+		}
+		select {
+		case <-s.wake:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-timer.C:
+		}
+	}
+}
+
+// close marks the stream closed, delivering the data already in flight before
+// signalling EOF.
+func (s *stream) close() {
+	s.lock.Lock()
+	s.closed = true
+	s.lock.Unlock()
+	s.notify()
+}
+
+// abort closes the stream, dropping any data in flight.
+func (s *stream) abort() {
+	s.lock.Lock()
+	s.closed = true
+	s.queue, s.buf = nil, nil
+	s.lock.Unlock()
+	s.notify()
+}
+
+// setDeadline updates the read deadline of the stream.
+func (s *stream) setDeadline(t time.Time) {
+	s.lock.Lock()
+	s.deadline = t
+	s.lock.Unlock()
+	s.notify()
+}
+
+// conn is one endpoint of a virtual connection, implementing net.Conn.
+type conn struct {
+	in, out       *stream
+	local, remote net.Addr
+	closeOnce     sync.Once
+	net           *Network
+}
+
+// Read implements net.Conn.
+func (c *conn) Read(p []byte) (int, error) { return c.in.read(p) }
+
+// Write implements net.Conn. Writes never block, as the virtual link buffers
+// any amount of data.
+func (c *conn) Write(p []byte) (int, error) { return c.out.write(p) }
+
+// Close implements net.Conn, closing both directions of the connection.
+func (c *conn) Close() error {
+	c.closeOnce.Do(func() {
+		c.out.close()
+		c.in.abort()
+		c.net.dropConn(c)
+	})
+	return nil
+}
+
+// abort tears down both directions of the connection, dropping any data in
+// flight, as a connection reset would.
+func (c *conn) abort() {
+	c.closeOnce.Do(func() {
+		c.out.abort()
+		c.in.abort()
+		c.net.dropConn(c)
+	})
+}
+
+// LocalAddr implements net.Conn.
+func (c *conn) LocalAddr() net.Addr { return c.local }
+
+// RemoteAddr implements net.Conn.
+func (c *conn) RemoteAddr() net.Addr { return c.remote }
+
+// SetDeadline implements net.Conn.
+func (c *conn) SetDeadline(t time.Time) error { return c.SetReadDeadline(t) }
+
+// SetReadDeadline implements net.Conn.
+func (c *conn) SetReadDeadline(t time.Time) error {
+	c.in.setDeadline(t)
+	return nil
+}
+
+// SetWriteDeadline implements net.Conn. It is a noop since writes never block.
+func (c *conn) SetWriteDeadline(t time.Time) error { return nil }
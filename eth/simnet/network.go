@@ -0,0 +1,302 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simnet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+var (
+	errUnknownNode  = errors.New("unknown node")
+	errUnreachable  = errors.New("network unreachable")
+	errListening    = errors.New("node already listening")
+	errListenerDown = errors.New("listener closed")
+)
+
+// retransmitTimeout is the delay added to a write for every time it is lost on
+// a lossy link, simulating the retransmission of a reliable transport.
+const retransmitTimeout = 200 * time.Millisecond
+
+// LinkConfig describes the conditions of a virtual link between two nodes.
+type LinkConfig struct {
+	Latency time.Duration // One-way delay of each write
+	Jitter  time.Duration // Maximum random delay added on top of the latency
+	Loss    float64       // Probability of a write getting lost and retransmitted
+}
+
+// linkKey identifies the link between two nodes, independent of direction.
+type linkKey struct {
+	a, b enode.ID
+}
+
+func newLinkKey(a, b enode.ID) linkKey {
+	if bytes.Compare(b[:], a[:]) < 0 {
+		a, b = b, a
+	}
+	return linkKey{a, b}
+}
+
+// Network is a virtual network connecting nodes over in-memory links. Links can
+// be configured to add latency and packet loss, and the network can be split
+// into partitions which cannot reach each other.
+//
+// Since the transport is a reliable stream, packet loss is modelled the way a
+// TCP connection would experience it: lost writes are delivered after an extra
+// retransmission delay. Partitions reset the connections between the separated
+// nodes, as their transport timeouts would eventually do, and fail new dials.
+type Network struct {
+	lock      sync.Mutex
+	defaults  LinkConfig                  // Conditions of links without override
+	links     map[linkKey]LinkConfig      // Link specific conditions
+	groups    map[enode.ID]int            // Partition group of the nodes, nil if not partitioned
+	listeners map[enode.ID]*listener      // Listeners of the nodes accepting connections
+	conns     map[*conn]struct{}          // Live connections, for resets on partitioning
+	rand      *rand.Rand                  // Source of randomness for jitter and loss
+	ports     int                         // Counter for assigning dialer ports
+	hosts     map[enode.ID]netip.AddrPort // Virtual addresses of the nodes
+}
+
+// NewNetwork creates a virtual network with the given default link conditions.
+// The seed initializes the randomness of jitter and packet loss, making the
+// simulation reproducible.
+func NewNetwork(defaults LinkConfig, seed int64) *Network {
+	return &Network{
+		defaults:  defaults,
+		links:     make(map[linkKey]LinkConfig),
+		listeners: make(map[enode.ID]*listener),
+		conns:     make(map[*conn]struct{}),
+		rand:      rand.New(rand.NewSource(seed)),
+		ports:     40000,
+		hosts:     make(map[enode.ID]netip.AddrPort),
+	}
+}
+
+// SetLink overrides the conditions of the link between two nodes.
+func (n *Network) SetLink(a, b enode.ID, config LinkConfig) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.links[newLinkKey(a, b)] = config
+}
+
+// ResetLink reverts the link between two nodes to the default conditions.
+func (n *Network) ResetLink(a, b enode.ID) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	delete(n.links, newLinkKey(a, b))
+}
+
+// Partition splits the network into groups of nodes which can only reach the
+// nodes in the same group. Nodes not contained in any group form a group of
+// their own. Connections crossing the group boundaries are reset.
+func (n *Network) Partition(groups ...[]enode.ID) {
+	n.lock.Lock()
+	n.groups = make(map[enode.ID]int)
+	for i, group := range groups {
+		for _, id := range group {
+			n.groups[id] = i + 1
+		}
+	}
+	var cut []*conn
+	for c := range n.conns {
+		if n.groups[c.out.from] != n.groups[c.out.to] {
+			cut = append(cut, c)
+		}
+	}
+	n.lock.Unlock()
+
+	for _, c := range cut {
+		c.abort()
+	}
+}
+
+// Heal removes all partitions. Connections reset by the partitions are not
+// restored, the nodes need to redial each other.
+func (n *Network) Heal() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.groups = nil
+}
+
+// reachable reports whether data can flow between the two nodes.
+func (n *Network) reachable(a, b enode.ID) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.groups == nil || n.groups[a] == n.groups[b]
+}
+
+// delay returns the time it takes for a write to travel between the nodes,
+// including the jitter and the retransmissions of the lost attempts.
+func (n *Network) delay(from, to enode.ID) time.Duration {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	config, ok := n.links[newLinkKey(from, to)]
+	if !ok {
+		config = n.defaults
+	}
+	delay := config.Latency
+	if config.Jitter > 0 {
+		delay += time.Duration(n.rand.Int63n(int64(config.Jitter)))
+	}
+	for config.Loss > 0 && n.rand.Float64() < config.Loss {
+		delay += retransmitTimeout
+	}
+	return delay
+}
+
+// Listen creates a listener accepting the connections dialed to the given node.
+// The address is only used as the local address of the accepted connections.
+func (n *Network) Listen(id enode.ID, addr string) (net.Listener, error) {
+	ap, err := netip.ParseAddrPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if _, ok := n.listeners[id]; ok {
+		return nil, errListening
+	}
+	l := &listener{
+		net:    n,
+		id:     id,
+		addr:   net.TCPAddrFromAddrPort(ap),
+		accept: make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+	n.listeners[id] = l
+	n.hosts[id] = ap
+	return l, nil
+}
+
+// Dial opens a virtual connection from one node to another.
+func (n *Network) Dial(ctx context.Context, from, to enode.ID) (net.Conn, error) {
+	n.lock.Lock()
+	l := n.listeners[to]
+	if l == nil {
+		n.lock.Unlock()
+		return nil, errUnknownNode
+	}
+	if n.groups != nil && n.groups[from] != n.groups[to] {
+		n.lock.Unlock()
+		return nil, errUnreachable
+	}
+	n.ports++
+	host, ok := n.hosts[from]
+	if !ok {
+		host = netip.AddrPortFrom(netip.AddrFrom4([4]byte{127, 0, 0, 1}), 0)
+	}
+	local := net.TCPAddrFromAddrPort(netip.AddrPortFrom(host.Addr(), uint16(n.ports)))
+
+	var (
+		up   = newStream(n, from, to)
+		down = newStream(n, to, from)
+		out  = &conn{in: down, out: up, local: local, remote: l.addr, net: n}
+		in   = &conn{in: up, out: down, local: l.addr, remote: local, net: n}
+	)
+	n.conns[out] = struct{}{}
+	n.conns[in] = struct{}{}
+	n.lock.Unlock()
+
+	var err error
+	select {
+	case l.accept <- in:
+		return out, nil
+	case <-l.closed:
+		err = errListenerDown
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	out.Close()
+	in.Close()
+	return nil, err
+}
+
+// dropConn removes a closed connection from the set of live ones.
+func (n *Network) dropConn(c *conn) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	delete(n.conns, c)
+}
+
+// Dialer returns a p2p.NodeDialer opening virtual connections from the given
+// node to the dialed ones.
+func (n *Network) Dialer(id enode.ID) *Dialer {
+	return &Dialer{net: n, id: id}
+}
+
+// Dialer implements p2p.NodeDialer over a virtual network.
+type Dialer struct {
+	net *Network
+	id  enode.ID
+}
+
+// Dial implements p2p.NodeDialer.
+func (d *Dialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	return d.net.Dial(ctx, d.id, dest.ID())
+}
+
+// listener implements net.Listener for the connections dialed to a node.
+type listener struct {
+	net       *Network
+	id        enode.ID
+	addr      *net.TCPAddr
+	accept    chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Accept implements net.Listener.
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.accept:
+		return c, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements net.Listener.
+func (l *listener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+
+		l.net.lock.Lock()
+		delete(l.net.listeners, l.id)
+		l.net.lock.Unlock()
+	})
+	return nil
+}
+
+// Addr implements net.Listener.
+func (l *listener) Addr() net.Addr {
+	return l.addr
+}
@@ -0,0 +1,511 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simnet implements an in-process network of full Ethereum nodes for
+// testing chain convergence, transaction propagation and reorg behavior.
+//
+// The nodes are connected over a virtual network with configurable latency,
+// packet loss and partitions. Consensus is driven by the test: blocks are sealed
+// on a chosen node through its simulated beacon and announced to the other nodes
+// in the same way a consensus client would do, after which the nodes sync the
+// chain from each other over the eth protocol.
+package simnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	waitInterval    = 10 * time.Millisecond // Polling interval of the wait methods
+	connectTimeout  = 10 * time.Second      // Maximum time to wait for peers to connect
+	announceTimeout = 10 * time.Second      // Maximum time to wait for a node to sync an announced block
+)
+
+var (
+	errNoBlock  = errors.New("no block sealed")
+	errNoSource = errors.New("no peer to sync from")
+	errTimeout  = errors.New("timed out")
+)
+
+// Topology returns the pairs of node indices to connect in a network of n nodes.
+type Topology func(n int) [][2]int
+
+// FullMesh connects every node with every other node.
+func FullMesh(n int) [][2]int {
+	var pairs [][2]int
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			pairs = append(pairs, [2]int{i, j})
+		}
+	}
+	return pairs
+}
+
+// Chain connects the nodes in a line, each node peering only with its neighbours.
+func Chain(n int) [][2]int {
+	var pairs [][2]int
+	for i := 0; i+1 < n; i++ {
+		pairs = append(pairs, [2]int{i, i + 1})
+	}
+	return pairs
+}
+
+// Config contains the settings of a simulation.
+type Config struct {
+	Nodes    int                // Number of nodes in the network
+	Genesis  *core.Genesis      // Genesis of the chain, defaults to a dev chain with Alloc
+	Alloc    types.GenesisAlloc // Genesis allocation of the default dev chain
+	Topology Topology           // Initial peer connections, defaults to a full mesh
+	Link     LinkConfig         // Default conditions of the links between nodes
+	Seed     int64              // Seed of the link randomness
+
+	// Options are called for each node before it is created, allowing tests to
+	// customize the node and Ethereum service configurations.
+	Options []func(index int, nodeConf *node.Config, ethConf *ethconfig.Config)
+}
+
+// Node is a full Ethereum node participating in the simulation.
+type Node struct {
+	Index  int
+	Stack  *node.Node
+	Eth    *eth.Ethereum
+	Beacon *catalyst.SimulatedBeacon
+}
+
+// ID returns the node ID of the node.
+func (n *Node) ID() enode.ID {
+	return n.Stack.Server().LocalNode().ID()
+}
+
+// Enode returns the node record of the node, used to connect to it.
+func (n *Node) Enode() *enode.Node {
+	return n.Stack.Server().Self()
+}
+
+// Head returns the header of the current head block of the node.
+func (n *Node) Head() *types.Header {
+	return n.Eth.BlockChain().CurrentBlock()
+}
+
+// PeerCount returns the number of peers connected to the node.
+func (n *Node) PeerCount() int {
+	return n.Stack.Server().PeerCount()
+}
+
+// SendTx submits a transaction to the pool of the node, which will propagate it
+// to the network.
+func (n *Node) SendTx(tx *types.Transaction) error {
+	return n.Eth.APIBackend.SendTx(context.Background(), tx)
+}
+
+// HasTx reports whether the node knows about the transaction, either as pending
+// in its pool or as included in its chain.
+func (n *Node) HasTx(hash common.Hash) bool {
+	return n.Eth.TxPool().Has(hash) || rawdb.ReadTxLookupEntry(n.Eth.ChainDb(), hash) != nil
+}
+
+// announce instructs the node to switch its head to the given header, like a
+// consensus client would do with a forkchoice update. Blocks the node does not
+// have yet are synced from its peers.
+func (n *Node) announce(header *types.Header) error {
+	chain := n.Eth.BlockChain()
+	if block := chain.GetBlock(header.Hash(), header.Number.Uint64()); block != nil {
+		if chain.CurrentBlock().Hash() == header.Hash() {
+			return nil
+		}
+		_, err := chain.SetCanonical(block)
+		return err
+	}
+	return n.Eth.Downloader().BeaconSync(ethconfig.FullSync, header, nil)
+}
+
+// Simulation is a network of nodes running in the same process.
+type Simulation struct {
+	net   *Network
+	nodes []*Node
+	ids   map[enode.ID]*Node
+	peers map[[2]int]struct{} // Node pairs instructed to be connected
+}
+
+// New creates and starts the nodes of a simulation, connecting them according
+// to the configured topology.
+func New(config Config) (*Simulation, error) {
+	if config.Nodes <= 0 {
+		return nil, errors.New("no nodes configured")
+	}
+	if config.Genesis == nil {
+		config.Genesis = &core.Genesis{
+			Config:   params.AllDevChainProtocolChanges,
+			GasLimit: ethconfig.Defaults.Miner.GasCeil,
+			Alloc:    config.Alloc,
+		}
+	}
+	if config.Topology == nil {
+		config.Topology = FullMesh
+	}
+	sim := &Simulation{
+		net:   NewNetwork(config.Link, config.Seed),
+		ids:   make(map[enode.ID]*Node),
+		peers: make(map[[2]int]struct{}),
+	}
+	for i := 0; i < config.Nodes; i++ {
+		n, err := sim.newNode(i, &config)
+		if err != nil {
+			sim.Close()
+			return nil, fmt.Errorf("failed to create node %d: %w", i, err)
+		}
+		sim.nodes = append(sim.nodes, n)
+		sim.ids[n.ID()] = n
+	}
+	pairs := config.Topology(config.Nodes)
+	for _, pair := range pairs {
+		sim.Connect(pair[0], pair[1])
+	}
+	for _, pair := range pairs {
+		if err := sim.WaitConnected(pair[0], pair[1], connectTimeout); err != nil {
+			sim.Close()
+			return nil, err
+		}
+	}
+	return sim, nil
+}
+
+// newNode creates and starts a single node of the simulation.
+func (s *Simulation) newNode(index int, config *Config) (*Node, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	id := enode.PubkeyToIDV4(&key.PublicKey)
+
+	nodeConf := node.DefaultConfig
+	nodeConf.DataDir = ""
+	nodeConf.P2P = p2p.Config{
+		PrivateKey:  key,
+		MaxPeers:    max(25, 2*config.Nodes),
+		NoDiscovery: true,
+		ListenAddr:  fmt.Sprintf("127.0.0.%d:30303", index+1),
+		ListenFunc: func(network, addr string) (net.Listener, error) {
+			return s.net.Listen(id, addr)
+		},
+		Dialer: s.net.Dialer(id),
+	}
+	ethConf := ethconfig.Defaults
+	ethConf.Genesis = config.Genesis
+	ethConf.SyncMode = ethconfig.FullSync
+	ethConf.TxPool.NoLocals = true
+
+	for _, option := range config.Options {
+		option(index, &nodeConf, &ethConf)
+	}
+	stack, err := node.New(&nodeConf)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := eth.New(stack, &ethConf)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	if err := stack.Start(); err != nil {
+		stack.Close()
+		return nil, err
+	}
+	beacon, err := catalyst.NewSimulatedBeacon(0, common.Address{}, backend)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	// The simulation drives consensus, so there is no initial sync to wait for
+	// before accepting and relaying transactions.
+	backend.SetSynced()
+
+	return &Node{Index: index, Stack: stack, Eth: backend, Beacon: beacon}, nil
+}
+
+// Network returns the virtual network connecting the nodes.
+func (s *Simulation) Network() *Network {
+	return s.net
+}
+
+// Nodes returns all nodes of the simulation.
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+// Node returns the node with the given index.
+func (s *Simulation) Node(index int) *Node {
+	return s.nodes[index]
+}
+
+// Close shuts down all nodes of the simulation.
+func (s *Simulation) Close() error {
+	var errs []error
+	for _, n := range s.nodes {
+		errs = append(errs, n.Beacon.Stop(), n.Stack.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Connect instructs node a to connect to node b.
+func (s *Simulation) Connect(a, b int) {
+	s.peers[[2]int{a, b}] = struct{}{}
+	s.nodes[a].Stack.Server().AddPeer(s.nodes[b].Enode())
+}
+
+// Disconnect instructs node a to drop its connection to node b.
+func (s *Simulation) Disconnect(a, b int) {
+	delete(s.peers, [2]int{a, b})
+	s.nodes[a].Stack.Server().RemovePeer(s.nodes[b].Enode())
+}
+
+// WaitConnected waits until the two nodes are peered with each other.
+func (s *Simulation) WaitConnected(a, b int, timeout time.Duration) error {
+	return wait(timeout, fmt.Sprintf("connection between nodes %d and %d", a, b), func() bool {
+		return s.connected(a, b) && s.connected(b, a)
+	})
+}
+
+// connected reports whether node a completed the eth handshake with node b.
+func (s *Simulation) connected(a, b int) bool {
+	id := s.nodes[b].ID().String()
+	for _, info := range s.nodes[a].Stack.Server().PeersInfo() {
+		if info.ID == id {
+			proto, ok := info.Protocols["eth"]
+			return ok && proto != "handshake"
+		}
+	}
+	return false
+}
+
+// Partition splits the network into groups of nodes identified by their indices,
+// which can only reach nodes in the same group. It returns once the nodes have
+// dropped their peers on the other side of the partition.
+func (s *Simulation) Partition(groups ...[]int) error {
+	ids := make([][]enode.ID, len(groups))
+	for i, group := range groups {
+		for _, index := range group {
+			ids[i] = append(ids[i], s.nodes[index].ID())
+		}
+	}
+	s.net.Partition(ids...)
+
+	for pair := range s.peers {
+		a, b := pair[0], pair[1]
+		if s.net.reachable(s.nodes[a].ID(), s.nodes[b].ID()) {
+			continue
+		}
+		err := wait(connectTimeout, fmt.Sprintf("disconnect of nodes %d and %d", a, b), func() bool {
+			return !s.connected(a, b) && !s.connected(b, a)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Heal removes all network partitions and reconnects the nodes separated by
+// them. It returns once the connections are established again.
+func (s *Simulation) Heal() error {
+	s.net.Heal()
+
+	for pair := range s.peers {
+		a, b := pair[0], pair[1]
+		if s.connected(a, b) {
+			continue
+		}
+		if err := s.redial(a, b); err != nil {
+			return fmt.Errorf("failed to reconnect nodes %d and %d: %w", a, b, err)
+		}
+		if err := s.WaitConnected(a, b, connectTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redial connects node a to node b directly, bypassing the dial scheduler of
+// node a which refuses to redial recently dialed nodes for a while.
+func (s *Simulation) redial(a, b int) error {
+	fd, err := s.net.Dial(context.Background(), s.nodes[a].ID(), s.nodes[b].ID())
+	if err != nil {
+		return err
+	}
+	return s.nodes[a].Stack.Server().SetupConn(fd, 0, s.nodes[b].Enode())
+}
+
+// SetLink overrides the conditions of the link between two nodes.
+func (s *Simulation) SetLink(a, b int, config LinkConfig) {
+	s.net.SetLink(s.nodes[a].ID(), s.nodes[b].ID(), config)
+}
+
+// Commit seals a block with the pending transactions of the given node and
+// announces it as the new head to all nodes reachable from the producer.
+func (s *Simulation) Commit(index int) (*types.Header, error) {
+	producer := s.nodes[index]
+
+	parent := producer.Head().Hash()
+	if producer.Beacon.Commit() == parent {
+		return nil, errNoBlock
+	}
+	head := producer.Head()
+
+	var targets []int
+	for _, n := range s.nodes {
+		if n != producer && s.net.reachable(producer.ID(), n.ID()) {
+			targets = append(targets, n.Index)
+		}
+	}
+	return head, s.announce(head, targets)
+}
+
+// Announce instructs the given nodes to switch their head to the header, syncing
+// the chain from their peers if needed. If no nodes are given, the header is
+// announced to all nodes.
+//
+// The downloader does not retry fetching a block from peers which did not have
+// it when asked, so the announcement spreads in waves: only nodes with a peer
+// already having the block are instructed, and the next wave starts once they
+// have synced it.
+func (s *Simulation) Announce(header *types.Header, nodes ...int) error {
+	if len(nodes) == 0 {
+		nodes = s.all()
+	}
+	return s.announce(header, nodes)
+}
+
+// announce spreads the header to the given nodes in waves.
+func (s *Simulation) announce(header *types.Header, nodes []int) error {
+	pending := nodes
+	for len(pending) > 0 {
+		var wave, rest []int
+		for _, index := range pending {
+			if s.canSync(index, header) {
+				wave = append(wave, index)
+			} else {
+				rest = append(rest, index)
+			}
+		}
+		if len(wave) == 0 {
+			return fmt.Errorf("%w: nodes %v, block %x", errNoSource, rest, header.Hash())
+		}
+		for _, index := range wave {
+			if err := s.nodes[index].announce(header); err != nil {
+				return fmt.Errorf("node %d: %w", index, err)
+			}
+		}
+		err := wait(announceTimeout, fmt.Sprintf("block %x", header.Hash()), func() bool {
+			for _, index := range wave {
+				if !s.nodes[index].Eth.BlockChain().HasBlock(header.Hash(), header.Number.Uint64()) {
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		pending = rest
+	}
+	return nil
+}
+
+// canSync reports whether the node has the block or can fetch it from a peer.
+func (s *Simulation) canSync(index int, header *types.Header) bool {
+	has := func(n *Node) bool {
+		return n.Eth.BlockChain().HasBlock(header.Hash(), header.Number.Uint64())
+	}
+	if has(s.nodes[index]) {
+		return true
+	}
+	for _, peer := range s.nodes[index].Stack.Server().Peers() {
+		if n := s.ids[peer.ID()]; n != nil && has(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitHead waits until the given nodes all have the block with the given hash
+// as their head. If no nodes are given, it waits for all nodes.
+func (s *Simulation) WaitHead(hash common.Hash, timeout time.Duration, nodes ...int) error {
+	if len(nodes) == 0 {
+		nodes = s.all()
+	}
+	return wait(timeout, fmt.Sprintf("head %x", hash), func() bool {
+		for _, index := range nodes {
+			if s.nodes[index].Head().Hash() != hash {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// WaitTx waits until the given nodes all know about the transaction with the
+// given hash. If no nodes are given, it waits for all nodes.
+func (s *Simulation) WaitTx(hash common.Hash, timeout time.Duration, nodes ...int) error {
+	if len(nodes) == 0 {
+		nodes = s.all()
+	}
+	return wait(timeout, fmt.Sprintf("transaction %x", hash), func() bool {
+		for _, index := range nodes {
+			if !s.nodes[index].HasTx(hash) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// all returns the indices of all nodes.
+func (s *Simulation) all() []int {
+	indices := make([]int, len(s.nodes))
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+// wait polls the condition until it holds or the timeout expires.
+func wait(timeout time.Duration, what string, cond func() bool) error {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w waiting for %s", errTimeout, what)
+		}
+		time.Sleep(waitInterval)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simnet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testTimeout = 20 * time.Second
+)
+
+func newTestSimulation(t *testing.T, nodes int, topology Topology) *Simulation {
+	t.Helper()
+
+	sim, err := New(Config{
+		Nodes:    nodes,
+		Alloc:    types.GenesisAlloc{testAddr: {Balance: big.NewInt(params.Ether)}},
+		Topology: topology,
+		Link:     LinkConfig{Latency: 5 * time.Millisecond, Jitter: 5 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("failed to create simulation: %v", err)
+	}
+	t.Cleanup(func() { sim.Close() })
+	return sim
+}
+
+func makeTx(t *testing.T, nonce uint64) *types.Transaction {
+	t.Helper()
+
+	tx, err := types.SignNewTx(testKey, types.LatestSigner(params.AllDevChainProtocolChanges), &types.DynamicFeeTx{
+		ChainID:   params.AllDevChainProtocolChanges.ChainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(10 * params.GWei),
+		Gas:       params.TxGas,
+		To:        &common.Address{0xaa},
+		Value:     big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	return tx
+}
+
+// Tests that the virtual links delay the data by the configured latency and get
+// cut when the endpoints are partitioned.
+func TestNetworkLink(t *testing.T) {
+	var (
+		net     = NewNetwork(LinkConfig{Latency: 50 * time.Millisecond}, 1)
+		a, b    = enode.ID{1}, enode.ID{2}
+		l, err  = net.Listen(b, "127.0.0.1:30303")
+		payload = []byte("hello")
+	)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer l.Close()
+
+	accepted := make(chan io.ReadWriteCloser, 1)
+	accept := func() {
+		c, err := l.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}
+	go accept()
+	out, err := net.Dial(context.Background(), a, b)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer out.Close()
+	in := <-accepted
+	defer in.Close()
+
+	// Check that writes are delayed by the link latency.
+	start := time.Now()
+	out.Write(payload)
+	buf := make([]byte, len(payload))
+	if _, err := io.ReadFull(in, buf); err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("data delivered too early: %v", elapsed)
+	}
+	// Check that closing the connection delivers the data in flight before
+	// signalling EOF to the other side.
+	out.Write(payload)
+	out.Close()
+	if _, err := io.ReadFull(in, buf); err != nil {
+		t.Fatalf("failed to read before close: %v", err)
+	}
+	if _, err := in.Read(buf); err != io.EOF {
+		t.Fatalf("read after close: have %v, want %v", err, io.EOF)
+	}
+	// Check that partitions reset the connections and reject dials.
+	go accept()
+	if out, err = net.Dial(context.Background(), a, b); err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	in = <-accepted
+	defer in.Close()
+
+	out.Write(payload)
+	net.Partition([]enode.ID{a}, []enode.ID{b})
+	if _, err := in.Read(buf); err != io.EOF {
+		t.Fatalf("read across partition: have %v, want %v", err, io.EOF)
+	}
+	if _, err := net.Dial(context.Background(), a, b); !errors.Is(err, errUnreachable) {
+		t.Fatalf("dial across partition: have %v, want %v", err, errUnreachable)
+	}
+	net.Heal()
+	go accept()
+	if _, err := net.Dial(context.Background(), a, b); err != nil {
+		t.Fatalf("failed to dial after healing: %v", err)
+	}
+}
+
+// Tests that blocks sealed on one node are synced by all the others.
+func TestChainConvergence(t *testing.T) {
+	sim := newTestSimulation(t, 3, FullMesh)
+
+	for i := 0; i < 3; i++ {
+		head, err := sim.Commit(i)
+		if err != nil {
+			t.Fatalf("failed to commit block on node %d: %v", i, err)
+		}
+		if err := sim.WaitHead(head.Hash(), testTimeout); err != nil {
+			t.Fatalf("block %d not synced: %v", head.Number, err)
+		}
+	}
+}
+
+// Tests that transactions are relayed through the network to nodes which are
+// not directly connected to the originator, and included by any producer.
+func TestTxPropagation(t *testing.T) {
+	sim := newTestSimulation(t, 3, Chain)
+
+	tx := makeTx(t, 0)
+	if err := sim.Node(0).SendTx(tx); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if err := sim.WaitTx(tx.Hash(), testTimeout); err != nil {
+		t.Fatalf("transaction not propagated: %v", err)
+	}
+	head, err := sim.Commit(2)
+	if err != nil {
+		t.Fatalf("failed to commit block: %v", err)
+	}
+	if err := sim.WaitHead(head.Hash(), testTimeout); err != nil {
+		t.Fatalf("block not synced: %v", err)
+	}
+	for _, n := range sim.Nodes() {
+		state, err := n.Eth.BlockChain().State()
+		if err != nil {
+			t.Fatalf("node %d: failed to open state: %v", n.Index, err)
+		}
+		if nonce := state.GetNonce(testAddr); nonce != 1 {
+			t.Errorf("node %d: nonce mismatch: have %d, want 1", n.Index, nonce)
+		}
+	}
+}
+
+// Tests that nodes on the losing side of a network partition reorg to the
+// longer chain built by the other side after healing.
+func TestPartitionReorg(t *testing.T) {
+	sim := newTestSimulation(t, 3, FullMesh)
+
+	base, err := sim.Commit(0)
+	if err != nil {
+		t.Fatalf("failed to commit block: %v", err)
+	}
+	if err := sim.WaitHead(base.Hash(), testTimeout); err != nil {
+		t.Fatalf("block not synced: %v", err)
+	}
+	// Build competing chains on the two sides of the partition, the majority
+	// side producing the longer one.
+	if err := sim.Partition([]int{0, 1}, []int{2}); err != nil {
+		t.Fatalf("failed to partition network: %v", err)
+	}
+
+	minority, err := sim.Commit(2)
+	if err != nil {
+		t.Fatalf("failed to commit minority block: %v", err)
+	}
+	var majority *types.Header
+	for i := 0; i < 3; i++ {
+		if majority, err = sim.Commit(i % 2); err != nil {
+			t.Fatalf("failed to commit majority block: %v", err)
+		}
+		if err := sim.WaitHead(majority.Hash(), testTimeout, 0, 1); err != nil {
+			t.Fatalf("majority block not synced: %v", err)
+		}
+	}
+	if head := sim.Node(2).Head(); head.Hash() != minority.Hash() {
+		t.Fatalf("minority node synced across partition: head %d", head.Number)
+	}
+	// Heal the network and check that the minority node reorgs.
+	if err := sim.Heal(); err != nil {
+		t.Fatalf("failed to heal network: %v", err)
+	}
+	if err := sim.Announce(majority, 2); err != nil {
+		t.Fatalf("failed to announce head: %v", err)
+	}
+	if err := sim.WaitHead(majority.Hash(), testTimeout); err != nil {
+		t.Fatalf("network did not converge: %v", err)
+	}
+}
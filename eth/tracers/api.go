@@ -238,6 +238,58 @@ func (api *API) TraceChain(ctx context.Context, start, end rpc.BlockNumber, conf
 	return sub, nil
 }
 
+// TraceChainJob traces the blocks between two blocks (excluding start) like
+// TraceChain, for clients without subscription support. It only runs as an
+// asynchronous job started through rpc_startJob: the traces of each block are
+// reported as a partial result in block order, and the progress counts the
+// traced blocks. The job can be cancelled through rpc_cancelJob.
+func (api *API) TraceChainJob(ctx context.Context, start, end rpc.BlockNumber, config *TraceConfig) (bool, error) {
+	// Tracing a chain is a **long** operation, only do as a job
+	job := rpc.JobFromContext(ctx)
+	if job == nil {
+		return false, errors.New("chain tracing must run as a job")
+	}
+	from, err := api.blockByNumber(ctx, start)
+	if err != nil {
+		return false, err
+	}
+	to, err := api.blockByNumber(ctx, end)
+	if err != nil {
+		return false, err
+	}
+	if from.Number().Cmp(to.Number()) >= 0 {
+		return false, fmt.Errorf("end block (#%d) needs to come after start block (#%d)", end, start)
+	}
+	// Abort the tracing once the job is cancelled or stops consuming the
+	// results. The remaining results must be drained for the tracer to exit.
+	ctx, cancel := context.WithCancel(ctx)
+	closed := make(chan error)
+	go func() {
+		<-ctx.Done()
+		close(closed)
+	}()
+	resCh := api.traceChain(from, to, config, closed)
+	defer func() {
+		cancel()
+		for range resCh {
+		}
+	}()
+	var (
+		first = from.NumberU64()
+		total = to.NumberU64() - first
+	)
+	for result := range resCh {
+		if err := job.AddPartial(result); err != nil {
+			return false, err
+		}
+		job.SetProgress(uint64(result.Block)-first, total)
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // traceChain configures a new tracer according to the provided configuration, and
 // executes all the transactions contained within. The tracing chain range includes
 // the end block but excludes the start one. The return value will be one item per
@@ -900,6 +952,12 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 		TxIndex:     int(index),
 		TxHash:      hash,
 	}
+	// Traces of finalized transactions never change, allow caching them.
+	if rpc.CanMarkImmutable(ctx) {
+		if final, err := api.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber); err == nil && final != nil && blockNumber <= final.Number.Uint64() {
+			rpc.MarkImmutable(ctx)
+		}
+	}
 	return api.traceTx(ctx, tx, msg, txctx, vmctx, statedb, config, nil)
 }
 
@@ -1068,6 +1126,7 @@ func APIs(backend Backend) []rpc.API {
 		{
 			Namespace: "debug",
 			Service:   NewAPI(backend),
+			Cacheable: []string{"traceTransaction"},
 		},
 	}
 }
@@ -1153,6 +1153,105 @@ func TestTraceChain(t *testing.T) {
 	}
 }
 
+func TestTraceChainJob(t *testing.T) {
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	var (
+		ref    atomic.Uint32 // total refs has made
+		rel    atomic.Uint32 // total rels has made
+		signer = types.HomesteadSigner{}
+	)
+	backend := newTestBackend(t, 50, genesis, func(i int, b *core.BlockGen) {
+		// Transfer from account[0] to account[1] in every even block
+		if i%2 == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	backend.refHook = func() { ref.Add(1) }
+	backend.relHook = func() { rel.Add(1) }
+	api := NewAPI(backend)
+
+	// Chain tracing is only available as a job
+	if _, err := api.TraceChainJob(context.Background(), 10, 20, nil); err == nil {
+		t.Fatal("chain tracing accepted outside of a job")
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	server.SetJobLimits(1, 0, 0)
+	if err := server.RegisterName("debug", api); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	wait := func(id rpc.ID) rpc.JobStatus {
+		var status rpc.JobStatus
+		for {
+			if err := client.Call(&status, "rpc_jobStatus", id); err != nil {
+				t.Fatal(err)
+			}
+			if status.State != rpc.JobRunning {
+				return status
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	// Trace the blocks [11, 20], reported in order as partial results
+	var id rpc.ID
+	if err := client.Call(&id, "rpc_startJob", "debug_traceChainJob", []any{"0xa", "0x14"}); err != nil {
+		t.Fatal(err)
+	}
+	status := wait(id)
+	if status.State != rpc.JobDone {
+		t.Fatalf("job failed: %s %v", status.State, status.Error)
+	}
+	var blocks []uint64
+	for _, enc := range status.Partial {
+		var result blockTraceResult
+		if err := json.Unmarshal(enc, &result); err != nil {
+			t.Fatal(err)
+		}
+		if want := int(result.Block) % 2; len(result.Traces) != want {
+			t.Fatalf("block %d: have %d traces, want %d", result.Block, len(result.Traces), want)
+		}
+		blocks = append(blocks, uint64(result.Block))
+	}
+	// The blocks without transactions are skipped, except for the last one
+	if want := []uint64{11, 13, 15, 17, 19, 20}; !slices.Equal(blocks, want) {
+		t.Fatalf("wrong traced blocks: have %v, want %v", blocks, want)
+	}
+	if status.Progress.Done != 10 || status.Progress.Total != 10 {
+		t.Fatalf("wrong job progress: %+v", status.Progress)
+	}
+	if nref, nrel := ref.Load(), rel.Load(); nref != nrel {
+		t.Errorf("Ref and deref actions are not equal, ref %d rel %d", nref, nrel)
+	}
+
+	// Cancel a job tracing the entire chain, the tracer must clean up
+	ref.Store(0)
+	rel.Store(0)
+	if err := client.Call(&id, "rpc_startJob", "debug_traceChainJob", []any{"0x0", "0x32"}); err != nil {
+		t.Fatal(err)
+	}
+	var cancelled bool
+	if err := client.Call(&cancelled, "rpc_cancelJob", id); err != nil {
+		t.Fatal(err)
+	}
+	if status := wait(id); status.State != rpc.JobCancelled && status.State != rpc.JobDone {
+		t.Fatalf("wrong state of cancelled job: %s", status.State)
+	}
+	if nref, nrel := ref.Load(), rel.Load(); nref != nrel {
+		t.Errorf("Ref and deref actions are not equal after cancel, ref %d rel %d", nref, nrel)
+	}
+}
+
 // newTestMergedBackend creates a post-merge chain
 func newTestMergedBackend(t *testing.T, n int, gspec *core.Genesis, generator func(i int, b *core.BlockGen)) *testBackend {
 	backend := &testBackend{
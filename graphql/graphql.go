@@ -19,6 +19,7 @@ package graphql
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -31,17 +32,20 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
 var (
-	errBlockInvariant    = errors.New("block objects must be instantiated with at least one of num or hash")
-	errInvalidBlockRange = errors.New("invalid from and to block combination: from > to")
+	errBlockInvariant     = errors.New("block objects must be instantiated with at least one of num or hash")
+	errInvalidBlockRange  = errors.New("invalid from and to block combination: from > to")
+	errTracingUnsupported = errors.New("tracing is not supported by the backend")
 )
 
 type Long int64
@@ -77,6 +81,30 @@ func (b *Long) UnmarshalGraphQL(input interface{}) error {
 	return err
 }
 
+// JSON is an arbitrary JSON value.
+type JSON json.RawMessage
+
+// ImplementsGraphQLType returns true if JSON implements the provided GraphQL type.
+func (j JSON) ImplementsGraphQLType(name string) bool { return name == "JSON" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (j *JSON) UnmarshalGraphQL(input interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	*j = data
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
 // Account represents an Ethereum account at a particular block.
 type Account struct {
 	r             *Resolver
@@ -169,6 +197,10 @@ func (l *Log) Data(ctx context.Context) hexutil.Bytes {
 	return l.log.Data
 }
 
+func (l *Log) Removed(ctx context.Context) bool {
+	return l.log.Removed
+}
+
 // AccessTuple represents EIP-2930
 type AccessTuple struct {
 	address     common.Address
@@ -208,6 +240,123 @@ func (w *Withdrawal) Amount(ctx context.Context) hexutil.Uint64 {
 	return hexutil.Uint64(w.amount)
 }
 
+// Authorization represents an EIP-7702 code delegation.
+type Authorization struct {
+	auth types.SetCodeAuthorization
+}
+
+func (a *Authorization) ChainID(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*a.auth.ChainID.ToBig())
+}
+
+func (a *Authorization) Address(ctx context.Context) common.Address {
+	return a.auth.Address
+}
+
+func (a *Authorization) Nonce(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(a.auth.Nonce)
+}
+
+func (a *Authorization) YParity(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(a.auth.V)
+}
+
+func (a *Authorization) R(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*a.auth.R.ToBig())
+}
+
+func (a *Authorization) S(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*a.auth.S.ToBig())
+}
+
+func (a *Authorization) Authority(ctx context.Context) *common.Address {
+	authority, err := a.auth.Authority()
+	if err != nil {
+		return nil
+	}
+	return &authority
+}
+
+// BlobSidecar represents the blobs of an EIP-4844 transaction along with
+// their commitments and proofs.
+type BlobSidecar struct {
+	sidecar *types.BlobTxSidecar
+}
+
+func (s *BlobSidecar) Version(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(s.sidecar.Version)
+}
+
+func (s *BlobSidecar) Blobs(ctx context.Context) []hexutil.Bytes {
+	ret := make([]hexutil.Bytes, 0, len(s.sidecar.Blobs))
+	for i := range s.sidecar.Blobs {
+		ret = append(ret, s.sidecar.Blobs[i][:])
+	}
+	return ret
+}
+
+func (s *BlobSidecar) Commitments(ctx context.Context) []hexutil.Bytes {
+	ret := make([]hexutil.Bytes, 0, len(s.sidecar.Commitments))
+	for i := range s.sidecar.Commitments {
+		ret = append(ret, s.sidecar.Commitments[i][:])
+	}
+	return ret
+}
+
+func (s *BlobSidecar) Proofs(ctx context.Context) []hexutil.Bytes {
+	ret := make([]hexutil.Bytes, 0, len(s.sidecar.Proofs))
+	for i := range s.sidecar.Proofs {
+		ret = append(ret, s.sidecar.Proofs[i][:])
+	}
+	return ret
+}
+
+// TransactionTrace is the output of a tracer for a single transaction.
+type TransactionTrace struct {
+	transaction *Transaction
+	result      JSON
+	err         *string
+}
+
+func (t *TransactionTrace) Transaction(ctx context.Context) *Transaction {
+	return t.transaction
+}
+
+func (t *TransactionTrace) Result(ctx context.Context) *JSON {
+	if t.result == nil {
+		return nil
+	}
+	return &t.result
+}
+
+func (t *TransactionTrace) Error(ctx context.Context) *string {
+	return t.err
+}
+
+// TraceArgs encapsulates the arguments to the trace accessors.
+type TraceArgs struct {
+	Tracer       *string
+	TracerConfig *JSON
+}
+
+// config converts the arguments into a tracer configuration.
+func (args TraceArgs) config() *tracers.TraceConfig {
+	config := &tracers.TraceConfig{Tracer: args.Tracer}
+	if args.TracerConfig != nil {
+		config.TracerConfig = json.RawMessage(*args.TracerConfig)
+	}
+	return config
+}
+
+// tracerAPI returns the tracing API if the backend supports tracing.
+func (r *Resolver) tracerAPI() (*tracers.API, error) {
+	backend, ok := r.backend.(tracers.Backend)
+	if !ok {
+		return nil, errTracingUnsupported
+	}
+	return tracers.NewAPI(backend), nil
+}
+
 // Transaction represents an Ethereum transaction.
 // backend and hash are mandatory; all others will be fetched when required.
 type Transaction struct {
@@ -358,6 +507,54 @@ func (t *Transaction) BlobVersionedHashes(ctx context.Context) *[]common.Hash {
 	return &blobHashes
 }
 
+func (t *Transaction) BlobSidecar(ctx context.Context) *BlobSidecar {
+	tx, _ := t.resolve(ctx)
+	if tx == nil {
+		return nil
+	}
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil {
+		return nil
+	}
+	return &BlobSidecar{sidecar: sidecar}
+}
+
+func (t *Transaction) AuthorizationList(ctx context.Context) *[]*Authorization {
+	tx, _ := t.resolve(ctx)
+	if tx == nil || tx.Type() != types.SetCodeTxType {
+		return nil
+	}
+	auths := tx.SetCodeAuthorizations()
+	ret := make([]*Authorization, 0, len(auths))
+	for _, auth := range auths {
+		ret = append(ret, &Authorization{auth: auth})
+	}
+	return &ret
+}
+
+func (t *Transaction) Trace(ctx context.Context, args TraceArgs) (*JSON, error) {
+	tx, block := t.resolve(ctx)
+	if tx == nil {
+		return nil, nil
+	}
+	if block == nil {
+		return nil, errors.New("pending transactions can not be traced")
+	}
+	api, err := t.r.tracerAPI()
+	if err != nil {
+		return nil, err
+	}
+	result, err := api.TraceTransaction(ctx, t.hash, args.config())
+	if err != nil {
+		return nil, err
+	}
+	ret, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return (*JSON)(&ret), nil
+}
+
 func (t *Transaction) EffectiveTip(ctx context.Context) (*hexutil.Big, error) {
 	tx, block := t.resolve(ctx)
 	if tx == nil {
@@ -1087,6 +1284,59 @@ func (b *Block) ExcessBlobGas(ctx context.Context) (*hexutil.Uint64, error) {
 	return &ret, nil
 }
 
+func (b *Block) BlobBaseFee(ctx context.Context) (*hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.ExcessBlobGas == nil {
+		return nil, nil
+	}
+	return (*hexutil.Big)(eip4844.CalcBlobFee(b.r.backend.ChainConfig(), header)), nil
+}
+
+func (b *Block) ParentBeaconBlockRoot(ctx context.Context) (*common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return header.ParentBeaconRoot, nil
+}
+
+func (b *Block) RequestsHash(ctx context.Context) (*common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return header.RequestsHash, nil
+}
+
+func (b *Block) Traces(ctx context.Context, args TraceArgs) ([]*TransactionTrace, error) {
+	api, err := b.r.tracerAPI()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results, err := api.TraceBlockByHash(ctx, hash, args.config())
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*TransactionTrace, 0, len(results))
+	for _, res := range results {
+		trace := &TransactionTrace{transaction: &Transaction{r: b.r, hash: res.TxHash}}
+		if res.Error != "" {
+			trace.err = &res.Error
+		} else if trace.result, err = json.Marshal(res.Result); err != nil {
+			return nil, err
+		}
+		ret = append(ret, trace)
+	}
+	return ret, nil
+}
+
 // BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
 // a block.
 type BlockFilterCriteria struct {
@@ -1279,6 +1529,9 @@ func (p *Pending) EstimateGas(ctx context.Context, args struct {
 type Resolver struct {
 	backend      ethapi.Backend
 	filterSystem *filters.FilterSystem
+
+	events     *filters.EventSystem // Event source of subscriptions, created on first use
+	eventsOnce sync.Once
 }
 
 func (r *Resolver) Block(ctx context.Context, args struct {
@@ -1454,6 +1707,22 @@ func (r *Resolver) ChainID(ctx context.Context) (hexutil.Big, error) {
 	return hexutil.Big(*r.backend.ChainConfig().ChainID), nil
 }
 
+func (r *Resolver) BlobBaseFee(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*r.backend.BlobBaseFee(ctx))
+}
+
+func (r *Resolver) Simulate(ctx context.Context, args struct {
+	Options JSON
+	Block   *Long
+}) (JSON, error) {
+	blockNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if args.Block != nil {
+		blockNrOrHash = rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(*args.Block))
+	}
+	result, err := ethapi.Simulate(ctx, r.backend, json.RawMessage(args.Options), blockNrOrHash)
+	return JSON(result), err
+}
+
 // SyncState represents the synchronisation status returned from the `syncing` accessor.
 type SyncState struct {
 	progress ethereum.SyncProgress
@@ -38,9 +38,11 @@ import (
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/filters"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
 
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -444,7 +446,36 @@ func createNode(t *testing.T) *node.Node {
 	return stack
 }
 
+// createDebugNode creates a node with the debug namespace enabled on the HTTP
+// server, which exposes tracing and simulation on GraphQL.
+func createDebugNode(t *testing.T) *node.Node {
+	stack, err := node.New(&node.Config{
+		HTTPHost:     "127.0.0.1",
+		HTTPPort:     0,
+		HTTPModules:  []string{"eth", "debug"},
+		WSHost:       "127.0.0.1",
+		WSPort:       0,
+		HTTPTimeouts: node.DefaultConfig.HTTPTimeouts,
+	})
+	if err != nil {
+		t.Fatalf("could not create node: %v", err)
+	}
+	return stack
+}
+
 func newGQLService(t *testing.T, stack *node.Node, shanghai bool, gspec *core.Genesis, genBlocks int, genfunc func(i int, gen *core.BlockGen)) (*handler, []*types.Block) {
+	ethBackend, chain := newGQLBackend(t, stack, shanghai, gspec, genBlocks, genfunc)
+
+	// Set up handler
+	filterSystem := filters.NewFilterSystem(ethBackend.APIBackend, filters.Config{})
+	handler, err := newHandler(stack, ethBackend.APIBackend, filterSystem, []string{}, []string{})
+	if err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	return handler, chain
+}
+
+func newGQLBackend(t *testing.T, stack *node.Node, shanghai bool, gspec *core.Genesis, genBlocks int, genfunc func(i int, gen *core.BlockGen)) (*eth.Ethereum, []*types.Block) {
 	ethConf := &ethconfig.Config{
 		Genesis:        gspec,
 		NetworkId:      1337,
@@ -476,11 +507,139 @@ func newGQLService(t *testing.T, stack *node.Node, shanghai bool, gspec *core.Ge
 	if err != nil {
 		t.Fatalf("could not create import blocks: %v", err)
 	}
-	// Set up handler
-	filterSystem := filters.NewFilterSystem(ethBackend.APIBackend, filters.Config{})
-	handler, err := newHandler(stack, ethBackend.APIBackend, filterSystem, []string{}, []string{})
+	return ethBackend, chain
+}
+
+func TestGraphQLTracingAndSimulation(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		dad     = common.HexToAddress("0x0000000000000000000000000000000000000dad")
+		genesis = &core.Genesis{
+			Config:     params.AllEthashProtocolChanges,
+			GasLimit:   11500000,
+			Difficulty: big.NewInt(1048576),
+			Alloc: types.GenesisAlloc{
+				address: {Balance: big.NewInt(params.Ether)},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(genesis.Config)
+		stack  = createDebugNode(t)
+	)
+	defer stack.Close()
+
+	var txHash common.Hash
+	handler, _ := newGQLService(t, stack, false, genesis, 1, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignNewTx(key, signer, &types.LegacyTx{To: &dad, Value: big.NewInt(100), Gas: 50000, GasPrice: big.NewInt(params.InitialBaseFee)})
+		gen.AddTx(tx)
+		txHash = tx.Hash()
+	})
+	for i, tt := range []struct {
+		body string
+		want []string
+	}{
+		{
+			body: `{block(number: 1) { traces(tracer: "callTracer") { transaction { hash } result error } } }`,
+			want: []string{txHash.Hex(), `"type":"CALL"`, `"to":"0x0000000000000000000000000000000000000dad"`, `"error":null`},
+		},
+		{
+			body: fmt.Sprintf(`{transaction(hash: "%s") { trace(tracer: "callTracer", tracerConfig: {onlyTopCall: true}) } }`, txHash.Hex()),
+			want: []string{`"value":"0x64"`},
+		},
+		{
+			body: `{block(number: 1) { blobBaseFee parentBeaconBlockRoot requestsHash transactions { authorizationList { address } blobSidecar { version } } } }`,
+			want: []string{`"blobBaseFee":null,"parentBeaconBlockRoot":null,"requestsHash":null`, `"authorizationList":null,"blobSidecar":null`},
+		},
+		{
+			body: fmt.Sprintf(`{simulate(options: {blockStateCalls: [{calls: [{from: "%s", to: "%s", value: "0x1"}]}]}) }`, address.Hex(), dad.Hex()),
+			want: []string{`"number":"0x2"`, `"status":"0x1"`},
+		},
+	} {
+		res := handler.Schema.Exec(context.Background(), tt.body, "", map[string]interface{}{})
+		if res.Errors != nil {
+			t.Fatalf("failed to execute query for testcase #%d: %v", i, res.Errors)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(res.Data), want) {
+				t.Errorf("testcase #%d: response %s does not contain %s", i, res.Data, want)
+			}
+		}
+	}
+}
+
+// Tests that tracing and simulation are not exposed unless the debug namespace
+// is enabled.
+func TestGraphQLTracingDisabled(t *testing.T) {
+	stack := createNode(t)
+	defer stack.Close()
+
+	handler, err := newHandler(stack, nil, nil, []string{}, []string{})
 	if err != nil {
 		t.Fatalf("could not create graphql service: %v", err)
 	}
-	return handler, chain
+	for i, body := range []string{
+		`{block(number: 1) { traces { result } } }`,
+		`{transaction(hash: "0x0000000000000000000000000000000000000000000000000000000000000000") { trace } }`,
+		`{simulate(options: {}) }`,
+	} {
+		res := handler.Schema.Exec(context.Background(), body, "", map[string]interface{}{})
+		if res.Errors == nil {
+			t.Errorf("testcase #%d: query accepted without the debug namespace", i)
+		}
+	}
+}
+
+// TestGraphQLAccessRules checks that GraphQL is refused when API key access
+// rules are configured, as its queries would bypass them.
+func TestGraphQLAccessRules(t *testing.T) {
+	stack, err := node.New(&node.Config{
+		HTTPHost:     "127.0.0.1",
+		HTTPPort:     0,
+		HTTPTimeouts: node.DefaultConfig.HTTPTimeouts,
+		RPCAccess:    []node.AccessRule{{Name: "api", APIKey: "secret", Allow: []string{"eth"}}},
+	})
+	if err != nil {
+		t.Fatalf("could not create node: %v", err)
+	}
+	defer stack.Close()
+
+	if _, err := newHandler(stack, nil, nil, []string{}, []string{}); err == nil {
+		t.Fatal("graphql service created with API key access rules")
+	}
+}
+
+func TestAuthorizationList(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth, err := types.SignSetCode(key, types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: common.HexToAddress("0xdad"),
+		Nonce:   7,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign authorization: %v", err)
+	}
+	invalid := auth
+	invalid.R = uint256.Int{}
+
+	tx := types.NewTx(&types.SetCodeTx{
+		ChainID:  uint256.NewInt(1),
+		To:       common.HexToAddress("0xdad"),
+		AuthList: []types.SetCodeAuthorization{auth, invalid},
+	})
+	list := (&Transaction{hash: tx.Hash(), tx: tx}).AuthorizationList(context.Background())
+	if list == nil || len(*list) != 2 {
+		t.Fatalf("unexpected authorization list: %v", list)
+	}
+	ctx := context.Background()
+	valid := (*list)[0]
+	if chainID := valid.ChainID(ctx); valid.Address(ctx) != auth.Address || valid.Nonce(ctx) != 7 || chainID.ToInt().Uint64() != 1 {
+		t.Errorf("wrong authorization fields")
+	}
+	if authority := valid.Authority(ctx); authority == nil || *authority != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("wrong authority: %v", authority)
+	}
+	if authority := (*list)[1].Authority(ctx); authority != nil {
+		t.Errorf("expected no authority for invalid signature, got %v", authority)
+	}
 }
@@ -36,6 +36,7 @@ const schema string = `
     schema {
         query: Query
         mutation: Mutation
+        subscription: Subscription
     }
 
     # Account is an Ethereum account at a particular block.
@@ -69,6 +70,9 @@ const schema string = `
         data: Bytes!
         # Transaction is the transaction that generated this log entry.
         transaction: Transaction!
+        # Removed is true if the log was reverted due to a chain reorganisation.
+        # It is only ever set for logs delivered by the newLogs subscription.
+        removed: Boolean!
     }
 
     # EIP-2718
@@ -89,6 +93,34 @@ const schema string = `
         amount: Long!
     }
 
+    # EIP-7702
+    type Authorization {
+        # ChainID is the chain the authorization is valid on, zero for any chain.
+        chainID: BigInt!
+        # Address is the account whose code is delegated to.
+        address: Address!
+        # Nonce is the nonce of the authorizing account.
+        nonce: Long!
+        yParity: Long!
+        r: BigInt!
+        s: BigInt!
+        # Authority is the account which signed the authorization. This is null
+        # if the signature is invalid.
+        authority: Address
+    }
+
+    # EIP-4844
+    type BlobSidecar {
+        # Version is the version of the cell proof format.
+        version: Long!
+        # Blobs is the list of blobs carried by the transaction.
+        blobs: [Bytes!]!
+        # Commitments is the list of KZG commitments of the blobs.
+        commitments: [Bytes!]!
+        # Proofs is the list of KZG proofs of the blobs.
+        proofs: [Bytes!]!
+    }
+
     # Transaction is an Ethereum transaction.
     type Transaction {
         # Hash is the hash of this transaction.
@@ -170,6 +202,13 @@ const schema string = `
         rawReceipt: Bytes!
         # BlobVersionedHashes is a set of hash outputs from the blobs in the transaction.
         blobVersionedHashes: [Bytes32!]
+        # BlobSidecar contains the blobs, commitments and proofs of a blob
+        # transaction. It is only available for transactions in the pool, as
+        # sidecars are not part of the chain.
+        blobSidecar: BlobSidecar
+        # AuthorizationList is the list of code delegations of an EIP-7702
+        # transaction. This is null for other transaction types.
+        authorizationList: [Authorization!]
     }
 
     # BlockFilterCriteria encapsulates log filter criteria for a filter applied
@@ -276,6 +315,13 @@ const schema string = `
         blobGasUsed: Long
         # ExcessBlobGas is a running total of blob gas consumed in excess of the target, prior to the block.
         excessBlobGas: Long
+        # BlobBaseFee is the fee per unit of blob gas burned in this block.
+        blobBaseFee: BigInt
+        # ParentBeaconBlockRoot is the root of the parent beacon block (EIP-4788).
+        parentBeaconBlockRoot: Bytes32
+        # RequestsHash is the commitment to the execution layer requests of this
+        # block (EIP-7685).
+        requestsHash: Bytes32
     }
 
     # CallData represents the data associated with a local contract call.
@@ -382,10 +428,64 @@ const schema string = `
         syncing: SyncState
         # ChainID returns the current chain ID for transaction replay protection.
         chainID: BigInt!
+        # BlobBaseFee returns the fee per unit of blob gas of the next block.
+        blobBaseFee: BigInt!
     }
 
     type Mutation {
         # SendRawTransaction sends an RLP-encoded transaction to the network.
         sendRawTransaction(data: Bytes!): Bytes32!
     }
+
+    # Subscription is served over the WebSocket transport of the GraphQL endpoint.
+    type Subscription {
+        # NewBlocks fires for every block imported as the new head of the chain.
+        newBlocks: Block!
+        # NewLogs fires for every log matching the filter in newly imported blocks.
+        # Logs of blocks dropped in a chain reorganisation are delivered again
+        # with removed set to true.
+        newLogs(filter: BlockFilterCriteria): Log!
+        # NewPendingTransactions fires for every transaction entering the
+        # transaction pool.
+        newPendingTransactions: Transaction!
+    }
+`
+
+// debugSchema extends the schema with tracing and simulation. As these are as
+// expensive as the debug namespace of the RPC API, they are only exposed if the
+// debug namespace is enabled on the HTTP server.
+const debugSchema string = `
+    # JSON is an arbitrary JSON value. It is used for the output of tracers and
+    # for payloads mirroring the JSON-RPC API.
+    scalar JSON
+
+    # TransactionTrace is the result of tracing a single transaction.
+    type TransactionTrace {
+        # Transaction is the traced transaction.
+        transaction: Transaction!
+        # Result is the output of the tracer. This is null if tracing failed.
+        result: JSON
+        # Error is the reason tracing failed, if any.
+        error: String
+    }
+
+    extend type Transaction {
+        # Trace executes the transaction with the given tracer, defaulting to
+        # the struct logger, and returns the tracer output. The tracer config
+        # is passed to the tracer as is.
+        trace(tracer: String, tracerConfig: JSON): JSON
+    }
+
+    extend type Block {
+        # Traces executes all transactions of the block with the given tracer,
+        # defaulting to the struct logger, and returns the tracer outputs.
+        traces(tracer: String, tracerConfig: JSON): [TransactionTrace!]!
+    }
+
+    extend type Query {
+        # Simulate executes a series of blocks with calls on top of the given
+        # block, defaulting to the latest. The options and result have the
+        # same format as eth_simulateV1.
+        simulate(options: JSON!, block: Long): JSON!
+    }
 `
@@ -19,7 +19,9 @@ package graphql
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
@@ -28,15 +30,22 @@ import (
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
 	"github.com/graph-gophers/graphql-go"
 	gqlErrors "github.com/graph-gophers/graphql-go/errors"
 )
 
 type handler struct {
-	Schema *graphql.Schema
+	Schema  *graphql.Schema
+	origins []string // Origins allowed to open WebSocket connections
 }
 
 func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Subscriptions are served over WebSocket connections.
+	if websocket.IsWebSocketUpgrade(r) {
+		h.serveWebSocket(w, r)
+		return
+	}
 	var params struct {
 		Query         string                 `json:"query"`
 		OperationName string                 `json:"operationName"`
@@ -112,15 +121,27 @@ func New(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterS
 }
 
 // newHandler returns a new `http.Handler` that will answer GraphQL queries.
+// Subscriptions are answered on the same endpoint after a WebSocket upgrade.
 // It additionally exports an interactive query browser on the / endpoint.
 func newHandler(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterSystem, cors, vhosts []string) (*handler, error) {
-	q := Resolver{backend, filterSystem}
+	// The access rules of API keys restrict RPC methods, which GraphQL queries
+	// can't be matched against. Refuse to expose the data without a key.
+	for _, rule := range stack.Config().RPCAccess {
+		if rule.APIKey != "" {
+			return nil, errors.New("GraphQL can't be enabled with RPC API key access rules")
+		}
+	}
+	q := Resolver{backend: backend, filterSystem: filterSystem}
 
-	s, err := graphql.ParseSchema(schema, &q)
+	typedefs := schema
+	if slices.Contains(stack.Config().HTTPModules, "debug") {
+		typedefs += debugSchema
+	}
+	s, err := graphql.ParseSchema(typedefs, &q)
 	if err != nil {
 		return nil, err
 	}
-	h := handler{Schema: s}
+	h := handler{Schema: s, origins: cors}
 	handler := node.NewHTTPHandlerStack(h, cors, vhosts, nil)
 
 	stack.RegisterHandler("GraphQL UI", "/graphql/ui", GraphiQL{})
@@ -0,0 +1,151 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxQueuedEvents is the number of events that may be pending delivery to a
+// subscriber. Subscriptions of clients falling further behind are terminated,
+// so that slow consumers cannot stall the shared event system.
+const maxQueuedEvents = 1024
+
+var errSubscriptionsUnavailable = errors.New("subscriptions are not available")
+
+// eventSystem returns the event system feeding the subscriptions, creating it
+// on first use.
+func (r *Resolver) eventSystem() (*filters.EventSystem, error) {
+	if r.filterSystem == nil {
+		return nil, errSubscriptionsUnavailable
+	}
+	r.eventsOnce.Do(func() {
+		r.events = filters.NewEventSystem(r.filterSystem)
+	})
+	return r.events, nil
+}
+
+// relay forwards the events of a filter subscription to the GraphQL executor
+// until the subscription context is cancelled or the consumer falls more than
+// maxQueuedEvents behind. Events are drained from the event system eagerly and
+// queued locally, so a slow consumer never blocks the event system.
+func relay[E, R any](ctx context.Context, sub *filters.Subscription, events <-chan E, convert func(E) []R) <-chan R {
+	results := make(chan R)
+	go func() {
+		defer close(results)
+		defer sub.Unsubscribe()
+
+		var queue []R
+		for {
+			var (
+				send chan R
+				next R
+			)
+			if len(queue) > 0 {
+				send, next = results, queue[0]
+			}
+			select {
+			case ev := <-events:
+				queue = append(queue, convert(ev)...)
+				if len(queue) > maxQueuedEvents {
+					return
+				}
+			case send <- next:
+				queue = queue[1:]
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return results
+}
+
+func (r *Resolver) NewBlocks(ctx context.Context) (<-chan *Block, error) {
+	es, err := r.eventSystem()
+	if err != nil {
+		return nil, err
+	}
+	headers := make(chan *types.Header)
+	sub := es.SubscribeNewHeads(headers)
+
+	return relay(ctx, sub, headers, func(header *types.Header) []*Block {
+		numberOrHash := rpc.BlockNumberOrHashWithHash(header.Hash(), false)
+		return []*Block{{
+			r:            r,
+			numberOrHash: &numberOrHash,
+			hash:         header.Hash(),
+			header:       header,
+		}}
+	}), nil
+}
+
+func (r *Resolver) NewLogs(ctx context.Context, args struct{ Filter *BlockFilterCriteria }) (<-chan *Log, error) {
+	es, err := r.eventSystem()
+	if err != nil {
+		return nil, err
+	}
+	var crit ethereum.FilterQuery
+	if args.Filter != nil {
+		if args.Filter.Addresses != nil {
+			crit.Addresses = *args.Filter.Addresses
+		}
+		if args.Filter.Topics != nil {
+			crit.Topics = *args.Filter.Topics
+		}
+	}
+	logs := make(chan []*types.Log)
+	sub, err := es.SubscribeLogs(crit, logs)
+	if err != nil {
+		return nil, err
+	}
+	return relay(ctx, sub, logs, func(logs []*types.Log) []*Log {
+		ret := make([]*Log, 0, len(logs))
+		for _, log := range logs {
+			ret = append(ret, &Log{
+				r:           r,
+				transaction: &Transaction{r: r, hash: log.TxHash},
+				log:         log,
+			})
+		}
+		return ret
+	}), nil
+}
+
+func (r *Resolver) NewPendingTransactions(ctx context.Context) (<-chan *Transaction, error) {
+	es, err := r.eventSystem()
+	if err != nil {
+		return nil, err
+	}
+	txs := make(chan []*types.Transaction, 128)
+	sub := es.SubscribePendingTxs(txs)
+
+	return relay(ctx, sub, txs, func(txs []*types.Transaction) []*Transaction {
+		ret := make([]*Transaction, 0, len(txs))
+		for _, tx := range txs {
+			// Pending transactions carry no block, which marks them as such
+			// for the field resolvers.
+			ret = append(ret, &Transaction{r: r, hash: tx.Hash(), tx: tx})
+		}
+		return ret
+	}), nil
+}
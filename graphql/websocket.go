@@ -0,0 +1,293 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/websocket"
+)
+
+// The WebSocket transport implements both the graphql-transport-ws protocol of
+// the graphql-ws library and its predecessor graphql-ws of the deprecated
+// subscriptions-transport-ws library. The two only differ in message naming.
+const (
+	wsProtocolTransport = "graphql-transport-ws"
+	wsProtocolLegacy    = "graphql-ws"
+)
+
+const (
+	wsReadLimit        = 1024 * 1024
+	wsWriteTimeout     = 10 * time.Second
+	wsInitTimeout      = 10 * time.Second
+	wsMaxSubscriptions = 100
+)
+
+// Close codes defined by the graphql-transport-ws protocol.
+const (
+	wsCloseBadRequest      = 4400
+	wsCloseUnauthorized    = 4401
+	wsCloseInitTimeout     = 4408
+	wsCloseDuplicateID     = 4409
+	wsCloseTooManyInitReqs = 4429
+)
+
+// wsMessage is the envelope of all messages exchanged over the WebSocket.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsRequest is the payload of a subscribe message.
+type wsRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// wsConn is a single GraphQL WebSocket connection and the subscriptions which
+// are active on it.
+type wsConn struct {
+	h      *handler
+	conn   *websocket.Conn
+	legacy bool // whether the legacy graphql-ws protocol is spoken
+
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	subs   map[string]context.CancelFunc
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// wsOriginCheck returns an origin checker accepting the configured CORS domains.
+// Requests without an Origin header are not made by browsers and are accepted.
+func wsOriginCheck(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, domain := range allowed {
+			if domain == "*" || strings.EqualFold(domain, origin) {
+				return true
+			}
+		}
+		if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		log.Debug("Rejected GraphQL WebSocket connection", "origin", origin)
+		return false
+	}
+}
+
+// serveWebSocket upgrades the request to a WebSocket connection and serves
+// GraphQL operations, including subscriptions, over it until the connection
+// is closed.
+func (h *handler) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		Subprotocols:    []string{wsProtocolTransport, wsProtocolLegacy},
+		CheckOrigin:     wsOriginCheck(h.origins),
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Debug("GraphQL WebSocket upgrade failed", "err", err)
+		return
+	}
+	conn.SetReadLimit(wsReadLimit)
+
+	c := &wsConn{
+		h:      h,
+		conn:   conn,
+		legacy: conn.Subprotocol() == wsProtocolLegacy,
+		subs:   make(map[string]context.CancelFunc),
+	}
+	// The connection outlives the upgraded HTTP request, so its lifetime is
+	// not tied to the request context.
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.run()
+}
+
+// run reads and dispatches client messages until the connection fails.
+func (c *wsConn) run() {
+	defer func() {
+		c.cancel()
+		c.wg.Wait()
+		c.conn.Close()
+	}()
+	// The client has to initialise the connection first.
+	initTimer := time.AfterFunc(wsInitTimeout, func() {
+		c.close(wsCloseInitTimeout, "Connection initialisation timeout")
+	})
+	defer initTimer.Stop()
+
+	var initialised bool
+	for {
+		var msg wsMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				c.close(wsCloseBadRequest, "Invalid message received")
+			}
+			return
+		}
+		switch msg.Type {
+		case "connection_init":
+			if initialised {
+				c.close(wsCloseTooManyInitReqs, "Too many initialisation requests")
+				return
+			}
+			initialised = true
+			initTimer.Stop()
+			c.send(wsMessage{Type: "connection_ack"})
+
+		case "ping":
+			c.send(wsMessage{Type: "pong", Payload: msg.Payload})
+
+		case "pong":
+			// Replies to pings are not required to be processed.
+
+		case "subscribe", "start":
+			if !initialised {
+				c.close(wsCloseUnauthorized, "Unauthorized")
+				return
+			}
+			var req wsRequest
+			if err := json.Unmarshal(msg.Payload, &req); err != nil || msg.ID == "" {
+				c.close(wsCloseBadRequest, "Invalid subscribe message")
+				return
+			}
+			if !c.start(msg.ID, req) {
+				return
+			}
+
+		case "complete", "stop":
+			c.stop(msg.ID)
+
+		case "connection_terminate":
+			return
+
+		default:
+			c.close(wsCloseBadRequest, fmt.Sprintf("Invalid message type %q", msg.Type))
+			return
+		}
+	}
+}
+
+// start executes a GraphQL operation and streams its results to the client. It
+// returns false if the connection was closed due to a protocol violation.
+func (c *wsConn) start(id string, req wsRequest) bool {
+	c.mu.Lock()
+	if _, ok := c.subs[id]; ok {
+		c.mu.Unlock()
+		c.close(wsCloseDuplicateID, fmt.Sprintf("Subscriber for %s already exists", id))
+		return false
+	}
+	if len(c.subs) >= wsMaxSubscriptions {
+		c.mu.Unlock()
+		payload, _ := json.Marshal([]map[string]string{{"message": "too many subscriptions"}})
+		c.send(wsMessage{ID: id, Type: "error", Payload: payload})
+		return true
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.subs[id] = cancel
+	c.mu.Unlock()
+
+	responses, err := c.h.Schema.Subscribe(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		c.stop(id)
+		payload, _ := json.Marshal([]map[string]string{{"message": err.Error()}})
+		c.send(wsMessage{ID: id, Type: "error", Payload: payload})
+		return true
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		// The result channel has to be drained until it is closed, otherwise
+		// the executor leaks the goroutine delivering into it.
+		dataType := "next"
+		if c.legacy {
+			dataType = "data"
+		}
+		for resp := range responses {
+			if ctx.Err() != nil {
+				continue
+			}
+			payload, err := json.Marshal(resp)
+			if err != nil {
+				log.Debug("Failed to encode GraphQL response", "err", err)
+				continue
+			}
+			c.send(wsMessage{ID: id, Type: dataType, Payload: payload})
+		}
+		// Only announce completion if the client did not cancel the operation.
+		c.mu.Lock()
+		_, active := c.subs[id]
+		delete(c.subs, id)
+		c.mu.Unlock()
+		if active && c.ctx.Err() == nil {
+			c.send(wsMessage{ID: id, Type: "complete"})
+		}
+		cancel()
+	}()
+	return true
+}
+
+// stop cancels the operation with the given id.
+func (c *wsConn) stop(id string) {
+	c.mu.Lock()
+	cancel, ok := c.subs[id]
+	delete(c.subs, id)
+	c.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// send writes a message to the client.
+func (c *wsConn) send(msg wsMessage) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err := c.conn.WriteJSON(msg); err != nil {
+		log.Debug("Failed to write GraphQL WebSocket message", "err", err)
+		c.cancel()
+	}
+}
+
+// close terminates the connection with the given close code.
+func (c *wsConn) close(code int, reason string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	deadline := time.Now().Add(wsWriteTimeout)
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+	c.conn.Close()
+}
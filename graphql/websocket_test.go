@@ -0,0 +1,123 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/gorilla/websocket"
+)
+
+// wsTestClient is a minimal GraphQL WebSocket client.
+type wsTestClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+func (c *wsTestClient) send(typ, id, payload string) {
+	msg := wsMessage{ID: id, Type: typ}
+	if payload != "" {
+		msg.Payload = json.RawMessage(payload)
+	}
+	if err := c.conn.WriteJSON(msg); err != nil {
+		c.t.Fatalf("failed to send %s: %v", typ, err)
+	}
+}
+
+func (c *wsTestClient) expect(typ, id, payload string) {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg wsMessage
+	if err := c.conn.ReadJSON(&msg); err != nil {
+		c.t.Fatalf("failed to read %s: %v", typ, err)
+	}
+	if msg.Type != typ || msg.ID != id || (payload != "" && string(msg.Payload) != payload) {
+		c.t.Fatalf("unexpected message: have %s/%s %s, want %s/%s %s", msg.Type, msg.ID, msg.Payload, typ, id, payload)
+	}
+}
+
+// sync waits until all previously sent messages have been processed.
+func (c *wsTestClient) sync() {
+	c.send("ping", "", "")
+	c.expect("pong", "", "")
+}
+
+func TestGraphQLSubscriptions(t *testing.T) {
+	stack := createNode(t)
+	defer stack.Close()
+	genesis := &core.Genesis{
+		Config:     params.AllEthashProtocolChanges,
+		GasLimit:   11500000,
+		Difficulty: big.NewInt(1048576),
+	}
+	backend, _ := newGQLBackend(t, stack, false, genesis, 1, func(i int, gen *core.BlockGen) {})
+	filterSystem := filters.NewFilterSystem(backend.APIBackend, filters.Config{})
+	if _, err := newHandler(stack, backend.APIBackend, filterSystem, []string{}, []string{}); err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	dialer := websocket.Dialer{Subprotocols: []string{wsProtocolTransport}}
+	url := "ws" + strings.TrimPrefix(stack.HTTPEndpoint(), "http") + "/graphql"
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("could not dial graphql websocket: %v", err)
+	}
+	defer conn.Close()
+	client := &wsTestClient{t: t, conn: conn}
+
+	client.send("connection_init", "", "")
+	client.expect("connection_ack", "", "")
+
+	// Queries are answered with a single result.
+	client.send("subscribe", "q", `{"query":"{block{number}}"}`)
+	client.expect("next", "q", `{"data":{"block":{"number":"0x1"}}}`)
+	client.expect("complete", "q", "")
+
+	// Subscriptions deliver results as blocks are imported.
+	client.send("subscribe", "s", `{"query":"subscription{newBlocks{number}}"}`)
+	client.sync()
+
+	blocks, _ := core.GenerateChain(params.AllEthashProtocolChanges, backend.BlockChain().GetBlockByNumber(1), beacon.New(ethash.NewFaker()), backend.ChainDb(), 2, func(i int, gen *core.BlockGen) {})
+	if _, err := backend.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatalf("could not import blocks: %v", err)
+	}
+	client.expect("next", "s", `{"data":{"newBlocks":{"number":"0x2"}}}`)
+	client.expect("next", "s", `{"data":{"newBlocks":{"number":"0x3"}}}`)
+
+	// Cancelled subscriptions are not completed by the server.
+	client.send("complete", "s", "")
+	client.sync()
+
+	// Reusing the id of an active operation is a protocol violation.
+	client.send("subscribe", "d", `{"query":"subscription{newPendingTransactions{hash}}"}`)
+	client.send("subscribe", "d", `{"query":"subscription{newPendingTransactions{hash}}"}`)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, wsCloseDuplicateID) {
+		t.Fatalf("expected close code %d, got %v", wsCloseDuplicateID, err)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// defaultAddressTxPageSize is the number of transactions returned by
+	// eth_getTransactionsByAddress if no limit is specified.
+	defaultAddressTxPageSize = 100
+
+	// maxAddressTxPageSize is the maximum number of transactions returned by
+	// a single eth_getTransactionsByAddress call.
+	maxAddressTxPageSize = 1000
+)
+
+// AddressTxCursor identifies the position of a transaction in the chain. It is
+// used as the continuation token of eth_getTransactionsByAddress and points to
+// the first transaction that has not been returned yet.
+type AddressTxCursor struct {
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex hexutil.Uint   `json:"transactionIndex"`
+}
+
+// AddressTxArgs represents the arguments of eth_getTransactionsByAddress.
+type AddressTxArgs struct {
+	FromBlock        *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock          *rpc.BlockNumber `json:"toBlock"`
+	Limit            *hexutil.Uint64  `json:"limit"`
+	Cursor           *AddressTxCursor `json:"cursor"`
+	FullTransactions bool             `json:"fullTransactions"`
+}
+
+// AddressTxResult is a transaction the queried address took part in.
+type AddressTxResult struct {
+	Hash             common.Hash     `json:"hash"`
+	BlockNumber      hexutil.Uint64  `json:"blockNumber"`
+	TransactionIndex hexutil.Uint    `json:"transactionIndex"`
+	Roles            []string        `json:"roles"`
+	Transaction      *RPCTransaction `json:"transaction,omitempty"`
+}
+
+// AddressTxPage is a single page of results of eth_getTransactionsByAddress.
+type AddressTxPage struct {
+	Transactions []*AddressTxResult `json:"transactions"`
+	Cursor       *AddressTxCursor   `json:"cursor"` // Position to continue from, nil if the range is exhausted
+}
+
+// addressTxRoles converts the role bitmap of an index entry into names.
+func addressTxRoles(roles uint8) []string {
+	var names []string
+	if roles&rawdb.AddressTxSender != 0 {
+		names = append(names, "sender")
+	}
+	if roles&rawdb.AddressTxRecipient != 0 {
+		names = append(names, "recipient")
+	}
+	if roles&rawdb.AddressTxInternal != 0 {
+		names = append(names, "internal")
+	}
+	return names
+}
+
+// resolveBlockNumber converts a block number which may be a tag into a plain
+// block number.
+func (api *TransactionAPI) resolveBlockNumber(ctx context.Context, number *rpc.BlockNumber, fallback rpc.BlockNumber) (uint64, error) {
+	if number == nil {
+		number = &fallback
+	}
+	if *number == rpc.EarliestBlockNumber {
+		return 0, nil
+	}
+	if *number >= 0 {
+		return uint64(*number), nil
+	}
+	header, err := api.b.HeaderByNumber(ctx, *number)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("block %v not found", *number)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// GetTransactionsByAddress returns the transactions the given address took part
+// in as sender, recipient or, if enabled, as a participant of internal calls,
+// in ascending chain order. Results are paginated: if more transactions exist in
+// the requested range, the returned cursor can be passed to continue the query.
+//
+// The address index shares the retention of the transaction index, so only
+// transactions within the indexed range are returned.
+func (api *TransactionAPI) GetTransactionsByAddress(ctx context.Context, address common.Address, args *AddressTxArgs) (*AddressTxPage, error) {
+	if args == nil {
+		args = new(AddressTxArgs)
+	}
+	limit := defaultAddressTxPageSize
+	if args.Limit != nil {
+		if *args.Limit == 0 || *args.Limit > maxAddressTxPageSize {
+			return nil, fmt.Errorf("invalid limit, must be between 1 and %d", maxAddressTxPageSize)
+		}
+		limit = int(*args.Limit)
+	}
+	first, err := api.resolveBlockNumber(ctx, args.FromBlock, rpc.EarliestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	last, err := api.resolveBlockNumber(ctx, args.ToBlock, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if first > last {
+		return nil, errors.New("invalid block range")
+	}
+	var index uint32
+	if args.Cursor != nil {
+		if uint64(args.Cursor.BlockNumber) < first || uint64(args.Cursor.BlockNumber) > last {
+			return nil, errors.New("cursor out of block range")
+		}
+		first, index = uint64(args.Cursor.BlockNumber), uint32(args.Cursor.TransactionIndex)
+	}
+	// Retrieve one more entry than requested to find the continuation point.
+	entries, err := api.b.GetAddressTransactions(ctx, address, first, index, last, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &AddressTxPage{Transactions: make([]*AddressTxResult, 0, min(len(entries), limit))}
+	if len(entries) > limit {
+		page.Cursor = &AddressTxCursor{
+			BlockNumber:      hexutil.Uint64(entries[limit].BlockNumber),
+			TransactionIndex: hexutil.Uint(entries[limit].TxIndex),
+		}
+		entries = entries[:limit]
+	}
+	for _, entry := range entries {
+		result := &AddressTxResult{
+			Hash:             entry.TxHash,
+			BlockNumber:      hexutil.Uint64(entry.BlockNumber),
+			TransactionIndex: hexutil.Uint(entry.TxIndex),
+			Roles:            addressTxRoles(entry.Roles),
+		}
+		if args.FullTransactions {
+			found, tx, blockHash, blockNumber, index := api.b.GetCanonicalTransaction(entry.TxHash)
+			if !found {
+				return nil, fmt.Errorf("transaction %x not found", entry.TxHash)
+			}
+			header, err := api.b.HeaderByHash(ctx, blockHash)
+			if header == nil || err != nil {
+				return nil, fmt.Errorf("header of block %x not found", blockHash)
+			}
+			result.Transaction = newRPCTransaction(tx, blockHash, blockNumber, header.Time, index, header.BaseFee, api.b.ChainConfig())
+		}
+		page.Transactions = append(page.Transactions, result)
+	}
+	return page, nil
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/gasestimator"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/internal/ethapi/override"
 	"github.com/ethereum/go-ethereum/log"
@@ -137,6 +138,55 @@ func (api *EthereumAPI) BlobBaseFee(ctx context.Context) *hexutil.Big {
 	return (*hexutil.Big)(api.b.BlobBaseFee(ctx))
 }
 
+type feeTierResult struct {
+	Blocks               hexutil.Uint64 `json:"blocks"`
+	Seconds              hexutil.Uint64 `json:"seconds"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+}
+
+type feeEstimatesResult struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BaseFee     *hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	BlobBaseFee []*hexutil.Big `json:"baseFeePerBlobGas,omitempty"`
+	Slow        feeTierResult  `json:"slow"`
+	Standard    feeTierResult  `json:"standard"`
+	Fast        feeTierResult  `json:"fast"`
+}
+
+func newFeeTierResult(tier gasprice.FeeTier) feeTierResult {
+	return feeTierResult{
+		Blocks:               hexutil.Uint64(tier.Blocks),
+		Seconds:              hexutil.Uint64(tier.Seconds),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tier.MaxPriorityFeePerGas),
+		MaxFeePerGas:         (*hexutil.Big)(tier.MaxFeePerGas),
+	}
+}
+
+// FeeEstimates returns slow, standard and fast fee suggestions targeting the
+// inclusion of a transaction within a number of blocks, together with the base
+// fee of the next block and predicted blob base fees of the upcoming blocks.
+func (api *EthereumAPI) FeeEstimates(ctx context.Context) (*feeEstimatesResult, error) {
+	estimates, err := api.b.FeeEstimates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := &feeEstimatesResult{
+		BlockNumber: hexutil.Uint64(estimates.BlockNumber),
+		BaseFee:     (*hexutil.Big)(estimates.BaseFee),
+		Slow:        newFeeTierResult(estimates.Slow),
+		Standard:    newFeeTierResult(estimates.Standard),
+		Fast:        newFeeTierResult(estimates.Fast),
+	}
+	if estimates.BlobBaseFee != nil {
+		results.BlobBaseFee = make([]*hexutil.Big, len(estimates.BlobBaseFee))
+		for i, v := range estimates.BlobBaseFee {
+			results.BlobBaseFee[i] = (*hexutil.Big)(v)
+		}
+	}
+	return results, nil
+}
+
 // Syncing returns false in case the node is currently not syncing with the network. It can be up-to-date or has not
 // yet received the latest block headers from its peers. In case it is synchronizing:
 // - startingBlock: block number this node started to synchronize from
@@ -514,11 +564,24 @@ func (api *BlockChainAPI) GetBlockByNumber(ctx context.Context, number rpc.Block
 func (api *BlockChainAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
 	block, err := api.b.BlockByHash(ctx, hash)
 	if block != nil {
+		markFinalized(ctx, api.b, block.NumberU64())
 		return RPCMarshalBlock(block, true, fullTx, api.b.ChainConfig()), nil
 	}
 	return nil, err
 }
 
+// markFinalized declares the result of the RPC call immutable if the block with
+// the given number is finalized, allowing the RPC server to cache it.
+func markFinalized(ctx context.Context, b Backend, number uint64) {
+	if !rpc.CanMarkImmutable(ctx) {
+		return
+	}
+	final, err := b.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+	if err == nil && final != nil && number <= final.Number.Uint64() {
+		rpc.MarkImmutable(ctx)
+	}
+}
+
 // GetUncleByBlockNumberAndIndex returns the uncle block for the given block hash and index.
 func (api *BlockChainAPI) GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) (map[string]interface{}, error) {
 	block, err := api.b.BlockByNumber(ctx, blockNr)
@@ -809,18 +872,16 @@ func (api *BlockChainAPI) SimulateV1(ctx context.Context, opts simOpts, blockNrO
 	return sim.execute(ctx, opts.BlockStateCalls)
 }
 
-// DoEstimateGas returns the lowest possible gas limit that allows the transaction to run
-// successfully at block `blockNrOrHash`. It returns error if the transaction would revert, or if
-// there are unexpected failures. The gas limit is capped by both `args.Gas` (if non-nil &
-// non-zero) and `gasCap` (if non-zero).
-func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *override.StateOverride, blockOverrides *override.BlockOverrides, gasCap uint64) (hexutil.Uint64, error) {
+// prepareEstimate assembles the message and the estimation options of a gas
+// estimation on top of the given block.
+func prepareEstimate(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *override.StateOverride, blockOverrides *override.BlockOverrides, gasCap uint64) (*core.Message, *gasestimator.Options, error) {
 	// Retrieve the base state and mutate it with any overrides
 	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 	if state == nil || err != nil {
-		return 0, err
+		return nil, nil, err
 	}
 	if err := overrides.Apply(state, nil); err != nil {
-		return 0, err
+		return nil, nil, err
 	}
 	// Construct the gas estimator option from the user input
 	opts := &gasestimator.Options{
@@ -837,10 +898,20 @@ func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNr
 		args.Gas = new(hexutil.Uint64)
 	}
 	if err := args.CallDefaults(gasCap, header.BaseFee, b.ChainConfig().ChainID); err != nil {
-		return 0, err
+		return nil, nil, err
 	}
-	call := args.ToMessage(header.BaseFee, true, true)
+	return args.ToMessage(header.BaseFee, true, true), opts, nil
+}
 
+// DoEstimateGas returns the lowest possible gas limit that allows the transaction to run
+// successfully at block `blockNrOrHash`. It returns error if the transaction would revert, or if
+// there are unexpected failures. The gas limit is capped by both `args.Gas` (if non-nil &
+// non-zero) and `gasCap` (if non-zero).
+func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *override.StateOverride, blockOverrides *override.BlockOverrides, gasCap uint64) (hexutil.Uint64, error) {
+	call, opts, err := prepareEstimate(ctx, b, args, blockNrOrHash, overrides, blockOverrides, gasCap)
+	if call == nil {
+		return 0, err
+	}
 	// Run the gas estimation and wrap any revertals into a custom return
 	estimate, revert, err := gasestimator.Estimate(ctx, call, opts, gasCap)
 	if err != nil {
@@ -1387,6 +1458,8 @@ func (api *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash commo
 	if err != nil {
 		return nil, err
 	}
+	markFinalized(ctx, api.b, blockNumber)
+
 	// Derive the sender.
 	return marshalReceipt(receipt, blockHash, blockNumber, api.signer, tx, int(index)), nil
 }
@@ -53,6 +53,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/blocktest"
@@ -476,6 +477,9 @@ func (b testBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 func (b testBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, error) {
 	return nil, nil, nil, nil, nil, nil, nil
 }
+func (b testBackend) FeeEstimates(ctx context.Context) (*gasprice.FeeEstimates, error) {
+	return nil, nil
+}
 func (b testBackend) BlobBaseFee(ctx context.Context) *big.Int { return new(big.Int) }
 func (b testBackend) ChainDb() ethdb.Database                  { return b.db }
 func (b testBackend) AccountManager() *accounts.Manager        { return b.accman }
@@ -596,6 +600,9 @@ func (b testBackend) GetCanonicalReceipt(tx *types.Transaction, blockHash common
 func (b testBackend) TxIndexDone() bool {
 	return true
 }
+func (b testBackend) GetAddressTransactions(ctx context.Context, address common.Address, first uint64, index uint32, last uint64, limit int) ([]rawdb.AddressTxEntry, error) {
+	return b.chain.GetAddressTransactions(address, first, index, last, limit)
+}
 func (b testBackend) GetPoolTransactions() (types.Transactions, error)         { panic("implement me") }
 func (b testBackend) GetPoolTransaction(txHash common.Hash) *types.Transaction { panic("implement me") }
 func (b testBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
@@ -923,6 +930,89 @@ func TestEstimateGas(t *testing.T) {
 	}
 }
 
+func TestEstimateGasDetailed(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(1)
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		caller   = common.HexToAddress("0xaa")
+		storer   = common.HexToAddress("0xbb")
+		reverter = common.HexToAddress("0xcc")
+		latest   = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	api := NewBlockChainAPI(newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	}))
+	// The caller reads the timestamp and forwards all its gas to the storer,
+	// reverting if the call fails. The storer sets and clears a storage slot,
+	// earning a refund.
+	callerCode := append([]byte{
+		byte(vm.TIMESTAMP), byte(vm.POP),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.PUSH20),
+	}, storer.Bytes()...)
+	callerCode = append(callerCode,
+		byte(vm.GAS), byte(vm.CALL),
+		byte(vm.ISZERO), byte(vm.PUSH1), 40, byte(vm.JUMPI), byte(vm.STOP),
+		byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT),
+	)
+	storerCode := []byte{
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.STOP),
+	}
+	// The reverter reverts with Error("boom").
+	revert := append(crypto.Keccak256([]byte("Error(string)"))[:4], make([]byte, 96)...)
+	revert[4+31], revert[4+63] = 0x20, 4
+	copy(revert[4+64:], "boom")
+	reverterCode := append([]byte{
+		byte(vm.PUSH1), byte(len(revert)), byte(vm.PUSH1), 12, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), byte(len(revert)), byte(vm.PUSH1), 0, byte(vm.REVERT),
+	}, revert...)
+
+	overrides := override.StateOverride{
+		caller:   override.OverrideAccount{Code: (*hexutil.Bytes)(&callerCode)},
+		storer:   override.OverrideAccount{Code: (*hexutil.Bytes)(&storerCode)},
+		reverter: override.OverrideAccount{Code: (*hexutil.Bytes)(&reverterCode)},
+	}
+	result, err := api.EstimateGasDetailed(context.Background(), TransactionArgs{From: &accounts[0].addr, To: &caller}, &latest, &overrides, nil)
+	if err != nil {
+		t.Fatalf("failed to estimate gas: %v", err)
+	}
+	if result.Gas == nil || result.Error != "" {
+		t.Fatalf("estimation failed: %s", result.Error)
+	}
+	if result.Iterations < 2 || result.Refund == 0 || uint64(result.GasUsed) > uint64(*result.Gas) {
+		t.Errorf("wrong estimation details: %d iterations, %d gas used, %d refund, estimate %d", result.Iterations, result.GasUsed, result.Refund, *result.Gas)
+	}
+	if len(result.Frames) != 2 || result.Frames[0].To != caller || result.Frames[1].To != storer || result.Frames[1].Depth != 1 || result.Frames[1].Error != "" {
+		t.Fatalf("wrong call frames: %+v", result.Frames)
+	}
+	if !result.Frames[1].Capped || result.Bottleneck == nil || *result.Bottleneck != 1 {
+		t.Errorf("wrong 63/64 rule bottleneck: capped %v, bottleneck %v", result.Frames[1].Capped, result.Bottleneck)
+	}
+	if len(result.BlockContext) != 1 || result.BlockContext[0] != "TIMESTAMP" {
+		t.Errorf("wrong block context dependencies: %v", result.BlockContext)
+	}
+
+	// Reverting transactions are explained instead of returning an error.
+	result, err = api.EstimateGasDetailed(context.Background(), TransactionArgs{From: &accounts[0].addr, To: &reverter}, &latest, &overrides, nil)
+	if err != nil {
+		t.Fatalf("failed to estimate gas: %v", err)
+	}
+	if result.Gas != nil || result.Error != vm.ErrExecutionReverted.Error() || result.RevertReason != "boom" {
+		t.Fatalf("wrong revert explanation: gas %v, error %q, reason %q", result.Gas, result.Error, result.RevertReason)
+	}
+	if len(result.Frames) != 1 || result.Frames[0].RevertReason != "boom" {
+		t.Fatalf("wrong call frames: %+v", result.Frames)
+	}
+}
+
 func TestCall(t *testing.T) {
 	t.Parallel()
 
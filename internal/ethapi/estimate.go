@@ -0,0 +1,128 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/eth/gasestimator"
+	"github.com/ethereum/go-ethereum/internal/ethapi/override"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// estimateFrame is a call frame of the execution explaining a gas estimate.
+type estimateFrame struct {
+	Depth        hexutil.Uint   `json:"depth"`
+	Type         string         `json:"type"`
+	From         common.Address `json:"from"`
+	To           common.Address `json:"to"`
+	Gas          hexutil.Uint64 `json:"gas"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Capped       bool           `json:"cappedBy63of64"`
+	Error        string         `json:"error,omitempty"`
+	Revert       hexutil.Bytes  `json:"revert,omitempty"`
+	RevertReason string         `json:"revertReason,omitempty"`
+}
+
+// EstimateGasDetailedResult is the result of eth_estimateGasDetailed.
+type EstimateGasDetailedResult struct {
+	Gas          *hexutil.Uint64  `json:"gas"` // Estimated gas limit, nil if the estimation failed
+	Allowance    hexutil.Uint64   `json:"allowance,omitempty"`
+	Iterations   hexutil.Uint64   `json:"iterations"`
+	GasUsed      hexutil.Uint64   `json:"gasUsed"`
+	Refund       hexutil.Uint64   `json:"refund"`
+	Frames       []*estimateFrame `json:"frames"`
+	Bottleneck   *hexutil.Uint    `json:"bottleneck"` // Index of the frame limiting the estimate via the 63/64 rule
+	BlockContext []string         `json:"blockContext"`
+	Error        string           `json:"error,omitempty"`
+	Revert       hexutil.Bytes    `json:"revert,omitempty"`
+	RevertReason string           `json:"revertReason,omitempty"`
+}
+
+// decodeRevertReason returns the reason string of a revert, if it carries one.
+func decodeRevertReason(revert []byte) string {
+	reason, err := abi.UnpackRevert(revert)
+	if err != nil {
+		return ""
+	}
+	return reason
+}
+
+// EstimateGasDetailed estimates the gas limit of a transaction like EstimateGas,
+// and explains the estimate: the number of executions performed, the refund,
+// the gas used by each call frame, the frame most constrained by the 63/64 rule
+// and the block context the execution depends on. Failing estimations do not
+// return an error but report the failure and decoded revert reason instead.
+func (api *BlockChainAPI) EstimateGasDetailed(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *override.StateOverride, blockOverrides *override.BlockOverrides) (*EstimateGasDetailedResult, error) {
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	call, opts, err := prepareEstimate(ctx, api.b, args, bNrOrHash, overrides, blockOverrides, api.b.RPCGasCap())
+	if call == nil {
+		return nil, err
+	}
+	report, err := gasestimator.EstimateDetailed(ctx, call, opts, api.b.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	result := &EstimateGasDetailedResult{
+		Iterations:   hexutil.Uint64(report.Iterations),
+		GasUsed:      hexutil.Uint64(report.UsedGas),
+		Refund:       hexutil.Uint64(report.Refund),
+		Frames:       make([]*estimateFrame, 0, len(report.Frames)),
+		BlockContext: make([]string, 0, len(report.BlockContext)),
+	}
+	if report.Err == nil {
+		result.Gas = (*hexutil.Uint64)(&report.Gas)
+	} else {
+		result.Allowance = hexutil.Uint64(report.Gas)
+		result.Error = report.Err.Error()
+		result.Revert = report.Revert
+		result.RevertReason = decodeRevertReason(report.Revert)
+	}
+	for i, frame := range report.Frames {
+		f := &estimateFrame{
+			Depth:   hexutil.Uint(frame.Depth),
+			Type:    frame.Type.String(),
+			From:    frame.From,
+			To:      frame.To,
+			Gas:     hexutil.Uint64(frame.Gas),
+			GasUsed: hexutil.Uint64(frame.GasUsed),
+			Capped:  frame.Capped,
+		}
+		if frame.Error != nil {
+			f.Error = frame.Error.Error()
+		}
+		if frame.Revert != nil {
+			f.Revert = frame.Revert
+			f.RevertReason = decodeRevertReason(frame.Revert)
+		}
+		if frame == report.Bottleneck {
+			index := hexutil.Uint(i)
+			result.Bottleneck = &index
+		}
+		result.Frames = append(result.Frames, f)
+	}
+	for _, op := range report.BlockContext {
+		result.BlockContext = append(result.BlockContext, op.String())
+	}
+	return result, nil
+}
@@ -108,6 +108,21 @@ type simOpts struct {
 	ReturnFullTransactions bool
 }
 
+// Simulate runs SimulateV1 on JSON encoded simulation options and returns the
+// JSON encoded result. It allows API frontends other than JSON-RPC to offer
+// simulations in the same format.
+func Simulate(ctx context.Context, b Backend, opts json.RawMessage, blockNrOrHash rpc.BlockNumberOrHash) (json.RawMessage, error) {
+	var decoded simOpts
+	if err := json.Unmarshal(opts, &decoded); err != nil {
+		return nil, &invalidParamsError{message: err.Error()}
+	}
+	results, err := NewBlockChainAPI(b).SimulateV1(ctx, decoded, &blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(results)
+}
+
 // simChainHeadReader implements ChainHeaderReader which is needed as input for FinalizeAndAssemble.
 type simChainHeadReader struct {
 	context.Context
@@ -121,6 +121,12 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'backfillHistory',
+			call: 'admin_backfillHistory',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -158,6 +164,15 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'setBandwidthLimits',
+			call: 'admin_setBandwidthLimits',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'reloadAllowlist',
+			call: 'admin_reloadAllowlist'
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -172,6 +187,10 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'bandwidthLimits',
+			getter: 'admin_bandwidthLimits'
+		}),
 	]
 });
 `
@@ -432,6 +451,63 @@ web3._extend({
 			params: 2,
 			inputFormatter:[null, null],
 		}),
+		new web3._extend.Method({
+			name: 'getStateDiffByNumber',
+			call: 'debug_getStateDiffByNumber',
+			params: 2,
+			inputFormatter: [null, null],
+		}),
+		new web3._extend.Method({
+			name: 'getStateDiffByHash',
+			call: 'debug_getStateDiffByHash',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'startStateAccessProfile',
+			call: 'debug_startStateAccessProfile',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'stopStateAccessProfile',
+			call: 'debug_stopStateAccessProfile',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'resetStateAccessProfile',
+			call: 'debug_resetStateAccessProfile',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'stateAccessProfile',
+			call: 'debug_stateAccessProfile',
+			params: 1,
+			inputFormatter: [null],
+		}),
+		new web3._extend.Method({
+			name: 'snapSyncStatus',
+			call: 'debug_snapSyncStatus',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'pauseSnapSync',
+			call: 'debug_pauseSnapSync',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'resumeSnapSync',
+			call: 'debug_resumeSnapSync',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'banSnapPeer',
+			call: 'debug_banSnapPeer',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'unbanSnapPeer',
+			call: 'debug_unbanSnapPeer',
+			params: 1,
+		}),
 		new web3._extend.Method({
 			name: 'freezeClient',
 			call: 'debug_freezeClient',
@@ -512,6 +588,12 @@ web3._extend({
 			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputBlockNumberFormatter, null, null],
 			outputFormatter: web3._extend.utils.toDecimal
 		}),
+		new web3._extend.Method({
+			name: 'estimateGasDetailed',
+			call: 'eth_estimateGasDetailed',
+			params: 4,
+			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputBlockNumberFormatter, null, null],
+		}),
 		new web3._extend.Method({
 			name: 'submitTransaction',
 			call: 'eth_submitTransaction',
@@ -578,11 +660,21 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'feeEstimates',
+			call: 'eth_feeEstimates',
+			params: 0,
+		}),
 		new web3._extend.Method({
 			name: 'getLogs',
 			call: 'eth_getLogs',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'getLogsPage',
+			call: 'eth_getLogsPage',
+			params: 1,
+		}),
 		new web3._extend.Method({
 			name: 'call',
 			call: 'eth_call',
@@ -0,0 +1,177 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// unauthorizedMeter counts requests rejected for lack of a known credential.
+var unauthorizedMeter = metrics.NewRegisteredMeter("rpc/unauthorized", nil)
+
+// AccessRule grants a credential access to a subset of the RPC methods. Rules
+// with an API key apply to the HTTP and WebSocket endpoints, rules with a JWT
+// client ID to the authenticated endpoints. See rpc.AccessPolicy for the
+// syntax of the method patterns.
+type AccessRule struct {
+	// Name identifies the credential in logs and metrics.
+	Name string
+
+	// APIKey is the key presented by clients of the HTTP and WebSocket
+	// endpoints as a bearer token in the Authorization header.
+	APIKey string `toml:",omitempty"`
+
+	// JWTID is the value of the "id" claim of the JWT tokens presented by
+	// clients of the authenticated endpoints.
+	JWTID string `toml:",omitempty"`
+
+	// JWTWithoutID makes the rule apply to the JWT tokens of the authenticated
+	// endpoints which have no "id" claim, such as those of most consensus
+	// clients. Without such a rule, these tokens keep unrestricted access.
+	JWTWithoutID bool `toml:",omitempty"`
+
+	// Allow and Deny select the methods the credential may call. Denied
+	// methods take precedence over allowed ones.
+	Allow []string
+	Deny  []string `toml:",omitempty"`
+}
+
+// accessList resolves the credentials of requests to their access policies.
+type accessList struct {
+	keys    []accessKey
+	jwtIDs  map[string]*rpc.AccessPolicy
+	jwtNoID *rpc.AccessPolicy // policy of tokens without client ID, nil if unrestricted
+}
+
+type accessKey struct {
+	key    []byte
+	policy *rpc.AccessPolicy
+}
+
+// newAccessList validates the access rules and creates the policies of the
+// configured credentials.
+func newAccessList(rules []AccessRule) (*accessList, error) {
+	var (
+		list  = &accessList{jwtIDs: make(map[string]*rpc.AccessPolicy)}
+		names = make(map[string]bool)
+		keys  = make(map[string]bool)
+	)
+	for _, rule := range rules {
+		switch {
+		case rule.Name == "":
+			return nil, errors.New("RPC access rule without name")
+		case names[rule.Name]:
+			return nil, fmt.Errorf("duplicate RPC access rule %q", rule.Name)
+		case credentials(rule) != 1:
+			return nil, fmt.Errorf("RPC access rule %q must have exactly one of an API key, a JWT ID or JWTWithoutID", rule.Name)
+		case keys[rule.APIKey] || list.jwtIDs[rule.JWTID] != nil || (rule.JWTWithoutID && list.jwtNoID != nil):
+			return nil, fmt.Errorf("RPC access rule %q reuses the credential of another rule", rule.Name)
+		}
+		names[rule.Name] = true
+
+		policy, err := rpc.NewAccessPolicy(rule.Name, rule.Allow, rule.Deny)
+		if err != nil {
+			return nil, fmt.Errorf("RPC access rule %q: %w", rule.Name, err)
+		}
+		switch {
+		case rule.APIKey != "":
+			keys[rule.APIKey] = true
+			list.keys = append(list.keys, accessKey{[]byte(rule.APIKey), policy})
+		case rule.JWTID != "":
+			list.jwtIDs[rule.JWTID] = policy
+		default:
+			list.jwtNoID = policy
+		}
+	}
+	return list, nil
+}
+
+// credentials returns the number of credentials the rule applies to.
+func credentials(rule AccessRule) int {
+	var n int
+	for _, set := range []bool{rule.APIKey != "", rule.JWTID != "", rule.JWTWithoutID} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// keyPolicy returns the policy of the API key, or nil if the key is unknown.
+func (l *accessList) keyPolicy(key string) *rpc.AccessPolicy {
+	var policy *rpc.AccessPolicy
+	for _, k := range l.keys {
+		// Compare against all keys in constant time to not leak them.
+		if subtle.ConstantTimeCompare(k.key, []byte(key)) == 1 {
+			policy = k.policy
+		}
+	}
+	return policy
+}
+
+// accessHandler restricts the RPC methods callable by a request to those
+// permitted for its credential, rejecting requests without a known one. On the
+// authenticated endpoints, the credential is the client ID claimed by the JWT
+// token verified by the enclosing jwtHandler. Tokens without client ID are only
+// restricted if a rule with JWTWithoutID is configured. On the other endpoints,
+// the credential is the API key sent as bearer token.
+type accessHandler struct {
+	list *accessList
+	jwt  bool
+	next http.Handler
+}
+
+// newAccessHandler wraps next with access control if the list configures any
+// credential for the kind of endpoint.
+func newAccessHandler(list *accessList, jwt bool, next http.Handler) http.Handler {
+	if list == nil || (jwt && len(list.jwtIDs) == 0 && list.jwtNoID == nil) || (!jwt && len(list.keys) == 0) {
+		return next
+	}
+	return &accessHandler{list: list, jwt: jwt, next: next}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *accessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var policy *rpc.AccessPolicy
+	if h.jwt {
+		id, _ := jwtClientID(r.Context())
+		if id == "" && h.list.jwtNoID == nil {
+			h.next.ServeHTTP(w, r)
+			return
+		}
+		if id == "" {
+			policy = h.list.jwtNoID
+		} else {
+			policy = h.list.jwtIDs[id]
+		}
+	} else if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		policy = h.list.keyPolicy(strings.TrimPrefix(auth, "Bearer "))
+	}
+	if policy == nil {
+		unauthorizedMeter.Mark(1)
+		http.Error(w, "unknown credential", http.StatusUnauthorized)
+		return
+	}
+	h.next.ServeHTTP(w, r.WithContext(rpc.WithAccessPolicy(r.Context(), policy)))
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// accessCall dials the URL with the given authorization header and calls the
+// method, returning the RPC error code of a failed call, or -1 if the call
+// failed without an RPC error.
+func accessCall(t *testing.T, url, auth, method string) (int, error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var opts []rpc.ClientOption
+	if auth != "" {
+		opts = append(opts, rpc.WithHeader("Authorization", auth))
+	}
+	client, err := rpc.DialOptions(ctx, url, opts...)
+	if err != nil {
+		return -1, err
+	}
+	defer client.Close()
+
+	var result any
+	if err := client.CallContext(ctx, &result, method); err != nil {
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			return rpcErr.ErrorCode(), err
+		}
+		return -1, err
+	}
+	return 0, nil
+}
+
+// startAccessServer starts a server serving the test API over HTTP and WebSocket.
+func startAccessServer(t *testing.T, cfg rpcEndpointConfig) *httpServer {
+	t.Helper()
+
+	srv := newHTTPServer(testlog.Logger(t, log.LvlDebug), rpc.DefaultHTTPTimeouts)
+	if err := srv.enableRPC(apis(), httpConfig{rpcEndpointConfig: cfg}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.enableWS(apis(), wsConfig{Origins: []string{"*"}, rpcEndpointConfig: cfg}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.setListenAddr("localhost", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.start(); err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+func TestAccessRules(t *testing.T) {
+	_, err := newAccessList([]AccessRule{{Name: "a", APIKey: "k", JWTID: "id", Allow: []string{"eth"}}})
+	if err == nil {
+		t.Error("rule with API key and JWT ID accepted")
+	}
+	_, err = newAccessList([]AccessRule{{Name: "a", JWTID: "id", JWTWithoutID: true}})
+	if err == nil {
+		t.Error("rule with JWT ID and JWTWithoutID accepted")
+	}
+	_, err = newAccessList([]AccessRule{{Name: "a", JWTWithoutID: true}, {Name: "b", JWTWithoutID: true}})
+	if err == nil {
+		t.Error("rules sharing JWTWithoutID accepted")
+	}
+	_, err = newAccessList([]AccessRule{{Name: "a", APIKey: "k"}, {Name: "b", APIKey: "k"}})
+	if err == nil {
+		t.Error("rules sharing an API key accepted")
+	}
+	_, err = newAccessList([]AccessRule{{Name: "a", APIKey: "k"}, {Name: "a", APIKey: "l"}})
+	if err == nil {
+		t.Error("rules sharing a name accepted")
+	}
+	_, err = newAccessList([]AccessRule{{Name: "a", APIKey: "k", Allow: []string{"eth_*call"}}})
+	if err == nil {
+		t.Error("rule with invalid pattern accepted")
+	}
+}
+
+// TestAccessAPIKey checks that the HTTP and WebSocket endpoints restrict the
+// callable methods by API key.
+func TestAccessAPIKey(t *testing.T) {
+	access, err := newAccessList([]AccessRule{
+		{Name: "greeter", APIKey: "secret-1", Allow: []string{"test"}, Deny: []string{"test_sleep"}},
+		{Name: "meta", APIKey: "secret-2", Allow: []string{"rpc_*"}},
+		{Name: "engine", JWTID: "cl", Allow: []string{"*"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := rpcEndpointConfig{access: access}
+	srv := startAccessServer(t, cfg)
+	defer srv.stop()
+
+	for _, url := range []string{"http://" + srv.listenAddr(), "ws://" + srv.listenAddr()} {
+		if code, err := accessCall(t, url, "Bearer secret-1", "test_greet"); code != 0 {
+			t.Errorf("%s: allowed call failed: %v", url, err)
+		}
+		if code, err := accessCall(t, url, "Bearer secret-1", "test_sleep"); code != -32006 {
+			t.Errorf("%s: denied call returned %v", url, err)
+		}
+		if code, err := accessCall(t, url, "Bearer secret-2", "test_greet"); code != -32006 {
+			t.Errorf("%s: call outside of allowed methods returned %v", url, err)
+		}
+		if code, err := accessCall(t, url, "Bearer secret-2", "rpc_modules"); code != 0 {
+			t.Errorf("%s: allowed call failed: %v", url, err)
+		}
+		for _, auth := range []string{"", "Bearer secret", "Bearer cl", "secret-1"} {
+			if _, err := accessCall(t, url, auth, "test_greet"); err == nil || errors.As(err, new(rpc.Error)) {
+				t.Errorf("%s: call with authorization %q not rejected: %v", url, auth, err)
+			}
+		}
+	}
+}
+
+// TestAccessJWT checks that the authenticated endpoints restrict the callable
+// methods by the client ID claimed by the JWT token.
+func TestAccessJWT(t *testing.T) {
+	access, err := newAccessList([]AccessRule{
+		{Name: "engine", JWTID: "cl", Allow: []string{"test_greet"}},
+		{Name: "api", APIKey: "secret-1", Allow: []string{"*"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("secret")
+	cfg := rpcEndpointConfig{jwtSecret: secret, access: access}
+	srv := startAccessServer(t, cfg)
+	defer srv.stop()
+
+	token := func(claims testClaim) string {
+		claims["iat"] = time.Now().Unix()
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		return fmt.Sprintf("Bearer %v", signed)
+	}
+	for _, url := range []string{"http://" + srv.listenAddr(), "ws://" + srv.listenAddr()} {
+		if code, err := accessCall(t, url, token(testClaim{"id": "cl"}), "test_greet"); code != 0 {
+			t.Errorf("%s: allowed call failed: %v", url, err)
+		}
+		if code, err := accessCall(t, url, token(testClaim{"id": "cl"}), "test_sleep"); code != -32006 {
+			t.Errorf("%s: denied call returned %v", url, err)
+		}
+		// Tokens without client ID keep unrestricted access.
+		for _, claims := range []testClaim{{}, {"jti": "cl"}} {
+			if code, err := accessCall(t, url, token(claims), "test_sleep"); code != 0 {
+				t.Errorf("%s: call with claims %v failed: %v", url, claims, err)
+			}
+		}
+		if _, err := accessCall(t, url, token(testClaim{"id": "other"}), "test_greet"); err == nil || errors.As(err, new(rpc.Error)) {
+			t.Errorf("%s: call with unknown client ID not rejected: %v", url, err)
+		}
+		if _, err := accessCall(t, url, "Bearer secret-1", "test_greet"); err == nil || errors.As(err, new(rpc.Error)) {
+			t.Errorf("%s: call with API key not rejected: %v", url, err)
+		}
+	}
+}
+
+// TestAccessJWTWithoutID checks that tokens without client ID are restricted
+// by a rule with JWTWithoutID.
+func TestAccessJWTWithoutID(t *testing.T) {
+	access, err := newAccessList([]AccessRule{
+		{Name: "engine", JWTWithoutID: true, Allow: []string{"test_greet"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("secret")
+	cfg := rpcEndpointConfig{jwtSecret: secret, access: access}
+	srv := startAccessServer(t, cfg)
+	defer srv.stop()
+
+	token := func(claims testClaim) string {
+		claims["iat"] = time.Now().Unix()
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		return fmt.Sprintf("Bearer %v", signed)
+	}
+	for _, url := range []string{"http://" + srv.listenAddr(), "ws://" + srv.listenAddr()} {
+		if code, err := accessCall(t, url, token(testClaim{}), "test_greet"); code != 0 {
+			t.Errorf("%s: allowed call failed: %v", url, err)
+		}
+		if code, err := accessCall(t, url, token(testClaim{}), "test_sleep"); code != -32006 {
+			t.Errorf("%s: denied call returned %v", url, err)
+		}
+		if _, err := accessCall(t, url, token(testClaim{"id": "other"}), "test_greet"); err == nil || errors.As(err, new(rpc.Error)) {
+			t.Errorf("%s: call with unknown client ID not rejected: %v", url, err)
+		}
+	}
+}
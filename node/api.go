@@ -297,6 +297,43 @@ func (api *adminAPI) StopWS() (bool, error) {
 	return true, nil
 }
 
+// BandwidthLimits retrieves the current bandwidth limits of the p2p server, in
+// bytes per second.
+func (api *adminAPI) BandwidthLimits() (*p2p.BandwidthLimits, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	limits := server.BandwidthLimits()
+	return &limits, nil
+}
+
+// SetBandwidthLimits changes the bandwidth limits of the p2p server, in bytes
+// per second. Zero values mean unlimited.
+func (api *adminAPI) SetBandwidthLimits(limits p2p.BandwidthLimits) (bool, error) {
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	if err := server.SetBandwidthLimits(limits); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReloadAllowlist re-reads the peer allowlist file and disconnects the peers
+// which are no longer listed.
+func (api *adminAPI) ReloadAllowlist() (bool, error) {
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	if err := server.ReloadAllowlist(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // Peers retrieves all the information we know about each individual peer at the
 // protocol granularity.
 func (api *adminAPI) Peers() ([]*p2p.PeerInfo, error) {
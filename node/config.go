@@ -24,6 +24,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -137,6 +138,11 @@ type Config struct {
 	// HTTPPathPrefix specifies a path prefix on which http-rpc is to be served.
 	HTTPPathPrefix string `toml:",omitempty"`
 
+	// HTTPTLS enables TLS on the HTTP RPC interface, optionally requiring client
+	// certificates. It also applies to the websocket RPC interface if both are
+	// served on the same port.
+	HTTPTLS *TLSConfig `toml:",omitempty"`
+
 	// AuthAddr is the listening address on which authenticated APIs are provided.
 	AuthAddr string `toml:",omitempty"`
 
@@ -147,6 +153,10 @@ type Config struct {
 	// for the authenticated api. This is by default {'localhost'}.
 	AuthVirtualHosts []string `toml:",omitempty"`
 
+	// AuthTLS enables TLS on the authenticated API endpoints, optionally requiring
+	// client certificates in addition to the JWT authentication.
+	AuthTLS *TLSConfig `toml:",omitempty"`
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string
@@ -159,6 +169,11 @@ type Config struct {
 	// WSPathPrefix specifies a path prefix on which ws-rpc is to be served.
 	WSPathPrefix string `toml:",omitempty"`
 
+	// WSTLS enables TLS on the websocket RPC interface, optionally requiring client
+	// certificates. If the interface shares its port with the HTTP RPC interface,
+	// it defaults to HTTPTLS and must otherwise match it.
+	WSTLS *TLSConfig `toml:",omitempty"`
+
 	// WSOrigins is the list of domain to accept websocket requests from. Please be
 	// aware that the server can only act upon the HTTP request the client sends and
 	// cannot verify the validity of the request header.
@@ -176,6 +191,15 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// RPCAccess restricts the RPC methods callable by clients according to the
+	// credential they present. If rules with API keys are configured, the HTTP
+	// and WebSocket endpoints reject requests without a known key, and GraphQL
+	// can't be enabled. If rules with JWT client IDs are configured, the
+	// authenticated endpoints reject tokens with an unknown ID. Tokens without
+	// an ID are only restricted by a rule with JWTWithoutID. IPC is never
+	// restricted.
+	RPCAccess []AccessRule `toml:",omitempty"`
+
 	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
 	// clients. Please be aware that CORS is a browser enforced security, it's fully
 	// useless for custom HTTP clients.
@@ -204,6 +228,22 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCJobLimit is the maximum number of asynchronous jobs started through
+	// rpc_startJob which may run concurrently on an RPC endpoint. Jobs are
+	// disabled if zero.
+	RPCJobLimit int `toml:",omitempty"`
+
+	// RPCJobTimeout is the maximum duration of an asynchronous RPC job.
+	RPCJobTimeout time.Duration `toml:",omitempty"`
+
+	// RPCJobRetention is how long the outcome of a finished asynchronous RPC job
+	// remains available to the client.
+	RPCJobRetention time.Duration `toml:",omitempty"`
+
+	// RPCCacheSize is the size in megabytes of the cache holding immutable
+	// results of RPC calls, per RPC endpoint. Zero disables the cache.
+	RPCCacheSize int `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
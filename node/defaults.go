@@ -66,6 +66,9 @@ var DefaultConfig = Config{
 	WSModules:            []string{"net", "web3"},
 	BatchRequestLimit:    1000,
 	BatchResponseMaxSize: 25 * 1000 * 1000,
+	RPCJobLimit:          rpc.DefaultJobLimit,
+	RPCJobTimeout:        rpc.DefaultJobTimeout,
+	RPCJobRetention:      rpc.DefaultJobRetention,
 	GraphQLVirtualHosts:  []string{"localhost"},
 	P2P: p2p.Config{
 		ListenAddr: ":30303",
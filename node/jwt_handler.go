@@ -17,6 +17,7 @@
 package node
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"time"
@@ -26,6 +27,22 @@ import (
 
 const jwtExpiryTimeout = 60 * time.Second
 
+// jwtClaims are the claims of the tokens authenticating requests. The optional
+// "id" claim identifies the client.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	ClientID string `json:"id,omitempty"`
+}
+
+type jwtClientIDContextKey struct{}
+
+// jwtClientID returns the client ID claimed by the token which authenticated
+// the request, if any.
+func jwtClientID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(jwtClientIDContextKey{}).(string)
+	return id, ok
+}
+
 type jwtHandler struct {
 	keyFunc func(token *jwt.Token) (interface{}, error)
 	next    http.Handler
@@ -45,7 +62,7 @@ func newJWTHandler(secret []byte, next http.Handler) http.Handler {
 func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	var (
 		strToken string
-		claims   jwt.RegisteredClaims
+		claims   jwtClaims
 	)
 	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
 		strToken = strings.TrimPrefix(auth, "Bearer ")
@@ -75,6 +92,7 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	case time.Until(claims.IssuedAt.Time) > jwtExpiryTimeout:
 		http.Error(out, "future token", http.StatusUnauthorized)
 	default:
-		handler.next.ServeHTTP(out, r)
+		ctx := context.WithValue(r.Context(), jwtClientIDContextKey{}, claims.ClientID)
+		handler.next.ServeHTTP(out, r.WithContext(ctx))
 	}
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/rawdb/eradb"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/event"
@@ -105,6 +106,8 @@ func New(conf *Config) (*Node, error) {
 	}
 	server := rpc.NewServer()
 	server.SetBatchLimits(conf.BatchRequestLimit, conf.BatchResponseMaxSize)
+	server.SetJobLimits(conf.RPCJobLimit, conf.RPCJobTimeout, conf.RPCJobRetention)
+	server.SetResponseCache(conf.RPCCacheSize * 1024 * 1024)
 	node := &Node{
 		config:        conf,
 		inprocHandler: server,
@@ -390,15 +393,27 @@ func (n *Node) startRPC() error {
 		openAPIs, allAPIs = n.getAPIs()
 	)
 
+	access, err := newAccessList(n.config.RPCAccess)
+	if err != nil {
+		return err
+	}
 	rpcConfig := rpcEndpointConfig{
+		access:                 access,
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		jobLimit:               n.config.RPCJobLimit,
+		jobTimeout:             n.config.RPCJobTimeout,
+		jobRetention:           n.config.RPCJobRetention,
+		cacheSize:              n.config.RPCCacheSize * 1024 * 1024,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
 		if err := server.setListenAddr(n.config.HTTPHost, port); err != nil {
 			return err
 		}
+		if err := server.setTLS(n.config.HTTPTLS); err != nil {
+			return err
+		}
 		if err := server.enableRPC(openAPIs, httpConfig{
 			CorsAllowedOrigins: n.config.HTTPCors,
 			Vhosts:             n.config.HTTPVirtualHosts,
@@ -417,6 +432,13 @@ func (n *Node) startRPC() error {
 		if err := server.setListenAddr(n.config.WSHost, port); err != nil {
 			return err
 		}
+		tlsConfig := n.config.WSTLS
+		if tlsConfig == nil && server == n.http {
+			tlsConfig = n.config.HTTPTLS // shared with HTTP
+		}
+		if err := server.setTLS(tlsConfig); err != nil {
+			return err
+		}
 		if err := server.enableWS(openAPIs, wsConfig{
 			Modules:           n.config.WSModules,
 			Origins:           n.config.WSOrigins,
@@ -435,8 +457,12 @@ func (n *Node) startRPC() error {
 		if err := server.setListenAddr(n.config.AuthAddr, port); err != nil {
 			return err
 		}
+		if err := server.setTLS(n.config.AuthTLS); err != nil {
+			return err
+		}
 		sharedConfig := rpcEndpointConfig{
 			jwtSecret:              secret,
+			access:                 access,
 			batchItemLimit:         engineAPIBatchItemLimit,
 			batchResponseSizeLimit: engineAPIBatchResponseSizeLimit,
 			httpBodyLimit:          engineAPIBodyLimit,
@@ -458,6 +484,9 @@ func (n *Node) startRPC() error {
 		if err := server.setListenAddr(n.config.AuthAddr, port); err != nil {
 			return err
 		}
+		if err := server.setTLS(n.config.AuthTLS); err != nil {
+			return err
+		}
 		if err := server.enableWS(allAPIs, wsConfig{
 			Modules:           DefaultAuthModules,
 			Origins:           DefaultAuthOrigins,
@@ -529,6 +558,9 @@ func (n *Node) startInProc(apis []rpc.API) error {
 		if err := n.inprocHandler.RegisterName(api.Namespace, api.Service); err != nil {
 			return err
 		}
+		if err := n.inprocHandler.RegisterCacheable(api.Namespace, api.Cacheable...); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -594,6 +626,7 @@ func (n *Node) getAPIs() (unauthenticated, all []rpc.API) {
 //
 // The name of the handler is shown in a log message when the HTTP server starts
 // and should be a descriptive term for the service provided by the handler.
+// Registered handlers are not restricted by the RPC access rules of the node.
 func (n *Node) RegisterHandler(name, path string, handler http.Handler) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
@@ -666,28 +699,39 @@ func (n *Node) IPCEndpoint() string {
 // HTTPEndpoint returns the URL of the HTTP server. Note that this URL does not
 // contain the JSON-RPC path prefix set by HTTPPathPrefix.
 func (n *Node) HTTPEndpoint() string {
-	return "http://" + n.http.listenAddr()
+	return n.http.scheme("http") + "://" + n.http.listenAddr()
 }
 
 // WSEndpoint returns the current JSON-RPC over WebSocket endpoint.
 func (n *Node) WSEndpoint() string {
 	if n.http.wsAllowed() {
-		return "ws://" + n.http.listenAddr() + n.http.wsConfig.prefix
+		return n.http.scheme("ws") + "://" + n.http.listenAddr() + n.http.wsConfig.prefix
 	}
-	return "ws://" + n.ws.listenAddr() + n.ws.wsConfig.prefix
+	return n.ws.scheme("ws") + "://" + n.ws.listenAddr() + n.ws.wsConfig.prefix
 }
 
 // HTTPAuthEndpoint returns the URL of the authenticated HTTP server.
 func (n *Node) HTTPAuthEndpoint() string {
-	return "http://" + n.httpAuth.listenAddr()
+	return n.httpAuth.scheme("http") + "://" + n.httpAuth.listenAddr()
 }
 
 // WSAuthEndpoint returns the current authenticated JSON-RPC over WebSocket endpoint.
 func (n *Node) WSAuthEndpoint() string {
 	if n.httpAuth.wsAllowed() {
-		return "ws://" + n.httpAuth.listenAddr() + n.httpAuth.wsConfig.prefix
+		return n.httpAuth.scheme("ws") + "://" + n.httpAuth.listenAddr() + n.httpAuth.wsConfig.prefix
 	}
-	return "ws://" + n.wsAuth.listenAddr() + n.wsAuth.wsConfig.prefix
+	return n.wsAuth.scheme("ws") + "://" + n.wsAuth.listenAddr() + n.wsAuth.wsConfig.prefix
+}
+
+// ReloadTLS reloads the TLS credentials of all RPC endpoints serving TLS. The
+// new credentials are used for subsequent connections.
+func (n *Node) ReloadTLS() error {
+	for _, server := range []*httpServer{n.http, n.ws, n.httpAuth, n.wsAuth} {
+		if err := server.reloadTLS(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // EventMux retrieves the event multiplexer used by all the network services in
@@ -784,6 +828,11 @@ func (db *closeTrackingDB) Close() error {
 	return db.Database.Close()
 }
 
+// EraStore returns the era1 history backend of the wrapped database.
+func (db *closeTrackingDB) EraStore() *eradb.Store {
+	return rawdb.EraStore(db.Database)
+}
+
 // wrapDatabase ensures the database will be auto-closed when Node is closed.
 func (n *Node) wrapDatabase(db ethdb.Database) ethdb.Database {
 	wrapper := &closeTrackingDB{db, n}
@@ -19,6 +19,7 @@ package node
 import (
 	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -54,10 +55,15 @@ type wsConfig struct {
 }
 
 type rpcEndpointConfig struct {
-	jwtSecret              []byte // optional JWT secret
+	jwtSecret              []byte      // optional JWT secret
+	access                 *accessList // optional per-credential access control
 	batchItemLimit         int
 	batchResponseSizeLimit int
 	httpBodyLimit          int
+	jobLimit               int
+	jobTimeout             time.Duration
+	jobRetention           time.Duration
+	cacheSize              int // response cache size in bytes, zero to disable
 }
 
 type rpcHandler struct {
@@ -75,6 +81,11 @@ type httpServer struct {
 	server   *http.Server
 	listener net.Listener // non-nil when server is running
 
+	// TLS termination, configured by the endpoints served.
+	tlsConfig *TLSConfig
+	tlsSet    bool // whether an endpoint configured TLS (possibly disabled)
+	tls       *tlsReloader
+
 	// HTTP RPC handler things.
 
 	httpConfig  httpConfig
@@ -116,6 +127,58 @@ func (h *httpServer) setListenAddr(host string, port int) error {
 	return nil
 }
 
+// setTLS configures TLS termination of the server, nil disabling it. All
+// endpoints sharing the server must agree on the configuration. It can only
+// be set while the server isn't running.
+func (h *httpServer) setTLS(config *TLSConfig) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.tlsSet {
+		if (config == nil) != (h.tlsConfig == nil) || (config != nil && *config != *h.tlsConfig) {
+			return fmt.Errorf("conflicting TLS configuration for endpoints on %s", h.endpoint)
+		}
+		return nil
+	}
+	if h.listener != nil {
+		return fmt.Errorf("HTTP server already running on %s", h.endpoint)
+	}
+	if config != nil {
+		if err := config.validate(); err != nil {
+			return err
+		}
+	}
+	h.tlsConfig, h.tlsSet = config, true
+	return nil
+}
+
+// reloadTLS reloads the TLS credentials of the server if it is serving TLS.
+func (h *httpServer) reloadTLS() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.tls == nil {
+		return nil
+	}
+	return h.tls.reload()
+}
+
+// scheme returns the URL scheme of the server for the given plain protocol,
+// which is http or ws.
+func (h *httpServer) scheme(plain string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.schemeLocked(plain)
+}
+
+func (h *httpServer) schemeLocked(plain string) string {
+	if h.tlsConfig != nil {
+		return plain + "s"
+	}
+	return plain
+}
+
 // listenAddr returns the listening address of the server.
 func (h *httpServer) listenAddr() string {
 	h.mu.Lock()
@@ -146,6 +209,17 @@ func (h *httpServer) start() error {
 		h.server.IdleTimeout = h.timeouts.IdleTimeout
 	}
 
+	// Load the TLS credentials, if configured.
+	if h.tlsConfig != nil {
+		reloader, err := newTLSReloader(*h.tlsConfig, h.log)
+		if err != nil {
+			h.disableRPC()
+			h.disableWS()
+			h.clearTLS()
+			return err
+		}
+		h.tls = reloader
+	}
 	// Start the server.
 	listener, err := net.Listen("tcp", h.endpoint)
 	if err != nil {
@@ -153,13 +227,18 @@ func (h *httpServer) start() error {
 		// configuration so they can be configured another time.
 		h.disableRPC()
 		h.disableWS()
+		h.clearTLS()
 		return err
 	}
+	if h.tls != nil {
+		listener = tls.NewListener(listener, h.tls.serverConfig())
+		h.tls.start()
+	}
 	h.listener = listener
 	go h.server.Serve(listener)
 
 	if h.wsAllowed() {
-		url := fmt.Sprintf("ws://%v", listener.Addr())
+		url := fmt.Sprintf("%s://%v", h.schemeLocked("ws"), listener.Addr())
 		if h.wsConfig.prefix != "" {
 			url += h.wsConfig.prefix
 		}
@@ -171,7 +250,7 @@ func (h *httpServer) start() error {
 	}
 	// Log http endpoint.
 	h.log.Info("HTTP server started",
-		"endpoint", listener.Addr(), "auth", h.httpConfig.jwtSecret != nil,
+		"endpoint", listener.Addr(), "auth", h.httpConfig.jwtSecret != nil, "tls", h.tls != nil,
 		"prefix", h.httpConfig.prefix,
 		"cors", strings.Join(h.httpConfig.CorsAllowedOrigins, ","),
 		"vhosts", strings.Join(h.httpConfig.Vhosts, ","),
@@ -187,7 +266,7 @@ func (h *httpServer) start() error {
 	for _, path := range paths {
 		name := h.handlerNames[path]
 		if !logged[name] {
-			log.Info(name+" enabled", "url", "http://"+listener.Addr().String()+path)
+			log.Info(name+" enabled", "url", h.schemeLocked("http")+"://"+listener.Addr().String()+path)
 			logged[name] = true
 		}
 	}
@@ -195,12 +274,11 @@ func (h *httpServer) start() error {
 }
 
 func (h *httpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// check if ws request and serve if ws enabled
+	// check if ws request and serve if ws enabled. Upgrade requests to other
+	// paths fall through to the handlers registered in the mux.
 	ws := h.wsHandler.Load()
-	if ws != nil && isWebsocket(r) {
-		if checkPath(r, ws.prefix) {
-			ws.ServeHTTP(w, r)
-		}
+	if ws != nil && isWebsocket(r) && checkPath(r, ws.prefix) {
+		ws.ServeHTTP(w, r)
 		return
 	}
 
@@ -261,6 +339,7 @@ func (h *httpServer) stop() {
 
 func (h *httpServer) doStop() {
 	if h.listener == nil {
+		h.clearTLS()
 		return // not running
 	}
 
@@ -290,6 +369,16 @@ func (h *httpServer) doStop() {
 	// Clear out everything to allow re-configuring it later.
 	h.host, h.port, h.endpoint = "", 0, ""
 	h.server, h.listener = nil, nil
+	h.clearTLS()
+}
+
+// clearTLS stops the TLS credential watcher and clears the TLS configuration.
+// This is internal, the caller must hold h.mu.
+func (h *httpServer) clearTLS() {
+	if h.tls != nil {
+		h.tls.stop()
+	}
+	h.tls, h.tlsConfig, h.tlsSet = nil, nil, false
 }
 
 // enableRPC turns on JSON-RPC over HTTP on the server.
@@ -304,6 +393,8 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetJobLimits(config.jobLimit, config.jobTimeout, config.jobRetention)
+	srv.SetResponseCache(config.cacheSize)
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
@@ -312,7 +403,7 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	}
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
+		Handler: NewHTTPHandlerStack(newAccessHandler(config.access, len(config.jwtSecret) != 0, srv), config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
 		prefix:  config.prefix,
 		server:  srv,
 	})
@@ -340,6 +431,8 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetJobLimits(config.jobLimit, config.jobTimeout, config.jobRetention)
+	srv.SetResponseCache(config.cacheSize)
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
@@ -348,7 +441,7 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	}
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
-		Handler: NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret),
+		Handler: NewWSHandlerStack(newAccessHandler(config.access, len(config.jwtSecret) != 0, srv.WebsocketHandler(config.Origins)), config.jwtSecret),
 		prefix:  config.prefix,
 		server:  srv,
 	})
@@ -572,7 +665,8 @@ func (w *gzipResponseWriter) close() {
 
 func newGzipHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		// Connection upgrades need the original writer to hijack the connection.
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") || isWebsocket(r) {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -646,6 +740,9 @@ func RegisterApis(apis []rpc.API, modules []string, srv *rpc.Server) error {
 			if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
 				return err
 			}
+			if err := srv.RegisterCacheable(api.Namespace, api.Cacheable...); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -0,0 +1,211 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// tlsReloadInterval is the interval at which the certificate files of TLS
+// endpoints are checked for modifications.
+const tlsReloadInterval = 10 * time.Second
+
+// TLSConfig configures TLS termination for an RPC endpoint.
+type TLSConfig struct {
+	// CertFile and KeyFile are the paths of the PEM encoded certificate chain
+	// and private key served by the endpoint.
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile is the path of a PEM encoded bundle of certificate authorities.
+	// If set, clients are required to present a certificate signed by one of
+	// them (mutual TLS).
+	ClientCAFile string `toml:",omitempty"`
+}
+
+// validate checks that the configuration is complete.
+func (c *TLSConfig) validate() error {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("TLS requires both a certificate and a key file")
+	}
+	return nil
+}
+
+// files returns the paths of all files the configuration is loaded from.
+func (c *TLSConfig) files() []string {
+	files := []string{c.CertFile, c.KeyFile}
+	if c.ClientCAFile != "" {
+		files = append(files, c.ClientCAFile)
+	}
+	return files
+}
+
+// tlsReloader holds the TLS credentials of an endpoint, which can be replaced
+// while the endpoint is serving. New credentials take effect for subsequent
+// connections; established ones are not affected.
+type tlsReloader struct {
+	config TLSConfig
+	log    log.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  []time.Time // modification times of the loaded files
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newTLSReloader loads the credentials of the given configuration.
+func newTLSReloader(config TLSConfig, log log.Logger) (*tlsReloader, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	r := &tlsReloader{config: config, log: log}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the credentials from disk, replacing the current ones. If the
+// files cannot be loaded, the current credentials remain in use.
+func (r *tlsReloader) reload() error {
+	// Record the modification times first, so that files changing during the
+	// reload are picked up again by the watcher.
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS client CAs: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in TLS client CA file %s", r.config.ClientCAFile)
+		}
+	}
+	r.mu.Lock()
+	r.cert, r.clientCAs, r.modTimes = &cert, clientCAs, modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// stat returns the modification times of the credential files.
+func (r *tlsReloader) stat() ([]time.Time, error) {
+	files := r.config.files()
+	times := make([]time.Time, len(files))
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		times[i] = info.ModTime()
+	}
+	return times, nil
+}
+
+// modified reports whether any credential file changed since it was loaded.
+func (r *tlsReloader) modified() bool {
+	times, err := r.stat()
+	if err != nil {
+		return false // files are being replaced, try again later
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := range times {
+		if !times[i].Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// start begins watching the credential files, reloading them upon modification.
+func (r *tlsReloader) start() {
+	r.quit = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(tlsReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if !r.modified() {
+					continue
+				}
+				if err := r.reload(); err != nil {
+					r.log.Warn("Failed to reload TLS credentials", "err", err)
+				} else {
+					r.log.Info("Reloaded TLS credentials", "cert", r.config.CertFile)
+				}
+			case <-r.quit:
+				return
+			}
+		}
+	}()
+}
+
+// stop terminates the watcher.
+func (r *tlsReloader) stop() {
+	if r.quit != nil {
+		close(r.quit)
+		r.wg.Wait()
+		r.quit = nil
+	}
+}
+
+// serverConfig returns a TLS server configuration serving the most recently
+// loaded credentials.
+func (r *tlsReloader) serverConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"http/1.1"},
+			}
+			if r.clientCAs != nil {
+				config.ClientCAs = r.clientCAs
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}
+}
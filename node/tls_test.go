@@ -0,0 +1,235 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testCert is a certificate along with its key for TLS tests.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCert creates a certificate signed by parent, or a self-signed CA
+// certificate if parent is nil.
+func newTestCert(t *testing.T, name string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"localhost"},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// write stores the certificate and key PEM encoded in the given files.
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der})
+	if err := os.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile == "" {
+		return
+	}
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tlsCertificate returns the certificate for use in a TLS configuration.
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+// TestTLSClientCertificate checks that an endpoint configured with a client CA
+// only accepts clients presenting a certificate signed by it.
+func TestTLSClientCertificate(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		ca     = newTestCert(t, "ca", nil, x509.ExtKeyUsageAny)
+		server = newTestCert(t, "server", ca, x509.ExtKeyUsageServerAuth)
+		client = newTestCert(t, "client", ca, x509.ExtKeyUsageClientAuth)
+		rogue  = newTestCert(t, "client", newTestCert(t, "rogue", nil, x509.ExtKeyUsageAny), x509.ExtKeyUsageClientAuth)
+		config = &TLSConfig{
+			CertFile:     filepath.Join(dir, "server.crt"),
+			KeyFile:      filepath.Join(dir, "server.key"),
+			ClientCAFile: filepath.Join(dir, "ca.crt"),
+		}
+	)
+	server.write(t, config.CertFile, config.KeyFile)
+	ca.write(t, config.ClientCAFile, "")
+
+	srv := newHTTPServer(testlog.Logger(t, log.LvlDebug), rpc.DefaultHTTPTimeouts)
+	if err := srv.enableRPC(apis(), httpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.enableWS(apis(), wsConfig{Origins: []string{"*"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.setTLS(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.setListenAddr("127.0.0.1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	call := func(url string, opts ...rpc.ClientOption) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		opts = append([]rpc.ClientOption{rpc.WithTLSConfig(&tls.Config{RootCAs: roots})}, opts...)
+		c, err := rpc.DialOptions(ctx, url, opts...)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+
+		var result string
+		return c.CallContext(ctx, &result, "test_greet")
+	}
+	for _, url := range []string{srv.scheme("http") + "://" + srv.listenAddr(), srv.scheme("ws") + "://" + srv.listenAddr()} {
+		if err := call(url, rpc.WithClientCertificate(client.tlsCertificate())); err != nil {
+			t.Errorf("%s: call with client certificate failed: %v", url, err)
+		}
+		if err := call(url); err == nil {
+			t.Errorf("%s: call without client certificate succeeded", url)
+		}
+		if err := call(url, rpc.WithClientCertificate(rogue.tlsCertificate())); err == nil {
+			t.Errorf("%s: call with untrusted client certificate succeeded", url)
+		}
+	}
+}
+
+// TestTLSReload checks that replaced credentials are served after a reload.
+func TestTLSReload(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		ca     = newTestCert(t, "ca", nil, x509.ExtKeyUsageAny)
+		first  = newTestCert(t, "first", ca, x509.ExtKeyUsageServerAuth)
+		second = newTestCert(t, "second", ca, x509.ExtKeyUsageServerAuth)
+		config = &TLSConfig{
+			CertFile: filepath.Join(dir, "server.crt"),
+			KeyFile:  filepath.Join(dir, "server.key"),
+		}
+	)
+	first.write(t, config.CertFile, config.KeyFile)
+
+	srv := newHTTPServer(testlog.Logger(t, log.LvlDebug), rpc.DefaultHTTPTimeouts)
+	if err := srv.enableRPC(apis(), httpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.setTLS(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.setListenAddr("127.0.0.1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	served := func() string {
+		conn, err := tls.Dial("tcp", srv.listenAddr(), &tls.Config{RootCAs: roots, ServerName: "localhost"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+	}
+	if name := served(); name != "first" {
+		t.Fatalf("wrong certificate served: %q", name)
+	}
+	second.write(t, config.CertFile, config.KeyFile)
+	if err := srv.reloadTLS(); err != nil {
+		t.Fatal(err)
+	}
+	if name := served(); name != "second" {
+		t.Fatalf("wrong certificate served after reload: %q", name)
+	}
+
+	// Broken credentials must not replace the loaded ones.
+	if err := os.WriteFile(config.KeyFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.reloadTLS(); err == nil {
+		t.Fatal("reload of broken credentials succeeded")
+	}
+	if name := served(); name != "second" {
+		t.Fatalf("wrong certificate served after failed reload: %q", name)
+	}
+}
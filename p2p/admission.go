@@ -0,0 +1,228 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	errNotAdmitted    = errors.New("not admitted")
+	errNotAllowlisted = errors.New("node not in allowlist")
+	errNoAllowlist    = errors.New("no allowlist configured")
+	errUnsignedRecord = errors.New("no signed record")
+)
+
+// AdmissionPolicy decides whether a remote node may be connected to. The policy
+// is consulted for inbound and dialed connections right after the encryption
+// handshake, when the identity of the remote node is established, and before
+// dialing discovered nodes.
+//
+// The node passed to the policy is the most recent record known about the node.
+// For dialed nodes this is the dialed record. For inbound connections it is the
+// record learned through discovery or during earlier connections. If no record
+// is known, the server looks the node up through discovery v5 when it's running.
+// If that fails too, the policy gets a bare, unsigned record containing only the
+// identity and endpoint of the connection.
+type AdmissionPolicy interface {
+	// Admit returns an error if the node must not be connected.
+	Admit(n *enode.Node) error
+}
+
+// AdmissionFunc is an adapter to allow the use of ordinary functions as
+// admission policies.
+type AdmissionFunc func(n *enode.Node) error
+
+// Admit implements AdmissionPolicy.
+func (f AdmissionFunc) Admit(n *enode.Node) error {
+	return f(n)
+}
+
+// ENRPredicate returns an admission policy accepting the nodes with a record
+// containing the given key, whose raw RLP encoded value passes the check. It
+// can be used to admit nodes by an attestation signed into a custom entry.
+//
+// The check is only evaluated on signed records. Nodes whose signed record is
+// not known, i.e. inbound connections from nodes that were never seen through
+// discovery and cannot be found by a lookup, are rejected.
+func ENRPredicate(key string, check func(n *enode.Node, value rlp.RawValue) error) AdmissionPolicy {
+	return AdmissionFunc(func(n *enode.Node) error {
+		if !signedRecord(n) {
+			return errUnsignedRecord
+		}
+		var value rlp.RawValue
+		if err := n.Load(enr.WithEntry(key, &value)); err != nil {
+			return fmt.Errorf("missing ENR entry %q", key)
+		}
+		return check(n, value)
+	})
+}
+
+// Allowlist is an admission policy accepting only the nodes listed in a file.
+// The file contains one node per line, either as a hex node ID or as an enode
+// or ENR URL. Empty lines and lines starting with '#' are ignored.
+type Allowlist struct {
+	path string
+	lock sync.RWMutex
+	ids  map[enode.ID]struct{}
+}
+
+// NewAllowlist loads an allowlist from the given file.
+func NewAllowlist(path string) (*Allowlist, error) {
+	l := &Allowlist{path: path}
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Reload re-reads the allowlist file. If the file cannot be parsed, the previous
+// list is kept in effect.
+func (l *Allowlist) Reload() error {
+	ids, err := loadAllowlist(l.path)
+	if err != nil {
+		return err
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.ids = ids
+	return nil
+}
+
+// loadAllowlist parses the node IDs listed in an allowlist file.
+func loadAllowlist(path string) (map[enode.ID]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		ids     = make(map[enode.ID]struct{})
+		scanner = bufio.NewScanner(file)
+	)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		var id enode.ID
+		if strings.HasPrefix(entry, "enode://") || strings.HasPrefix(entry, "enr:") {
+			n, err := enode.Parse(enode.ValidSchemes, entry)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+			}
+			id = n.ID()
+		} else if id, err = enode.ParseID(entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid node ID: %v", path, line, err)
+		}
+		ids[id] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Admit implements AdmissionPolicy.
+func (l *Allowlist) Admit(n *enode.Node) error {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if _, ok := l.ids[n.ID()]; !ok {
+		return errNotAllowlisted
+	}
+	return nil
+}
+
+// Len returns the number of nodes in the allowlist.
+func (l *Allowlist) Len() int {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return len(l.ids)
+}
+
+// signedRecord reports whether the record of the node carries a signature, as
+// opposed to the bare records created for the endpoint of a connection.
+func signedRecord(n *enode.Node) bool {
+	return len(n.Record().Signature()) > 0
+}
+
+// admit checks a node against the allowlist and the admission policy of the
+// server.
+func (srv *Server) admit(n *enode.Node) error {
+	if srv.allowlist == nil && srv.AdmissionPolicy == nil {
+		return nil
+	}
+	// Evaluate the policies on the most recent record known about the node,
+	// which may carry more entries than the dialed or connecting one. A signed
+	// record always replaces the bare record of a connection.
+	if srv.nodedb != nil {
+		if known := srv.nodedb.Node(n.ID()); known != nil && (known.Seq() > n.Seq() || !signedRecord(n) && signedRecord(known)) {
+			n = known
+		}
+	}
+	// Look up the signed record of unknown nodes, the policy may need entries
+	// which are not in the bare record of the connection.
+	if srv.AdmissionPolicy != nil && !signedRecord(n) && srv.discv5 != nil {
+		if resolved := srv.discv5.ResolveNodeId(n.ID()); resolved != nil && signedRecord(resolved) {
+			n = resolved
+		}
+	}
+	if srv.allowlist != nil {
+		if err := srv.allowlist.Admit(n); err != nil {
+			return fmt.Errorf("%w: %v", errNotAdmitted, err)
+		}
+	}
+	if srv.AdmissionPolicy != nil {
+		if err := srv.AdmissionPolicy.Admit(n); err != nil {
+			return fmt.Errorf("%w: %v", errNotAdmitted, err)
+		}
+	}
+	return nil
+}
+
+// ReloadAllowlist re-reads the allowlist file of the server and disconnects
+// the peers which are no longer admitted.
+func (srv *Server) ReloadAllowlist() error {
+	if srv.allowlist == nil {
+		return errNoAllowlist
+	}
+	if err := srv.allowlist.Reload(); err != nil {
+		return err
+	}
+	srv.log.Info("Reloaded peer allowlist", "nodes", srv.allowlist.Len())
+
+	for _, p := range srv.Peers() {
+		if err := srv.admit(p.Node()); err != nil {
+			p.log.Debug("Dropping peer no longer admitted", "err", err)
+			p.Disconnect(DiscUselessPeer)
+		}
+	}
+	return nil
+}
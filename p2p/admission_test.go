@@ -0,0 +1,229 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestAllowlist(t *testing.T) {
+	var (
+		path  = filepath.Join(t.TempDir(), "allowlist")
+		node1 = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303)
+		node2 = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30304, 30304)
+		node3 = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30305, 30305)
+	)
+	content := "# consortium members\n" + node1.ID().String() + "\n\n  " + node2.URLv4() + "  \n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	list, err := NewAllowlist(path)
+	if err != nil {
+		t.Fatalf("failed to load allowlist: %v", err)
+	}
+	check := func(n *enode.Node, want bool) {
+		t.Helper()
+		if err := list.Admit(n); (err == nil) != want {
+			t.Errorf("node %v: admission mismatch: err %v, want admitted %v", n.ID(), err, want)
+		}
+	}
+	check(node1, true)
+	check(node2, true)
+	check(node3, false)
+
+	// Check that an invalid file is rejected, keeping the previous list.
+	if err := os.WriteFile(path, []byte("invalid\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Reload(); err == nil {
+		t.Fatal("invalid allowlist accepted")
+	}
+	check(node1, true)
+
+	// Check that reloading a valid file replaces the list.
+	if err := os.WriteFile(path, []byte(node3.ID().String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := list.Reload(); err != nil {
+		t.Fatalf("failed to reload allowlist: %v", err)
+	}
+	check(node1, false)
+	check(node3, true)
+}
+
+func TestENRPredicate(t *testing.T) {
+	makeNode := func(entries ...enr.Entry) *enode.Node {
+		var r enr.Record
+		for _, e := range entries {
+			r.Set(e)
+		}
+		if err := enode.SignV4(&r, newkey()); err != nil {
+			t.Fatal(err)
+		}
+		n, err := enode.New(enode.ValidSchemes, &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	policy := ENRPredicate("org", func(n *enode.Node, value rlp.RawValue) error {
+		var org string
+		if err := rlp.DecodeBytes(value, &org); err != nil {
+			return err
+		}
+		if org != "acme" {
+			return errors.New("unknown organization")
+		}
+		return nil
+	})
+	if err := policy.Admit(makeNode(enr.WithEntry("org", "acme"))); err != nil {
+		t.Errorf("member rejected: %v", err)
+	}
+	if err := policy.Admit(makeNode(enr.WithEntry("org", "evil"))); err == nil {
+		t.Error("foreign node admitted")
+	}
+	if err := policy.Admit(makeNode()); err == nil {
+		t.Error("node without entry admitted")
+	}
+	// The predicate is not evaluated on the bare records of inbound connections.
+	bare := enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303)
+	if err := policy.Admit(bare); !errors.Is(err, errUnsignedRecord) {
+		t.Errorf("wrong error for unsigned record: %v", err)
+	}
+}
+
+// Tests that ENR predicates are evaluated on the signed record of an inbound
+// node if it's known, and reject the node otherwise.
+func TestServerAdmissionENR(t *testing.T) {
+	var (
+		srvkey    = newkey()
+		memberkey = newkey()
+	)
+	cfg := Config{
+		PrivateKey:  srvkey,
+		MaxPeers:    10,
+		NoDial:      true,
+		NoDiscovery: true,
+		Protocols:   []Protocol{discard},
+		Logger:      testlog.Logger(t, log.LvlTrace),
+		AdmissionPolicy: ENRPredicate("org", func(n *enode.Node, value rlp.RawValue) error {
+			var org string
+			return rlp.DecodeBytes(value, &org)
+		}),
+	}
+	var tt *setupTransport
+	srv := &Server{
+		Config:       cfg,
+		newTransport: func(fd net.Conn, dialDest *ecdsa.PublicKey) transport { return tt },
+		log:          cfg.Logger,
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("couldn't start server: %v", err)
+	}
+	defer srv.Stop()
+
+	connect := func() error {
+		pubkey := &memberkey.PublicKey
+		tt = &setupTransport{pubkey: pubkey, phs: protoHandshake{ID: crypto.FromECDSAPub(pubkey)[1:]}}
+		p1, _ := net.Pipe()
+		srv.SetupConn(p1, inboundConn, nil)
+		return tt.closeErr
+	}
+	// Without a known record, the node is rejected.
+	if err := connect(); !errors.Is(err, errNotAdmitted) {
+		t.Fatalf("unknown node: wrong error %v", err)
+	}
+	// Once the signed record is known, e.g. from discovery, it is evaluated.
+	var r enr.Record
+	r.Set(enr.WithEntry("org", "acme"))
+	if err := enode.SignV4(&r, memberkey); err != nil {
+		t.Fatal(err)
+	}
+	n, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.nodedb.UpdateNode(n)
+	if err := connect(); errors.Is(err, errNotAdmitted) {
+		t.Fatalf("known node rejected: %v", err)
+	}
+}
+
+// Tests that the server rejects connections from nodes not admitted by the
+// allowlist right after the encryption handshake.
+func TestServerAdmission(t *testing.T) {
+	var (
+		srvkey  = newkey()
+		member  = &newkey().PublicKey
+		foreign = &newkey().PublicKey
+		path    = filepath.Join(t.TempDir(), "allowlist")
+	)
+	if err := os.WriteFile(path, []byte(enode.PubkeyToIDV4(member).String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pubkey    *ecdsa.PublicKey
+		wantCalls string
+		wantErr   error
+	}{
+		{foreign, "doEncHandshake,close,", errNotAdmitted},
+		{member, "doEncHandshake,doProtoHandshake,close,", DiscUselessPeer},
+	}
+	cfg := Config{
+		PrivateKey:  srvkey,
+		MaxPeers:    10,
+		NoDial:      true,
+		NoDiscovery: true,
+		Allowlist:   path,
+		Protocols:   []Protocol{discard},
+		Logger:      testlog.Logger(t, log.LvlTrace),
+	}
+	var tt *setupTransport
+	srv := &Server{
+		Config:       cfg,
+		newTransport: func(fd net.Conn, dialDest *ecdsa.PublicKey) transport { return tt },
+		log:          cfg.Logger,
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("couldn't start server: %v", err)
+	}
+	defer srv.Stop()
+
+	for i, test := range tests {
+		tt = &setupTransport{pubkey: test.pubkey, phs: protoHandshake{ID: crypto.FromECDSAPub(test.pubkey)[1:]}}
+		p1, _ := net.Pipe()
+		srv.SetupConn(p1, inboundConn, nil)
+		if !errors.Is(tt.closeErr, test.wantErr) {
+			t.Errorf("test %d: close error mismatch: got %q, want %q", i, tt.closeErr, test.wantErr)
+		}
+		if tt.calls != test.wantCalls {
+			t.Errorf("test %d: calls mismatch: got %q, want %q", i, tt.calls, test.wantCalls)
+		}
+	}
+}
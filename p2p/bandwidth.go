@@ -0,0 +1,327 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Priority is the traffic class of a protocol message. When the bandwidth is
+// capped, messages of higher priority are sent and processed first.
+type Priority uint8
+
+const (
+	PriorityHigh   Priority = iota // Latency critical traffic, e.g. block propagation
+	PriorityNormal                 // Request/response traffic, e.g. serving historical data
+	PriorityLow                    // Bulk gossip, e.g. transaction propagation
+
+	numPriorities
+)
+
+// BandwidthLimits configures the bandwidth caps of the server, in bytes per
+// second of wire traffic. Zero values mean unlimited.
+type BandwidthLimits struct {
+	Ingress     int `json:"ingress"`     // Total incoming traffic of all peers
+	Egress      int `json:"egress"`      // Total outgoing traffic of all peers
+	PeerIngress int `json:"peerIngress"` // Incoming traffic of a single peer
+	PeerEgress  int `json:"peerEgress"`  // Outgoing traffic of a single peer
+}
+
+// enabled reports whether any of the limits is set.
+func (l BandwidthLimits) enabled() bool {
+	return l.Ingress > 0 || l.Egress > 0 || l.PeerIngress > 0 || l.PeerEgress > 0
+}
+
+const (
+	// maxThrottleWait is the maximum time a throttled caller sleeps before
+	// re-checking the limiter, so that rate changes and newly arrived higher
+	// priority traffic are taken into account.
+	maxThrottleWait = 100 * time.Millisecond
+
+	// minThrottleWait is the minimum time a throttled caller sleeps, to avoid
+	// spinning on tiny token deficits.
+	minThrottleWait = time.Millisecond
+
+	// frameOverhead is the approximate number of bytes added by the transport
+	// framing to a message, used for reserving its egress bandwidth.
+	frameOverhead = 64
+
+	// ingressQueueSize is the number of received messages of a protocol which
+	// can wait for ingress bandwidth per priority, before reading from the
+	// connection stops.
+	ingressQueueSize = 4
+)
+
+var errThrottleClosed = errors.New("throttled connection closed")
+
+// rateLimiter is a token bucket capping traffic to a configured rate. The bucket
+// holds at most one second worth of traffic. Messages larger than the available
+// tokens are let through as long as the bucket is not in debt, delaying the
+// following ones until the debt is paid off.
+//
+// Callers waiting for tokens are served in priority order: a caller proceeds
+// only if no caller of higher priority is waiting.
+type rateLimiter struct {
+	lock    sync.Mutex
+	rate    float64            // Allowed traffic in bytes per second, zero if unlimited
+	tokens  float64            // Available bytes, negative if in debt
+	updated time.Time          // Time of the last token refill
+	waiting [numPriorities]int // Number of waiting callers per priority
+}
+
+func newRateLimiter(rate int) *rateLimiter {
+	l := &rateLimiter{updated: time.Now()}
+	l.setRate(rate)
+	return l
+}
+
+// setRate changes the rate of the limiter. Zero disables the limit.
+func (l *rateLimiter) setRate(rate int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.refill()
+	old := l.rate
+	l.rate = float64(max(rate, 0))
+
+	// Start with a full bucket when enabling the limit, and drop the excess
+	// tokens when lowering it.
+	if old == 0 || l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+}
+
+// refill adds the tokens accumulated since the last update. The lock must be
+// held by the caller.
+func (l *rateLimiter) refill() {
+	now := time.Now()
+	if elapsed := now.Sub(l.updated); elapsed > 0 {
+		l.tokens = min(l.rate, l.tokens+l.rate*elapsed.Seconds())
+		l.updated = now
+	}
+}
+
+// ready reports whether a caller of the given priority may proceed. The lock
+// must be held by the caller.
+func (l *rateLimiter) ready(prio Priority) bool {
+	if l.rate == 0 {
+		return true
+	}
+	if l.tokens < 0 {
+		return false
+	}
+	for p := Priority(0); p < prio; p++ {
+		if l.waiting[p] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// wait blocks until traffic of the given priority may pass the limiter, or the
+// closed channel fires.
+func (l *rateLimiter) wait(prio Priority, closed <-chan struct{}) error {
+	var queued bool
+	for {
+		l.lock.Lock()
+		l.refill()
+		if l.ready(prio) {
+			if queued {
+				l.waiting[prio]--
+			}
+			l.lock.Unlock()
+			return nil
+		}
+		if !queued {
+			l.waiting[prio]++
+			queued = true
+		}
+		delay := minThrottleWait
+		if l.tokens < 0 {
+			delay = max(delay, time.Duration(-l.tokens/l.rate*float64(time.Second)))
+		}
+		l.lock.Unlock()
+
+		timer := time.NewTimer(min(delay, maxThrottleWait))
+		select {
+		case <-timer.C:
+		case <-closed:
+			timer.Stop()
+
+			l.lock.Lock()
+			l.waiting[prio]--
+			l.lock.Unlock()
+			return errThrottleClosed
+		}
+	}
+}
+
+// consume charges the given amount of traffic to the limiter. Negative amounts
+// return previously charged traffic.
+func (l *rateLimiter) consume(n int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.rate == 0 {
+		return
+	}
+	l.refill()
+	l.tokens -= float64(n)
+}
+
+// throttle applies the per-peer and global bandwidth limits to the traffic of
+// a single connection.
+type throttle struct {
+	ingress  [2]*rateLimiter            // Peer and global ingress limiters
+	egress   [2]*rateLimiter            // Peer and global egress limiters
+	priority func(code uint64) Priority // Classifier of the messages by their wire code
+	closed   <-chan struct{}            // Aborts all waits when closed
+}
+
+// newThrottle creates the limiter of a connection. The waits on it are aborted
+// once the given channel is closed.
+func newThrottle(limits BandwidthLimits, global [2]*rateLimiter, priority func(uint64) Priority, closed <-chan struct{}) *throttle {
+	return &throttle{
+		ingress:  [2]*rateLimiter{newRateLimiter(limits.PeerIngress), global[0]},
+		egress:   [2]*rateLimiter{newRateLimiter(limits.PeerEgress), global[1]},
+		priority: priority,
+		closed:   closed,
+	}
+}
+
+// setLimits updates the per-peer limits of the connection.
+func (t *throttle) setLimits(limits BandwidthLimits) {
+	t.ingress[0].setRate(limits.PeerIngress)
+	t.egress[0].setRate(limits.PeerEgress)
+}
+
+// waitIngress delays the processing of a received message until it fits into
+// the ingress limits, then charges its wire size.
+func (t *throttle) waitIngress(code uint64, size int) error {
+	return t.pass(t.ingress, code, size)
+}
+
+// waitEgress delays the sending of a message until it fits into the egress
+// limits, then reserves the given estimate of its wire size. The estimate must
+// be corrected by settleEgress once the message is sent.
+func (t *throttle) waitEgress(code uint64, size int) error {
+	return t.pass(t.egress, code, size)
+}
+
+// settleEgress corrects the bandwidth reserved for a sent message to its actual
+// wire size.
+func (t *throttle) settleEgress(msg Msg, size int) {
+	if msg.Code < baseProtocolLength {
+		return
+	}
+	for _, l := range t.egress {
+		l.consume(size - egressEstimate(msg))
+	}
+}
+
+// egressEstimate is the wire size of a message reserved by waitEgress before
+// it is sent.
+func egressEstimate(msg Msg) int {
+	return int(msg.Size) + frameOverhead
+}
+
+// pass waits for both limiters of a direction, charging the given size. Base
+// protocol messages are never throttled, so that pings are not delayed into a
+// timeout on a saturated link.
+func (t *throttle) pass(limiters [2]*rateLimiter, code uint64, size int) error {
+	if code < baseProtocolLength {
+		return nil
+	}
+	prio := t.priority(code)
+	for _, l := range limiters {
+		if err := l.wait(prio, t.closed); err != nil {
+			return err
+		}
+	}
+	for _, l := range limiters {
+		l.consume(size)
+	}
+	return nil
+}
+
+// ingressOrder keeps the delivery order of the received messages of a protocol
+// on a throttled connection. A message may overtake the messages of lower
+// priority waiting for bandwidth, but never an earlier message of equal or
+// higher priority. This keeps e.g. a handshake message ahead of the messages
+// which follow it.
+type ingressOrder struct {
+	lock    sync.Mutex
+	next    uint64                  // Sequence number of the next received message
+	pending [numPriorities][]uint64 // Sequence numbers of the undelivered messages
+	changed chan struct{}           // Closed when a message is delivered
+}
+
+func newIngressOrder() *ingressOrder {
+	return &ingressOrder{changed: make(chan struct{})}
+}
+
+// add records the arrival of a message of the given priority.
+func (o *ingressOrder) add(prio Priority) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.pending[prio] = append(o.pending[prio], o.next)
+	o.next++
+}
+
+// wait blocks until the oldest undelivered message of the given priority may be
+// delivered, or the closed channel fires.
+func (o *ingressOrder) wait(prio Priority, closed <-chan struct{}) bool {
+	for {
+		o.lock.Lock()
+		ready, changed := o.ready(prio), o.changed
+		o.lock.Unlock()
+		if ready {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-closed:
+			return false
+		}
+	}
+}
+
+// ready reports whether no message of higher priority was received before the
+// oldest undelivered message of the given priority. The lock must be held by
+// the caller.
+func (o *ingressOrder) ready(prio Priority) bool {
+	seq := o.pending[prio][0]
+	for p := Priority(0); p < prio; p++ {
+		if len(o.pending[p]) > 0 && o.pending[p][0] < seq {
+			return false
+		}
+	}
+	return true
+}
+
+// done records the delivery of the oldest message of the given priority.
+func (o *ingressOrder) done(prio Priority) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.pending[prio] = o.pending[prio][1:]
+	close(o.changed)
+	o.changed = make(chan struct{})
+}
@@ -0,0 +1,302 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Tests that the rate limiter caps the throughput to the configured rate.
+func TestRateLimiterRate(t *testing.T) {
+	const rate = 100_000
+	l := newRateLimiter(rate)
+
+	// The bucket starts full, so the first second worth of traffic passes
+	// immediately, the rest is delayed.
+	start := time.Now()
+	for sent := 0; sent < rate*3/2; sent += 10_000 {
+		if err := l.wait(PriorityNormal, nil); err != nil {
+			t.Fatalf("wait failed: %v", err)
+		}
+		l.consume(10_000)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Fatalf("wrong throttling delay: %v", elapsed)
+	}
+	// Check that lifting the limit unblocks the traffic.
+	l.consume(rate)
+	l.setRate(0)
+
+	start = time.Now()
+	if err := l.wait(PriorityNormal, nil); err != nil {
+		t.Fatalf("wait failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("unlimited traffic delayed: %v", elapsed)
+	}
+}
+
+// Tests that waiting traffic of higher priority passes the limiter first.
+func TestRateLimiterPriority(t *testing.T) {
+	l := newRateLimiter(1000)
+	l.consume(1200) // put the bucket into debt
+
+	var (
+		lock  sync.Mutex
+		order []Priority
+		wg    sync.WaitGroup
+	)
+	pass := func(prio Priority) {
+		defer wg.Done()
+		if err := l.wait(prio, nil); err != nil {
+			t.Errorf("wait failed: %v", err)
+			return
+		}
+		l.consume(200)
+
+		lock.Lock()
+		order = append(order, prio)
+		lock.Unlock()
+	}
+	wg.Add(3)
+	go pass(PriorityLow)
+	time.Sleep(20 * time.Millisecond)
+	go pass(PriorityNormal)
+	time.Sleep(20 * time.Millisecond)
+	go pass(PriorityHigh)
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != PriorityHigh || order[1] != PriorityNormal || order[2] != PriorityLow {
+		t.Fatalf("wrong pass order: %v", order)
+	}
+}
+
+// Tests that closing a throttle aborts the waits on it, and that base protocol
+// messages are never throttled.
+func TestThrottleClose(t *testing.T) {
+	global := [2]*rateLimiter{newRateLimiter(0), newRateLimiter(0)}
+	closed := make(chan struct{})
+	th := newThrottle(BandwidthLimits{PeerEgress: 1000}, global, func(uint64) Priority { return PriorityNormal }, closed)
+	if err := th.waitEgress(baseProtocolLength, 5000); err != nil {
+		t.Fatalf("first message throttled: %v", err)
+	}
+	if err := th.waitEgress(pingMsg, 5000); err != nil {
+		t.Fatalf("base protocol message throttled: %v", err)
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- th.waitEgress(baseProtocolLength, 0) }()
+
+	select {
+	case err := <-errc:
+		t.Fatalf("throttled message passed: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(closed)
+	select {
+	case err := <-errc:
+		if err != errThrottleClosed {
+			t.Fatalf("wrong error: have %v, want %v", err, errThrottleClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wait not aborted by close")
+	}
+}
+
+// Tests that peers are only throttled while a bandwidth limit is set, and that
+// setting a limit at runtime throttles the connected peers.
+func TestServerBandwidthLimits(t *testing.T) {
+	newServer := func() *Server {
+		srv := &Server{Config: Config{
+			PrivateKey:  newkey(),
+			ListenAddr:  "127.0.0.1:0",
+			MaxPeers:    10,
+			NoDiscovery: true,
+			Protocols:   []Protocol{discard},
+			Logger:      testlog.Logger(t, log.LvlTrace),
+		}}
+		if err := srv.Start(); err != nil {
+			t.Fatal("can't start:", err)
+		}
+		return srv
+	}
+	srv1, srv2 := newServer(), newServer()
+	defer srv1.Stop()
+	defer srv2.Stop()
+
+	if !syncAddPeer(srv1, srv2.Self()) {
+		t.Fatal("peer not connected")
+	}
+	var peer *Peer
+	srv1.doPeerOp(func(peers map[enode.ID]*Peer) { peer = peers[srv2.Self().ID()] })
+	if peer.throttle.Load() != nil {
+		t.Fatal("peer throttled without bandwidth limits")
+	}
+	if proto := peer.running[discard.Name]; proto.order != nil || proto.queued[0] != nil {
+		t.Fatal("ingress queues created without bandwidth limits")
+	}
+
+	if err := srv1.SetBandwidthLimits(BandwidthLimits{PeerEgress: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	if peer.throttle.Load() == nil {
+		t.Fatal("peer not throttled after setting a limit")
+	}
+	if proto := peer.running[discard.Name]; proto.order == nil || proto.queued[0] == nil {
+		t.Fatal("ingress queues not created after setting a limit")
+	}
+	srv1.bwLock.Lock()
+	throttles := len(srv1.throttles)
+	srv1.bwLock.Unlock()
+	if throttles != 1 {
+		t.Fatalf("wrong number of throttles: have %d, want 1", throttles)
+	}
+}
+
+// priorityTestProtocol is a protocol with a high priority message (code 0) and
+// a low priority one (code 1).
+func priorityTestProtocol(run func(p *Peer, rw MsgReadWriter) error) Protocol {
+	return Protocol{
+		Name:   "a",
+		Length: 2,
+		Run:    run,
+		MessagePriority: func(code uint64) Priority {
+			if code == 0 {
+				return PriorityHigh
+			}
+			return PriorityLow
+		},
+	}
+}
+
+// Tests that a high priority message is sent ahead of the low priority messages
+// waiting for egress bandwidth.
+func TestPeerEgressPriority(t *testing.T) {
+	payload := make([]byte, 15000)
+	proto := priorityTestProtocol(func(p *Peer, rw MsgReadWriter) error {
+		go func() {
+			// The first message puts the connection into debt, the following
+			// low priority messages wait for it to be paid off.
+			Send(rw, 1, payload)
+			for i := 0; i < 3; i++ {
+				go Send(rw, 1, payload)
+			}
+			time.Sleep(100 * time.Millisecond)
+			Send(rw, 0, payload)
+		}()
+		for {
+			if _, err := rw.ReadMsg(); err != nil {
+				return err
+			}
+		}
+	})
+	closer, rw, _, _ := testThrottledPeer([]Protocol{proto}, &BandwidthLimits{PeerEgress: 10000})
+	defer closer()
+
+	var codes []uint64
+	for len(codes) < 2 {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		msg.Discard()
+		if msg.Code >= baseProtocolLength {
+			codes = append(codes, msg.Code-baseProtocolLength)
+		}
+	}
+	if codes[0] != 1 || codes[1] != 0 {
+		t.Fatalf("wrong send order: %v", codes)
+	}
+}
+
+// Tests that a received high priority message is delivered ahead of the low
+// priority messages waiting for ingress bandwidth.
+func TestPeerIngressPriority(t *testing.T) {
+	codes := make(chan uint64, 5)
+	proto := priorityTestProtocol(func(p *Peer, rw MsgReadWriter) error {
+		for {
+			msg, err := rw.ReadMsg()
+			if err != nil {
+				return err
+			}
+			msg.Discard()
+			codes <- msg.Code
+		}
+	})
+	closer, rw, _, _ := testThrottledPeer([]Protocol{proto}, &BandwidthLimits{PeerIngress: 10000})
+	defer closer()
+
+	// The first message puts the connection into debt, the following low
+	// priority messages wait for it to be paid off.
+	payload := make([]byte, 15000)
+	if err := Send(rw, baseProtocolLength+1, payload); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-codes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("first message not delivered")
+	}
+	for i := 0; i < 3; i++ {
+		if err := Send(rw, baseProtocolLength+1, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Send(rw, baseProtocolLength, payload); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case code := <-codes:
+		if code != 0 {
+			t.Fatalf("low priority message delivered first")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("messages not delivered")
+	}
+}
+
+// Tests that a received message never overtakes an earlier message of equal or
+// higher priority.
+func TestIngressOrder(t *testing.T) {
+	o := newIngressOrder()
+	o.add(PriorityHigh)
+	o.add(PriorityLow)
+	o.add(PriorityHigh)
+
+	closed := make(chan struct{})
+	close(closed)
+	if o.wait(PriorityLow, closed) {
+		t.Fatal("low priority message overtook earlier high priority message")
+	}
+	if !o.wait(PriorityHigh, closed) {
+		t.Fatal("high priority message not ready")
+	}
+	o.done(PriorityHigh)
+
+	// The later high priority message may overtake the low priority one.
+	if !o.wait(PriorityHigh, closed) {
+		t.Fatal("later high priority message not ready")
+	}
+	if !o.wait(PriorityLow, closed) {
+		t.Fatal("low priority message not ready after earlier message was delivered")
+	}
+}
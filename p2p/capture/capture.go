@@ -0,0 +1,227 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package capture implements a file format for recording the messages exchanged
+// with a peer over devp2p.
+//
+// A capture file starts with an 8-byte magic value and a version number, followed
+// by the RLP encoded Header describing the connection. The remaining content is a
+// sequence of RLP encoded Records, one for each message sent or received.
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Version is the version of the capture format.
+const Version = 1
+
+var magic = [8]byte{'d', 'e', 'v', 'p', '2', 'p', 'c', 'p'}
+
+var (
+	errBadMagic   = errors.New("not a devp2p capture file")
+	errBadVersion = errors.New("unsupported capture version")
+)
+
+// Direction tells whether a captured message was received or sent.
+type Direction uint8
+
+const (
+	Ingress Direction = iota // received from the peer
+	Egress                   // sent to the peer
+)
+
+func (d Direction) String() string {
+	if d == Ingress {
+		return "<-"
+	}
+	return "->"
+}
+
+// Header describes the captured connection.
+type Header struct {
+	ID         enode.ID // remote node ID
+	Name       string   // client name of the remote node
+	RemoteAddr string
+	LocalAddr  string
+	Inbound    bool
+	Caps       []string // negotiated protocols, e.g. "eth/68"
+	Start      uint64   // capture start time, in nanoseconds since the Unix epoch
+}
+
+// StartTime returns the capture start time.
+func (h *Header) StartTime() time.Time {
+	return time.Unix(0, int64(h.Start))
+}
+
+// Record is a captured message.
+type Record struct {
+	Time      uint64 // nanoseconds since the capture start
+	Direction Direction
+	Protocol  string
+	Version   uint
+	Code      uint64 // message code relative to the protocol offset
+	Payload   []byte
+}
+
+// Elapsed returns the time of the record relative to the capture start.
+func (r *Record) Elapsed() time.Duration {
+	return time.Duration(r.Time)
+}
+
+// Writer records messages into a capture file. It is safe for concurrent use.
+type Writer struct {
+	mu    sync.Mutex
+	out   io.Writer
+	buf   *bufio.Writer
+	start time.Time
+	err   error
+}
+
+// Create creates a capture file at the given path.
+func Create(path string, h *Header) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(f, h)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// NewWriter writes the capture header to out and returns a writer for the
+// records. If h.Start is zero, it is set to the current time.
+func NewWriter(out io.Writer, h *Header) (*Writer, error) {
+	if h.Start == 0 {
+		h.Start = uint64(time.Now().UnixNano())
+	}
+	w := &Writer{out: out, buf: bufio.NewWriter(out), start: h.StartTime()}
+	w.buf.Write(magic[:])
+	binary.Write(w.buf, binary.BigEndian, uint32(Version))
+	if err := rlp.Encode(w.buf, h); err != nil {
+		return nil, err
+	}
+	if err := w.buf.Flush(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write records a message. The record time is set to the current time. Records
+// are buffered and written out by Flush or Close.
+func (w *Writer) Write(dir Direction, proto string, version uint, code uint64, payload []byte) error {
+	r := &Record{
+		Time:      uint64(time.Since(w.start)),
+		Direction: dir,
+		Protocol:  proto,
+		Version:   version,
+		Code:      code,
+		Payload:   payload,
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+	w.err = rlp.Encode(w.buf, r)
+	return w.err
+}
+
+// Flush writes the buffered records to the underlying writer.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err == nil {
+		w.err = w.buf.Flush()
+	}
+	return w.err
+}
+
+// Close flushes the buffered records and closes the underlying file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err == nil {
+		w.err = w.buf.Flush()
+	}
+	if c, ok := w.out.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	return w.err
+}
+
+// Reader reads a capture file.
+type Reader struct {
+	header Header
+	stream *rlp.Stream
+}
+
+// NewReader reads the capture header from r.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	var prefix [12]byte
+	if _, err := io.ReadFull(br, prefix[:]); err != nil {
+		return nil, errBadMagic
+	}
+	if !bytes.Equal(prefix[:8], magic[:]) {
+		return nil, errBadMagic
+	}
+	if v := binary.BigEndian.Uint32(prefix[8:]); v != Version {
+		return nil, fmt.Errorf("%w %d", errBadVersion, v)
+	}
+	cr := &Reader{stream: rlp.NewStream(br, 0)}
+	if err := cr.stream.Decode(&cr.header); err != nil {
+		return nil, fmt.Errorf("invalid capture header: %v", err)
+	}
+	return cr, nil
+}
+
+// Header returns the capture header.
+func (r *Reader) Header() *Header {
+	return &r.header
+}
+
+// Next reads the next record. It returns io.EOF at the end of the capture.
+func (r *Reader) Next() (*Record, error) {
+	rec := new(Record)
+	if err := r.stream.Decode(rec); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("invalid capture record: %v", err)
+	}
+	return rec, nil
+}
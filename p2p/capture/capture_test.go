@@ -0,0 +1,86 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package capture
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestCaptureRoundtrip(t *testing.T) {
+	var (
+		buf    = new(bytes.Buffer)
+		header = &Header{
+			ID:         enode.ID{1, 2, 3},
+			Name:       "Geth/v1.16.0",
+			RemoteAddr: "10.0.0.1:30303",
+			LocalAddr:  "10.0.0.2:41234",
+			Caps:       []string{"eth/68", "snap/1"},
+		}
+		records = []Record{
+			{Direction: Ingress, Protocol: "eth", Version: 68, Code: 0, Payload: []byte{0xc0}},
+			{Direction: Egress, Protocol: "snap", Version: 1, Code: 7, Payload: []byte{1, 2, 3}},
+		}
+	)
+	w, err := NewWriter(buf, header)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	for _, r := range records {
+		if err := w.Write(r.Direction, r.Protocol, r.Version, r.Code, r.Payload); err != nil {
+			t.Fatalf("failed to write record: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+	r, err := NewReader(buf)
+	if err != nil {
+		t.Fatalf("failed to open capture: %v", err)
+	}
+	if !reflect.DeepEqual(r.Header(), header) {
+		t.Errorf("header mismatch: have %+v, want %+v", r.Header(), header)
+	}
+	var last uint64
+	for i, want := range records {
+		have, err := r.Next()
+		if err != nil {
+			t.Fatalf("record %d: read failed: %v", i, err)
+		}
+		if have.Time < last {
+			t.Errorf("record %d: time went backwards", i)
+		}
+		last, want.Time = have.Time, have.Time
+		if !reflect.DeepEqual(*have, want) {
+			t.Errorf("record %d: mismatch: have %+v, want %+v", i, have, want)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatalf("wrong error at end of capture: %v", err)
+	}
+}
+
+func TestCaptureBadMagic(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader([]byte("not a capture file"))); !errors.Is(err, errBadMagic) {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
@@ -49,6 +49,10 @@ type Config struct {
 	// Setting DialRatio to zero defaults it to 3.
 	DialRatio int `toml:",omitempty"`
 
+	// Bandwidth caps the traffic of the server, globally and per peer. Zero
+	// values mean unlimited. The limits can be adjusted at runtime.
+	Bandwidth BandwidthLimits `toml:",omitempty"`
+
 	// NoDiscovery can be used to disable the peer discovery mechanism.
 	// Disabling is useful for protocol debugging (manual topology).
 	NoDiscovery bool
@@ -60,6 +64,10 @@ type Config struct {
 	// protocol should be started or not.
 	DiscoveryV5 bool `toml:",omitempty"`
 
+	// DiscoveryV5Topics enables the experimental topic advertisement protocol
+	// of V5 discovery, serving topic registrations and queries.
+	DiscoveryV5Topics bool `toml:",omitempty"`
+
 	// Name sets the node name of this server.
 	Name string `toml:"-"`
 
@@ -85,6 +93,16 @@ type Config struct {
 	// IP networks contained in the list are considered.
 	NetRestrict *netutil.Netlist `toml:",omitempty"`
 
+	// Allowlist is the path of a file listing the only nodes allowed to connect,
+	// inbound or dialed, one node ID or enode URL per line. The file can be
+	// reloaded at runtime.
+	Allowlist string `toml:",omitempty"`
+
+	// AdmissionPolicy, if set, is consulted during the handshake of every
+	// connection and before dialing discovered nodes, in addition to the
+	// allowlist. It can be used to admit nodes based on their ENR entries.
+	AdmissionPolicy AdmissionPolicy `toml:"-"`
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string `toml:",omitempty"`
@@ -106,6 +124,13 @@ type Config struct {
 	// for TCP and DiscAddr for the UDP discovery protocol.
 	DiscAddr string
 
+	// If QUICAddr is set to a non-nil address, the server also accepts connections
+	// over the experimental QUIC transport on this UDP address and advertises it
+	// in the node record. Nodes advertising a QUIC endpoint are then dialed over
+	// QUIC, falling back to RLPx over TCP if that fails. The address must not be
+	// the same as the discovery address.
+	QUICAddr string
+
 	// If set to a non-nil value, the given NAT port mapper
 	// is used to make the listening port available to the
 	// Internet.
@@ -115,6 +140,12 @@ type Config struct {
 	// is used to dial outbound peer connections.
 	Dialer NodeDialer `toml:"-"`
 
+	// If ListenFunc is set to a non-nil value, it is used instead of
+	// net.Listen to create the listener for inbound connections.
+	// Together with Dialer, this allows running the server over
+	// in-memory transports.
+	ListenFunc ListenFunc `toml:"-"`
+
 	// If NoDial is true, the server will not dial any peers.
 	NoDial bool `toml:",omitempty"`
 
@@ -122,6 +153,10 @@ type Config struct {
 	// whenever a message is sent to or received from a peer
 	EnableMsgEvents bool
 
+	// If CaptureDir is set, the messages exchanged with peers are recorded
+	// into this directory, in a capture file per connection.
+	CaptureDir string `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:"-"`
 
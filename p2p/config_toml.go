@@ -16,51 +16,65 @@ var _ = (*configMarshaling)(nil)
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		PrivateKey       *ecdsa.PrivateKey `toml:"-"`
-		MaxPeers         int
-		MaxPendingPeers  int `toml:",omitempty"`
-		DialRatio        int `toml:",omitempty"`
-		NoDiscovery      bool
-		DiscoveryV4      bool   `toml:",omitempty"`
-		DiscoveryV5      bool   `toml:",omitempty"`
-		Name             string `toml:"-"`
-		BootstrapNodes   []*enode.Node
-		BootstrapNodesV5 []*enode.Node `toml:",omitempty"`
-		StaticNodes      []*enode.Node
-		TrustedNodes     []*enode.Node
-		NetRestrict      *netutil.Netlist `toml:",omitempty"`
-		NodeDatabase     string           `toml:",omitempty"`
-		Protocols        []Protocol       `toml:"-" json:"-"`
-		ListenAddr       string
-		DiscAddr         string
-		NAT              nat.Interface `toml:",omitempty"`
-		Dialer           NodeDialer    `toml:"-"`
-		NoDial           bool          `toml:",omitempty"`
-		EnableMsgEvents  bool
-		Logger           log.Logger `toml:"-"`
+		PrivateKey        *ecdsa.PrivateKey `toml:"-"`
+		MaxPeers          int
+		MaxPendingPeers   int             `toml:",omitempty"`
+		DialRatio         int             `toml:",omitempty"`
+		Bandwidth         BandwidthLimits `toml:",omitempty"`
+		NoDiscovery       bool
+		DiscoveryV4       bool   `toml:",omitempty"`
+		DiscoveryV5       bool   `toml:",omitempty"`
+		DiscoveryV5Topics bool   `toml:",omitempty"`
+		Name              string `toml:"-"`
+		BootstrapNodes    []*enode.Node
+		BootstrapNodesV5  []*enode.Node `toml:",omitempty"`
+		StaticNodes       []*enode.Node
+		TrustedNodes      []*enode.Node
+		NetRestrict       *netutil.Netlist `toml:",omitempty"`
+		Allowlist         string           `toml:",omitempty"`
+		AdmissionPolicy   AdmissionPolicy  `toml:"-"`
+		NodeDatabase      string           `toml:",omitempty"`
+		Protocols         []Protocol       `toml:"-" json:"-"`
+		ListenAddr        string
+		DiscAddr          string
+		QUICAddr          string
+		NAT               nat.Interface `toml:",omitempty"`
+		Dialer            NodeDialer    `toml:"-"`
+		ListenFunc        ListenFunc    `toml:"-"`
+		NoDial            bool          `toml:",omitempty"`
+		EnableMsgEvents   bool
+		CaptureDir        string     `toml:",omitempty"`
+		Logger            log.Logger `toml:"-"`
 	}
 	var enc Config
 	enc.PrivateKey = c.PrivateKey
 	enc.MaxPeers = c.MaxPeers
 	enc.MaxPendingPeers = c.MaxPendingPeers
 	enc.DialRatio = c.DialRatio
+	enc.Bandwidth = c.Bandwidth
 	enc.NoDiscovery = c.NoDiscovery
 	enc.DiscoveryV4 = c.DiscoveryV4
 	enc.DiscoveryV5 = c.DiscoveryV5
+	enc.DiscoveryV5Topics = c.DiscoveryV5Topics
 	enc.Name = c.Name
 	enc.BootstrapNodes = c.BootstrapNodes
 	enc.BootstrapNodesV5 = c.BootstrapNodesV5
 	enc.StaticNodes = c.StaticNodes
 	enc.TrustedNodes = c.TrustedNodes
 	enc.NetRestrict = c.NetRestrict
+	enc.Allowlist = c.Allowlist
+	enc.AdmissionPolicy = c.AdmissionPolicy
 	enc.NodeDatabase = c.NodeDatabase
 	enc.Protocols = c.Protocols
 	enc.ListenAddr = c.ListenAddr
 	enc.DiscAddr = c.DiscAddr
+	enc.QUICAddr = c.QUICAddr
 	enc.NAT = c.NAT
 	enc.Dialer = c.Dialer
+	enc.ListenFunc = c.ListenFunc
 	enc.NoDial = c.NoDial
 	enc.EnableMsgEvents = c.EnableMsgEvents
+	enc.CaptureDir = c.CaptureDir
 	enc.Logger = c.Logger
 	return &enc, nil
 }
@@ -68,28 +82,35 @@ func (c Config) MarshalTOML() (interface{}, error) {
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		PrivateKey       *ecdsa.PrivateKey `toml:"-"`
-		MaxPeers         *int
-		MaxPendingPeers  *int `toml:",omitempty"`
-		DialRatio        *int `toml:",omitempty"`
-		NoDiscovery      *bool
-		DiscoveryV4      *bool   `toml:",omitempty"`
-		DiscoveryV5      *bool   `toml:",omitempty"`
-		Name             *string `toml:"-"`
-		BootstrapNodes   []*enode.Node
-		BootstrapNodesV5 []*enode.Node `toml:",omitempty"`
-		StaticNodes      []*enode.Node
-		TrustedNodes     []*enode.Node
-		NetRestrict      *netutil.Netlist `toml:",omitempty"`
-		NodeDatabase     *string          `toml:",omitempty"`
-		Protocols        []Protocol       `toml:"-" json:"-"`
-		ListenAddr       *string
-		DiscAddr         *string
-		NAT              *configNAT `toml:",omitempty"`
-		Dialer           NodeDialer `toml:"-"`
-		NoDial           *bool      `toml:",omitempty"`
-		EnableMsgEvents  *bool
-		Logger           log.Logger `toml:"-"`
+		PrivateKey        *ecdsa.PrivateKey `toml:"-"`
+		MaxPeers          *int
+		MaxPendingPeers   *int             `toml:",omitempty"`
+		DialRatio         *int             `toml:",omitempty"`
+		Bandwidth         *BandwidthLimits `toml:",omitempty"`
+		NoDiscovery       *bool
+		DiscoveryV4       *bool   `toml:",omitempty"`
+		DiscoveryV5       *bool   `toml:",omitempty"`
+		DiscoveryV5Topics *bool   `toml:",omitempty"`
+		Name              *string `toml:"-"`
+		BootstrapNodes    []*enode.Node
+		BootstrapNodesV5  []*enode.Node `toml:",omitempty"`
+		StaticNodes       []*enode.Node
+		TrustedNodes      []*enode.Node
+		NetRestrict       *netutil.Netlist `toml:",omitempty"`
+		Allowlist         *string          `toml:",omitempty"`
+		AdmissionPolicy   AdmissionPolicy  `toml:"-"`
+		NodeDatabase      *string          `toml:",omitempty"`
+		Protocols         []Protocol       `toml:"-" json:"-"`
+		ListenAddr        *string
+		DiscAddr          *string
+		QUICAddr          *string
+		NAT               *configNAT  `toml:",omitempty"`
+		Dialer            NodeDialer  `toml:"-"`
+		ListenFunc        *ListenFunc `toml:"-"`
+		NoDial            *bool       `toml:",omitempty"`
+		EnableMsgEvents   *bool
+		CaptureDir        *string    `toml:",omitempty"`
+		Logger            log.Logger `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -107,6 +128,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DialRatio != nil {
 		c.DialRatio = *dec.DialRatio
 	}
+	if dec.Bandwidth != nil {
+		c.Bandwidth = *dec.Bandwidth
+	}
 	if dec.NoDiscovery != nil {
 		c.NoDiscovery = *dec.NoDiscovery
 	}
@@ -116,6 +140,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DiscoveryV5 != nil {
 		c.DiscoveryV5 = *dec.DiscoveryV5
 	}
+	if dec.DiscoveryV5Topics != nil {
+		c.DiscoveryV5Topics = *dec.DiscoveryV5Topics
+	}
 	if dec.Name != nil {
 		c.Name = *dec.Name
 	}
@@ -134,6 +161,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.NetRestrict != nil {
 		c.NetRestrict = dec.NetRestrict
 	}
+	if dec.Allowlist != nil {
+		c.Allowlist = *dec.Allowlist
+	}
+	if dec.AdmissionPolicy != nil {
+		c.AdmissionPolicy = dec.AdmissionPolicy
+	}
 	if dec.NodeDatabase != nil {
 		c.NodeDatabase = *dec.NodeDatabase
 	}
@@ -146,18 +179,27 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DiscAddr != nil {
 		c.DiscAddr = *dec.DiscAddr
 	}
+	if dec.QUICAddr != nil {
+		c.QUICAddr = *dec.QUICAddr
+	}
 	if dec.NAT != nil {
 		c.NAT = dec.NAT
 	}
 	if dec.Dialer != nil {
 		c.Dialer = dec.Dialer
 	}
+	if dec.ListenFunc != nil {
+		c.ListenFunc = *dec.ListenFunc
+	}
 	if dec.NoDial != nil {
 		c.NoDial = *dec.NoDial
 	}
 	if dec.EnableMsgEvents != nil {
 		c.EnableMsgEvents = *dec.EnableMsgEvents
 	}
+	if dec.CaptureDir != nil {
+		c.CaptureDir = *dec.CaptureDir
+	}
 	if dec.Logger != nil {
 		c.Logger = dec.Logger
 	}
@@ -80,6 +80,7 @@ var (
 	errNetRestrict      = errors.New("not contained in netrestrict list")
 	errNoPort           = errors.New("node does not provide TCP port")
 	errNoResolvedIP     = errors.New("node does not provide a resolved IP")
+	errLowScore         = errors.New("low reputation score")
 )
 
 // dialer creates outbound connections and submits them into Server.
@@ -130,10 +131,12 @@ type dialScheduler struct {
 type dialSetupFunc func(net.Conn, connFlag, *enode.Node) error
 
 type dialConfig struct {
-	self           enode.ID         // our own ID
-	maxDialPeers   int              // maximum number of dialed peers
-	maxActiveDials int              // maximum number of active dials
-	netRestrict    *netutil.Netlist // IP netrestrict list, disabled if nil
+	self           enode.ID                // our own ID
+	maxDialPeers   int                     // maximum number of dialed peers
+	maxActiveDials int                     // maximum number of active dials
+	netRestrict    *netutil.Netlist        // IP netrestrict list, disabled if nil
+	nodeScore      func(enode.ID) float64  // recorded reputation of nodes, disabled if nil
+	admit          func(*enode.Node) error // admission policy of nodes, disabled if nil
 	resolver       nodeResolver
 	dialer         NodeDialer
 	log            log.Logger
@@ -244,7 +247,7 @@ loop:
 
 		select {
 		case node := <-nodesCh:
-			if err := d.checkDial(node); err != nil {
+			if err := d.checkDynDial(node); err != nil {
 				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IPAddr(), "reason", err)
 			} else {
 				d.startDial(newDialTask(node, dynDialedConn))
@@ -399,6 +402,23 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 	return nil
 }
 
+// checkDynDial returns an error if the discovered node n should not be dialed.
+// On top of the checks done for static nodes, it also rejects nodes with a bad
+// reputation recorded during previous connections, and nodes not admitted by
+// the admission policy of the server.
+func (d *dialScheduler) checkDynDial(n *enode.Node) error {
+	if err := d.checkDial(n); err != nil {
+		return err
+	}
+	if d.nodeScore != nil && d.nodeScore(n.ID()) < minDialScore {
+		return errLowScore
+	}
+	if d.admit != nil && d.admit(n) != nil {
+		return errNotAdmitted
+	}
+	return nil
+}
+
 // startStaticDials starts n static dial tasks.
 func (d *dialScheduler) startStaticDials(n int) (started int) {
 	for started = 0; started < n && len(d.staticPool) > 0; started++ {
@@ -616,7 +636,11 @@ func (t *dialTask) dial(d *dialScheduler, dest *enode.Node) error {
 		dialConnectionError.Mark(1)
 		return &dialError{err}
 	}
-	return d.setupFunc(newMeteredConn(fd), t.flags, dest)
+	// QUIC connections are metered by their transport, across all streams.
+	if _, ok := fd.(*quicConn); !ok {
+		fd = newMeteredConn(fd)
+	}
+	return d.setupFunc(fd, t.flags, dest)
 }
 
 func (t *dialTask) String() string {
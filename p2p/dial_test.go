@@ -153,6 +153,46 @@ func TestDialSchedNetRestrict(t *testing.T) {
 	})
 }
 
+// This test checks that discovered nodes with a bad recorded reputation are not
+// dialed, while static nodes are dialed regardless.
+func TestDialSchedLowScore(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*enode.Node{
+		newNode(uintID(0x01), "127.0.0.1:30303"),
+		newNode(uintID(0x02), "127.0.0.2:30303"),
+		newNode(uintID(0x03), "127.0.0.3:30303"),
+		newNode(uintID(0x04), "127.0.0.4:30303"),
+	}
+	scores := map[enode.ID]float64{
+		nodes[0].ID(): 10,
+		nodes[1].ID(): minDialScore - 1,
+		nodes[2].ID(): minDialScore,
+		nodes[3].ID(): -100,
+	}
+	config := dialConfig{
+		maxActiveDials: 10,
+		maxDialPeers:   10,
+		nodeScore:      func(id enode.ID) float64 { return scores[id] },
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			update: func(d *dialScheduler) {
+				d.addStatic(nodes[3])
+			},
+			discovered:   nodes[:3],
+			wantNewDials: []*enode.Node{nodes[3], nodes[0], nodes[2]},
+		},
+		{
+			succeeded: []enode.ID{
+				nodes[0].ID(),
+				nodes[2].ID(),
+				nodes[3].ID(),
+			},
+		},
+	})
+}
+
 // This test checks that static dials work and obey the limits.
 func TestDialSchedStaticDial(t *testing.T) {
 	t.Parallel()
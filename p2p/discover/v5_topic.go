@@ -0,0 +1,504 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// This file implements the experimental discv5 topic advertisement protocol.
+// Nodes advertise themselves for a topic by placing ads on registrar nodes close
+// to the topic hash. Registrars make the advertisers wait for a while before
+// accepting an ad. The waiting time grows with the occupancy of the ad table,
+// which keeps the table from filling up and prevents any single topic from
+// dominating it.
+
+const (
+	topicAdLifetime     = 15 * time.Minute // how long an ad stays in the table
+	topicTableCapacity  = 10000            // max number of ads in the table
+	topicQueueCapacity  = 100              // max number of ads per topic
+	topicRegWindow      = 10 * time.Second // time after ticket expiry in which it can be used
+	topicQueryLimit     = 16               // max number of nodes in a TOPICQUERY response
+	topicMaxWaitTime    = topicAdLifetime  // advertisers give up when asked to wait longer
+	topicRegistrars     = 8                // number of registrars an advertiser places ads on
+	topicLookupInterval = 5 * time.Minute  // how often advertisers look for new registrars
+	topicSearchInterval = 30 * time.Second // delay between topic search rounds
+	topicOccupancyPower = 10               // exponent of the occupancy term in the waiting time
+	topicBaseWait       = 1e-7             // waiting time factor of a topic with no ads
+)
+
+var (
+	errTopicsDisabled = errors.New("topic advertisement is disabled")
+	errInvalidTicket  = errors.New("invalid ticket")
+	errTicketEarly    = errors.New("ticket used before waiting time elapsed")
+	errTicketExpired  = errors.New("ticket expired")
+)
+
+// TopicHash returns the hash identifying a topic name.
+func TopicHash(name string) common.Hash {
+	return sha256.Sum256([]byte(name))
+}
+
+// topicAd is a node registered for a topic.
+type topicAd struct {
+	node    *enode.Node
+	ip      netip.Addr
+	expires mclock.AbsTime
+}
+
+// ticket is the content of the opaque tickets handed out to advertisers. The
+// registrar encrypts tickets with a local key, so they can't be forged.
+type ticket struct {
+	Topic      common.Hash
+	Node       enode.ID
+	Issued     uint64 // local time of issuance
+	WaitTime   uint64 // waiting time assigned at issuance
+	Cumulative uint64 // waiting time spent on previous tickets
+}
+
+// topicTable holds the ads placed on the local node. It is accessed by the
+// dispatch loop only.
+type topicTable struct {
+	clock mclock.Clock
+	aead  cipher.AEAD
+	ads   map[common.Hash][]topicAd
+	total int
+
+	lifetime      time.Duration
+	capacity      int
+	queueCapacity int
+}
+
+func newTopicTable(clock mclock.Clock) *topicTable {
+	var key [16]byte
+	crand.Read(key[:])
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic("can't create topic ticket cipher: " + err.Error())
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic("can't create topic ticket cipher: " + err.Error())
+	}
+	return &topicTable{
+		clock:         clock,
+		aead:          aead,
+		ads:           make(map[common.Hash][]topicAd),
+		lifetime:      topicAdLifetime,
+		capacity:      topicTableCapacity,
+		queueCapacity: topicQueueCapacity,
+	}
+}
+
+// expire removes the ads whose lifetime has ended.
+func (tab *topicTable) expire(now mclock.AbsTime) {
+	for topic, queue := range tab.ads {
+		// Ads are appended in placement order, so expired ones are at the front.
+		n := 0
+		for n < len(queue) && queue[n].expires <= now {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		tab.total -= n
+		if n == len(queue) {
+			delete(tab.ads, topic)
+		} else {
+			tab.ads[topic] = slices.Delete(queue, 0, n)
+		}
+	}
+}
+
+// registered reports whether the node has an ad for the topic.
+func (tab *topicTable) registered(topic common.Hash, id enode.ID) bool {
+	return slices.ContainsFunc(tab.ads[topic], func(ad topicAd) bool { return ad.node.ID() == id })
+}
+
+// waitTime computes how long a node has to wait before it can place an ad for
+// the topic. The waiting time is proportional to the share of the table taken
+// by the topic and by ads from the same IP, and grows steeply as the table fills.
+func (tab *topicTable) waitTime(topic common.Hash, ip netip.Addr, now mclock.AbsTime) time.Duration {
+	queue := tab.ads[topic]
+	if tab.total >= tab.capacity || len(queue) >= tab.queueCapacity {
+		// No space left, wait for the oldest ad to expire.
+		oldest := mclock.AbsTime(math.MaxInt64)
+		if len(queue) >= tab.queueCapacity {
+			oldest = queue[0].expires
+		} else {
+			for _, q := range tab.ads {
+				oldest = min(oldest, q[0].expires)
+			}
+		}
+		return time.Duration(oldest - now)
+	}
+	var sameIP int
+	for _, q := range tab.ads {
+		for _, ad := range q {
+			if ad.ip == ip {
+				sameIP++
+			}
+		}
+	}
+	var (
+		capacity  = float64(tab.capacity)
+		occupancy = 1 / math.Pow(1-float64(tab.total)/capacity, topicOccupancyPower)
+		share     = float64(len(queue))/capacity + float64(sameIP)/capacity + topicBaseWait
+	)
+	return time.Duration(float64(tab.lifetime) * occupancy * share)
+}
+
+// add places an ad for the node.
+func (tab *topicTable) add(topic common.Hash, n *enode.Node, ip netip.Addr, now mclock.AbsTime) {
+	tab.ads[topic] = append(tab.ads[topic], topicAd{node: n, ip: ip, expires: now.Add(tab.lifetime)})
+	tab.total++
+}
+
+// nodes returns the nodes registered for a topic.
+func (tab *topicTable) nodes(topic common.Hash) []*enode.Node {
+	queue := tab.ads[topic]
+	nodes := make([]*enode.Node, len(queue))
+	for i, ad := range queue {
+		nodes[i] = ad.node
+	}
+	return nodes
+}
+
+// sealTicket encrypts a ticket.
+func (tab *topicTable) sealTicket(t *ticket) []byte {
+	enc, err := rlp.EncodeToBytes(t)
+	if err != nil {
+		panic("can't encode ticket: " + err.Error())
+	}
+	nonce := make([]byte, tab.aead.NonceSize())
+	crand.Read(nonce)
+	return tab.aead.Seal(nonce, nonce, enc, nil)
+}
+
+// openTicket decrypts a ticket created by sealTicket.
+func (tab *topicTable) openTicket(data []byte) (*ticket, error) {
+	size := tab.aead.NonceSize()
+	if len(data) < size {
+		return nil, errInvalidTicket
+	}
+	enc, err := tab.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return nil, errInvalidTicket
+	}
+	t := new(ticket)
+	if err := rlp.DecodeBytes(enc, t); err != nil {
+		return nil, errInvalidTicket
+	}
+	return t, nil
+}
+
+// register processes a registration attempt. It returns a ticket and waiting time
+// if the node has to wait, and a nil ticket if the ad was placed.
+func (tab *topicTable) register(req *v5wire.Regtopic, n *enode.Node, ip netip.Addr) ([]byte, time.Duration, error) {
+	now := tab.clock.Now()
+	tab.expire(now)
+
+	var cumulative time.Duration
+	if len(req.Ticket) > 0 {
+		t, err := tab.openTicket(req.Ticket)
+		if err != nil {
+			return nil, 0, err
+		}
+		if t.Topic != req.Topic || t.Node != n.ID() {
+			return nil, 0, errInvalidTicket
+		}
+		usable := mclock.AbsTime(t.Issued).Add(time.Duration(t.WaitTime))
+		switch {
+		case now < usable:
+			return nil, 0, errTicketEarly
+		case now > usable.Add(topicRegWindow):
+			return nil, 0, errTicketExpired
+		}
+		cumulative = time.Duration(t.Cumulative) + time.Duration(now-mclock.AbsTime(t.Issued))
+	}
+	if tab.registered(req.Topic, n.ID()) {
+		return nil, 0, nil
+	}
+	// Place the ad if the node has waited long enough. The waiting time is sent
+	// in whole seconds, so any remainder below a second is waived.
+	wait := tab.waitTime(req.Topic, ip, now) - cumulative
+	if wait < time.Second {
+		tab.add(req.Topic, n, ip, now)
+		return nil, 0, nil
+	}
+	wait = wait.Round(time.Second)
+	t := &ticket{
+		Topic:      req.Topic,
+		Node:       n.ID(),
+		Issued:     uint64(now),
+		WaitTime:   uint64(wait),
+		Cumulative: uint64(cumulative),
+	}
+	return tab.sealTicket(t), wait, nil
+}
+
+// handleRegtopic processes a topic registration request.
+func (t *UDPv5) handleRegtopic(p *v5wire.Regtopic, fromID enode.ID, fromAddr netip.AddrPort) {
+	if t.topics == nil {
+		return
+	}
+	n, err := enode.New(t.validSchemes, p.ENR)
+	if err != nil {
+		t.log.Debug("Invalid record in "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	// Only accept the record of the sender, reachable on the sending endpoint,
+	// so that nodes can't be registered by others.
+	if n.ID() != fromID || n.IPAddr() != fromAddr.Addr() {
+		t.log.Debug("Foreign record in "+p.Name(), "id", fromID, "addr", fromAddr)
+		return
+	}
+	tk, wait, err := t.topics.register(p, n, fromAddr.Addr())
+	if err != nil {
+		t.log.Debug("Rejected "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	if tk == nil {
+		t.sendResponse(fromID, fromAddr, &v5wire.Regconfirmation{ReqID: p.ReqID, Topic: p.Topic})
+		return
+	}
+	t.sendResponse(fromID, fromAddr, &v5wire.Ticket{ReqID: p.ReqID, Ticket: tk, WaitTime: uint(wait / time.Second)})
+}
+
+// handleTopicQuery returns the nodes registered for a topic.
+func (t *UDPv5) handleTopicQuery(p *v5wire.TopicQuery, fromID enode.ID, fromAddr netip.AddrPort) {
+	if t.topics == nil {
+		return
+	}
+	t.topics.expire(t.clock.Now())
+
+	var nodes []*enode.Node
+	for _, n := range t.topics.nodes(p.Topic) {
+		if netutil.CheckRelayAddr(fromAddr.Addr(), n.IPAddr()) == nil {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) > topicQueryLimit {
+		t.tab.rand.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+		nodes = nodes[:topicQueryLimit]
+	}
+	for _, resp := range packNodes(p.ReqID, nodes) {
+		t.sendResponse(fromID, fromAddr, resp)
+	}
+}
+
+// Regtopic sends a topic registration request to a node. If the node asks to wait
+// before registering, the returned ticket must be sent with the next attempt after
+// the waiting time. A nil ticket means that the registration succeeded.
+func (t *UDPv5) Regtopic(n *enode.Node, topic common.Hash, ticket []byte) ([]byte, time.Duration, error) {
+	if t.topics == nil {
+		return nil, 0, errTopicsDisabled
+	}
+	req := &v5wire.Regtopic{Topic: topic, ENR: t.localNode.Node().Record(), Ticket: ticket}
+	resp := t.callToNode(n, v5wire.TicketMsg, req)
+	defer t.callDone(resp)
+
+	select {
+	case p := <-resp.ch:
+		if p, ok := p.(*v5wire.Ticket); ok {
+			return p.Ticket, time.Duration(p.WaitTime) * time.Second, nil
+		}
+		return nil, 0, nil
+	case err := <-resp.err:
+		return nil, 0, err
+	}
+}
+
+// TopicQuery asks a node for the nodes registered for a topic.
+func (t *UDPv5) TopicQuery(n *enode.Node, topic common.Hash) ([]*enode.Node, error) {
+	if t.topics == nil {
+		return nil, errTopicsDisabled
+	}
+	resp := t.callToNode(n, v5wire.NodesMsg, &v5wire.TopicQuery{Topic: topic})
+	return t.waitForNodes(resp, nil)
+}
+
+// RegisterTopic advertises the local node for a topic. It places ads on the nodes
+// closest to the topic hash, and keeps them alive until the context is canceled.
+func (t *UDPv5) RegisterTopic(ctx context.Context, topic common.Hash) error {
+	if t.topics == nil {
+		return errTopicsDisabled
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		active  = make(map[enode.ID]struct{})
+		done    = make(chan enode.ID)
+		refresh = t.clock.NewTimer(0)
+	)
+	defer refresh.Stop()
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-refresh.C():
+			if t.tab.len() == 0 {
+				<-t.tab.refresh()
+			}
+			for _, n := range t.newLookup(ctx, enode.ID(topic)).run() {
+				if len(active) >= topicRegistrars {
+					break
+				}
+				if _, ok := active[n.ID()]; ok {
+					continue
+				}
+				active[n.ID()] = struct{}{}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					t.advertise(ctx, n, topic)
+					select {
+					case done <- n.ID():
+					case <-ctx.Done():
+					}
+				}()
+			}
+			refresh.Reset(topicLookupInterval)
+		case id := <-done:
+			delete(active, id)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.closeCtx.Done():
+			return errClosed
+		}
+	}
+}
+
+// advertise keeps an ad for the local node on a registrar. It returns when the
+// registrar fails to respond or asks to wait for too long.
+func (t *UDPv5) advertise(ctx context.Context, n *enode.Node, topic common.Hash) {
+	var ticket []byte
+	for {
+		tk, wait, err := t.Regtopic(n, topic, ticket)
+		if err != nil {
+			t.log.Debug("Topic registration failed", "id", n.ID(), "topic", topic, "err", err)
+			return
+		}
+		if tk == nil {
+			t.log.Debug("Registered topic", "id", n.ID(), "topic", topic)
+			wait = topicAdLifetime
+		} else if wait > topicMaxWaitTime {
+			t.log.Debug("Topic registrar is full", "id", n.ID(), "topic", topic, "wait", wait)
+			return
+		}
+		ticket = tk
+
+		timer := t.clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// TopicNodes returns an iterator over the nodes registered for a topic. It looks
+// up the nodes closest to the topic hash and queries them for registrations,
+// repeating the search periodically. If topic advertisement is disabled, the
+// iterator is empty.
+func (t *UDPv5) TopicNodes(topic common.Hash) enode.Iterator {
+	if t.topics == nil {
+		return enode.IterNodes(nil)
+	}
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	it := &topicIterator{ctx: ctx, cancel: cancel, ch: make(chan *enode.Node)}
+	go it.search(t, topic)
+	return it
+}
+
+// topicIterator is the iterator returned by TopicNodes.
+type topicIterator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	ch     chan *enode.Node
+	cur    *enode.Node
+}
+
+func (it *topicIterator) Next() bool {
+	select {
+	case n := <-it.ch:
+		it.cur = n
+		return true
+	case <-it.ctx.Done():
+		it.cur = nil
+		return false
+	}
+}
+
+func (it *topicIterator) Node() *enode.Node {
+	return it.cur
+}
+
+func (it *topicIterator) Close() {
+	it.cancel()
+}
+
+// search runs the topic search rounds, delivering the results to the iterator.
+func (it *topicIterator) search(t *UDPv5, topic common.Hash) {
+	for {
+		if t.tab.len() == 0 {
+			<-t.tab.refresh()
+		}
+		seen := make(map[enode.ID]struct{})
+		for _, registrar := range t.newLookup(it.ctx, enode.ID(topic)).run() {
+			nodes, _ := t.TopicQuery(registrar, topic)
+			for _, n := range nodes {
+				if _, ok := seen[n.ID()]; ok || n.ID() == t.Self().ID() {
+					continue
+				}
+				seen[n.ID()] = struct{}{}
+				select {
+				case it.ch <- n:
+				case <-it.ctx.Done():
+					return
+				}
+			}
+		}
+		timer := t.clock.NewTimer(topicSearchInterval)
+		select {
+		case <-timer.C():
+		case <-it.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// This test checks the ticket waiting logic of the topic table.
+func TestTopicTableRegister(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		tab   = newTopicTable(clock)
+		topic = TopicHash("test")
+		nodes = make([]*enode.Node, 4)
+	)
+	tab.capacity, tab.queueCapacity, tab.lifetime = 10, 2, 10*time.Minute
+	for i := range nodes {
+		nodes[i] = enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 0, byte(i)}, 30303, 30303)
+	}
+	register := func(n *enode.Node, ticket []byte) ([]byte, time.Duration, error) {
+		return tab.register(&v5wire.Regtopic{Topic: topic, Ticket: ticket}, n, n.IPAddr())
+	}
+
+	// The first registration on an empty table is accepted immediately.
+	if tk, _, err := register(nodes[0], nil); tk != nil || err != nil {
+		t.Fatalf("first registration not accepted: ticket %x, err %v", tk, err)
+	}
+	// The next one has to wait.
+	tk, wait, err := register(nodes[1], nil)
+	if tk == nil || err != nil {
+		t.Fatalf("second registration without ticket: err %v", err)
+	}
+	if wait < time.Second {
+		t.Fatalf("too short waiting time %v", wait)
+	}
+	if _, _, err := register(nodes[1], tk); !errors.Is(err, errTicketEarly) {
+		t.Fatalf("wrong error for early ticket: %v", err)
+	}
+	if _, _, err := register(nodes[2], tk); !errors.Is(err, errInvalidTicket) {
+		t.Fatalf("wrong error for foreign ticket: %v", err)
+	}
+	clock.Run(wait)
+	if tk, _, err := register(nodes[1], tk); tk != nil || err != nil {
+		t.Fatalf("registration with ticket not accepted: ticket %x, err %v", tk, err)
+	}
+
+	// The topic queue is full now, so the next node has to wait until the first
+	// ad expires.
+	tk, wait, err = register(nodes[2], nil)
+	if tk == nil || err != nil {
+		t.Fatalf("registration on full queue: err %v", err)
+	}
+	if wait <= 0 || wait > tab.lifetime {
+		t.Fatalf("wrong waiting time on full queue: %v", wait)
+	}
+	clock.Run(wait + topicRegWindow + time.Second)
+	if _, _, err := register(nodes[2], tk); !errors.Is(err, errTicketExpired) {
+		t.Fatalf("wrong error for expired ticket: %v", err)
+	}
+
+	// Check that ads expire. The first one is gone already, since the waiting
+	// time on the full queue has elapsed.
+	if n := len(tab.nodes(topic)); n != 1 {
+		t.Fatalf("wrong number of ads: %d", n)
+	}
+	clock.Run(tab.lifetime)
+	tab.expire(clock.Now())
+	if n := len(tab.nodes(topic)); n != 0 || tab.total != 0 {
+		t.Fatalf("ads not expired: %d nodes, total %d", n, tab.total)
+	}
+}
+
+// Real sockets, real crypto: this test checks topic registration and search.
+func TestUDPv5_topicE2E(t *testing.T) {
+	t.Parallel()
+
+	var (
+		topic = TopicHash("test")
+		nodes []*UDPv5
+	)
+	for i := 0; i < 3; i++ {
+		cfg := Config{V5Topics: true}
+		if len(nodes) > 0 {
+			cfg.Bootnodes = []*enode.Node{nodes[0].Self()}
+		}
+		node := startLocalhostV5(t, cfg)
+		nodes = append(nodes, node)
+		defer node.Close()
+	}
+	registrar, advertiser, searcher := nodes[0], nodes[1], nodes[2]
+
+	// Register directly with a registrar and query it.
+	if tk, _, err := advertiser.Regtopic(registrar.Self(), topic, nil); tk != nil || err != nil {
+		t.Fatalf("registration failed: ticket %x, err %v", tk, err)
+	}
+	result, err := searcher.TopicQuery(registrar.Self(), topic)
+	if err != nil {
+		t.Fatalf("topic query failed: %v", err)
+	}
+	if len(result) != 1 || result[0].ID() != advertiser.Self().ID() {
+		t.Fatalf("wrong topic query result: %v", result)
+	}
+	if result, _ := searcher.TopicQuery(registrar.Self(), TopicHash("other")); len(result) != 0 {
+		t.Fatalf("unexpected nodes for unregistered topic: %v", result)
+	}
+
+	// Check that the iterator finds the advertiser.
+	it := searcher.TopicNodes(topic)
+	defer it.Close()
+
+	found := make(chan *enode.Node, 1)
+	go func() {
+		if it.Next() {
+			found <- it.Node()
+		}
+	}()
+	select {
+	case n := <-found:
+		if n.ID() != advertiser.Self().ID() {
+			t.Fatalf("iterator returned wrong node %v", n.ID())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("iterator found no nodes")
+	}
+}
+
+// This test checks that the topic protocol can't be used when it is disabled.
+func TestUDPv5_topicsDisabled(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	if err := test.udp.RegisterTopic(context.Background(), TopicHash("test")); !errors.Is(err, errTopicsDisabled) {
+		t.Fatalf("wrong error: %v", err)
+	}
+	if test.udp.TopicNodes(TopicHash("test")).Next() {
+		t.Fatal("iterator returned nodes")
+	}
+	// Registrations are ignored, so the next packet sent is the PONG.
+	record := test.getNode(test.remotekey, test.remoteaddr).Node().Record()
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte("foo"), Topic: TopicHash("test"), ENR: record})
+	test.packetIn(&v5wire.Ping{ReqID: []byte("bar")})
+	test.waitPacketOut(func(p *v5wire.Pong, addr netip.AddrPort, _ v5wire.Nonce) {})
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/p2p/enode"
@@ -47,13 +48,20 @@ const (
 	NodesMsg
 	TalkRequestMsg
 	TalkResponseMsg
-	RequestTicketMsg
+	RegtopicMsg
 	TicketMsg
+	RegconfirmationMsg
+	TopicQueryMsg
 
 	UnknownPacket   = byte(255) // any non-decryptable packet
 	WhoareyouPacket = byte(254) // the WHOAREYOU packet
 )
 
+// RequestTicketMsg is the former name of RegtopicMsg.
+//
+// Deprecated: use RegtopicMsg.
+const RequestTicketMsg = RegtopicMsg
+
 // Protocol messages.
 type (
 	// Unknown represents any packet that can't be decrypted.
@@ -121,6 +129,34 @@ type (
 		ReqID   []byte
 		Message []byte
 	}
+
+	// REGTOPIC requests the registration of the sender's record for a topic.
+	Regtopic struct {
+		ReqID  []byte
+		Topic  common.Hash
+		ENR    *enr.Record
+		Ticket []byte // ticket of a previous registration attempt, if any
+	}
+
+	// TICKET is the reply to REGTOPIC when the registrant has to wait before it
+	// can be registered.
+	Ticket struct {
+		ReqID    []byte
+		Ticket   []byte
+		WaitTime uint // in seconds
+	}
+
+	// REGCONFIRMATION is the reply to REGTOPIC when the registration succeeded.
+	Regconfirmation struct {
+		ReqID []byte
+		Topic common.Hash
+	}
+
+	// TOPICQUERY is a query for nodes registered for a topic.
+	TopicQuery struct {
+		ReqID []byte
+		Topic common.Hash
+	}
 )
 
 // DecodeMessage decodes the message body of a packet.
@@ -139,6 +175,14 @@ func DecodeMessage(ptype byte, body []byte) (Packet, error) {
 		dec = new(TalkRequest)
 	case TalkResponseMsg:
 		dec = new(TalkResponse)
+	case RegtopicMsg:
+		dec = new(Regtopic)
+	case TicketMsg:
+		dec = new(Ticket)
+	case RegconfirmationMsg:
+		dec = new(Regconfirmation)
+	case TopicQueryMsg:
+		dec = new(TopicQuery)
 	default:
 		return nil, fmt.Errorf("unknown packet type %d", ptype)
 	}
@@ -230,3 +274,39 @@ func (p *TalkResponse) SetRequestID(id []byte) { p.ReqID = id }
 func (p *TalkResponse) AppendLogInfo(ctx []interface{}) []interface{} {
 	return append(ctx, "req", hexutil.Bytes(p.ReqID), "len", len(p.Message))
 }
+
+func (*Regtopic) Name() string             { return "REGTOPIC/v5" }
+func (*Regtopic) Kind() byte               { return RegtopicMsg }
+func (p *Regtopic) RequestID() []byte      { return p.ReqID }
+func (p *Regtopic) SetRequestID(id []byte) { p.ReqID = id }
+
+func (p *Regtopic) AppendLogInfo(ctx []interface{}) []interface{} {
+	return append(ctx, "req", hexutil.Bytes(p.ReqID), "topic", p.Topic, "ticket", len(p.Ticket) > 0)
+}
+
+func (*Ticket) Name() string             { return "TICKET/v5" }
+func (*Ticket) Kind() byte               { return TicketMsg }
+func (p *Ticket) RequestID() []byte      { return p.ReqID }
+func (p *Ticket) SetRequestID(id []byte) { p.ReqID = id }
+
+func (p *Ticket) AppendLogInfo(ctx []interface{}) []interface{} {
+	return append(ctx, "req", hexutil.Bytes(p.ReqID), "wait", p.WaitTime)
+}
+
+func (*Regconfirmation) Name() string             { return "REGCONFIRMATION/v5" }
+func (*Regconfirmation) Kind() byte               { return RegconfirmationMsg }
+func (p *Regconfirmation) RequestID() []byte      { return p.ReqID }
+func (p *Regconfirmation) SetRequestID(id []byte) { p.ReqID = id }
+
+func (p *Regconfirmation) AppendLogInfo(ctx []interface{}) []interface{} {
+	return append(ctx, "req", hexutil.Bytes(p.ReqID), "topic", p.Topic)
+}
+
+func (*TopicQuery) Name() string             { return "TOPICQUERY/v5" }
+func (*TopicQuery) Kind() byte               { return TopicQueryMsg }
+func (p *TopicQuery) RequestID() []byte      { return p.ReqID }
+func (p *TopicQuery) SetRequestID(id []byte) { p.ReqID = id }
+
+func (p *TopicQuery) AppendLogInfo(ctx []interface{}) []interface{} {
+	return append(ctx, "req", hexutil.Bytes(p.ReqID), "topic", p.Topic)
+}
@@ -21,8 +21,10 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
+	"math"
 	"net/netip"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
@@ -50,6 +52,7 @@ const (
 	dbNodePing      = "lastping"
 	dbNodePong      = "lastpong"
 	dbNodeSeq       = "seq"
+	dbNodeScore     = "score"
 
 	// Local information is keyed by ID only, the full key is "local:<ID>:seq".
 	// Use localItemKey to create those keys.
@@ -427,6 +430,64 @@ func (db *DB) UpdateFindFailsV5(id ID, ip netip.Addr, fails int) error {
 	return db.storeInt64(v5Key(id, ip, dbNodeFindFails), int64(fails))
 }
 
+// NodeScore retrieves the reputation score recorded for a node when it was last
+// disconnected, or zero if the node was never connected.
+func (db *DB) NodeScore(id ID) float64 {
+	return math.Float64frombits(db.fetchUint64(nodeItemKey(id, zeroIP, dbNodeScore)))
+}
+
+// UpdateNodeScore stores the reputation score of a node.
+func (db *DB) UpdateNodeScore(id ID, score float64) error {
+	return db.storeUint64(nodeItemKey(id, zeroIP, dbNodeScore), math.Float64bits(score))
+}
+
+// QueryReputableNodes retrieves up to n nodes with a positive reputation score,
+// ordered by score with the best node first. Nodes without a stored record are
+// skipped, as they cannot be dialed.
+func (db *DB) QueryReputableNodes(n int) []*Node {
+	type scored struct {
+		id    ID
+		score float64
+	}
+	var candidates []scored
+
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbNodePrefix)), nil)
+	for it.Next() {
+		id, rest := splitNodeKey(it.Key())
+		if !bytes.HasPrefix(rest, []byte(dbDiscoverRoot+":")) {
+			continue
+		}
+		if _, ip, field := splitNodeItemKey(it.Key()); field != dbNodeScore || ip != zeroIP {
+			continue
+		}
+		val, _ := binary.Uvarint(it.Value())
+		if score := math.Float64frombits(val); score > 0 {
+			candidates = append(candidates, scored{id, score})
+		}
+	}
+	it.Release()
+
+	slices.SortFunc(candidates, func(a, b scored) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return bytes.Compare(a.id[:], b.id[:])
+	})
+	nodes := make([]*Node, 0, n)
+	for _, c := range candidates {
+		if len(nodes) >= n {
+			break
+		}
+		if node := db.Node(c.id); node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
 // localSeq retrieves the local record sequence counter, defaulting to the current
 // timestamp if no previous exists. This ensures that wiping all data associated
 // with a node (apart from its key) will not generate already used sequence nums.
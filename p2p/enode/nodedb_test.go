@@ -300,6 +300,50 @@ func testSeedQuery() error {
 	return nil
 }
 
+func TestDBReputableNodes(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	// Store the seed query nodes with decreasing scores, some of them negative
+	// and one of them without a node record.
+	for i, seed := range nodeDBSeedQueryNodes {
+		if i != 2 {
+			if err := db.UpdateNode(seed.node); err != nil {
+				t.Fatalf("node %d: failed to insert: %v", i, err)
+			}
+		}
+		if err := db.UpdateLastPongReceived(seed.node.ID(), seed.node.IPAddr(), seed.pong); err != nil {
+			t.Fatalf("node %d: failed to insert pong: %v", i, err)
+		}
+		if err := db.UpdateNodeScore(seed.node.ID(), float64(4-i)); err != nil {
+			t.Fatalf("node %d: failed to insert score: %v", i, err)
+		}
+	}
+	if score := db.NodeScore(nodeDBSeedQueryNodes[5].node.ID()); score != -1 {
+		t.Fatalf("score mismatch: have %v, want %v", score, -1)
+	}
+	if score := db.NodeScore(keytestID); score != 0 {
+		t.Fatalf("unknown node score mismatch: have %v, want 0", score)
+	}
+	want := []ID{
+		nodeDBSeedQueryNodes[0].node.ID(),
+		nodeDBSeedQueryNodes[1].node.ID(),
+		nodeDBSeedQueryNodes[3].node.ID(),
+	}
+	nodes := db.QueryReputableNodes(10)
+	if len(nodes) != len(want) {
+		t.Fatalf("node count mismatch: have %d, want %d", len(nodes), len(want))
+	}
+	for i, n := range nodes {
+		if n.ID() != want[i] {
+			t.Errorf("node %d: have %v, want %v", i, n.ID(), want[i])
+		}
+	}
+	if nodes := db.QueryReputableNodes(1); len(nodes) != 1 || nodes[0].ID() != want[0] {
+		t.Errorf("limited query mismatch: have %v", nodes)
+	}
+}
+
 func TestDBPersistency(t *testing.T) {
 	root := t.TempDir()
 
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/p2p/capture"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -45,6 +46,8 @@ type Msg struct {
 	meterCap  Cap    // Protocol name and version for egress metering
 	meterCode uint64 // Message within protocol for egress metering
 	meterSize uint32 // Compressed message size for ingress metering
+
+	throttle *throttle // Limiter which reserved the egress bandwidth, if throttled
 }
 
 // Decode parses the RLP content of a message into
@@ -323,3 +326,52 @@ func (ev *msgEventer) Close() error {
 	}
 	return nil
 }
+
+// msgRecorder wraps a MsgReadWriter and records the messages sent and received
+// into a capture file.
+type msgRecorder struct {
+	MsgReadWriter
+
+	w        *capture.Writer
+	protocol string
+	version  uint
+}
+
+func newMsgRecorder(rw MsgReadWriter, w *capture.Writer, proto string, version uint) *msgRecorder {
+	return &msgRecorder{MsgReadWriter: rw, w: w, protocol: proto, version: version}
+}
+
+// ReadMsg reads a message from the underlying MsgReadWriter and records it.
+func (rec *msgRecorder) ReadMsg() (Msg, error) {
+	msg, err := rec.MsgReadWriter.ReadMsg()
+	if err != nil {
+		return msg, err
+	}
+	payload, err := rec.record(capture.Ingress, &msg)
+	if err != nil {
+		return msg, err
+	}
+	msg.Payload = bytes.NewReader(payload)
+	return msg, nil
+}
+
+// WriteMsg records a message and writes it to the underlying MsgReadWriter.
+func (rec *msgRecorder) WriteMsg(msg Msg) error {
+	payload, err := rec.record(capture.Egress, &msg)
+	if err != nil {
+		return err
+	}
+	msg.Payload = bytes.NewReader(payload)
+	return rec.MsgReadWriter.WriteMsg(msg)
+}
+
+// record reads the payload of a message and writes it to the capture. Failures
+// to write the capture are ignored, the traffic is not affected by them.
+func (rec *msgRecorder) record(dir capture.Direction, msg *Msg) ([]byte, error) {
+	payload := make([]byte, msg.Size)
+	if _, err := io.ReadFull(msg.Payload, payload); err != nil {
+		return nil, err
+	}
+	rec.w.Write(dir, rec.protocol, rec.version, msg.Code, payload)
+	return payload, nil
+}
@@ -23,6 +23,9 @@ import (
 	"runtime"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/capture"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 func ExampleMsgPipe() {
@@ -139,3 +142,53 @@ func TestEOFSignal(t *testing.T) {
 	default:
 	}
 }
+
+// This test checks that the message recorder captures the traffic without
+// altering it.
+func TestMsgRecorder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := capture.NewWriter(buf, &capture.Header{Caps: []string{"test/1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw1, rw2 := MsgPipe()
+	defer rw1.Close()
+	rec := newMsgRecorder(rw1, w, "test", 1)
+
+	go Send(rw2, 3, []string{"ping"})
+	if err := ExpectMsg(rec, 3, []string{"ping"}); err != nil {
+		t.Fatalf("recorded read: %v", err)
+	}
+	go Send(rec, 4, []string{"pong"})
+	if err := ExpectMsg(rw2, 4, []string{"pong"}); err != nil {
+		t.Fatalf("recorded write: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := capture.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []struct {
+		dir     capture.Direction
+		code    uint64
+		payload string
+	}{
+		{capture.Ingress, 3, "ping"},
+		{capture.Egress, 4, "pong"},
+	} {
+		have, err := r.Next()
+		if err != nil {
+			t.Fatalf("can't read record: %v", err)
+		}
+		var payload []string
+		if err := rlp.DecodeBytes(have.Payload, &payload); err != nil {
+			t.Fatalf("invalid payload: %v", err)
+		}
+		if have.Direction != want.dir || have.Code != want.code || have.Protocol != "test" || payload[0] != want.payload {
+			t.Errorf("wrong record %+v, want %+v", have, want)
+		}
+	}
+}
@@ -199,6 +199,14 @@ func (t *Tracker) Update(kind uint64, elapsed time.Duration, items int) {
 	t.roundtrip = time.Duration((1-measurementImpact)*float64(t.roundtrip) + measurementImpact*float64(elapsed))
 }
 
+// Roundtrip returns the estimated latency of the peer to respond to requests.
+func (t *Tracker) Roundtrip() time.Duration {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.roundtrip
+}
+
 // Trackers is a set of message rate trackers across a number of peers with the
 // goal of aggregating certain measurements across the entire set for outlier
 // filtering and newly joining initialization.
@@ -23,12 +23,14 @@ import (
 	"net"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/capture"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -104,10 +106,13 @@ type PeerEvent struct {
 
 // Peer represents a connected remote node.
 type Peer struct {
-	rw      *conn
-	running map[string]*protoRW
-	log     log.Logger
-	created mclock.AbsTime
+	rw       *conn
+	running  map[string]*protoRW
+	log      log.Logger
+	created  mclock.AbsTime
+	score    *peerScore
+	throttle atomic.Pointer[throttle] // Bandwidth limiter of the connection, nil if not throttled
+	streams  bool                     // Whether the transport carries every protocol on its own stream
 
 	wg       sync.WaitGroup
 	protoErr chan error
@@ -115,6 +120,9 @@ type Peer struct {
 	pingRecv chan struct{}
 	disc     chan DiscReason
 
+	// capture records the messages sent and received if set
+	capture *capture.Writer
+
 	// events receives message send / receive events if set
 	events   *event.Feed
 	testPipe *MsgPipeRW // for testing
@@ -249,6 +257,25 @@ func (p *Peer) Lifetime() mclock.AbsTime {
 	return mclock.Now() - p.created
 }
 
+// Score returns the current reputation score of the peer. The score is positive
+// for peers behaving usefully and negative for peers timing out or sending
+// invalid data.
+func (p *Peer) Score() float64 {
+	return p.score.score()
+}
+
+// Report adjusts the reputation score of the peer for the given behaviour.
+func (p *Peer) Report(event ScoreEvent) {
+	p.score.report(event)
+}
+
+// ReportResponse records the time the peer took to deliver a number of items in
+// response to a request of the given kind. The response times are compared to
+// the other connected peers to reward fast peers and penalize slow ones.
+func (p *Peer) ReportResponse(kind uint64, elapsed time.Duration, items int) {
+	p.score.response(kind, elapsed, items)
+}
+
 func newPeer(log log.Logger, conn *conn, protocols []Protocol) *Peer {
 	protomap := matchProtocols(protocols, conn.caps, conn)
 	p := &Peer{
@@ -260,10 +287,25 @@ func newPeer(log log.Logger, conn *conn, protocols []Protocol) *Peer {
 		closed:   make(chan struct{}),
 		pingRecv: make(chan struct{}, 16),
 		log:      log.New("id", conn.node.ID(), "conn", conn.flags),
+		score:    newPeerScore(mclock.System{}, 0, nil),
 	}
 	return p
 }
 
+// priority returns the traffic class of a message, identified by its code on the
+// multiplexed connection.
+func (p *Peer) priority(code uint64) Priority {
+	for _, proto := range p.running {
+		if code >= proto.offset && code < proto.offset+proto.Length {
+			if proto.MessagePriority == nil {
+				break
+			}
+			return proto.MessagePriority(code - proto.offset)
+		}
+	}
+	return PriorityNormal
+}
+
 func (p *Peer) Log() log.Logger {
 	return p.log
 }
@@ -275,6 +317,11 @@ func (p *Peer) run() (remoteRequested bool, err error) {
 		readErr    = make(chan error, 1)
 		reason     DiscReason // sent to the peer
 	)
+	if t, ok := p.rw.transport.(streamTransport); ok {
+		// Messages of the protocol streams are delivered by the transport.
+		t.setProtocols(p.running, p.handle)
+		p.streams = true
+	}
 	p.wg.Add(2)
 	go p.readLoop(readErr)
 	go p.pingLoop()
@@ -392,14 +439,66 @@ func (p *Peer) handle(msg Msg) error {
 			metrics.GetOrRegisterMeter(m, nil).Mark(int64(msg.meterSize))
 			metrics.GetOrRegisterMeter(m+"/packets", nil).Mark(1)
 		}
+		return p.deliver(proto, msg)
+	}
+	return nil
+}
+
+// deliver hands a received subprotocol message to its protocol handler. On a
+// throttled connection, the message first waits for the ingress bandwidth in the
+// queue of its priority, so that a delayed message doesn't hold back messages of
+// higher priority or of other protocols.
+func (p *Peer) deliver(proto *protoRW, msg Msg) error {
+	in := proto.in
+	if p.throttle.Load() != nil {
+		prio := p.priority(msg.Code)
+		proto.order.add(prio)
+		in = proto.queued[prio]
+	}
+	select {
+	case in <- msg:
+		return nil
+	case <-p.closed:
+		return io.EOF
+	}
+}
+
+// setThrottle applies a bandwidth limiter to the connection, creating the
+// ingress queues of the protocols. It may be called at most once, either before
+// or while the peer is running.
+func (p *Peer) setThrottle(t *throttle) {
+	for _, proto := range p.running {
+		proto.order = newIngressOrder()
+		for prio := range proto.queued {
+			proto.queued[prio] = make(chan Msg, ingressQueueSize)
+			go p.throttleLoop(proto, t, Priority(prio))
+		}
+	}
+	p.throttle.Store(t)
+}
+
+// throttleLoop delivers the received messages of a protocol waiting in the
+// queue of one priority, as the ingress bandwidth becomes available.
+func (p *Peer) throttleLoop(proto *protoRW, t *throttle, prio Priority) {
+	for {
 		select {
-		case proto.in <- msg:
-			return nil
+		case msg := <-proto.queued[prio]:
+			if err := t.waitIngress(msg.Code, int(msg.meterSize)); err != nil {
+				return
+			}
+			if !proto.order.wait(prio, p.closed) {
+				return
+			}
+			select {
+			case proto.in <- msg:
+				proto.order.done(prio)
+			case <-p.closed:
+				return
+			}
 		case <-p.closed:
-			return io.EOF
+			return
 		}
 	}
-	return nil
 }
 
 // decodeDisconnectMessage decodes the payload of discMsg.
@@ -464,12 +563,18 @@ func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error)
 	p.wg.Add(len(p.running))
 	for _, proto := range p.running {
 		proto.closed = p.closed
-		proto.wstart = writeStart
 		proto.werr = writeErr
+		if !p.streams {
+			proto.wstart = writeStart
+		}
+		proto.throttle = &p.throttle
 		var rw MsgReadWriter = proto
 		if p.events != nil {
 			rw = newMsgEventer(rw, p.events, p.ID(), proto.Name, p.Info().Network.RemoteAddress, p.Info().Network.LocalAddress)
 		}
+		if p.capture != nil {
+			rw = newMsgRecorder(rw, p.capture, proto.Name, proto.Version)
+		}
 		p.log.Trace(fmt.Sprintf("Starting protocol %s/%d", proto.Name, proto.Version))
 		go func() {
 			defer p.wg.Done()
@@ -496,14 +601,24 @@ func (p *Peer) getProto(code uint64) (*protoRW, error) {
 	return nil, newPeerError(errInvalidMsgCode, "%d", code)
 }
 
+// streamTransport is implemented by transports which carry every protocol on
+// its own stream. The writes of the protocols are not serialized on such
+// transports, and the transport delivers their messages to the handler.
+type streamTransport interface {
+	setProtocols(running map[string]*protoRW, handle func(Msg) error)
+}
+
 type protoRW struct {
 	Protocol
-	in     chan Msg        // receives read messages
-	closed <-chan struct{} // receives when peer is shutting down
-	wstart <-chan struct{} // receives when write may start
-	werr   chan<- error    // for write results
-	offset uint64
-	w      MsgWriter
+	in       chan Msg                  // receives read messages
+	queued   [numPriorities]chan Msg   // read messages waiting for ingress bandwidth
+	order    *ingressOrder             // delivery order of the queued messages
+	closed   <-chan struct{}           // receives when peer is shutting down
+	wstart   <-chan struct{}           // receives when write may start, nil if not serialized
+	werr     chan<- error              // for write results
+	throttle *atomic.Pointer[throttle] // bandwidth limiter of the connection
+	offset   uint64
+	w        MsgWriter
 }
 
 func (rw *protoRW) WriteMsg(msg Msg) (err error) {
@@ -515,6 +630,20 @@ func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 
 	msg.Code += rw.offset
 
+	// Reserve the egress bandwidth before waiting for the write to start, so
+	// that a throttled message doesn't hold back the messages of higher
+	// priority, nor those of the other protocols.
+	if rw.throttle != nil {
+		if t := rw.throttle.Load(); t != nil {
+			if err := t.waitEgress(msg.Code, egressEstimate(msg)); err != nil {
+				return err
+			}
+			msg.throttle = t
+		}
+	}
+	if rw.wstart == nil {
+		return rw.writeStream(msg)
+	}
 	select {
 	case <-rw.wstart:
 		err = rw.w.WriteMsg(msg)
@@ -529,6 +658,25 @@ func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 	return err
 }
 
+// writeStream sends a message on a transport which carries the protocol on its
+// own stream. Such writes are not serialized with the writes of the other
+// protocols, only failures are reported to Peer.run.
+func (rw *protoRW) writeStream(msg Msg) error {
+	select {
+	case <-rw.closed:
+		return ErrShuttingDown
+	default:
+	}
+	err := rw.w.WriteMsg(msg)
+	if err != nil {
+		select {
+		case rw.werr <- err:
+		case <-rw.closed:
+		}
+	}
+	return err
+}
+
 func (rw *protoRW) ReadMsg() (Msg, error) {
 	select {
 	case msg := <-rw.in:
@@ -548,6 +696,7 @@ type PeerInfo struct {
 	ID      string   `json:"id"`            // Unique node identifier
 	Name    string   `json:"name"`          // Name of the node, including client type, version, OS, custom data
 	Caps    []string `json:"caps"`          // Protocols advertised by this peer
+	Score   float64  `json:"score"`         // Reputation score of the peer
 	Network struct {
 		LocalAddress  string `json:"localAddress"`  // Local endpoint of the TCP data connection
 		RemoteAddress string `json:"remoteAddress"` // Remote endpoint of the TCP data connection
@@ -571,6 +720,7 @@ func (p *Peer) Info() *PeerInfo {
 		ID:        p.ID().String(),
 		Name:      p.Fullname(),
 		Caps:      caps,
+		Score:     p.Score(),
 		Protocols: make(map[string]interface{}, len(p.running)),
 	}
 	if p.Node().Seq() > 0 {
@@ -86,6 +86,12 @@ func newNode(id enode.ID, addr string) *enode.Node {
 }
 
 func testPeer(protos []Protocol) (func(), *conn, *Peer, <-chan error) {
+	return testThrottledPeer(protos, nil)
+}
+
+// testThrottledPeer is like testPeer, but applies the given bandwidth limits to
+// the peer if they are non-nil.
+func testThrottledPeer(protos []Protocol, limits *BandwidthLimits) (func(), *conn, *Peer, <-chan error) {
 	var (
 		fd1, fd2   = net.Pipe()
 		key1, key2 = newkey(), newkey()
@@ -101,6 +107,10 @@ func testPeer(protos []Protocol) (func(), *conn, *Peer, <-chan error) {
 	}
 
 	peer := newPeer(log.Root(), c1, protos)
+	if limits != nil {
+		global := [2]*rateLimiter{newRateLimiter(0), newRateLimiter(0)}
+		peer.setThrottle(newThrottle(*limits, global, peer.priority, peer.closed))
+	}
 	errc := make(chan error, 1)
 	go func() {
 		_, err := peer.run()
@@ -63,6 +63,11 @@ type Protocol struct {
 
 	// Attributes contains protocol specific information for the node record.
 	Attributes []enr.Entry
+
+	// MessagePriority is an optional classifier of the protocol messages into
+	// traffic classes, deciding which messages are delayed first when the
+	// bandwidth of the server is capped. Messages default to PriorityNormal.
+	MessagePriority func(code uint64) Priority
 }
 
 func (p Protocol) cap() Cap {
@@ -0,0 +1,139 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/msgrate"
+)
+
+// ScoreEvent is a peer behaviour reported by the protocol handlers, affecting
+// the reputation score of the peer.
+type ScoreEvent int
+
+const (
+	ScoreUseful  ScoreEvent = iota // Peer announced or delivered new, useful data
+	ScoreTimeout                   // Peer failed to respond to a request in time
+	ScoreInvalid                   // Peer sent invalid or unsolicited data
+)
+
+// scoreWeights is the score adjustment applied for each reported event.
+var scoreWeights = [...]float64{
+	ScoreUseful:  1,
+	ScoreTimeout: -5,
+	ScoreInvalid: -20,
+}
+
+const (
+	// scoreHalfLife is the time after which the event based part of the score
+	// decays to half of its value, so that old behaviour is slowly forgotten.
+	scoreHalfLife = 30 * time.Minute
+
+	// scoreLimit is the absolute cap on the event based part of the score, to
+	// prevent long lived peers from accumulating unbeatable reputations.
+	scoreLimit = 100
+
+	// latencyWeight is the maximum score awarded to peers responding faster
+	// than the median peer, or deducted from peers responding slower.
+	latencyWeight = 10
+
+	// minDialScore is the persisted score below which discovered nodes are not
+	// dialed anymore. Static nodes are dialed regardless.
+	minDialScore = -20
+)
+
+// peerScore tracks the reputation of a connected peer. The score consists of
+// an exponentially decaying sum of the reported events and a latency component
+// comparing the response times of the peer to the other connected peers.
+type peerScore struct {
+	clock   mclock.Clock
+	rates   *msgrate.Trackers // Response rates of all peers, nil if latency is not scored
+	tracker *msgrate.Tracker  // Response rate of this peer
+
+	value   float64        // Event based score at the time of the last update
+	updated mclock.AbsTime // Time of the last update
+	lock    sync.Mutex
+}
+
+// newPeerScore creates a score tracker starting off from the given initial
+// score, typically the one recorded at the previous connection of the peer.
+func newPeerScore(clock mclock.Clock, initial float64, rates *msgrate.Trackers) *peerScore {
+	s := &peerScore{
+		clock:   clock,
+		rates:   rates,
+		value:   math.Max(-scoreLimit, math.Min(scoreLimit, initial)),
+		updated: clock.Now(),
+	}
+	if rates != nil {
+		s.tracker = msgrate.NewTracker(rates.MeanCapacities(), rates.MedianRoundTrip())
+	} else {
+		s.tracker = msgrate.NewTracker(nil, 0)
+	}
+	return s
+}
+
+// decay applies the time based decay to the event based score. The lock must
+// be held by the caller.
+func (s *peerScore) decay() {
+	now := s.clock.Now()
+	if elapsed := time.Duration(now - s.updated); elapsed > 0 {
+		s.value *= math.Pow(0.5, float64(elapsed)/float64(scoreHalfLife))
+		s.updated = now
+	}
+}
+
+// report adjusts the score with the weight of the given event.
+func (s *peerScore) report(event ScoreEvent) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.decay()
+	s.value = math.Max(-scoreLimit, math.Min(scoreLimit, s.value+scoreWeights[event]))
+}
+
+// response records the time it took the peer to deliver a response.
+func (s *peerScore) response(kind uint64, elapsed time.Duration, items int) {
+	s.tracker.Update(kind, elapsed, items)
+}
+
+// score returns the current reputation score of the peer.
+func (s *peerScore) score() float64 {
+	s.lock.Lock()
+	s.decay()
+	value := s.value
+	s.lock.Unlock()
+
+	return value + s.latency()
+}
+
+// latency returns the latency component of the score, positive if the peer is
+// faster than the median peer and negative if it is slower.
+func (s *peerScore) latency() float64 {
+	if s.rates == nil {
+		return 0
+	}
+	median, rtt := s.rates.MedianRoundTrip(), s.tracker.Roundtrip()
+	if median <= 0 || rtt <= 0 {
+		return 0
+	}
+	ratio := float64(median-rtt) / float64(median)
+	return latencyWeight * math.Max(-1, math.Min(1, ratio))
+}
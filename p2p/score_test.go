@@ -0,0 +1,84 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/msgrate"
+)
+
+func TestPeerScoreEvents(t *testing.T) {
+	clock := new(mclock.Simulated)
+	s := newPeerScore(clock, 0, nil)
+
+	for i := 0; i < 10; i++ {
+		s.report(ScoreUseful)
+	}
+	s.report(ScoreTimeout)
+	if have, want := s.score(), 10*scoreWeights[ScoreUseful]+scoreWeights[ScoreTimeout]; have != want {
+		t.Fatalf("score mismatch: have %v, want %v", have, want)
+	}
+	// Check that the score halves after the half life.
+	clock.Run(scoreHalfLife)
+	if have, want := s.score(), (10*scoreWeights[ScoreUseful]+scoreWeights[ScoreTimeout])/2; math.Abs(have-want) > 1e-9 {
+		t.Fatalf("decayed score mismatch: have %v, want %v", have, want)
+	}
+	// Check that the score is capped.
+	for i := 0; i < 10; i++ {
+		s.report(ScoreInvalid)
+	}
+	if have := s.score(); have != -scoreLimit {
+		t.Fatalf("capped score mismatch: have %v, want %v", have, -scoreLimit)
+	}
+	// Check that the initial score is capped too.
+	if have := newPeerScore(clock, 1000, nil).score(); have != scoreLimit {
+		t.Fatalf("initial score mismatch: have %v, want %v", have, scoreLimit)
+	}
+}
+
+func TestPeerScoreLatency(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		rates = msgrate.NewTrackers(log.Root())
+		fast  = newPeerScore(clock, 0, rates)
+		avg   = newPeerScore(clock, 0, rates)
+		slow  = newPeerScore(clock, 0, rates)
+	)
+	rates.Track("fast", fast.tracker)
+	rates.Track("avg", avg.tracker)
+	rates.Track("slow", slow.tracker)
+
+	for i := 0; i < 100; i++ {
+		fast.response(0, time.Second, 1)
+		avg.response(0, 4*time.Second, 1)
+		slow.response(0, 20*time.Second, 1)
+	}
+	if score := fast.score(); score <= 0 || score > latencyWeight {
+		t.Errorf("fast peer score out of range: %v", score)
+	}
+	if score := avg.score(); math.Abs(score) > 1e-9 {
+		t.Errorf("median peer score not neutral: %v", score)
+	}
+	if score := slow.score(); score != -latencyWeight {
+		t.Errorf("slow peer score mismatch: have %v, want %v", score, -latencyWeight)
+	}
+}
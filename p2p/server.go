@@ -26,6 +26,8 @@ import (
 	"fmt"
 	"net"
 	"net/netip"
+	"os"
+	"path/filepath"
 	"slices"
 	"sync"
 	"sync/atomic"
@@ -36,9 +38,11 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/capture"
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/msgrate"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 )
 
@@ -70,6 +74,9 @@ type protoHandshakeError struct{ err error }
 func (e *protoHandshakeError) Error() string { return fmt.Sprintf("rlpx proto error: %v", e.err) }
 func (e *protoHandshakeError) Unwrap() error { return e.err }
 
+// ListenFunc creates the listener for inbound connections, like net.Listen.
+type ListenFunc func(network, addr string) (net.Listener, error)
+
 // Server manages all peer connections.
 type Server struct {
 	// Config fields may not be modified while the server is running.
@@ -79,12 +86,13 @@ type Server struct {
 	// the whole protocol stack.
 	newTransport func(net.Conn, *ecdsa.PublicKey) transport
 	newPeerHook  func(*Peer)
-	listenFunc   func(network, addr string) (net.Listener, error)
+	listenFunc   ListenFunc
 
 	lock    sync.Mutex // protects running
 	running bool
 
 	listener     net.Listener
+	quic         *quicHost // QUIC endpoint, nil if the QUIC transport is disabled
 	ourHandshake *protoHandshake
 	loopWG       sync.WaitGroup // loop, listenLoop
 	peerFeed     event.Feed
@@ -96,6 +104,14 @@ type Server struct {
 	discv5    *discover.UDPv5
 	discmix   *enode.FairMix
 	dialsched *dialScheduler
+	rates     *msgrate.Trackers // Response rates of the peers, used for scoring latency
+	allowlist *Allowlist        // Nodes allowed to connect, nil if not restricted
+
+	bwLock    sync.Mutex
+	bandwidth BandwidthLimits        // Current bandwidth limits, adjustable at runtime
+	ingress   *rateLimiter           // Ingress limiter shared by all peers
+	egress    *rateLimiter           // Egress limiter shared by all peers
+	throttles map[*throttle]struct{} // Bandwidth limiters of the live peers
 
 	// This is read by the NAT port mapping loop.
 	portMappingRegister chan *portMapping
@@ -110,7 +126,8 @@ type Server struct {
 	checkpointPostHandshake chan *conn
 	checkpointAddPeer       chan *conn
 
-	// State of run loop and listenLoop.
+	// State of the listen loops.
+	inboundLock    sync.Mutex
 	inboundHistory expHeap
 }
 
@@ -325,6 +342,9 @@ func (srv *Server) Stop() {
 		// this unblocks listener Accept
 		srv.listener.Close()
 	}
+	if srv.quic != nil {
+		srv.quic.close()
+	}
 	close(srv.quit)
 	srv.lock.Unlock()
 	srv.loopWG.Wait()
@@ -383,10 +403,23 @@ func (srv *Server) Start() (err error) {
 	if srv.newTransport == nil {
 		srv.newTransport = newRLPX
 	}
+	if srv.listenFunc == nil {
+		srv.listenFunc = srv.ListenFunc
+	}
 	if srv.listenFunc == nil {
 		srv.listenFunc = net.Listen
 	}
+	if srv.Allowlist != "" {
+		if srv.allowlist, err = NewAllowlist(srv.Allowlist); err != nil {
+			return fmt.Errorf("failed to load allowlist: %v", err)
+		}
+	}
 	srv.quit = make(chan struct{})
+	srv.rates = msgrate.NewTrackers(srv.log)
+	srv.bandwidth = srv.Bandwidth
+	srv.ingress = newRateLimiter(srv.bandwidth.Ingress)
+	srv.egress = newRateLimiter(srv.bandwidth.Egress)
+	srv.throttles = make(map[*throttle]struct{})
 	srv.delpeer = make(chan peerDrop)
 	srv.checkpointPostHandshake = make(chan *conn)
 	srv.checkpointAddPeer = make(chan *conn)
@@ -405,6 +438,11 @@ func (srv *Server) Start() (err error) {
 			return err
 		}
 	}
+	if srv.QUICAddr != "" {
+		if err := srv.setupQUIC(); err != nil {
+			return err
+		}
+	}
 	if err := srv.setupDiscovery(); err != nil {
 		return err
 	}
@@ -487,6 +525,7 @@ func (srv *Server) setupDiscovery() error {
 			NetRestrict: srv.NetRestrict,
 			Bootnodes:   srv.BootstrapNodesV5,
 			Log:         srv.log,
+			V5Topics:    srv.Config.DiscoveryV5Topics,
 		}
 		srv.discv5, err = discover.ListenV5(sconn, srv.localnode, cfg)
 		if err != nil {
@@ -527,6 +566,10 @@ func (srv *Server) setupDialScheduler() {
 		netRestrict:    srv.NetRestrict,
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
+		nodeScore:      srv.nodedb.NodeScore,
+	}
+	if srv.allowlist != nil || srv.AdmissionPolicy != nil {
+		config.admit = srv.admit
 	}
 	if srv.discv4 != nil {
 		config.resolver = srv.discv4
@@ -534,6 +577,14 @@ func (srv *Server) setupDialScheduler() {
 	if config.dialer == nil {
 		config.dialer = tcpDialer{&net.Dialer{Timeout: defaultDialTimeout}}
 	}
+	if srv.quic != nil {
+		config.dialer = &quicDialer{host: srv.quic, fallback: config.dialer, log: srv.log}
+	}
+	// Prefer redialing nodes which behaved well in the past over the random
+	// nodes found by discovery.
+	if reputable := srv.nodedb.QueryReputableNodes(config.maxDialPeers); len(reputable) > 0 {
+		srv.discmix.AddSource(enode.WithSourceName("reputation", enode.IterNodes(reputable)))
+	}
 	srv.dialsched = newDialScheduler(config, srv.discmix, srv.SetupConn)
 	for _, n := range srv.StaticNodes {
 		srv.dialsched.addStatic(n)
@@ -710,7 +761,9 @@ running:
 			// A peer disconnected.
 			d := common.PrettyDuration(mclock.Now() - pd.created)
 			delete(peers, pd.ID())
-			srv.log.Debug("Removing p2p peer", "peercount", len(peers), "id", pd.ID(), "duration", d, "req", pd.requested, "err", pd.err)
+			srv.log.Debug("Removing p2p peer", "peercount", len(peers), "id", pd.ID(), "duration", d, "score", pd.Score(), "req", pd.requested, "err", pd.err)
+			srv.dropPeerScore(pd.Peer)
+			srv.dropThrottle(pd.Peer)
 			srv.dialsched.peerRemoved(pd.rw)
 			if pd.Inbound() {
 				inboundCount--
@@ -742,6 +795,7 @@ running:
 		p := <-srv.delpeer
 		p.log.Trace("<-delpeer (spindown)")
 		delete(peers, p.ID())
+		srv.dropPeerScore(p.Peer)
 	}
 }
 
@@ -849,6 +903,8 @@ func (srv *Server) checkInboundConn(remoteIP netip.Addr) error {
 		return errors.New("not in netrestrict list")
 	}
 	// Reject Internet peers that try too often.
+	srv.inboundLock.Lock()
+	defer srv.inboundLock.Unlock()
 	now := srv.clock.Now()
 	srv.inboundHistory.expire(now, nil)
 	if !netutil.AddrIsLAN(remoteIP) && srv.inboundHistory.contains(remoteIP.String()) {
@@ -863,10 +919,14 @@ func (srv *Server) checkInboundConn(remoteIP netip.Addr) error {
 // or the handshakes have failed.
 func (srv *Server) SetupConn(fd net.Conn, flags connFlag, dialDest *enode.Node) error {
 	c := &conn{fd: fd, flags: flags, cont: make(chan error)}
-	if dialDest == nil {
-		c.transport = srv.newTransport(fd, nil)
+	var dialPubkey *ecdsa.PublicKey
+	if dialDest != nil {
+		dialPubkey = dialDest.Pubkey()
+	}
+	if qc, ok := fd.(*quicConn); ok {
+		c.transport = newQUICTransport(qc, dialPubkey)
 	} else {
-		c.transport = srv.newTransport(fd, dialDest.Pubkey())
+		c.transport = srv.newTransport(fd, dialPubkey)
 	}
 
 	err := srv.setupConn(c, dialDest)
@@ -912,6 +972,10 @@ func (srv *Server) setupConn(c *conn, dialDest *enode.Node) error {
 		c.node = nodeFromConn(remotePubkey, c.fd)
 	}
 	clog := srv.log.New("id", c.node.ID(), "addr", c.fd.RemoteAddr(), "conn", c.flags)
+	if err := srv.admit(c.node); err != nil {
+		clog.Trace("Rejected peer", "err", err)
+		return err
+	}
 	err = srv.checkpoint(c, srv.checkpointPostHandshake)
 	if err != nil {
 		clog.Trace("Rejected peer", "err", err)
@@ -941,9 +1005,13 @@ func (srv *Server) setupConn(c *conn, dialDest *enode.Node) error {
 func nodeFromConn(pubkey *ecdsa.PublicKey, conn net.Conn) *enode.Node {
 	var ip net.IP
 	var port int
-	if tcp, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
-		ip = tcp.IP
-		port = tcp.Port
+	switch addr := conn.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		ip = addr.IP
+		port = addr.Port
+	case *net.UDPAddr:
+		// The listening port of QUIC peers is not known.
+		ip = addr.IP
 	}
 	return enode.NewV4(pubkey, ip, port, port)
 }
@@ -961,15 +1029,142 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 
 func (srv *Server) launchPeer(c *conn) *Peer {
 	p := newPeer(srv.log, c, srv.Protocols)
+	p.score = newPeerScore(srv.clock, srv.nodedb.NodeScore(c.node.ID()), srv.rates)
+	srv.rates.Track(c.node.ID().String(), p.score.tracker)
+	srv.throttlePeer(p)
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
 		p.events = &srv.peerFeed
 	}
+	if srv.CaptureDir != "" {
+		p.capture = srv.openCapture(p)
+	}
 	go srv.runPeer(p)
 	return p
 }
 
+// openCapture creates the capture file recording the messages of a peer. It
+// returns nil if the file can't be created.
+func (srv *Server) openCapture(p *Peer) *capture.Writer {
+	if err := os.MkdirAll(srv.CaptureDir, 0755); err != nil {
+		p.log.Warn("Failed to create capture directory", "err", err)
+		return nil
+	}
+	header := &capture.Header{
+		ID:         p.ID(),
+		Name:       p.Fullname(),
+		RemoteAddr: p.RemoteAddr().String(),
+		LocalAddr:  p.LocalAddr().String(),
+		Inbound:    p.Inbound(),
+	}
+	for _, proto := range p.running {
+		header.Caps = append(header.Caps, proto.cap().String())
+	}
+	slices.Sort(header.Caps)
+	now := time.Now()
+	header.Start = uint64(now.UnixNano())
+	name := fmt.Sprintf("%s-%x.cap", now.UTC().Format("20060102T150405.000"), p.ID().Bytes()[:8])
+	w, err := capture.Create(filepath.Join(srv.CaptureDir, name), header)
+	if err != nil {
+		p.log.Warn("Failed to create capture file", "err", err)
+		return nil
+	}
+	p.log.Debug("Capturing peer traffic", "file", name)
+	return w
+}
+
+// dropPeerScore stops tracking the response rate of a disconnected peer and
+// records its reputation score in the node database, to be picked up again
+// when the peer reconnects or when selecting nodes to dial.
+func (srv *Server) dropPeerScore(p *Peer) {
+	srv.rates.Untrack(p.ID().String())
+	if err := srv.nodedb.UpdateNodeScore(p.ID(), p.Score()); err != nil {
+		p.log.Debug("Failed to store peer score", "err", err)
+	}
+	// Only remember the records of dialed peers, the endpoints of inbound
+	// connections are not dialable.
+	if !p.Inbound() {
+		srv.nodedb.UpdateNode(p.Node())
+	}
+}
+
+// throttlePeer applies the bandwidth limits to a peer if any limit is set and
+// the peer isn't throttled yet. Only the connections of real transports are
+// throttled.
+func (srv *Server) throttlePeer(p *Peer) {
+	switch p.rw.transport.(type) {
+	case *rlpxTransport, *quicTransport:
+	default:
+		return
+	}
+	srv.bwLock.Lock()
+	defer srv.bwLock.Unlock()
+
+	if !srv.bandwidth.enabled() || p.throttle.Load() != nil {
+		return
+	}
+	t := newThrottle(srv.bandwidth, [2]*rateLimiter{srv.ingress, srv.egress}, p.priority, p.closed)
+	srv.throttles[t] = struct{}{}
+	p.setThrottle(t)
+}
+
+// dropThrottle forgets the bandwidth limiter of a disconnected peer.
+func (srv *Server) dropThrottle(p *Peer) {
+	t := p.throttle.Load()
+	if t == nil {
+		return
+	}
+	srv.bwLock.Lock()
+	defer srv.bwLock.Unlock()
+
+	delete(srv.throttles, t)
+}
+
+// BandwidthLimits returns the current bandwidth limits of the server.
+func (srv *Server) BandwidthLimits() BandwidthLimits {
+	srv.bwLock.Lock()
+	defer srv.bwLock.Unlock()
+
+	if srv.throttles == nil {
+		return srv.Bandwidth
+	}
+	return srv.bandwidth
+}
+
+// SetBandwidthLimits changes the bandwidth limits of the server, applying them
+// to the connected peers too. Peers connected while no limit was set are
+// throttled from now on.
+func (srv *Server) SetBandwidthLimits(limits BandwidthLimits) error {
+	if limits.Ingress < 0 || limits.Egress < 0 || limits.PeerIngress < 0 || limits.PeerEgress < 0 {
+		return errors.New("negative bandwidth limit")
+	}
+	srv.bwLock.Lock()
+	if srv.throttles == nil {
+		srv.bwLock.Unlock()
+		return errServerStopped
+	}
+	srv.bandwidth = limits
+	srv.ingress.setRate(limits.Ingress)
+	srv.egress.setRate(limits.Egress)
+	for t := range srv.throttles {
+		t.setLimits(limits)
+	}
+	srv.bwLock.Unlock()
+
+	// The peer operation must not run under bwLock, the server loop takes it
+	// when launching peers.
+	if limits.enabled() {
+		srv.doPeerOp(func(peers map[enode.ID]*Peer) {
+			for _, p := range peers {
+				srv.throttlePeer(p)
+			}
+		})
+	}
+	srv.log.Info("Updated bandwidth limits", "ingress", limits.Ingress, "egress", limits.Egress, "peeringress", limits.PeerIngress, "peeregress", limits.PeerEgress)
+	return nil
+}
+
 // runPeer runs in its own goroutine for each peer.
 func (srv *Server) runPeer(p *Peer) {
 	if srv.newPeerHook != nil {
@@ -984,6 +1179,9 @@ func (srv *Server) runPeer(p *Peer) {
 
 	// Run the per-peer main loop.
 	remoteRequested, err := p.run()
+	if p.capture != nil {
+		p.capture.Close()
+	}
 
 	// Announce disconnect on the main loop to update the peer set.
 	// The main loop waits for existing peers to be sent on srv.delpeer
@@ -22,6 +22,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 )
@@ -67,6 +68,8 @@ type Tracker struct {
 	expire  *list.List          // Linked list tracking the expiration order
 	wake    *time.Timer         // Timer tracking the expiration of the next item
 
+	timeouts event.FeedOf[string] // Feed of peers failing to respond in time
+
 	lock sync.Mutex // Lock protecting from concurrent updates
 }
 
@@ -84,9 +87,6 @@ func New(protocol string, timeout time.Duration) *Tracker {
 // Track adds a network request to the tracker to wait for a response to arrive
 // or until the request it cancelled or times out.
 func (t *Tracker) Track(peer string, version uint, reqCode uint64, resCode uint64, id uint64) {
-	if !metrics.Enabled() {
-		return
-	}
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
@@ -111,8 +111,10 @@ func (t *Tracker) Track(peer string, version uint, reqCode uint64, resCode uint6
 		time:    time.Now(),
 		expire:  t.expire.PushBack(id),
 	}
-	g := fmt.Sprintf("%s/%s/%d/%#02x", trackedGaugeName, t.protocol, version, reqCode)
-	metrics.GetOrRegisterGauge(g, nil).Inc(1)
+	if metrics.Enabled() {
+		g := fmt.Sprintf("%s/%s/%d/%#02x", trackedGaugeName, t.protocol, version, reqCode)
+		metrics.GetOrRegisterGauge(g, nil).Inc(1)
+	}
 
 	// If we've just inserted the first item, start the expiration timer
 	if t.wake == nil {
@@ -124,10 +126,10 @@ func (t *Tracker) Track(peer string, version uint, reqCode uint64, resCode uint6
 // being delivered for the first network request.
 func (t *Tracker) clean() {
 	t.lock.Lock()
-	defer t.lock.Unlock()
 
 	// Expire anything within a certain threshold (might be no items at all if
 	// we raced with the delivery)
+	var lost []string
 	for t.expire.Len() > 0 {
 		// Stop iterating if the next pending request is still alive
 		var (
@@ -141,14 +143,24 @@ func (t *Tracker) clean() {
 		// Nope, dead, drop it
 		t.expire.Remove(head)
 		delete(t.pending, id)
+		lost = append(lost, req.peer)
 
-		g := fmt.Sprintf("%s/%s/%d/%#02x", trackedGaugeName, t.protocol, req.version, req.reqCode)
-		metrics.GetOrRegisterGauge(g, nil).Dec(1)
+		if metrics.Enabled() {
+			g := fmt.Sprintf("%s/%s/%d/%#02x", trackedGaugeName, t.protocol, req.version, req.reqCode)
+			metrics.GetOrRegisterGauge(g, nil).Dec(1)
 
-		m := fmt.Sprintf("%s/%s/%d/%#02x", lostMeterName, t.protocol, req.version, req.reqCode)
-		metrics.GetOrRegisterMeter(m, nil).Mark(1)
+			m := fmt.Sprintf("%s/%s/%d/%#02x", lostMeterName, t.protocol, req.version, req.reqCode)
+			metrics.GetOrRegisterMeter(m, nil).Mark(1)
+		}
 	}
 	t.schedule()
+	t.lock.Unlock()
+
+	// Notify the subscribers outside of the lock, they might need to interact
+	// with the tracker.
+	for _, peer := range lost {
+		t.timeouts.Send(peer)
+	}
 }
 
 // schedule starts a timer to trigger on the expiration of the first network
@@ -161,20 +173,27 @@ func (t *Tracker) schedule() {
 	t.wake = time.AfterFunc(time.Until(t.pending[t.expire.Front().Value.(uint64)].time.Add(t.timeout)), t.clean)
 }
 
+// SubscribeTimeouts subscribes to the identifiers of peers failing to respond to
+// a tracked request before the timeout.
+func (t *Tracker) SubscribeTimeouts(ch chan<- string) event.Subscription {
+	return t.timeouts.Subscribe(ch)
+}
+
 // Fulfil fills a pending request, if any is available, reporting on various metrics.
-func (t *Tracker) Fulfil(peer string, version uint, code uint64, id uint64) {
-	if !metrics.Enabled() {
-		return
-	}
+// The time elapsed since the request was made is returned, or zero if no matching
+// request was pending.
+func (t *Tracker) Fulfil(peer string, version uint, code uint64, id uint64) time.Duration {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
 	// If it's a non existing request, track as stale response
 	req, ok := t.pending[id]
 	if !ok {
-		m := fmt.Sprintf("%s/%s/%d/%#02x", staleMeterName, t.protocol, version, code)
-		metrics.GetOrRegisterMeter(m, nil).Mark(1)
-		return
+		if metrics.Enabled() {
+			m := fmt.Sprintf("%s/%s/%d/%#02x", staleMeterName, t.protocol, version, code)
+			metrics.GetOrRegisterMeter(m, nil).Mark(1)
+		}
+		return 0
 	}
 	// If the response is funky, it might be some active attack
 	if req.peer != peer || req.version != version || req.resCode != code {
@@ -182,7 +201,7 @@ func (t *Tracker) Fulfil(peer string, version uint, code uint64, id uint64) {
 			"have", fmt.Sprintf("%s:%s/%d:%d", peer, t.protocol, version, code),
 			"want", fmt.Sprintf("%s:%s/%d:%d", peer, t.protocol, req.version, req.resCode),
 		)
-		return
+		return 0
 	}
 	// Everything matches, mark the request serviced and meter it
 	t.expire.Remove(req.expire)
@@ -192,14 +211,18 @@ func (t *Tracker) Fulfil(peer string, version uint, code uint64, id uint64) {
 			t.schedule()
 		}
 	}
-	g := fmt.Sprintf("%s/%s/%d/%#02x", trackedGaugeName, t.protocol, req.version, req.reqCode)
-	metrics.GetOrRegisterGauge(g, nil).Dec(1)
+	elapsed := time.Since(req.time)
+	if metrics.Enabled() {
+		g := fmt.Sprintf("%s/%s/%d/%#02x", trackedGaugeName, t.protocol, req.version, req.reqCode)
+		metrics.GetOrRegisterGauge(g, nil).Dec(1)
 
-	h := fmt.Sprintf("%s/%s/%d/%#02x", waitHistName, t.protocol, req.version, req.reqCode)
-	sampler := func() metrics.Sample {
-		return metrics.ResettingSample(
-			metrics.NewExpDecaySample(1028, 0.015),
-		)
+		h := fmt.Sprintf("%s/%s/%d/%#02x", waitHistName, t.protocol, req.version, req.reqCode)
+		sampler := func() metrics.Sample {
+			return metrics.ResettingSample(
+				metrics.NewExpDecaySample(1028, 0.015),
+			)
+		}
+		metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(elapsed.Microseconds())
 	}
-	metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(time.Since(req.time).Microseconds())
+	return elapsed
 }
@@ -95,6 +95,10 @@ func (t *rlpxTransport) WriteMsg(msg Msg) error {
 	if err != nil {
 		return err
 	}
+	if msg.throttle != nil {
+		// The bandwidth was reserved by the peer before sending.
+		msg.throttle.settleEgress(msg, int(size))
+	}
 
 	// Set metrics.
 	msg.meterSize = size
@@ -0,0 +1,803 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/netip"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/golang/snappy"
+	"github.com/quic-go/quic-go"
+)
+
+// The QUIC transport is an experimental alternative to RLPx over TCP. A connection
+// consists of a bidirectional control stream, opened by the dialer, and one
+// unidirectional stream per subprotocol in each direction. The control stream
+// carries the authentication and the base protocol messages. Since every
+// subprotocol gets its own stream, packet loss or flow control on one of them
+// doesn't stall the delivery of the others. The peer writes the messages of each
+// subprotocol independently, and the reader of each stream delivers the messages
+// to the subprotocol directly, so a slow subprotocol only holds back its own
+// stream.
+//
+// Messages are framed as uvarint(code) || uvarint(size) || payload, with the
+// payload snappy-compressed after the protocol handshake if both sides support it.
+//
+// QUIC encrypts with TLS 1.3, using ephemeral self-signed certificates. The node
+// identity is established by signing the TLS exporter secret with the node key:
+// the first frame on the control stream in each direction contains this signature.
+// The disconnect reason is sent as the QUIC application error code.
+
+const (
+	quicALPN          = "devp2p/1"
+	quicExporterLabel = "EXPORTER-devp2p-quic"
+	quicMaxMsgSize    = 16 * 1024 * 1024
+
+	// quicNoReason is the application error code used when closing a connection
+	// without a disconnect reason. Disconnect reasons are sent as is.
+	quicNoReason = quic.ApplicationErrorCode(0x100)
+
+	// quicMaxStreams is the number of unidirectional streams a peer can open.
+	quicMaxStreams = 64
+
+	// quicMaxBuffered is the maximum size of the messages read from all the
+	// streams of a connection and not yet delivered. It fits the compressed and
+	// decompressed payload of the largest message.
+	quicMaxBuffered = 2 * quicMaxMsgSize
+)
+
+var (
+	errQUICAuth        = errors.New("invalid QUIC authentication")
+	errQUICReadTimeout = errors.New("read timeout")
+	errQUICMsgTooBig   = errors.New("message too big")
+	errQUICStream      = errors.New("invalid subprotocol stream")
+)
+
+// quicHost is the QUIC endpoint of the server. It is used both for listening
+// and for dialing.
+type quicHost struct {
+	tr        *quic.Transport
+	ln        *quic.Listener
+	config    *quic.Config
+	clientTLS *tls.Config
+}
+
+func newQUICHost(conn *net.UDPConn) (*quicHost, error) {
+	cert, err := quicCertificate()
+	if err != nil {
+		return nil, err
+	}
+	h := &quicHost{
+		tr: &quic.Transport{Conn: conn},
+		config: &quic.Config{
+			HandshakeIdleTimeout:  handshakeTimeout,
+			MaxIdleTimeout:        frameReadTimeout,
+			MaxIncomingStreams:    1,
+			MaxIncomingUniStreams: quicMaxStreams,
+		},
+		// Certificates are not verified, the node key signature is checked in
+		// the encryption handshake instead.
+		clientTLS: &tls.Config{
+			InsecureSkipVerify: true,
+			NextProtos:         []string{quicALPN},
+			MinVersion:         tls.VersionTLS13,
+		},
+	}
+	serverTLS := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{quicALPN},
+		MinVersion:   tls.VersionTLS13,
+	}
+	if h.ln, err = h.tr.Listen(serverTLS, h.config); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// quicCertificate creates an ephemeral self-signed TLS certificate.
+func quicCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(10 * 365 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// dial connects to a QUIC endpoint and opens the control stream.
+func (h *quicHost) dial(ctx context.Context, addr netip.AddrPort) (*quicConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+	defer cancel()
+
+	conn, err := h.tr.Dial(ctx, net.UDPAddrFromAddrPort(addr), h.clientTLS, h.config)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		conn.CloseWithError(quicNoReason, "")
+		return nil, err
+	}
+	return &quicConn{Stream: stream, conn: conn, dialed: true}, nil
+}
+
+// accept waits for the next inbound connection. The control stream is accepted
+// separately by acceptQUICConn, so slow peers don't block the listener.
+func (h *quicHost) accept() (quic.Connection, error) {
+	return h.ln.Accept(context.Background())
+}
+
+func (h *quicHost) close() {
+	h.ln.Close()
+	h.tr.Close()
+}
+
+// acceptQUICConn waits for the control stream of an inbound connection.
+func acceptQUICConn(conn quic.Connection) (*quicConn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+
+	stream, err := conn.AcceptStream(ctx)
+	if err != nil {
+		conn.CloseWithError(quicNoReason, "")
+		return nil, err
+	}
+	return &quicConn{Stream: stream, conn: conn}, nil
+}
+
+// quicConn is a QUIC connection. It implements net.Conn using the control
+// stream, so it can be handled like a TCP connection by the server.
+type quicConn struct {
+	quic.Stream // control stream
+	conn        quic.Connection
+	dialed      bool
+}
+
+func (c *quicConn) LocalAddr() net.Addr  { return c.conn.LocalAddr() }
+func (c *quicConn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+func (c *quicConn) Close() error         { return c.conn.CloseWithError(quicNoReason, "") }
+
+// quicDialer dials nodes which advertise a QUIC endpoint over QUIC, and falls
+// back to the regular dialer for other nodes and if the QUIC dial fails.
+type quicDialer struct {
+	host     *quicHost
+	fallback NodeDialer
+	log      log.Logger
+}
+
+func (d *quicDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	if addr, ok := dest.QUICEndpoint(); ok {
+		fd, err := d.host.dial(ctx, addr)
+		if err == nil {
+			return fd, nil
+		}
+		d.log.Trace("QUIC dial failed, falling back to TCP", "id", dest.ID(), "addr", addr, "err", err)
+	}
+	return d.fallback.Dial(ctx, dest)
+}
+
+// quicProtocol is the message code range of a subprotocol.
+type quicProtocol struct {
+	offset, length uint64
+}
+
+// quicMsg is a message read from one of the streams.
+type quicMsg struct {
+	msg Msg
+	err error
+}
+
+// quicTransport is the transport of QUIC connections.
+type quicTransport struct {
+	fd       *quicConn
+	ctrl     *bufio.Reader
+	dialDest *ecdsa.PublicKey
+	snappy   bool // set by the protocol handshake, before the readers are started
+
+	mu        sync.Mutex
+	protocols []quicProtocol             // code ranges of the subprotocols, sorted
+	streams   map[uint64]*quicSendStream // outgoing stream of each subprotocol, by offset
+	receiving map[uint64]bool            // subprotocols with an incoming stream, by offset
+	control   *quicSendStream            // outgoing side of the control stream
+	handle    func(Msg) error            // delivers the subprotocol messages of the streams
+	ready     chan struct{}              // closed when the subprotocols are set
+	closeOnce sync.Once
+
+	in       chan quicMsg
+	buffered *quicBudget // memory of the messages read but not yet delivered
+	closed   chan struct{}
+}
+
+// quicBudget bounds the memory held by the messages of a connection which have
+// been read from the streams but not yet delivered.
+type quicBudget struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	used   uint64
+	limit  uint64
+	closed bool
+}
+
+func newQUICBudget(limit uint64) *quicBudget {
+	b := &quicBudget{limit: limit}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// acquire waits until n bytes are available, returning false if the budget was
+// closed in the meantime.
+func (b *quicBudget) acquire(n uint64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for !b.closed && b.used+n > b.limit {
+		b.cond.Wait()
+	}
+	if b.closed {
+		return false
+	}
+	b.used += n
+	return true
+}
+
+// release returns n bytes to the budget.
+func (b *quicBudget) release(n uint64) {
+	b.mu.Lock()
+	b.used -= n
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
+
+// close wakes up and fails all waiting acquisitions.
+func (b *quicBudget) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
+
+// quicSendStream is an outgoing stream. Every stream has its own lock, so a
+// stream blocked by flow control doesn't stall the writes to the others.
+type quicSendStream struct {
+	mu     sync.Mutex
+	stream quic.SendStream // nil until opened, unused for the control stream
+	w      io.Writer
+	buf    bytes.Buffer
+}
+
+func newQUICTransport(fd *quicConn, dialDest *ecdsa.PublicKey) transport {
+	return &quicTransport{
+		fd:        fd,
+		ctrl:      bufio.NewReader(fd),
+		dialDest:  dialDest,
+		streams:   make(map[uint64]*quicSendStream),
+		receiving: make(map[uint64]bool),
+		control:   &quicSendStream{w: fd},
+		ready:     make(chan struct{}),
+		in:        make(chan quicMsg),
+		buffered:  newQUICBudget(quicMaxBuffered),
+		closed:    make(chan struct{}),
+	}
+}
+
+// doEncHandshake authenticates the connection by exchanging signatures of the
+// TLS exporter secret.
+func (t *quicTransport) doEncHandshake(prv *ecdsa.PrivateKey) (*ecdsa.PublicKey, error) {
+	t.fd.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer t.fd.SetDeadline(time.Time{})
+
+	tlsState := t.fd.conn.ConnectionState().TLS
+	secret, err := tlsState.ExportKeyingMaterial(quicExporterLabel, nil, 32)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(quicAuthHash(secret, t.fd.dialed), prv)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := t.writeFrame(t.fd, handshakeMsg, sig); err != nil {
+		return nil, err
+	}
+	// The peer is not authenticated yet, don't accept anything larger than
+	// the signature.
+	msg, err := t.readFrame(t.ctrl, crypto.SignatureLength, nil)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Code != handshakeMsg || msg.Size != crypto.SignatureLength {
+		return nil, errQUICAuth
+	}
+	remoteSig, _ := io.ReadAll(msg.Payload)
+	remote, err := crypto.SigToPub(quicAuthHash(secret, !t.fd.dialed), remoteSig)
+	if err != nil {
+		return nil, errQUICAuth
+	}
+	if t.dialDest != nil && !bytes.Equal(crypto.FromECDSAPub(remote), crypto.FromECDSAPub(t.dialDest)) {
+		return nil, fmt.Errorf("%w: wrong remote identity", errQUICAuth)
+	}
+	return remote, nil
+}
+
+// quicAuthHash is the hash signed by the dialer or the listener side of a
+// connection to prove its identity.
+func quicAuthHash(secret []byte, dialer bool) []byte {
+	role := byte(0)
+	if !dialer {
+		role = 1
+	}
+	return crypto.Keccak256(secret, []byte{role})
+}
+
+func (t *quicTransport) doProtoHandshake(our *protoHandshake) (their *protoHandshake, err error) {
+	t.fd.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer t.fd.SetDeadline(time.Time{})
+
+	werr := make(chan error, 1)
+	go func() { werr <- Send(t, handshakeMsg, our) }()
+	if their, err = readProtocolHandshake(quicStreamReader{t}); err != nil {
+		<-werr // make sure the write terminates too
+		return nil, err
+	}
+	if err := <-werr; err != nil {
+		return nil, fmt.Errorf("write error: %v", err)
+	}
+	t.snappy = their.Version >= snappyProtocolVersion
+
+	// From here on, messages of the control stream are delivered through ReadMsg.
+	go t.readLoop(t.ctrl)
+	go t.acceptLoop()
+	return their, nil
+}
+
+// quicStreamReader reads messages from the control stream during the handshake.
+type quicStreamReader struct{ t *quicTransport }
+
+func (r quicStreamReader) ReadMsg() (Msg, error) {
+	return r.t.readFrame(r.t.ctrl, baseProtocolMaxMsgSize, nil)
+}
+
+// setProtocols configures the message code ranges of the subprotocols, so their
+// messages can be sent on separate streams, and the handler which receives the
+// messages of the subprotocol streams. Until it is called, all messages are sent
+// on the control stream, and the subprotocol streams are not read. It must not
+// be called more than once.
+func (t *quicTransport) setProtocols(running map[string]*protoRW, handle func(Msg) error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, rw := range running {
+		t.protocols = append(t.protocols, quicProtocol{rw.offset, rw.Length})
+	}
+	sort.Slice(t.protocols, func(i, j int) bool { return t.protocols[i].offset < t.protocols[j].offset })
+	t.handle = handle
+	close(t.ready)
+}
+
+// protocol returns the offset of the subprotocol of a message code.
+func (t *quicTransport) protocol(code uint64) (uint64, bool) {
+	for _, proto := range t.protocols {
+		if code >= proto.offset && code < proto.offset+proto.length {
+			return proto.offset, true
+		}
+	}
+	return 0, false
+}
+
+// sendStream returns the stream which carries messages with the given code.
+// The streams of the subprotocols are opened lazily by the first write.
+func (t *quicTransport) sendStream(code uint64) *quicSendStream {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	offset, ok := t.protocol(code)
+	if !ok {
+		return t.control
+	}
+	s := t.streams[offset]
+	if s == nil {
+		s = new(quicSendStream)
+		t.streams[offset] = s
+	}
+	return s
+}
+
+// open opens the stream if it's not opened yet. The lock of the stream must be
+// held by the caller.
+func (s *quicSendStream) open(conn quic.Connection) error {
+	if s.w != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), frameWriteTimeout)
+	defer cancel()
+
+	stream, err := conn.OpenUniStreamSync(ctx)
+	if err != nil {
+		return err
+	}
+	s.stream, s.w = stream, stream
+	return nil
+}
+
+// setWriteDeadline sets the write deadline of the stream.
+func (s *quicSendStream) setWriteDeadline(fd *quicConn, deadline time.Time) {
+	if s.stream != nil {
+		s.stream.SetWriteDeadline(deadline)
+	} else {
+		fd.SetWriteDeadline(deadline)
+	}
+}
+
+func (t *quicTransport) WriteMsg(msg Msg) error {
+	s := t.sendStream(msg.Code)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf.Reset()
+	if _, err := io.CopyN(&s.buf, msg.Payload, int64(msg.Size)); err != nil {
+		return err
+	}
+	if err := s.open(t.fd.conn); err != nil {
+		return quicError(err)
+	}
+	s.setWriteDeadline(t.fd, time.Now().Add(frameWriteTimeout))
+	size, err := t.writeFrame(s.w, msg.Code, s.buf.Bytes())
+	if err != nil {
+		return quicError(err)
+	}
+	if msg.throttle != nil {
+		// The bandwidth was reserved by the peer before sending.
+		msg.throttle.settleEgress(msg, int(size))
+	}
+	msg.meterSize = size
+	return nil
+}
+
+// writeFrame writes a message to a stream, returning the size on the wire.
+func (t *quicTransport) writeFrame(w io.Writer, code uint64, data []byte) (uint32, error) {
+	if t.snappy {
+		data = snappy.Encode(nil, data)
+	}
+	if len(data) > quicMaxMsgSize {
+		return 0, errQUICMsgTooBig
+	}
+	frame := binary.AppendUvarint(nil, code)
+	frame = binary.AppendUvarint(frame, uint64(len(data)))
+	frame = append(frame, data...)
+	n, err := w.Write(frame)
+	egressTrafficMeter.Mark(int64(n))
+	return uint32(len(frame)), err
+}
+
+// readFrame reads a message of at most limit bytes, compressed or not, from a
+// stream. If a budget is given, the memory of the message is reserved in it
+// before reading the payload, and remains reserved for the returned message.
+func (t *quicTransport) readFrame(r *bufio.Reader, limit uint64, budget *quicBudget) (Msg, error) {
+	code, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, quicError(err)
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, quicError(err)
+	}
+	if size > limit {
+		return Msg{}, errQUICMsgTooBig
+	}
+	// Determine the decompressed size from the snappy header, so the memory of
+	// both the compressed and decompressed payload is reserved at once.
+	var decoded uint64
+	if t.snappy {
+		hdr, err := r.Peek(int(min(size, binary.MaxVarintLen32)))
+		if err != nil {
+			return Msg{}, quicError(err)
+		}
+		n, err := snappy.DecodedLen(hdr)
+		if err != nil {
+			return Msg{}, err
+		}
+		if uint64(n) > limit {
+			return Msg{}, errQUICMsgTooBig
+		}
+		decoded = uint64(n)
+	}
+	kept := size // memory reserved for the returned message
+	if t.snappy {
+		kept = decoded
+	}
+	if budget != nil {
+		if !budget.acquire(size + decoded) {
+			return Msg{}, net.ErrClosed
+		}
+		// Only the decompressed payload stays reserved for the message.
+		if t.snappy {
+			defer budget.release(size)
+		}
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if budget != nil {
+			budget.release(kept)
+		}
+		return Msg{}, quicError(err)
+	}
+	wireSize := len(binary.AppendUvarint(binary.AppendUvarint(nil, code), size)) + int(size)
+	ingressTrafficMeter.Mark(int64(wireSize))
+	if t.snappy {
+		if data, err = snappy.Decode(make([]byte, decoded), data); err != nil {
+			if budget != nil {
+				budget.release(kept)
+			}
+			return Msg{}, err
+		}
+	}
+	return Msg{
+		ReceivedAt: time.Now(),
+		Code:       code,
+		Size:       uint32(len(data)),
+		meterSize:  uint32(wireSize),
+		Payload:    bytes.NewReader(data),
+	}, nil
+}
+
+// readLoop delivers the messages of the control stream to ReadMsg.
+func (t *quicTransport) readLoop(r *bufio.Reader) {
+	for {
+		msg, err := t.readFrame(r, quicMaxMsgSize, t.buffered)
+		if errors.Is(err, io.EOF) {
+			return // stream closed by the remote end
+		}
+		if !t.deliver(msg, err) {
+			return
+		}
+	}
+}
+
+// deliver hands a message or a read error of a stream to ReadMsg. It returns
+// false if the reading should stop.
+func (t *quicTransport) deliver(msg Msg, err error) bool {
+	select {
+	case t.in <- quicMsg{msg, err}:
+	case <-t.closed:
+		if err == nil {
+			t.buffered.release(uint64(msg.Size))
+		}
+		return false
+	}
+	return err == nil
+}
+
+// streamLoop reads a subprotocol stream opened by the remote end. Each stream
+// carries the messages of a single subprotocol, which are handed to the handler
+// directly. The reader blocks while the subprotocol is busy, so flow control
+// holds back the remote writer of this stream only.
+func (t *quicTransport) streamLoop(r *bufio.Reader) {
+	select {
+	case <-t.ready:
+	case <-t.closed:
+		return
+	}
+	var (
+		offset  uint64
+		claimed bool
+	)
+	for {
+		msg, err := t.readFrame(r, quicMaxMsgSize, t.buffered)
+		if errors.Is(err, io.EOF) {
+			return // stream closed by the remote end
+		}
+		if err == nil {
+			// The message memory is handed over to the handler. While the
+			// subprotocol is busy, the stream holds back this single message,
+			// and there is only one stream per subprotocol.
+			t.buffered.release(uint64(msg.Size))
+			if err = t.claim(msg.Code, &offset, &claimed); err == nil {
+				err = t.handle(msg)
+			}
+			if err == nil {
+				continue
+			}
+			if errors.Is(err, io.EOF) {
+				return // peer shutting down
+			}
+			msg = Msg{}
+		}
+		// Report the error through ReadMsg, failing the connection.
+		t.deliver(msg, err)
+		return
+	}
+}
+
+// claim checks that a message read from a stream belongs to the subprotocol of
+// the stream. The first message assigns the stream to its subprotocol, which
+// must not have another incoming stream.
+func (t *quicTransport) claim(code uint64, offset *uint64, claimed *bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	proto, ok := t.protocol(code)
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: message code %d", errQUICStream, code)
+	case *claimed && proto != *offset:
+		return fmt.Errorf("%w: mixed subprotocols", errQUICStream)
+	case !*claimed && t.receiving[proto]:
+		return fmt.Errorf("%w: duplicate stream", errQUICStream)
+	}
+	if !*claimed {
+		t.receiving[proto] = true
+		*offset, *claimed = proto, true
+	}
+	return nil
+}
+
+// acceptLoop starts a reader for every stream opened by the remote end.
+func (t *quicTransport) acceptLoop() {
+	for {
+		s, err := t.fd.conn.AcceptUniStream(context.Background())
+		if err != nil {
+			return // the control stream reader reports the error
+		}
+		go t.streamLoop(bufio.NewReader(s))
+	}
+}
+
+func (t *quicTransport) ReadMsg() (Msg, error) {
+	timeout := time.NewTimer(frameReadTimeout)
+	defer timeout.Stop()
+
+	select {
+	case m := <-t.in:
+		if m.err == nil {
+			// The message memory is handed over to the caller.
+			t.buffered.release(uint64(m.msg.Size))
+		}
+		return m.msg, m.err
+	case <-timeout.C:
+		return Msg{}, errQUICReadTimeout
+	case <-t.closed:
+		return Msg{}, net.ErrClosed
+	}
+}
+
+func (t *quicTransport) close(err error) {
+	code := quicNoReason
+	if reason, ok := err.(DiscReason); ok && reason != DiscNetworkError {
+		code = quic.ApplicationErrorCode(reason)
+	}
+	t.closeOnce.Do(func() {
+		t.buffered.close()
+		close(t.closed)
+		t.fd.conn.CloseWithError(code, "")
+	})
+}
+
+// quicError converts the errors of QUIC streams. If the remote end closed the
+// connection with a disconnect reason, the reason is returned.
+func quicError(err error) error {
+	var appErr *quic.ApplicationError
+	if errors.As(err, &appErr) && appErr.Remote && appErr.ErrorCode < quicNoReason {
+		return DiscReason(appErr.ErrorCode)
+	}
+	return err
+}
+
+// setupQUIC starts the QUIC listener and advertises it in the local node record.
+func (srv *Server) setupQUIC() error {
+	addr, err := net.ResolveUDPAddr("udp", srv.QUICAddr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return err
+	}
+	host, err := newQUICHost(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	srv.quic = host
+	laddr := conn.LocalAddr().(*net.UDPAddr)
+	srv.QUICAddr = laddr.String()
+	srv.localnode.Set(enr.QUIC(laddr.Port))
+	if !laddr.IP.IsLoopback() && !laddr.IP.IsPrivate() {
+		srv.portMappingRegister <- &portMapping{
+			protocol: "UDP",
+			name:     "ethereum p2p quic",
+			port:     laddr.Port,
+		}
+	}
+	srv.loopWG.Add(1)
+	go srv.quicListenLoop()
+	return nil
+}
+
+// quicListenLoop runs in its own goroutine and accepts inbound QUIC connections.
+func (srv *Server) quicListenLoop() {
+	srv.log.Debug("QUIC listener up", "addr", srv.QUICAddr)
+
+	tokens := defaultMaxPendingPeers
+	if srv.MaxPendingPeers > 0 {
+		tokens = srv.MaxPendingPeers
+	}
+	slots := make(chan struct{}, tokens)
+	for i := 0; i < tokens; i++ {
+		slots <- struct{}{}
+	}
+	defer srv.loopWG.Done()
+	defer func() {
+		for i := 0; i < cap(slots); i++ {
+			<-slots
+		}
+	}()
+
+	for {
+		<-slots
+		conn, err := srv.quic.accept()
+		if err != nil {
+			srv.log.Debug("QUIC accept error", "err", err)
+			slots <- struct{}{}
+			return
+		}
+		remoteIP := netutil.AddrAddr(conn.RemoteAddr())
+		if err := srv.checkInboundConn(remoteIP); err != nil {
+			srv.log.Debug("Rejected inbound QUIC connection", "addr", conn.RemoteAddr(), "err", err)
+			conn.CloseWithError(quicNoReason, "")
+			slots <- struct{}{}
+			continue
+		}
+		serveMeter.Mark(1)
+		go func() {
+			defer func() { slots <- struct{}{} }()
+			fd, err := acceptQUICConn(conn)
+			if err != nil {
+				srv.log.Trace("QUIC control stream not opened", "addr", conn.RemoteAddr(), "err", err)
+				return
+			}
+			srv.SetupConn(fd, inboundConn, nil)
+		}()
+	}
+}
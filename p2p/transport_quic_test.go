@@ -0,0 +1,390 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/golang/snappy"
+)
+
+// quicTestPeer is a peer connected to a QUIC test server.
+type quicTestPeer struct {
+	peer *Peer
+	msgs chan []byte
+}
+
+// startQUICTestServer starts a server with a protocol which sends a small and a
+// large message to every peer and delivers the messages it receives.
+func startQUICTestServer(t *testing.T, withQUIC bool, peers chan *quicTestPeer) *Server {
+	proto := Protocol{
+		Name:    "test",
+		Version: 1,
+		Length:  2,
+		Run: func(p *Peer, rw MsgReadWriter) error {
+			tp := &quicTestPeer{peer: p, msgs: make(chan []byte, 2)}
+			peers <- tp
+			go func() {
+				Send(rw, 0, []byte("small"))
+				Send(rw, 1, bytes.Repeat([]byte{1}, 2*1024*1024))
+			}()
+			for {
+				msg, err := rw.ReadMsg()
+				if err != nil {
+					return err
+				}
+				var data []byte
+				if err := msg.Decode(&data); err != nil {
+					return err
+				}
+				tp.msgs <- data
+			}
+		},
+	}
+	config := Config{
+		Name:        "test",
+		MaxPeers:    10,
+		ListenAddr:  "127.0.0.1:0",
+		NoDiscovery: true,
+		PrivateKey:  newkey(),
+		Protocols:   []Protocol{proto},
+		Logger:      testlog.Logger(t, log.LvlTrace),
+	}
+	if withQUIC {
+		config.QUICAddr = "127.0.0.1:0"
+	}
+	srv := &Server{Config: config}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func waitQUICTestPeer(t *testing.T, peers chan *quicTestPeer) *quicTestPeer {
+	t.Helper()
+	select {
+	case p := <-peers:
+		return p
+	case <-time.After(10 * time.Second):
+		t.Fatal("peer not connected")
+		return nil
+	}
+}
+
+func checkQUICTestMessages(t *testing.T, p *quicTestPeer) {
+	t.Helper()
+	for i := 0; i < 2; i++ {
+		select {
+		case data := <-p.msgs:
+			if len(data) != len("small") && len(data) != 2*1024*1024 {
+				t.Fatalf("wrong message size %d", len(data))
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("message not received")
+		}
+	}
+}
+
+func TestQUICTransport(t *testing.T) {
+	var (
+		peersA = make(chan *quicTestPeer, 1)
+		peersB = make(chan *quicTestPeer, 1)
+		srvA   = startQUICTestServer(t, true, peersA)
+		srvB   = startQUICTestServer(t, true, peersB)
+	)
+	if _, ok := srvB.Self().QUICEndpoint(); !ok {
+		t.Fatal("QUIC endpoint not in node record")
+	}
+	srvA.AddPeer(srvB.Self())
+
+	peerA, peerB := waitQUICTestPeer(t, peersA), waitQUICTestPeer(t, peersB)
+	if _, ok := peerA.peer.RemoteAddr().(*net.UDPAddr); !ok {
+		t.Fatalf("dialer not connected over QUIC, remote address %v", peerA.peer.RemoteAddr())
+	}
+	if _, ok := peerB.peer.RemoteAddr().(*net.UDPAddr); !ok {
+		t.Fatalf("listener not connected over QUIC, remote address %v", peerB.peer.RemoteAddr())
+	}
+	if peerA.peer.ID() != srvB.Self().ID() || peerB.peer.ID() != srvA.Self().ID() {
+		t.Fatal("wrong peer identity")
+	}
+	checkQUICTestMessages(t, peerA)
+	checkQUICTestMessages(t, peerB)
+
+	// Check the disconnect reason is delivered to the remote end.
+	events := make(chan *PeerEvent, 10)
+	sub := srvB.SubscribeEvents(events)
+	defer sub.Unsubscribe()
+
+	peerA.peer.Disconnect(DiscTooManyPeers)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type != PeerEventTypeDrop {
+				continue
+			}
+			if ev.Error != DiscTooManyPeers.Error() {
+				t.Fatalf("wrong disconnect reason %q", ev.Error)
+			}
+			return
+		case <-time.After(10 * time.Second):
+			t.Fatal("peer not disconnected")
+		}
+	}
+}
+
+func TestQUICFallback(t *testing.T) {
+	var (
+		peersA = make(chan *quicTestPeer, 1)
+		peersB = make(chan *quicTestPeer, 1)
+		srvA   = startQUICTestServer(t, true, peersA)
+		srvB   = startQUICTestServer(t, false, peersB)
+	)
+	srvA.AddPeer(srvB.Self())
+
+	peerA := waitQUICTestPeer(t, peersA)
+	if _, ok := peerA.peer.RemoteAddr().(*net.TCPAddr); !ok {
+		t.Fatalf("not connected over TCP, remote address %v", peerA.peer.RemoteAddr())
+	}
+	checkQUICTestMessages(t, peerA)
+}
+
+func TestQUICWrongIdentity(t *testing.T) {
+	listenQUIC := func() *quicHost {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		host, err := newQUICHost(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(host.close)
+		return host
+	}
+	var (
+		dialer   = listenQUIC()
+		listener = listenQUIC()
+		key      = newkey()
+		wrongKey = newkey()
+	)
+	go func() {
+		conn, err := listener.accept()
+		if err != nil {
+			return
+		}
+		fd, err := acceptQUICConn(conn)
+		if err != nil {
+			return
+		}
+		tr := newQUICTransport(fd, nil)
+		tr.doEncHandshake(key)
+	}()
+	addr := listener.ln.Addr().(*net.UDPAddr).AddrPort()
+	fd, err := dialer.dial(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	tr := newQUICTransport(fd, &wrongKey.PublicKey)
+	defer tr.close(nil)
+
+	if _, err := tr.doEncHandshake(newkey()); !errors.Is(err, errQUICAuth) {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
+
+// Tests that a subprotocol which doesn't read its messages doesn't stall the
+// other subprotocols of a QUIC connection, neither when writing nor reading.
+func TestQUICStreamIndependence(t *testing.T) {
+	const blockedMsgs = 32
+	var (
+		started = make(chan struct{}, 2)
+		sent    = make(chan struct{}, blockedMsgs)
+		recv    = make(chan []byte, 1)
+		payload = make([]byte, 1024*1024)
+	)
+	rand.Read(payload) // defeat the compression
+	protocols := []Protocol{
+		{
+			// Protocol "a" floods the remote end, which never reads the messages.
+			Name:    "a",
+			Version: 1,
+			Length:  1,
+			Run: func(p *Peer, rw MsgReadWriter) error {
+				started <- struct{}{}
+				if p.Inbound() {
+					<-p.closed
+					return nil
+				}
+				for i := 0; i < blockedMsgs; i++ {
+					if err := Send(rw, 0, payload); err != nil {
+						return err
+					}
+					sent <- struct{}{}
+				}
+				<-p.closed
+				return nil
+			},
+		},
+		{
+			// Protocol "b" sends a message once "a" is stuck.
+			Name:    "b",
+			Version: 1,
+			Length:  1,
+			Run: func(p *Peer, rw MsgReadWriter) error {
+				if !p.Inbound() {
+					go func() {
+						time.Sleep(time.Second)
+						Send(rw, 0, []byte("b"))
+					}()
+				}
+				for {
+					msg, err := rw.ReadMsg()
+					if err != nil {
+						return err
+					}
+					var data []byte
+					if err := msg.Decode(&data); err != nil {
+						return err
+					}
+					recv <- data
+				}
+			},
+		},
+	}
+	newServer := func() *Server {
+		srv := &Server{Config: Config{
+			Name:        "test",
+			MaxPeers:    10,
+			ListenAddr:  "127.0.0.1:0",
+			QUICAddr:    "127.0.0.1:0",
+			NoDiscovery: true,
+			PrivateKey:  newkey(),
+			Protocols:   protocols,
+			Logger:      testlog.Logger(t, log.LvlTrace),
+		}}
+		if err := srv.Start(); err != nil {
+			t.Fatalf("could not start server: %v", err)
+		}
+		t.Cleanup(srv.Stop)
+		return srv
+	}
+	srvA, srvB := newServer(), newServer()
+	srvA.AddPeer(srvB.Self())
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(10 * time.Second):
+			t.Fatal("peers not connected")
+		}
+	}
+	select {
+	case data := <-recv:
+		if string(data) != "b" {
+			t.Fatalf("wrong message %q", data)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("protocol stalled by blocked stream")
+	}
+	// Check that protocol "a" was actually blocked by flow control.
+	if n := len(sent); n == blockedMsgs {
+		t.Fatal("writes of the unread protocol were not blocked")
+	}
+	if peers := srvA.Peers(); len(peers) != 1 {
+		t.Fatal("peer not connected")
+	} else if _, ok := peers[0].RemoteAddr().(*net.UDPAddr); !ok {
+		t.Fatalf("not connected over QUIC, remote address %v", peers[0].RemoteAddr())
+	}
+}
+
+// TestQUICFrameLimit checks that oversized frames are rejected before their
+// payload is allocated or read.
+func TestQUICFrameLimit(t *testing.T) {
+	var tr quicTransport
+	for _, snappy := range []bool{false, true} {
+		tr.snappy = snappy
+
+		// A frame declaring a 16 MiB payload, without sending it.
+		frame := binary.AppendUvarint([]byte{handshakeMsg}, quicMaxMsgSize)
+		_, err := tr.readFrame(bufio.NewReader(bytes.NewReader(frame)), crypto.SignatureLength, nil)
+		if !errors.Is(err, errQUICMsgTooBig) {
+			t.Errorf("snappy %t: oversized frame not rejected: %v", snappy, err)
+		}
+	}
+	// A small snappy frame which decompresses beyond the limit.
+	tr.snappy = true
+	payload := snappy.Encode(nil, make([]byte, 2*crypto.SignatureLength))
+	frame := binary.AppendUvarint([]byte{handshakeMsg}, uint64(len(payload)))
+	frame = append(frame, payload...)
+	_, err := tr.readFrame(bufio.NewReader(bytes.NewReader(frame)), crypto.SignatureLength, nil)
+	if !errors.Is(err, errQUICMsgTooBig) {
+		t.Errorf("oversized decompressed frame not rejected: %v", err)
+	}
+}
+
+// TestQUICBudget checks that the messages buffered across streams are bounded,
+// and reserved memory is returned on delivery.
+func TestQUICBudget(t *testing.T) {
+	var (
+		tr     = quicTransport{buffered: newQUICBudget(100)}
+		frame  = append(binary.AppendUvarint([]byte{0x10}, 60), make([]byte, 60)...)
+		second = make(chan Msg)
+	)
+	msg, err := tr.readFrame(bufio.NewReader(bytes.NewReader(frame)), quicMaxMsgSize, tr.buffered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		msg, _ := tr.readFrame(bufio.NewReader(bytes.NewReader(frame)), quicMaxMsgSize, tr.buffered)
+		second <- msg
+	}()
+	select {
+	case <-second:
+		t.Fatal("second message read beyond the budget")
+	case <-time.After(50 * time.Millisecond):
+	}
+	tr.buffered.release(uint64(msg.Size))
+	select {
+	case msg := <-second:
+		if msg.Size != 60 {
+			t.Fatalf("wrong message size %d", msg.Size)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second message not read after release")
+	}
+	// Closing the budget fails pending reads.
+	errc := make(chan error)
+	go func() {
+		_, err := tr.readFrame(bufio.NewReader(bytes.NewReader(frame)), quicMaxMsgSize, tr.buffered)
+		errc <- err
+	}()
+	tr.buffered.close()
+	if err := <-errc; !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("pending read not failed by close: %v", err)
+	}
+}
@@ -436,6 +436,29 @@ type ChainConfig struct {
 	// those cases.
 	EnableVerkleAtGenesis bool `json:"enableVerkleAtGenesis,omitempty"`
 
+	// EnableBinaryTreeAtGenesis is a flag that specifies whether the network
+	// stores its state in the binary tree of EIP-7864 starting from the genesis
+	// block. It only changes how the state is stored and committed, the state
+	// root is derived from the binary tree, but the execution rules are left
+	// untouched.
+	//
+	// This is a temporary flag only for testing the binary tree on development
+	// networks, it requires the path-based state scheme and cancun to be active
+	// at genesis.
+	EnableBinaryTreeAtGenesis bool `json:"enableBinaryTreeAtGenesis,omitempty"`
+
+	// EnableBinaryTreeOverlayAtGenesis is a flag that specifies whether the
+	// network converts its state into the binary tree of EIP-7864 starting from
+	// the genesis block. The genesis allocation is stored in a Merkle-Patricia
+	// trie, which is frozen as the read-only base of a binary overlay tree. The
+	// state changes of all the following blocks are written into the overlay,
+	// and the reads fall back to the base if the overlay doesn't hold the value.
+	//
+	// This is a temporary flag only for testing the transition into the binary
+	// tree on development networks. It implies EnableBinaryTreeAtGenesis, with
+	// the same requirements.
+	EnableBinaryTreeOverlayAtGenesis bool `json:"enableBinaryTreeOverlayAtGenesis,omitempty"`
+
 	// Various consensus engines
 	Ethash             *EthashConfig       `json:"ethash,omitempty"`
 	Clique             *CliqueConfig       `json:"clique,omitempty"`
@@ -718,6 +741,13 @@ func (c *ChainConfig) IsVerkleGenesis() bool {
 	return c.EnableVerkleAtGenesis
 }
 
+// IsBinaryTreeGenesis checks whether the state is stored in the binary tree since
+// the genesis block, either directly or through the overlay on top of the genesis
+// Merkle-Patricia trie.
+func (c *ChainConfig) IsBinaryTreeGenesis() bool {
+	return c.EnableBinaryTreeAtGenesis || c.EnableBinaryTreeOverlayAtGenesis
+}
+
 // IsEIP4762 returns whether eip 4762 has been activated at given block.
 func (c *ChainConfig) IsEIP4762(num *big.Int, time uint64) bool {
 	return c.IsVerkle(num, time)
@@ -0,0 +1,134 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AccessPolicy restricts the methods an authenticated client may call.
+//
+// Methods are selected by patterns, which are either
+//
+//   - a full method name, e.g. "eth_sendRawTransaction",
+//   - a namespace, e.g. "eth", selecting all methods of the namespace,
+//   - a method name prefix followed by '*', e.g. "debug_trace*", or
+//   - a single '*', selecting all methods.
+//
+// A method may be called if it is selected by an allow pattern and not selected
+// by any deny pattern. Subscriptions are selected by their subscribe and
+// unsubscribe methods, e.g. "eth_subscribe".
+type AccessPolicy struct {
+	name  string
+	allow []string
+	deny  []string
+}
+
+// NewAccessPolicy creates an access policy for the named credential.
+func NewAccessPolicy(name string, allow, deny []string) (*AccessPolicy, error) {
+	p := &AccessPolicy{name: name}
+	for _, pattern := range allow {
+		prefix, err := parseMethodPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		p.allow = append(p.allow, prefix)
+	}
+	for _, pattern := range deny {
+		prefix, err := parseMethodPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		p.deny = append(p.deny, prefix)
+	}
+	return p, nil
+}
+
+// parseMethodPattern validates a method pattern and converts it into its
+// canonical form, in which only a trailing '*' has a special meaning.
+func parseMethodPattern(pattern string) (string, error) {
+	switch {
+	case pattern == "":
+		return "", errors.New("empty method pattern")
+	case strings.Contains(pattern[:len(pattern)-1], "*"):
+		return "", fmt.Errorf("invalid method pattern %q: '*' is only allowed at the end", pattern)
+	case !strings.Contains(pattern, "*") && !strings.Contains(pattern, serviceMethodSeparator):
+		return pattern + serviceMethodSeparator + "*", nil // namespace
+	}
+	return pattern, nil
+}
+
+// matchMethod reports whether the canonical pattern selects the method.
+func matchMethod(pattern, method string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(method, prefix)
+	}
+	return pattern == method
+}
+
+// Name returns the name of the credential the policy belongs to.
+func (p *AccessPolicy) Name() string {
+	return p.name
+}
+
+// Allowed reports whether the policy permits calling the method.
+func (p *AccessPolicy) Allowed(method string) bool {
+	for _, pattern := range p.deny {
+		if matchMethod(pattern, method) {
+			return false
+		}
+	}
+	for _, pattern := range p.allow {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+type accessPolicyContextKey struct{}
+
+// WithAccessPolicy returns a copy of ctx restricting the methods callable by
+// requests served with it. This is meant to be used by HTTP middleware
+// authenticating clients, before passing the request to the handlers returned
+// by Server.ServeHTTP and Server.WebsocketHandler. For WebSocket connections,
+// the policy applies to all calls made over the connection.
+func WithAccessPolicy(ctx context.Context, policy *AccessPolicy) context.Context {
+	return context.WithValue(ctx, accessPolicyContextKey{}, policy)
+}
+
+// AccessPolicyFromContext returns the access policy of the client, or nil if
+// the client is not restricted.
+func AccessPolicyFromContext(ctx context.Context) *AccessPolicy {
+	policy, _ := ctx.Value(accessPolicyContextKey{}).(*AccessPolicy)
+	return policy
+}
+
+// checkAccess returns an error if the access policy in ctx does not permit
+// calling the method.
+func checkAccess(ctx context.Context, method string) error {
+	policy := AccessPolicyFromContext(ctx)
+	if policy == nil || policy.Allowed(method) {
+		return nil
+	}
+	accessDeniedMeter.Mark(1)
+	updateAccessDeniedMeter(policy.name)
+	return &accessDeniedError{method: method}
+}
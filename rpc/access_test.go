@@ -0,0 +1,102 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessPolicy(t *testing.T) {
+	policy, err := NewAccessPolicy("test", []string{"eth", "debug_trace*", "net_version"}, []string{"eth_sendRawTransaction"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]bool{
+		"eth_call":               true,
+		"eth_subscribe":          true,
+		"eth_sendRawTransaction": false,
+		"ethx_call":              false,
+		"debug_traceCall":        true,
+		"debug_setHead":          false,
+		"net_version":            true,
+		"net_listening":          false,
+		"admin_peers":            false,
+	}
+	for method, want := range tests {
+		if have := policy.Allowed(method); have != want {
+			t.Errorf("%s: allowed %t, want %t", method, have, want)
+		}
+	}
+	if _, err := NewAccessPolicy("test", []string{"eth_*call"}, nil); err == nil {
+		t.Error("pattern with inner wildcard accepted")
+	}
+	if _, err := NewAccessPolicy("test", nil, []string{""}); err == nil {
+		t.Error("empty pattern accepted")
+	}
+}
+
+func TestAccessPolicyServer(t *testing.T) {
+	policy, err := NewAccessPolicy("test", []string{"test", "nftest"}, []string{"test_echo", "nftest_subscribe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer()
+	defer server.Stop()
+
+	restrict := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithAccessPolicy(r.Context(), policy)))
+		})
+	}
+	httpsrv := httptest.NewServer(restrict(server))
+	defer httpsrv.Close()
+	wssrv := httptest.NewServer(restrict(server.WebsocketHandler([]string{"*"})))
+	defer wssrv.Close()
+
+	for _, url := range []string{httpsrv.URL, "ws:" + strings.TrimPrefix(wssrv.URL, "http:")} {
+		client, err := Dial(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result echoResult
+		if err := client.Call(&result, "test_echoWithCtx", "x", 1, &echoArgs{"y"}); err != nil {
+			t.Errorf("%s: allowed call failed: %v", url, err)
+		}
+		err = client.Call(&result, "test_echo", "x", 1, &echoArgs{"y"})
+		var rpcErr Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeAccessDenied {
+			t.Errorf("%s: denied call returned %v", url, err)
+		}
+		// Denied methods must not reveal whether they exist.
+		err = client.Call(&result, "admin_peers")
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeAccessDenied {
+			t.Errorf("%s: call of unknown method returned %v", url, err)
+		}
+		if strings.HasPrefix(url, "ws:") {
+			_, err := client.Subscribe(context.Background(), "nftest", make(chan int), "someSubscription", 1, 1)
+			if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeAccessDenied {
+				t.Errorf("%s: denied subscription returned %v", url, err)
+			}
+		}
+		client.Close()
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+)
+
+// maxCacheKeyLength is the maximum length of the canonical arguments of a call
+// served by the response cache. Calls with larger arguments, e.g. traces with
+// custom tracer code, are always executed.
+const maxCacheKeyLength = 1024
+
+// responseCache holds the encoded results of calls to cacheable methods which
+// declared their result immutable. Entries are keyed on the method name and the
+// canonical encoding of the decoded arguments, so equivalent requests differing
+// in formatting, hex case or omitted optional arguments share an entry.
+type responseCache struct {
+	results *lru.SizeConstrainedCache[string, json.RawMessage]
+}
+
+func newResponseCache(maxSize int) *responseCache {
+	return &responseCache{results: lru.NewSizeConstrainedCache[string, json.RawMessage](uint64(maxSize))}
+}
+
+// cacheKey returns the cache key of the call, or false if the arguments cannot
+// be canonicalized.
+func cacheKey(method string, args []reflect.Value) (string, bool) {
+	var key strings.Builder
+	key.WriteString(method)
+	for _, arg := range args {
+		enc, err := json.Marshal(arg.Interface())
+		if err != nil {
+			return "", false
+		}
+		key.WriteByte(0)
+		key.Write(enc)
+	}
+	if key.Len()-len(method) > maxCacheKeyLength {
+		return "", false
+	}
+	return key.String(), true
+}
+
+// get returns the cached result of the call.
+func (c *responseCache) get(key string) (json.RawMessage, bool) {
+	result, ok := c.results.Get(key)
+	if ok {
+		cacheHitMeter.Mark(1)
+	} else {
+		cacheMissMeter.Mark(1)
+	}
+	return result, ok
+}
+
+// add caches the result of the call.
+func (c *responseCache) add(key string, result json.RawMessage) {
+	if c.results.Add(key, result) {
+		cacheEvictMeter.Mark(1)
+	}
+}
+
+// immutableMarker records whether a cacheable method declared its result
+// immutable.
+type immutableMarker struct {
+	immutable atomic.Bool
+}
+
+type immutableMarkerContextKey struct{}
+
+// MarkImmutable declares the result of the method call immutable, i.e. that
+// calling the method with the same arguments will always return the same
+// result, for example because it only depends on finalized blocks. Servers
+// with a response cache enabled then serve subsequent calls from the cache.
+//
+// Calling MarkImmutable has no effect unless the method was declared cacheable
+// through API.Cacheable. Errors are never cached.
+func MarkImmutable(ctx context.Context) {
+	if marker, ok := ctx.Value(immutableMarkerContextKey{}).(*immutableMarker); ok {
+		marker.immutable.Store(true)
+	}
+}
+
+// CanMarkImmutable reports whether the result of the method call can be cached,
+// i.e. whether MarkImmutable has any effect. Methods can use it to skip the work
+// of determining whether their result is immutable.
+func CanMarkImmutable(ctx context.Context) bool {
+	_, ok := ctx.Value(immutableMarkerContextKey{}).(*immutableMarker)
+	return ok
+}
+
+// runCachedMethod serves the call of a cacheable method from the cache, or runs
+// it and caches the result if the method declared it immutable.
+func (h *handler) runCachedMethod(ctx context.Context, cache *responseCache, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
+	key, ok := cacheKey(msg.Method, args)
+	if !ok {
+		return h.runMethod(ctx, msg, callb, args)
+	}
+	if result, ok := cache.get(key); ok {
+		return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: result}
+	}
+	marker := new(immutableMarker)
+	answer := h.runMethod(context.WithValue(ctx, immutableMarkerContextKey{}, marker), msg, callb, args)
+	if answer.Error == nil && marker.immutable.Load() {
+		cache.add(key, answer.Result)
+	}
+	return answer
+}
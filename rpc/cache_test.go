@@ -0,0 +1,184 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// cacheTestService counts the executions of its methods.
+type cacheTestService struct {
+	calls atomic.Int64
+}
+
+// Lookup returns the number of executions so far, declaring the result
+// immutable if final is set.
+func (s *cacheTestService) Lookup(ctx context.Context, hash common.Hash, final bool) int64 {
+	if final {
+		MarkImmutable(ctx)
+	}
+	return s.calls.Add(1)
+}
+
+// Uncached behaves like Lookup, but is not declared cacheable.
+func (s *cacheTestService) Uncached(ctx context.Context, final bool) int64 {
+	if final {
+		MarkImmutable(ctx)
+	}
+	return s.calls.Add(1)
+}
+
+// Markable reports whether the result of the call can be declared immutable.
+func (s *cacheTestService) Markable(ctx context.Context) bool {
+	return CanMarkImmutable(ctx)
+}
+
+// Fail declares its result immutable, but returns an error.
+func (s *cacheTestService) Fail(ctx context.Context) (int64, error) {
+	MarkImmutable(ctx)
+	return 0, fmt.Errorf("failure %d", s.calls.Add(1))
+}
+
+func newCacheTestServer(t *testing.T, cacheSize int) (*Server, *cacheTestService) {
+	t.Helper()
+
+	server := NewServer()
+	service := new(cacheTestService)
+	if err := server.RegisterName("cache", service); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterCacheable("cache", "lookup", "markable", "fail"); err != nil {
+		t.Fatal(err)
+	}
+	server.SetResponseCache(cacheSize)
+	return server, service
+}
+
+func TestResponseCache(t *testing.T) {
+	server, service := newCacheTestServer(t, 1024*1024)
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	call := func(want int64, method string, args ...any) {
+		t.Helper()
+		var result int64
+		if err := client.Call(&result, method, args...); err != nil {
+			t.Fatal(err)
+		}
+		if result != want {
+			t.Errorf("%s %v: result %d, want %d", method, args, result, want)
+		}
+	}
+	hash := "0x" + strings.Repeat("ab", 32)
+
+	// Results not declared immutable are not cached.
+	call(1, "cache_lookup", hash, false)
+	call(2, "cache_lookup", hash, false)
+
+	// Immutable results are served from the cache, also for equivalent arguments.
+	call(3, "cache_lookup", hash, true)
+	call(3, "cache_lookup", hash, true)
+	call(3, "cache_lookup", "0x"+strings.ToUpper(hash[2:]), true)
+	call(4, "cache_lookup", "0x"+strings.Repeat("cd", 32), true)
+
+	// Methods not declared cacheable are always executed.
+	call(5, "cache_uncached", true)
+	call(6, "cache_uncached", true)
+
+	// Errors are not cached.
+	var result int64
+	err1 := client.Call(&result, "cache_fail")
+	err2 := client.Call(&result, "cache_fail")
+	if err1 == nil || err2 == nil || err1.Error() == err2.Error() {
+		t.Errorf("failing call served from cache: %v, %v", err1, err2)
+	}
+	if have := service.calls.Load(); have != 8 {
+		t.Errorf("wrong number of executions: %d, want 8", have)
+	}
+}
+
+func TestResponseCacheDisabled(t *testing.T) {
+	server, service := newCacheTestServer(t, 0)
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	hash := common.Hash{1}
+	for i := 0; i < 3; i++ {
+		var result int64
+		if err := client.Call(&result, "cache_lookup", hash, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if have := service.calls.Load(); have != 3 {
+		t.Errorf("wrong number of executions: %d, want 3", have)
+	}
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	// The cache holds a single result.
+	server, service := newCacheTestServer(t, 1)
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	for _, hash := range []common.Hash{{1}, {2}, {1}, {1}} {
+		var result int64
+		if err := client.Call(&result, "cache_lookup", hash, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if have := service.calls.Load(); have != 3 {
+		t.Errorf("wrong number of executions: %d, want 3", have)
+	}
+}
+
+func TestCanMarkImmutable(t *testing.T) {
+	for _, size := range []int{0, 1024} {
+		server, _ := newCacheTestServer(t, size)
+		client := DialInProc(server)
+
+		var markable bool
+		if err := client.Call(&markable, "cache_markable"); err != nil {
+			t.Fatal(err)
+		}
+		if markable != (size > 0) {
+			t.Errorf("cache size %d: markable %t", size, markable)
+		}
+		client.Close()
+		server.Stop()
+	}
+}
+
+func TestRegisterCacheable(t *testing.T) {
+	server, _ := newCacheTestServer(t, 1024)
+	defer server.Stop()
+
+	if err := server.RegisterCacheable("cache", "missing"); err == nil {
+		t.Error("unknown method declared cacheable")
+	}
+	if err := server.RegisterCacheable("other", "lookup"); err == nil {
+		t.Error("method of unknown service declared cacheable")
+	}
+}
@@ -120,6 +120,9 @@ func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
+	if c, ok := conn.(accessRestrictedCodec); ok && c.accessPolicy() != nil {
+		ctx = WithAccessPolicy(ctx, c.accessPolicy())
+	}
 	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize)
 	return &clientConn{conn, handler}
 }
@@ -17,6 +17,7 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"net/http"
 
 	"github.com/gorilla/websocket"
@@ -37,6 +38,9 @@ type clientConfig struct {
 	wsDialer           *websocket.Dialer
 	wsMessageSizeLimit *int64 // wsMessageSizeLimit nil = default, 0 = no limit
 
+	// TLS settings for HTTP and WebSocket connections
+	tlsConfig *tls.Config
+
 	// RPC handler options
 	idgen              func() ID
 	batchItemLimit     int
@@ -102,6 +106,29 @@ func WithHTTPClient(c *http.Client) ClientOption {
 	})
 }
 
+// WithTLSConfig configures the TLS settings of HTTP and WebSocket connections,
+// such as the trusted certificate authorities and client certificates. It does
+// not apply to HTTP connections made by a client configured via WithHTTPClient,
+// nor to WebSocket dialers configured via WithWebsocketDialer which carry their
+// own TLS settings.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.tlsConfig = config.Clone()
+	})
+}
+
+// WithClientCertificate configures a certificate presented to servers requiring
+// TLS client authentication. It is added to the settings of WithTLSConfig, if
+// that option is also given, so it has to be passed after it.
+func WithClientCertificate(cert tls.Certificate) ClientOption {
+	return optionFunc(func(cfg *clientConfig) {
+		if cfg.tlsConfig == nil {
+			cfg.tlsConfig = new(tls.Config)
+		}
+		cfg.tlsConfig.Certificates = append(cfg.tlsConfig.Certificates, cert)
+	})
+}
+
 // WithHTTPAuth configures HTTP request authentication. The given provider will be called
 // whenever a request is made. Note that only one authentication provider can be active at
 // any time.
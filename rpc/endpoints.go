@@ -36,6 +36,10 @@ func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, er
 			log.Info("IPC registration failed", "namespace", api.Namespace, "error", err)
 			return nil, nil, err
 		}
+		if err := handler.RegisterCacheable(api.Namespace, api.Cacheable...); err != nil {
+			log.Info("IPC registration failed", "namespace", api.Namespace, "error", err)
+			return nil, nil, err
+		}
 		if _, ok := regMap[api.Namespace]; !ok {
 			registered = append(registered, api.Namespace)
 			regMap[api.Namespace] = struct{}{}
@@ -55,12 +55,14 @@ var (
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
 	_ Error = new(internalServerError)
+	_ Error = new(accessDeniedError)
 )
 
 const (
 	errcodeDefault          = -32000
 	errcodeTimeout          = -32002
 	errcodeResponseTooLarge = -32003
+	errcodeAccessDenied     = -32006
 	errcodePanic            = -32603
 	errcodeMarshalError     = -32603
 
@@ -154,3 +156,13 @@ type internalServerError struct {
 func (e *internalServerError) ErrorCode() int { return e.code }
 
 func (e *internalServerError) Error() string { return e.message }
+
+// accessDeniedError is returned for calls of methods which the access policy
+// of the client does not permit.
+type accessDeniedError struct{ method string }
+
+func (e *accessDeniedError) ErrorCode() int { return errcodeAccessDenied }
+
+func (e *accessDeniedError) Error() string {
+	return fmt.Sprintf("access to method %s denied", e.method)
+}
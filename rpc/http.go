@@ -153,6 +153,11 @@ func newClientTransportHTTP(endpoint string, cfg *clientConfig) reconnectFunc {
 	client := cfg.httpClient
 	if client == nil {
 		client = new(http.Client)
+		if cfg.tlsConfig != nil {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = cfg.tlsConfig
+			client.Transport = transport
+		}
 	}
 
 	hc := &httpConn{
@@ -0,0 +1,467 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultJobLimit is the default maximum number of concurrently running jobs.
+	// Jobs are disabled unless a limit is configured.
+	DefaultJobLimit = 0
+
+	// DefaultJobTimeout is the default maximum duration of a job, after which
+	// its context is cancelled.
+	DefaultJobTimeout = time.Hour
+
+	// DefaultJobRetention is the default duration the status of a finished job
+	// is kept for clients to retrieve it.
+	DefaultJobRetention = 10 * time.Minute
+
+	// jobResultSizeLimit is the maximum total size of the partial and final
+	// results held by the running and retained jobs of a server.
+	jobResultSizeLimit = 32 * 1024 * 1024
+)
+
+var (
+	errJobNotFound   = errors.New("job not found")
+	errJobLimit      = errors.New("too many running jobs")
+	errJobsStopped   = errors.New("server stopped")
+	errJobsDisabled  = errors.New("asynchronous jobs are disabled")
+	errJobOwner      = errors.New("jobs require a persistent connection or a credential")
+	errJobResultSize = errors.New("job results exceed the size limit")
+)
+
+// JobState is the state of an asynchronous job.
+type JobState string
+
+const (
+	JobRunning   JobState = "running"   // the method is being executed
+	JobDone      JobState = "done"      // the method returned a result
+	JobFailed    JobState = "failed"    // the method returned an error or timed out
+	JobCancelled JobState = "cancelled" // the job was cancelled by the client
+)
+
+// JobProgress is the progress of a job as reported by the method. The unit of
+// the values depends on the method, e.g. blocks processed by a chain export.
+type JobProgress struct {
+	Done  uint64 `json:"done"`
+	Total uint64 `json:"total"`
+}
+
+// JobError is the error a failed job terminated with.
+type JobError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (err *JobError) Error() string  { return err.Message }
+func (err *JobError) ErrorCode() int { return err.Code }
+func (err *JobError) ErrorData() any { return err.Data }
+
+// JobStatus is the status of a job as reported to clients by rpc_jobStatus and
+// the "job" subscription.
+type JobStatus struct {
+	ID       ID          `json:"id"`
+	Method   string      `json:"method"`
+	State    JobState    `json:"state"`
+	Started  time.Time   `json:"started"`
+	Finished *time.Time  `json:"finished,omitempty"`
+	Progress JobProgress `json:"progress"`
+
+	// Partial contains the partial results reported by the method, starting
+	// at the index requested by the client. NextPartial is the index of the
+	// first partial result not yet reported.
+	Partial     []json.RawMessage `json:"partial,omitempty"`
+	NextPartial int               `json:"nextPartial"`
+
+	Result json.RawMessage `json:"result,omitempty"` // result of the method, once done
+	Error  *JobError       `json:"error,omitempty"`  // error of the method, once failed
+}
+
+// Job is an RPC method call executed asynchronously. Methods running as a job
+// can retrieve it from their context with JobFromContext to report progress and
+// partial results. Methods should also honor the cancellation of the context,
+// which is cancelled when the client cancels the job or it exceeds its lifetime.
+type Job struct {
+	id      ID
+	method  string
+	owner   any // access policy or connection of the client
+	manager *jobManager
+	cancel  context.CancelFunc
+	started time.Time
+
+	mu        sync.Mutex
+	state     JobState
+	cancelled bool
+	overflow  bool // results exceeded the size limit
+	size      int  // size of the results accounted in the manager
+	progress  JobProgress
+	partial   []json.RawMessage
+	result    json.RawMessage
+	err       *JobError
+	finished  time.Time
+	updated   chan struct{} // closed and replaced on every status change
+}
+
+type jobContextKey struct{}
+
+// JobFromContext returns the job executing the method call, or nil if the call
+// was not started as a job. All methods of Job may be called on nil, so methods
+// can report progress without checking whether they are running as a job.
+func JobFromContext(ctx context.Context) *Job {
+	job, _ := ctx.Value(jobContextKey{}).(*Job)
+	return job
+}
+
+// ID returns the ID of the job.
+func (j *Job) ID() ID {
+	if j == nil {
+		return ""
+	}
+	return j.id
+}
+
+// SetProgress reports the progress of the job.
+func (j *Job) SetProgress(done, total uint64) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.state == JobRunning {
+		j.progress = JobProgress{Done: done, Total: total}
+		j.notifyLocked()
+	}
+}
+
+// AddPartial reports a partial result of the job, which clients can consume
+// before the job is done. Once the results of the jobs exceed the size limit
+// of the server, the job fails and further partial results are rejected.
+func (j *Job) AddPartial(result any) error {
+	if j == nil {
+		return nil
+	}
+	enc, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.overflow {
+		return errJobResultSize
+	}
+	if j.state == JobRunning {
+		if !j.reserveLocked(len(enc)) {
+			return errJobResultSize
+		}
+		j.partial = append(j.partial, enc)
+		j.notifyLocked()
+	}
+	return nil
+}
+
+// reserveLocked accounts the size of a result in the manager, marking the job
+// as overflown if the size limit would be exceeded.
+func (j *Job) reserveLocked(size int) bool {
+	if !j.manager.reserve(size) {
+		j.overflow = true
+		return false
+	}
+	j.size += size
+	return true
+}
+
+// notifyLocked wakes up the subscribers waiting for a status change.
+func (j *Job) notifyLocked() {
+	close(j.updated)
+	j.updated = make(chan struct{})
+}
+
+// finish records the outcome of the method call.
+func (j *Job) finish(ctx context.Context, result any, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	switch {
+	case j.cancelled:
+		j.state = JobCancelled
+	case j.overflow:
+		j.state = JobFailed
+		j.err = &JobError{Code: errcodeDefault, Message: errJobResultSize.Error()}
+	case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		j.state = JobFailed
+		j.err = &JobError{Code: errcodeTimeout, Message: errMsgTimeout}
+	case err != nil:
+		j.state = JobFailed
+		enc := errorMessage(err).Error
+		j.err = &JobError{Code: enc.Code, Message: enc.Message, Data: enc.Data}
+	default:
+		enc, err := json.Marshal(result)
+		if err != nil {
+			j.state = JobFailed
+			j.err = &JobError{Code: errcodeMarshalError, Message: err.Error()}
+		} else if !j.reserveLocked(len(enc)) {
+			j.state = JobFailed
+			j.err = &JobError{Code: errcodeDefault, Message: errJobResultSize.Error()}
+		} else {
+			j.state = JobDone
+			j.result = enc
+		}
+	}
+	j.finished = time.Now()
+	j.notifyLocked()
+}
+
+// abort cancels the job, returning false if it is no longer running.
+func (j *Job) abort() bool {
+	j.mu.Lock()
+	running := j.state == JobRunning && !j.cancelled
+	j.cancelled = true
+	j.mu.Unlock()
+
+	j.cancel()
+	return running
+}
+
+// status returns the status of the job including the partial results starting
+// at the given index, and a channel which is closed upon the next change.
+func (j *Job) status(from int) (*JobStatus, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	status := &JobStatus{
+		ID:          j.id,
+		Method:      j.method,
+		State:       j.state,
+		Started:     j.started,
+		Progress:    j.progress,
+		NextPartial: len(j.partial),
+		Result:      j.result,
+		Error:       j.err,
+	}
+	if from >= 0 && from < len(j.partial) {
+		status.Partial = j.partial[from:]
+	}
+	if j.state != JobRunning {
+		finished := j.finished
+		status.Finished = &finished
+	}
+	return status, j.updated
+}
+
+// jobManager runs the jobs of a server.
+type jobManager struct {
+	idgen func() ID
+
+	mu        sync.Mutex
+	jobs      map[ID]*Job
+	running   int
+	size      int // total size of the results of all jobs
+	sizeLimit int
+	limit     int
+	timeout   time.Duration
+	retention time.Duration
+	stopped   bool
+}
+
+func newJobManager(idgen func() ID) *jobManager {
+	return &jobManager{
+		idgen:     idgen,
+		jobs:      make(map[ID]*Job),
+		sizeLimit: jobResultSizeLimit,
+		limit:     DefaultJobLimit,
+		timeout:   DefaultJobTimeout,
+		retention: DefaultJobRetention,
+	}
+}
+
+// setLimits configures the limits of subsequently started jobs. A non-positive
+// limit disables jobs, non-positive durations select the defaults.
+func (m *jobManager) setLimits(limit int, timeout, retention time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.limit, m.timeout, m.retention = 0, DefaultJobTimeout, DefaultJobRetention
+	if limit > 0 {
+		m.limit = limit
+	}
+	if timeout > 0 {
+		m.timeout = timeout
+	}
+	if retention > 0 {
+		m.retention = retention
+	}
+}
+
+// reserve accounts size bytes of job results, returning false if the size limit
+// would be exceeded.
+func (m *jobManager) reserve(size int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.size+size > m.sizeLimit {
+		return false
+	}
+	m.size += size
+	return true
+}
+
+// jobOwner returns the client which starts a job in ctx: its access policy if
+// it presented a credential, or its connection otherwise. Clients without
+// either, i.e. anonymous HTTP clients, cannot retrieve jobs and get nil.
+func jobOwner(ctx context.Context) any {
+	if policy := AccessPolicyFromContext(ctx); policy != nil {
+		return policy
+	}
+	if client, ok := ClientFromContext(ctx); ok {
+		return client
+	}
+	return nil
+}
+
+// start runs the method call as a job. The job inherits the values of ctx, such
+// as the peer info and access policy of the client, but not its cancellation.
+// Jobs started over a connection without credential are cancelled when the
+// connection is closed.
+func (m *jobManager) start(ctx context.Context, reg *serviceRegistry, method string, params json.RawMessage) (ID, error) {
+	owner := jobOwner(ctx)
+	if owner == nil {
+		return "", errJobOwner
+	}
+	if err := checkAccess(ctx, method); err != nil {
+		return "", err
+	}
+	if namespace, _, _ := strings.Cut(method, serviceMethodSeparator); namespace == MetadataApi {
+		return "", &invalidParamsError{fmt.Sprintf("method %s cannot run as a job", method)}
+	}
+	callb := reg.callback(method)
+	if callb == nil {
+		return "", &methodNotFoundError{method: method}
+	}
+	args, err := parsePositionalArguments(params, callb.argTypes)
+	if err != nil {
+		return "", &invalidParamsError{err.Error()}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped {
+		return "", errJobsStopped
+	}
+	if m.limit <= 0 {
+		return "", errJobsDisabled
+	}
+	if m.running >= m.limit {
+		return "", errJobLimit
+	}
+	job := &Job{
+		id:      m.idgen(),
+		method:  method,
+		owner:   owner,
+		manager: m,
+		started: time.Now(),
+		state:   JobRunning,
+		updated: make(chan struct{}),
+	}
+	ctx, job.cancel = context.WithTimeout(context.WithoutCancel(ctx), m.timeout)
+	ctx = context.WithValue(ctx, jobContextKey{}, job)
+
+	m.jobs[job.id] = job
+	m.running++
+	go m.run(ctx, job, callb, args)
+	if client, ok := owner.(*Client); ok {
+		go func() {
+			select {
+			case <-client.didClose:
+				job.abort()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return job.id, nil
+}
+
+// run executes the method call of the job and schedules the removal of the job
+// once its retention period has passed.
+func (m *jobManager) run(ctx context.Context, job *Job, callb *callback, args []reflect.Value) {
+	result, err := callb.call(ctx, job.method, args)
+	job.finish(ctx, result, err)
+	job.cancel()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.running--
+	time.AfterFunc(m.retention, func() {
+		job.mu.Lock()
+		size := job.size
+		job.mu.Unlock()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.jobs, job.id)
+		m.size -= size
+	})
+}
+
+// get returns the job with the given ID, if it was started by the same client
+// as the one in ctx.
+func (m *jobManager) get(ctx context.Context, id ID) (*Job, error) {
+	owner := jobOwner(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job := m.jobs[id]
+	if job == nil || owner == nil || job.owner != owner {
+		return nil, errJobNotFound
+	}
+	return job, nil
+}
+
+// cancel cancels the job, returning false if it is no longer running.
+func (m *jobManager) cancel(ctx context.Context, id ID) (bool, error) {
+	job, err := m.get(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return job.abort(), nil
+}
+
+// stop cancels all running jobs and prevents new ones from being started.
+func (m *jobManager) stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stopped = true
+	for _, job := range m.jobs {
+		job.cancel()
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitJob polls the job until it is no longer running.
+func waitJob(t *testing.T, client *Client, id ID) *JobStatus {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		var status JobStatus
+		if err := client.Call(&status, "rpc_jobStatus", id); err != nil {
+			t.Fatal(err)
+		}
+		if status.State != JobRunning {
+			return &status
+		}
+	}
+	t.Fatal("job did not finish")
+	return nil
+}
+
+// newJobTestServer creates a test server with jobs enabled.
+func newJobTestServer() *Server {
+	server := newTestServer()
+	server.SetJobLimits(16, 0, 0)
+	return server
+}
+
+func TestJobResult(t *testing.T) {
+	server := newJobTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var id ID
+	if err := client.Call(&id, "rpc_startJob", "test_count", []any{3}); err != nil {
+		t.Fatal(err)
+	}
+	status := waitJob(t, client, id)
+	if status.State != JobDone || string(status.Result) != "3" {
+		t.Fatalf("wrong job outcome: state %s, result %s, error %v", status.State, status.Result, status.Error)
+	}
+	if status.Progress != (JobProgress{Done: 3, Total: 3}) {
+		t.Errorf("wrong progress: %+v", status.Progress)
+	}
+	if status.Finished == nil {
+		t.Error("finished job has no finish time")
+	}
+	// Partial results are returned from the requested index.
+	if err := client.Call(&status, "rpc_jobStatus", id, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Partial) != 2 || string(status.Partial[0]) != "1" || status.NextPartial != 3 {
+		t.Errorf("wrong partial results: %s, next %d", status.Partial, status.NextPartial)
+	}
+
+	// Failing calls are reported in the status.
+	if err := client.Call(&id, "rpc_startJob", "test_returnError"); err != nil {
+		t.Fatal(err)
+	}
+	status = waitJob(t, client, id)
+	if status.State != JobFailed || status.Error == nil || status.Error.Code != (testError{}).ErrorCode() {
+		t.Fatalf("wrong job outcome: state %s, error %v", status.State, status.Error)
+	}
+
+	// Invalid jobs are rejected on start.
+	for _, args := range [][]any{
+		{"test_unknown"},
+		{"test_count", []any{"x"}},
+		{"rpc_startJob", []any{"test_count"}},
+		{"nftest_subscribe", []any{"someSubscription"}},
+	} {
+		if err := client.Call(&id, "rpc_startJob", args...); err == nil {
+			t.Errorf("job %v started", args)
+		}
+	}
+	var unknown JobStatus
+	if err := client.Call(&unknown, "rpc_jobStatus", "0x1234"); err == nil {
+		t.Error("status of unknown job returned")
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	server := newJobTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	// The job keeps running after the request which started it completed.
+	var id ID
+	if err := client.Call(&id, "rpc_startJob", "test_block"); err != nil {
+		t.Fatal(err)
+	}
+	var status JobStatus
+	if err := client.Call(&status, "rpc_jobStatus", id); err != nil {
+		t.Fatal(err)
+	}
+	if status.State != JobRunning {
+		t.Fatalf("job not running: %s", status.State)
+	}
+	var cancelled bool
+	if err := client.Call(&cancelled, "rpc_cancelJob", id); err != nil || !cancelled {
+		t.Fatalf("cancel failed: %v %v", cancelled, err)
+	}
+	if status := waitJob(t, client, id); status.State != JobCancelled {
+		t.Fatalf("wrong state of cancelled job: %s", status.State)
+	}
+	if err := client.Call(&cancelled, "rpc_cancelJob", id); err != nil || cancelled {
+		t.Fatalf("cancel of finished job returned %v %v", cancelled, err)
+	}
+}
+
+func TestJobLimits(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetJobLimits(1, 50*time.Millisecond, 50*time.Millisecond)
+	client := DialInProc(server)
+	defer client.Close()
+
+	var id, other ID
+	if err := client.Call(&id, "rpc_startJob", "test_block"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(&other, "rpc_startJob", "test_block"); err == nil {
+		t.Fatal("job limit not enforced")
+	}
+	status := waitJob(t, client, id)
+	if status.State != JobFailed || status.Error == nil || status.Error.Code != errcodeTimeout {
+		t.Fatalf("wrong outcome of timed out job: state %s, error %v", status.State, status.Error)
+	}
+	// The job is forgotten once its retention period passed.
+	time.Sleep(200 * time.Millisecond)
+	if err := client.Call(&status, "rpc_jobStatus", id); err == nil {
+		t.Fatal("expired job still available")
+	}
+}
+
+func TestJobsDisabled(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var id ID
+	if err := client.Call(&id, "rpc_startJob", "test_count", []any{1}); err == nil || err.Error() != errJobsDisabled.Error() {
+		t.Fatalf("job started on server without job limit: %v", err)
+	}
+}
+
+func TestJobResultSizeLimit(t *testing.T) {
+	server := newJobTestServer()
+	defer server.Stop()
+	server.jobs.sizeLimit = 4
+	client := DialInProc(server)
+	defer client.Close()
+
+	// The partial results 0,1,2,3 fill the limit, the final result exceeds it.
+	var id ID
+	if err := client.Call(&id, "rpc_startJob", "test_count", []any{4}); err != nil {
+		t.Fatal(err)
+	}
+	status := waitJob(t, client, id)
+	if status.State != JobFailed || status.Error == nil || status.Error.Message != errJobResultSize.Error() {
+		t.Fatalf("wrong outcome of oversized job: state %s, error %v", status.State, status.Error)
+	}
+	if len(status.Partial) != 4 {
+		t.Errorf("wrong number of partial results: %d", len(status.Partial))
+	}
+}
+
+// TestJobConnection checks that jobs started without credential are bound to
+// the connection which started them.
+func TestJobConnection(t *testing.T) {
+	server := newJobTestServer()
+	defer server.Stop()
+
+	// Anonymous HTTP clients can't start jobs, since they can't retrieve them.
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+	httpclient, err := Dial(httpsrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer httpclient.Close()
+
+	var id ID
+	if err := httpclient.Call(&id, "rpc_startJob", "test_count", []any{1}); err == nil {
+		t.Fatal("job started by anonymous HTTP client")
+	}
+
+	// Jobs are invisible to other connections, and cancelled when the
+	// connection which started them is closed.
+	client, other := DialInProc(server), DialInProc(server)
+	defer other.Close()
+	if err := client.Call(&id, "rpc_startJob", "test_block"); err != nil {
+		t.Fatal(err)
+	}
+	var status JobStatus
+	if err := other.Call(&status, "rpc_jobStatus", id); err == nil {
+		t.Fatal("job of other connection returned")
+	}
+	var cancelled bool
+	if err := other.Call(&cancelled, "rpc_cancelJob", id); err == nil {
+		t.Fatal("job of other connection cancelled")
+	}
+	server.jobs.mu.Lock()
+	job := server.jobs.jobs[id]
+	server.jobs.mu.Unlock()
+	client.Close()
+
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if status, _ := job.status(0); status.State == JobCancelled {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("job not cancelled after closing its connection")
+		}
+	}
+}
+
+func TestJobAccess(t *testing.T) {
+	server := newJobTestServer()
+	defer server.Stop()
+
+	first, _ := NewAccessPolicy("first", []string{"rpc", "test_count"}, nil)
+	second, _ := NewAccessPolicy("second", []string{"rpc", "test"}, nil)
+
+	ctx := WithAccessPolicy(context.Background(), first)
+	service := &RPCService{server}
+	if _, err := service.StartJob(ctx, "test_block", nil); err == nil {
+		t.Fatal("job of denied method started")
+	}
+	params := json.RawMessage(`[1]`)
+	id, err := service.StartJob(ctx, "test_count", &params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.JobStatus(ctx, id, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, ctx := range []context.Context{context.Background(), WithAccessPolicy(context.Background(), second)} {
+		if _, err := service.JobStatus(ctx, id, nil); !errors.Is(err, errJobNotFound) {
+			t.Errorf("job of other client returned: %v", err)
+		}
+		if _, err := service.CancelJob(ctx, id); !errors.Is(err, errJobNotFound) {
+			t.Errorf("job of other client cancelled: %v", err)
+		}
+	}
+}
+
+func TestJobSubscription(t *testing.T) {
+	server := newJobTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+
+	client, err := Dial("ws:" + strings.TrimPrefix(httpsrv.URL, "http:"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var id ID
+	if err := client.Call(&id, "rpc_startJob", "test_count", []any{5}); err != nil {
+		t.Fatal(err)
+	}
+	updates := make(chan *JobStatus)
+	sub, err := client.Subscribe(context.Background(), "rpc", updates, "job", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	var partial []string
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case status := <-updates:
+			for _, result := range status.Partial {
+				partial = append(partial, string(result))
+			}
+			if status.State == JobRunning {
+				continue
+			}
+			if status.State != JobDone || string(status.Result) != "5" {
+				t.Fatalf("wrong job outcome: state %s, result %s", status.State, status.Result)
+			}
+			if strings.Join(partial, ",") != "0,1,2,3,4" {
+				t.Fatalf("wrong partial results: %v", partial)
+			}
+			return
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-timeout:
+			t.Fatal("job did not finish")
+		}
+	}
+}
@@ -32,6 +32,15 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	// accessDeniedMeter counts calls rejected by access policies. Rejections
+	// are additionally tracked per credential under accessDeniedName.
+	accessDeniedMeter = metrics.NewRegisteredMeter("rpc/denied/all", nil)
+	accessDeniedName  = "rpc/denied"
+
+	cacheHitMeter   = metrics.NewRegisteredMeter("rpc/cache/hit", nil)
+	cacheMissMeter  = metrics.NewRegisteredMeter("rpc/cache/miss", nil)
+	cacheEvictMeter = metrics.NewRegisteredMeter("rpc/cache/evict", nil)
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -48,3 +57,9 @@ func updateServeTimeHistogram(method string, success bool, elapsed time.Duration
 	}
 	metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(elapsed.Nanoseconds())
 }
+
+// updateAccessDeniedMeter tracks a call rejected by the access policy of the
+// named credential.
+func updateAccessDeniedMeter(name string) {
+	metrics.GetOrRegisterMeter(fmt.Sprintf("%s/%s", accessDeniedName, name), nil).Mark(1)
+}
@@ -18,11 +18,13 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -47,6 +49,7 @@ const (
 type Server struct {
 	services serviceRegistry
 	idgen    func() ID
+	jobs     *jobManager
 
 	mutex              sync.Mutex
 	codecs             map[ServerCodec]struct{}
@@ -63,6 +66,7 @@ func NewServer() *Server {
 		codecs:        make(map[ServerCodec]struct{}),
 		httpBodyLimit: defaultBodyLimit,
 	}
+	server.jobs = newJobManager(func() ID { return server.idgen() })
 	server.run.Store(true)
 	// Register the default service providing meta information about the RPC service such
 	// as the services and methods it offers.
@@ -89,6 +93,28 @@ func (s *Server) SetHTTPBodyLimit(limit int) {
 	s.httpBodyLimit = limit
 }
 
+// SetJobLimits sets the limits of asynchronous jobs started through rpc_startJob:
+// the maximum number of concurrently running jobs, the maximum duration of a job
+// and how long the status of finished jobs is retained. Jobs are disabled with a
+// non-positive limit, non-positive durations select the defaults.
+func (s *Server) SetJobLimits(limit int, timeout, retention time.Duration) {
+	s.jobs.setLimits(limit, timeout, retention)
+}
+
+// SetResponseCache enables caching the results of cacheable methods which
+// declared them immutable, using at most maxSize bytes. A non-positive size
+// disables the cache.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetResponseCache(maxSize int) {
+	if maxSize <= 0 {
+		s.services.cache.Store(nil)
+		return
+	}
+	s.services.cache.Store(newResponseCache(maxSize))
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either an RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -97,6 +123,14 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// RegisterCacheable declares methods of the service registered under the given name
+// cacheable. Results of cacheable methods are served from the response cache after the
+// method declared them immutable using MarkImmutable. Methods are given by their RPC
+// name without namespace, e.g. "getBlockByHash".
+func (s *Server) RegisterCacheable(name string, methods ...string) error {
+	return s.services.setCacheable(name, methods)
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
@@ -189,6 +223,7 @@ func (s *Server) Stop() {
 
 	if s.run.CompareAndSwap(true, false) {
 		log.Debug("RPC server shutting down")
+		s.jobs.stop()
 		for codec := range s.codecs {
 			codec.close()
 		}
@@ -213,6 +248,73 @@ func (s *RPCService) Modules() map[string]string {
 	return modules
 }
 
+// StartJob starts the call of the given method with the given parameters as an
+// asynchronous job, returning its ID. The job keeps running when the request
+// which started it completes. Clients poll its status with rpc_jobStatus or
+// subscribe to it with rpc_subscribe("job", id), and cancel it with
+// rpc_cancelJob. Subscriptions cannot be started as jobs.
+//
+// Jobs belong to the credential of the client which started them, or to its
+// connection if it presented none, and are only visible to that client.
+func (s *RPCService) StartJob(ctx context.Context, method string, params *json.RawMessage) (ID, error) {
+	var args json.RawMessage
+	if params != nil {
+		args = *params
+	}
+	return s.server.jobs.start(ctx, &s.server.services, method, args)
+}
+
+// JobStatus returns the status of the job, including its partial results from
+// the given index on.
+func (s *RPCService) JobStatus(ctx context.Context, id ID, from *int) (*JobStatus, error) {
+	job, err := s.server.jobs.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var start int
+	if from != nil {
+		start = *from
+	}
+	status, _ := job.status(start)
+	return status, nil
+}
+
+// CancelJob cancels the job, returning false if it has already finished.
+func (s *RPCService) CancelJob(ctx context.Context, id ID) (bool, error) {
+	return s.server.jobs.cancel(ctx, id)
+}
+
+// Job subscribes to the status of the job. A notification is sent for every
+// change until the job finishes, each containing the partial results reported
+// since the previous one.
+func (s *RPCService) Job(ctx context.Context, id ID) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return &Subscription{}, ErrNotificationsUnsupported
+	}
+	job, err := s.server.jobs.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		var next int
+		for {
+			status, updated := job.status(next)
+			next = status.NextPartial
+			if err := notifier.Notify(sub.ID, status); err != nil || status.State != JobRunning {
+				return
+			}
+			select {
+			case <-updated:
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
 // PeerInfo contains information about the remote end of the network connection.
 //
 // This is available within RPC method handlers through the context. Call
@@ -23,6 +23,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -38,6 +39,7 @@ var (
 type serviceRegistry struct {
 	mu       sync.Mutex
 	services map[string]service
+	cache    atomic.Pointer[responseCache] // cache of immutable results, if enabled
 }
 
 // service represents a registered object.
@@ -55,6 +57,7 @@ type callback struct {
 	hasCtx      bool           // method's first argument is a context (not included in argTypes)
 	errPos      int            // err return idx, of -1 when method cannot return error
 	isSubscribe bool           // true if this is a subscription callback
+	cacheable   bool           // true if results of the method may be cached
 }
 
 func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
@@ -102,6 +105,25 @@ func (r *serviceRegistry) callback(method string) *callback {
 	return r.services[before].callbacks[after]
 }
 
+// setCacheable marks methods of a registered service as cacheable.
+func (r *serviceRegistry) setCacheable(name string, methods []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	svc, ok := r.services[name]
+	if !ok {
+		return fmt.Errorf("no service %q registered", name)
+	}
+	for _, method := range methods {
+		cb := svc.callbacks[method]
+		if cb == nil {
+			return fmt.Errorf("no method %q in service %q", method, name)
+		}
+		cb.cacheable = true
+	}
+	return nil
+}
+
 // subscription returns a subscription callback in the given service.
 func (r *serviceRegistry) subscription(service, name string) *callback {
 	r.mu.Lock()
@@ -107,6 +107,16 @@ func (s *testService) Block(ctx context.Context) error {
 	return errors.New("context canceled in testservice_block")
 }
 
+// Count reports n partial results and progress steps when running as a job.
+func (s *testService) Count(ctx context.Context, n int) int {
+	job := JobFromContext(ctx)
+	for i := 0; i < n; i++ {
+		job.AddPartial(i)
+		job.SetProgress(uint64(i+1), uint64(n))
+	}
+	return n
+}
+
 func (s *testService) Rets() (string, error) {
 	return "", nil
 }
@@ -35,6 +35,7 @@ type API struct {
 	Service       interface{} // receiver instance which holds the methods
 	Public        bool        // deprecated - this field is no longer used, but retained for compatibility
 	Authenticated bool        // whether the api should only be available behind authentication.
+	Cacheable     []string    // methods whose results may be cached, see MarkImmutable
 }
 
 // ServerCodec implements reading, parsing and writing RPC messages for the server side of
@@ -48,6 +49,12 @@ type ServerCodec interface {
 	jsonWriter
 }
 
+// accessRestrictedCodec is implemented by server codecs of connections whose
+// calls may be restricted by an access policy.
+type accessRestrictedCodec interface {
+	accessPolicy() *AccessPolicy
+}
+
 // jsonWriter can write JSON messages to its underlying connection.
 // Implementations must be safe for concurrent use.
 type jsonWriter interface {
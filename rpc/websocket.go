@@ -61,6 +61,7 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			return
 		}
 		codec := newWebsocketCodec(conn, r.Host, r.Header, wsDefaultReadLimit)
+		codec.(*websocketCodec).access = AccessPolicyFromContext(r.Context())
 		s.ServeCodec(codec, 0)
 	})
 }
@@ -231,6 +232,11 @@ func newClientTransportWS(endpoint string, cfg *clientConfig) (reconnectFunc, er
 			Proxy:           http.ProxyFromEnvironment,
 		}
 	}
+	if cfg.tlsConfig != nil && dialer.TLSClientConfig == nil {
+		withTLS := *dialer
+		withTLS.TLSClientConfig = cfg.tlsConfig
+		dialer = &withTLS
+	}
 
 	dialURL, header, err := wsClientHeaders(endpoint, "")
 	if err != nil {
@@ -283,8 +289,9 @@ func wsClientHeaders(endpoint, origin string) (string, http.Header, error) {
 
 type websocketCodec struct {
 	*jsonCodec
-	conn *websocket.Conn
-	info PeerInfo
+	conn   *websocket.Conn
+	info   PeerInfo
+	access *AccessPolicy // restricts the calls of the client, if authenticated
 
 	wg           sync.WaitGroup
 	pingReset    chan struct{}
@@ -332,6 +339,10 @@ func (wc *websocketCodec) peerInfo() PeerInfo {
 	return wc.info
 }
 
+func (wc *websocketCodec) accessPolicy() *AccessPolicy {
+	return wc.access
+}
+
 func (wc *websocketCodec) writeJSON(ctx context.Context, v interface{}, isError bool) error {
 	err := wc.jsonCodec.writeJSON(ctx, v, isError)
 	if err == nil {
@@ -0,0 +1,229 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bintrie
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// iteratorState represents the iteration state at one particular node of the
+// tree, or at one particular leaf value of a stem node.
+type iteratorState struct {
+	node  BinaryNode // Node being iterated, nil for leaf values
+	path  []byte     // Path of the node being iterated
+	index int        // Child or leaf value to be processed next
+
+	key   []byte // Full key of the leaf value
+	value []byte // Leaf value
+}
+
+// binaryNodeIterator is an iterator to traverse the binary tree pre-order,
+// visiting the internal nodes, the stem nodes and the leaf values of the stem
+// nodes.
+type binaryNodeIterator struct {
+	trie  *BinaryTrie
+	start []byte           // Path of the start key, the subtrees before are skipped
+	stack []*iteratorState // Hierarchy of tree nodes persisting the iteration state
+	err   error
+}
+
+// NodeIterator implements state.Trie, returning an iterator that returns
+// nodes of the tree. Iteration starts at the key after the given start key.
+func (t *BinaryTrie) NodeIterator(start []byte) (trie.NodeIterator, error) {
+	it := &binaryNodeIterator{trie: t}
+	if len(start) > 0 {
+		key := common.RightPadBytes(start, 32)
+		it.start = keyToPath(StemSize*8, key)
+		it.start = append(it.start, key[StemSize])
+	}
+	return it, nil
+}
+
+// Next moves the iterator to the next node. If the parameter is false, any
+// child nodes will be skipped.
+func (it *binaryNodeIterator) Next(descend bool) bool {
+	if it.err != nil {
+		return false
+	}
+	it.trie.lock.Lock()
+	defer it.trie.lock.Unlock()
+
+	if len(it.stack) == 0 {
+		if _, ok := it.trie.root.(Empty); ok {
+			return false
+		}
+		it.stack = append(it.stack, &iteratorState{node: it.trie.root})
+		return true
+	}
+	if !descend || it.stack[len(it.stack)-1].node == nil {
+		it.stack = it.stack[:len(it.stack)-1]
+	}
+	for len(it.stack) > 0 {
+		top := it.stack[len(it.stack)-1]
+		switch n := top.node.(type) {
+		case *InternalNode:
+			for top.index < 2 {
+				bit := top.index
+				top.index++
+
+				path := append(common.CopyBytes(top.path), byte(bit))
+				if it.skip(path) {
+					continue
+				}
+				child := n.children[bit]
+				if hn, ok := child.(HashedNode); ok {
+					blob, err := it.trie.resolve(path, common.Hash(hn))
+					if err != nil {
+						it.err = err
+						return false
+					}
+					if child, err = decodeNode(blob, n.depth+1, common.Hash(hn)); err != nil {
+						it.err = err
+						return false
+					}
+					n.children[bit] = child
+				}
+				if _, ok := child.(Empty); ok {
+					continue
+				}
+				it.stack = append(it.stack, &iteratorState{node: child, path: path})
+				return true
+			}
+		case *StemNode:
+			for top.index < NodeWidth {
+				index := top.index
+				top.index++
+
+				if n.Values[index] == nil {
+					continue
+				}
+				key := append(common.CopyBytes(n.Stem), byte(index))
+				if it.start != nil && bytes.Compare(key, it.startKey()) < 0 {
+					continue
+				}
+				it.stack = append(it.stack, &iteratorState{path: top.path, key: key, value: n.Values[index]})
+				return true
+			}
+		}
+		it.stack = it.stack[:len(it.stack)-1]
+	}
+	return false
+}
+
+// skip reports whether the subtree at the given path is entirely before the
+// start key.
+func (it *binaryNodeIterator) skip(path []byte) bool {
+	if it.start == nil {
+		return false
+	}
+	n := len(path)
+	if n > StemSize*8 {
+		n = StemSize * 8
+	}
+	return bytes.Compare(path[:n], it.start[:n]) < 0
+}
+
+// startKey reconstructs the start key from its path.
+func (it *binaryNodeIterator) startKey() []byte {
+	key := make([]byte, 32)
+	for i := 0; i < StemSize*8; i++ {
+		key[i/8] |= it.start[i] << (7 - i%8)
+	}
+	key[StemSize] = it.start[StemSize*8]
+	return key
+}
+
+// Error returns the error status of the iterator.
+func (it *binaryNodeIterator) Error() error {
+	return it.err
+}
+
+// Hash returns the hash of the current node, or the zero hash for leaf values.
+func (it *binaryNodeIterator) Hash() common.Hash {
+	if len(it.stack) == 0 || it.stack[len(it.stack)-1].node == nil {
+		return common.Hash{}
+	}
+	return it.stack[len(it.stack)-1].node.Hash()
+}
+
+// Parent returns the hash of the parent of the current node.
+func (it *binaryNodeIterator) Parent() common.Hash {
+	if len(it.stack) < 2 {
+		return common.Hash{}
+	}
+	return it.stack[len(it.stack)-2].node.Hash()
+}
+
+// Path returns the path to the current node, one byte per bit. The leaf values
+// share the path of their stem node.
+func (it *binaryNodeIterator) Path() []byte {
+	if len(it.stack) == 0 {
+		return nil
+	}
+	return it.stack[len(it.stack)-1].path
+}
+
+// NodeBlob returns the serialized blob of the current node.
+func (it *binaryNodeIterator) NodeBlob() []byte {
+	if len(it.stack) == 0 || it.stack[len(it.stack)-1].node == nil {
+		return nil
+	}
+	return SerializeNode(it.stack[len(it.stack)-1].node)
+}
+
+// Leaf returns true iff the current position is a leaf value.
+func (it *binaryNodeIterator) Leaf() bool {
+	return len(it.stack) > 0 && it.stack[len(it.stack)-1].node == nil
+}
+
+// LeafKey returns the key of the leaf. The method panics if the iterator is
+// not positioned at a leaf.
+func (it *binaryNodeIterator) LeafKey() []byte {
+	if !it.Leaf() {
+		panic("not at leaf")
+	}
+	return it.stack[len(it.stack)-1].key
+}
+
+// LeafBlob returns the content of the leaf. The method panics if the iterator
+// is not positioned at a leaf.
+func (it *binaryNodeIterator) LeafBlob() []byte {
+	if !it.Leaf() {
+		panic("not at leaf")
+	}
+	return it.stack[len(it.stack)-1].value
+}
+
+// LeafProof returns the serialized nodes on the path from the root to the
+// leaf. The method panics if the iterator is not positioned at a leaf.
+func (it *binaryNodeIterator) LeafProof() [][]byte {
+	if !it.Leaf() {
+		panic("not at leaf")
+	}
+	proofs := make([][]byte, 0, len(it.stack)-1)
+	for _, state := range it.stack[:len(it.stack)-1] {
+		proofs = append(proofs, SerializeNode(state.node))
+	}
+	return proofs
+}
+
+// AddResolver is a no-op, the nodes are always resolved from the database of
+// the tree.
+func (it *binaryNodeIterator) AddResolver(trie.NodeResolver) {}
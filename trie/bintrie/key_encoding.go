@@ -0,0 +1,94 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bintrie
+
+import (
+	"crypto/sha256"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+const (
+	BasicDataLeafKey = 0 // Leaf index of the account basic data
+	CodeHashLeafKey  = 1 // Leaf index of the account code hash
+
+	BasicDataVersionOffset  = 0  // Offset of the version byte in the basic data
+	BasicDataCodeSizeOffset = 5  // Offset of the 3-byte code size in the basic data
+	BasicDataNonceOffset    = 8  // Offset of the 8-byte nonce in the basic data
+	BasicDataBalanceOffset  = 16 // Offset of the 16-byte balance in the basic data
+
+	StemSize  = 31  // Number of bytes in a stem
+	NodeWidth = 256 // Number of leaves in a stem node
+)
+
+var (
+	headerStorageOffset = uint256.NewInt(64)  // Leaf index of the first header storage slot
+	codeOffset          = uint256.NewInt(128) // Leaf index of the first code chunk
+
+	// mainStorageTreeIndex is the tree index of the first main storage slot,
+	// namely MAIN_STORAGE_OFFSET (256**31) divided by the stem node width.
+	mainStorageTreeIndex = new(uint256.Int).Lsh(uint256.NewInt(1), 240)
+)
+
+// GetBinaryTreeKey derives the tree key of the leaf at the given tree index and
+// sub index of an account, as defined in EIP-7864:
+//
+//	tree_hash(address32 + tree_index.to_bytes(32, "little"))[:31] + sub_index
+func GetBinaryTreeKey(addr common.Address, treeIndex *uint256.Int, subIndex byte) []byte {
+	var buf [64]byte
+	copy(buf[12:32], addr[:])
+	index := treeIndex.Bytes32()
+	for i := 0; i < 32; i++ {
+		buf[32+i] = index[31-i] // little endian
+	}
+	key := sha256.Sum256(buf[:])
+	key[StemSize] = subIndex
+	return key[:]
+}
+
+// BasicDataKey returns the tree key of the basic data leaf of an account.
+func BasicDataKey(addr common.Address) []byte {
+	return GetBinaryTreeKey(addr, new(uint256.Int), BasicDataLeafKey)
+}
+
+// CodeHashKey returns the tree key of the code hash leaf of an account.
+func CodeHashKey(addr common.Address) []byte {
+	return GetBinaryTreeKey(addr, new(uint256.Int), CodeHashLeafKey)
+}
+
+// StorageSlotKey returns the tree key of a storage slot. The first 64 slots are
+// stored in the account header stem, the others in the main storage area.
+func StorageSlotKey(addr common.Address, slot []byte) []byte {
+	pos := new(uint256.Int).SetBytes(slot)
+	if pos.Lt(headerStorageOffset) {
+		return GetBinaryTreeKey(addr, new(uint256.Int), byte(pos.Uint64()+headerStorageOffset.Uint64()))
+	}
+	// MAIN_STORAGE_OFFSET + slot might overflow, split it into the tree index
+	// and sub index before adding the offset.
+	subIndex := byte(pos.Uint64() & 0xff)
+	treeIndex := pos.Rsh(pos, 8)
+	treeIndex.Add(treeIndex, mainStorageTreeIndex)
+	return GetBinaryTreeKey(addr, treeIndex, subIndex)
+}
+
+// CodeChunkKey returns the tree key of the given code chunk of an account.
+func CodeChunkKey(addr common.Address, chunk uint64) []byte {
+	pos := new(uint256.Int).Add(codeOffset, uint256.NewInt(chunk))
+	subIndex := byte(pos.Uint64() & 0xff)
+	return GetBinaryTreeKey(addr, pos.Rsh(pos, 8), subIndex)
+}
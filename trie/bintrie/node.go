@@ -0,0 +1,426 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bintrie
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	nodeTypeInternal = 1 // Serialized internal node: type || left hash || right hash
+	nodeTypeStem     = 2 // Serialized stem node: type || stem || bitmap || values
+)
+
+var (
+	errInvalidNode    = errors.New("invalid binary tree node")
+	errUnresolvedNode = errors.New("unresolved binary tree node")
+	errInvalidValue   = errors.New("binary tree values must be 32 bytes")
+)
+
+// NodeResolverFn resolves the serialized node at the given path with the given
+// hash from the database.
+type NodeResolverFn func(path []byte, hash common.Hash) ([]byte, error)
+
+// nodeFlushFn is invoked for every dirty node collected at commit time.
+type nodeFlushFn func(path []byte, node BinaryNode)
+
+// BinaryNode is a node of the binary tree, as specified in EIP-7864. The tree
+// consists of internal nodes branching on a single bit of the stem, and stem
+// nodes holding the 256 leaf values sharing the same 31-byte stem.
+type BinaryNode interface {
+	// Get retrieves the value of the given 32-byte key, nil if it's absent.
+	Get(key []byte, resolver NodeResolverFn) ([]byte, error)
+
+	// GetValuesAtStem retrieves all the leaf values of the given stem, nil if
+	// the stem is not present in the tree.
+	GetValuesAtStem(stem []byte, resolver NodeResolverFn) ([][]byte, error)
+
+	// Insert writes the value of the given key into the subtree at the given
+	// depth, returning the new root of the subtree.
+	Insert(key []byte, value []byte, resolver NodeResolverFn, depth int) (BinaryNode, error)
+
+	// Hash returns the merkle root of the subtree.
+	Hash() common.Hash
+
+	// Copy returns a deep-copied subtree.
+	Copy() BinaryNode
+
+	// collectNodes invokes the flush callback with every dirty node in the
+	// subtree, children first, and marks them as clean.
+	collectNodes(path []byte, flush nodeFlushFn)
+}
+
+// getBit returns the i-th bit of the key, counting from the most significant
+// bit of the first byte.
+func getBit(key []byte, i int) byte {
+	return (key[i/8] >> (7 - i%8)) & 1
+}
+
+// keyToPath converts the first depth bits of the key into a node path, which
+// uses one byte per bit.
+func keyToPath(depth int, key []byte) []byte {
+	path := make([]byte, depth)
+	for i := 0; i < depth; i++ {
+		path[i] = getBit(key, i)
+	}
+	return path
+}
+
+// hashPair hashes two child hashes together. The hash of two empty children is
+// the zero hash, to keep the empty subtrees free to compute.
+func hashPair(left, right common.Hash) common.Hash {
+	if left == (common.Hash{}) && right == (common.Hash{}) {
+		return common.Hash{}
+	}
+	h := sha256.New()
+	h.Write(left[:])
+	h.Write(right[:])
+	return common.BytesToHash(h.Sum(nil))
+}
+
+// Empty represents an empty subtree.
+type Empty struct{}
+
+func (Empty) Get(key []byte, resolver NodeResolverFn) ([]byte, error) { return nil, nil }
+
+func (Empty) GetValuesAtStem(stem []byte, resolver NodeResolverFn) ([][]byte, error) {
+	return nil, nil
+}
+
+func (Empty) Insert(key []byte, value []byte, resolver NodeResolverFn, depth int) (BinaryNode, error) {
+	n := &StemNode{
+		Stem:   common.CopyBytes(key[:StemSize]),
+		Values: make([][]byte, NodeWidth),
+		depth:  depth,
+	}
+	n.Values[key[StemSize]] = value
+	n.markDirty()
+	return n, nil
+}
+
+func (Empty) Hash() common.Hash                           { return common.Hash{} }
+func (e Empty) Copy() BinaryNode                          { return e }
+func (Empty) collectNodes(path []byte, flush nodeFlushFn) {}
+
+// HashedNode is a placeholder of a subtree which is not yet resolved from the
+// database.
+type HashedNode common.Hash
+
+func (h HashedNode) Get(key []byte, resolver NodeResolverFn) ([]byte, error) {
+	return nil, errUnresolvedNode
+}
+
+func (h HashedNode) GetValuesAtStem(stem []byte, resolver NodeResolverFn) ([][]byte, error) {
+	return nil, errUnresolvedNode
+}
+
+func (h HashedNode) Insert(key []byte, value []byte, resolver NodeResolverFn, depth int) (BinaryNode, error) {
+	return nil, errUnresolvedNode
+}
+
+func (h HashedNode) Hash() common.Hash                           { return common.Hash(h) }
+func (h HashedNode) Copy() BinaryNode                            { return h }
+func (h HashedNode) collectNodes(path []byte, flush nodeFlushFn) {}
+
+// InternalNode is a node with two children, branching on the bit of the stem
+// at its depth.
+type InternalNode struct {
+	children [2]BinaryNode
+	depth    int
+
+	hash   common.Hash // Cached hash of the node, valid if hashed is set
+	hashed bool
+	dirty  bool // Flag whether the node has been modified since the last commit
+}
+
+func (n *InternalNode) markDirty() {
+	n.hashed, n.dirty = false, true
+}
+
+// resolveChild returns the child on the path of the given key, resolving it
+// from the database if it's not yet loaded.
+func (n *InternalNode) resolveChild(key []byte, resolver NodeResolverFn) (BinaryNode, error) {
+	bit := getBit(key, n.depth)
+	hn, ok := n.children[bit].(HashedNode)
+	if !ok {
+		return n.children[bit], nil
+	}
+	path := keyToPath(n.depth+1, key)
+	blob, err := resolver(path, common.Hash(hn))
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) == 0 {
+		return nil, fmt.Errorf("missing binary tree node %x at path %x", common.Hash(hn), path)
+	}
+	child, err := decodeNode(blob, n.depth+1, common.Hash(hn))
+	if err != nil {
+		return nil, err
+	}
+	n.children[bit] = child
+	return child, nil
+}
+
+func (n *InternalNode) Get(key []byte, resolver NodeResolverFn) ([]byte, error) {
+	values, err := n.GetValuesAtStem(key[:StemSize], resolver)
+	if err != nil || values == nil {
+		return nil, err
+	}
+	return values[key[StemSize]], nil
+}
+
+func (n *InternalNode) GetValuesAtStem(stem []byte, resolver NodeResolverFn) ([][]byte, error) {
+	child, err := n.resolveChild(stem, resolver)
+	if err != nil {
+		return nil, err
+	}
+	return child.GetValuesAtStem(stem, resolver)
+}
+
+func (n *InternalNode) Insert(key []byte, value []byte, resolver NodeResolverFn, depth int) (BinaryNode, error) {
+	child, err := n.resolveChild(key, resolver)
+	if err != nil {
+		return nil, err
+	}
+	child, err = child.Insert(key, value, resolver, n.depth+1)
+	if err != nil {
+		return nil, err
+	}
+	n.children[getBit(key, n.depth)] = child
+	n.markDirty()
+	return n, nil
+}
+
+func (n *InternalNode) Hash() common.Hash {
+	if !n.hashed {
+		n.hash = hashPair(n.children[0].Hash(), n.children[1].Hash())
+		n.hashed = true
+	}
+	return n.hash
+}
+
+func (n *InternalNode) Copy() BinaryNode {
+	cpy := *n
+	cpy.children = [2]BinaryNode{n.children[0].Copy(), n.children[1].Copy()}
+	return &cpy
+}
+
+func (n *InternalNode) collectNodes(path []byte, flush nodeFlushFn) {
+	if !n.dirty {
+		return
+	}
+	for i, child := range n.children {
+		child.collectNodes(append(common.CopyBytes(path), byte(i)), flush)
+	}
+	flush(path, n)
+	n.dirty = false
+}
+
+// StemNode holds the 256 leaf values sharing the same stem.
+type StemNode struct {
+	Stem   []byte   // The 31-byte stem shared by all the values
+	Values [][]byte // The leaf values, nil if absent
+	depth  int
+
+	hash   common.Hash // Cached hash of the node, valid if hashed is set
+	hashed bool
+	dirty  bool // Flag whether the node has been modified since the last commit
+}
+
+func (n *StemNode) markDirty() {
+	n.hashed, n.dirty = false, true
+}
+
+func (n *StemNode) Get(key []byte, resolver NodeResolverFn) ([]byte, error) {
+	if !bytes.Equal(n.Stem, key[:StemSize]) {
+		return nil, nil
+	}
+	return n.Values[key[StemSize]], nil
+}
+
+func (n *StemNode) GetValuesAtStem(stem []byte, resolver NodeResolverFn) ([][]byte, error) {
+	if !bytes.Equal(n.Stem, stem) {
+		return nil, nil
+	}
+	return n.Values, nil
+}
+
+func (n *StemNode) Insert(key []byte, value []byte, resolver NodeResolverFn, depth int) (BinaryNode, error) {
+	if bytes.Equal(n.Stem, key[:StemSize]) {
+		n.Values[key[StemSize]] = value
+		n.markDirty()
+		return n, nil
+	}
+	// The stems diverge, push the stem node one level down behind a new
+	// internal node and retry the insertion from there. The stem node is
+	// relocated, so it needs to be rewritten at its new path.
+	parent := &InternalNode{depth: n.depth}
+	parent.markDirty()
+
+	bit := getBit(n.Stem, n.depth)
+	parent.children[bit] = n
+	parent.children[1-bit] = Empty{}
+	n.depth++
+	n.markDirty()
+
+	return parent.Insert(key, value, resolver, depth)
+}
+
+func (n *StemNode) Hash() common.Hash {
+	if n.hashed {
+		return n.hash
+	}
+	var level [NodeWidth]common.Hash
+	for i, value := range n.Values {
+		if value != nil {
+			level[i] = sha256.Sum256(value)
+		}
+	}
+	for width := NodeWidth / 2; width > 0; width /= 2 {
+		for i := 0; i < width; i++ {
+			level[i] = hashPair(level[2*i], level[2*i+1])
+		}
+	}
+	h := sha256.New()
+	h.Write(n.Stem)
+	h.Write([]byte{0})
+	h.Write(level[0][:])
+	n.hash, n.hashed = common.BytesToHash(h.Sum(nil)), true
+	return n.hash
+}
+
+func (n *StemNode) Copy() BinaryNode {
+	cpy := *n
+	cpy.Stem = common.CopyBytes(n.Stem)
+	cpy.Values = make([][]byte, NodeWidth)
+	for i, value := range n.Values {
+		cpy.Values[i] = common.CopyBytes(value)
+	}
+	return &cpy
+}
+
+func (n *StemNode) collectNodes(path []byte, flush nodeFlushFn) {
+	if !n.dirty {
+		return
+	}
+	flush(path, n)
+	n.dirty = false
+}
+
+// SerializeNode encodes the node into its database representation. Only the
+// internal and stem nodes can be serialized.
+func SerializeNode(node BinaryNode) []byte {
+	switch n := node.(type) {
+	case *InternalNode:
+		blob := make([]byte, 1+2*common.HashLength)
+		blob[0] = nodeTypeInternal
+		left, right := n.children[0].Hash(), n.children[1].Hash()
+		copy(blob[1:], left[:])
+		copy(blob[1+common.HashLength:], right[:])
+		return blob
+	case *StemNode:
+		var bitmap [NodeWidth / 8]byte
+		blob := make([]byte, 1+StemSize+len(bitmap), 1+StemSize+len(bitmap)+NodeWidth*32)
+		blob[0] = nodeTypeStem
+		copy(blob[1:], n.Stem)
+		for i, value := range n.Values {
+			if value != nil {
+				bitmap[i/8] |= 1 << (7 - i%8)
+				blob = append(blob, value...)
+			}
+		}
+		copy(blob[1+StemSize:], bitmap[:])
+		return blob
+	default:
+		panic(fmt.Sprintf("unexpected node type %T", node))
+	}
+}
+
+// decodeNode deserializes the node resolved from the database, caching its
+// known hash to avoid rehashing the unmodified subtrees.
+func decodeNode(blob []byte, depth int, hash common.Hash) (BinaryNode, error) {
+	node, err := DeserializeNode(blob, depth)
+	if err != nil {
+		return nil, err
+	}
+	switch n := node.(type) {
+	case *InternalNode:
+		n.hash, n.hashed = hash, true
+	case *StemNode:
+		n.hash, n.hashed = hash, true
+	}
+	return node, nil
+}
+
+// DeserializeNode decodes the node at the given depth from its database
+// representation. The children of the internal nodes are left unresolved.
+func DeserializeNode(blob []byte, depth int) (BinaryNode, error) {
+	if len(blob) == 0 {
+		return Empty{}, nil
+	}
+	switch blob[0] {
+	case nodeTypeInternal:
+		if len(blob) != 1+2*common.HashLength {
+			return nil, errInvalidNode
+		}
+		n := &InternalNode{depth: depth}
+		for i := range n.children {
+			hash := common.BytesToHash(blob[1+i*common.HashLength : 1+(i+1)*common.HashLength])
+			if hash == (common.Hash{}) {
+				n.children[i] = Empty{}
+			} else {
+				n.children[i] = HashedNode(hash)
+			}
+		}
+		return n, nil
+	case nodeTypeStem:
+		const headerSize = 1 + StemSize + NodeWidth/8
+		if len(blob) < headerSize || (len(blob)-headerSize)%32 != 0 {
+			return nil, errInvalidNode
+		}
+		var (
+			bitmap = blob[1+StemSize : headerSize]
+			values = blob[headerSize:]
+			n      = &StemNode{
+				Stem:   common.CopyBytes(blob[1 : 1+StemSize]),
+				Values: make([][]byte, NodeWidth),
+				depth:  depth,
+			}
+		)
+		for i := 0; i < NodeWidth; i++ {
+			if bitmap[i/8]&(1<<(7-i%8)) == 0 {
+				continue
+			}
+			if len(values) < 32 {
+				return nil, errInvalidNode
+			}
+			n.Values[i] = common.CopyBytes(values[:32])
+			values = values[32:]
+		}
+		if len(values) != 0 {
+			return nil, errInvalidNode
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown type %d", errInvalidNode, blob[0])
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bintrie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Prove implements state.Trie, constructing a merkle proof for the 32-byte
+// key. The result contains all serialized nodes on the path to the value at
+// key, keyed by their hashes. The value itself is included in the last node,
+// namely the stem node, and can be retrieved by verifying the proof.
+//
+// If the tree does not contain a value for key, the returned proof contains
+// all nodes of the longest existing prefix of the key (at least the root node),
+// ending with the node that proves the absence of the key.
+func (t *BinaryTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
+	if len(key) != 32 {
+		return fmt.Errorf("invalid key length %d", len(key))
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	node := t.root
+	for {
+		switch n := node.(type) {
+		case Empty:
+			return nil
+		case *StemNode:
+			hash := n.Hash()
+			return proofDb.Put(hash[:], SerializeNode(n))
+		case *InternalNode:
+			hash := n.Hash()
+			if err := proofDb.Put(hash[:], SerializeNode(n)); err != nil {
+				return err
+			}
+			child, err := n.resolveChild(key, t.resolve)
+			if err != nil {
+				return err
+			}
+			node = child
+		default:
+			return fmt.Errorf("unexpected node type %T", node)
+		}
+	}
+}
+
+// VerifyProof checks merkle proofs. The given proof must contain the value for
+// key in a tree with the given root hash. VerifyProof returns an error if the
+// proof contains invalid nodes, and a nil value if the key is proven absent.
+func VerifyProof(rootHash common.Hash, key []byte, proofDb ethdb.KeyValueReader) (value []byte, err error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid key length %d", len(key))
+	}
+	want := rootHash
+	for depth := 0; ; depth++ {
+		if want == (common.Hash{}) {
+			return nil, nil
+		}
+		blob, _ := proofDb.Get(want[:])
+		if blob == nil {
+			return nil, fmt.Errorf("proof node %d (hash %064x) missing", depth, want)
+		}
+		node, err := DeserializeNode(blob, depth)
+		if err != nil {
+			return nil, fmt.Errorf("bad proof node %d: %v", depth, err)
+		}
+		if node.Hash() != want {
+			return nil, fmt.Errorf("proof node %d hash mismatch", depth)
+		}
+		switch n := node.(type) {
+		case *StemNode:
+			if !bytes.Equal(n.Stem, key[:StemSize]) {
+				return nil, nil
+			}
+			return n.Values[key[StemSize]], nil
+		case *InternalNode:
+			if depth >= StemSize*8 {
+				return nil, fmt.Errorf("proof node %d too deep", depth)
+			}
+			want = n.children[getBit(key, depth)].Hash()
+		default:
+			return nil, fmt.Errorf("unexpected proof node %d type %T", depth, node)
+		}
+	}
+}
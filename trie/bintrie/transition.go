@@ -0,0 +1,259 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bintrie
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/triedb/database"
+	"github.com/holiman/uint256"
+)
+
+// TransitionTrie is the overlay tree used while the state is being converted
+// from the Merkle-Patricia trie into the binary tree. All the writes go to the
+// binary overlay, and the reads fall back to the frozen base MPT if the value
+// hasn't been written into the overlay yet.
+//
+// The account deletions are recorded in the overlay as well, so a deleted
+// account won't resurface from the base.
+type TransitionTrie struct {
+	overlay *BinaryTrie
+	base    *trie.StateTrie
+	db      database.NodeDatabase // Database of the base MPT
+
+	storage map[common.Address]*trie.StateTrie // Base storage tries, resolved lazily
+	lock    sync.Mutex                         // Lock protecting the base tries
+}
+
+// NewTransitionTrie constructs a transition tree on top of the given base MPT
+// and binary overlay. The node database must be the one of the base MPT, which
+// is used for resolving the base storage tries.
+func NewTransitionTrie(base *trie.StateTrie, overlay *BinaryTrie, db database.NodeDatabase) *TransitionTrie {
+	return &TransitionTrie{
+		overlay: overlay,
+		base:    base,
+		db:      db,
+		storage: make(map[common.Address]*trie.StateTrie),
+	}
+}
+
+// Base returns the frozen base MPT.
+func (t *TransitionTrie) Base() *trie.StateTrie {
+	return t.base
+}
+
+// Overlay returns the binary overlay tree.
+func (t *TransitionTrie) Overlay() *BinaryTrie {
+	return t.overlay
+}
+
+// GetKey returns the preimage of a hashed key that was previously used to
+// store a value in the base MPT, or the key itself if it's a binary tree key.
+func (t *TransitionTrie) GetKey(key []byte) []byte {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if preimage := t.base.GetKey(key); preimage != nil {
+		return preimage
+	}
+	return key
+}
+
+// GetAccount implements state.Trie, retrieving the account from the overlay,
+// or from the base MPT if it's not present in the overlay.
+func (t *TransitionTrie) GetAccount(addr common.Address) (*types.StateAccount, error) {
+	acc, found, err := t.overlay.getAccount(addr)
+	if err != nil || found {
+		return acc, err
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.base.GetAccount(addr)
+}
+
+// GetStorage implements state.Trie, retrieving the storage slot from the
+// overlay, or from the base MPT if it's not present in the overlay.
+func (t *TransitionTrie) GetStorage(addr common.Address, key []byte) ([]byte, error) {
+	val, err := t.overlay.getStorage(addr, key)
+	if err != nil {
+		return nil, err
+	}
+	if val != nil {
+		return common.TrimLeftZeroes(val), nil
+	}
+	// Don't resolve the base storage of the accounts deleted in the overlay
+	acc, found, err := t.overlay.getAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+	if found && acc == nil {
+		return nil, nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	st, err := t.baseStorage(addr)
+	if err != nil || st == nil {
+		return nil, err
+	}
+	return st.GetStorage(addr, key)
+}
+
+// baseStorage returns the storage trie of the account in the base MPT, nil if
+// the account has no storage. It assumes the lock is held.
+func (t *TransitionTrie) baseStorage(addr common.Address) (*trie.StateTrie, error) {
+	if st, ok := t.storage[addr]; ok {
+		return st, nil
+	}
+	acc, err := t.base.GetAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+	var st *trie.StateTrie
+	if acc != nil && acc.Root != types.EmptyRootHash {
+		id := trie.StorageTrieID(t.base.Hash(), crypto.Keccak256Hash(addr.Bytes()), acc.Root)
+		st, err = trie.NewStateTrie(id, t.db)
+		if err != nil {
+			return nil, err
+		}
+	}
+	t.storage[addr] = st
+	return st, nil
+}
+
+// UpdateAccount implements state.Trie, writing the account into the overlay.
+func (t *TransitionTrie) UpdateAccount(addr common.Address, acc *types.StateAccount, codeLen int) error {
+	return t.overlay.UpdateAccount(addr, acc, codeLen)
+}
+
+// UpdateStorage implements state.Trie, writing the storage slot into the overlay.
+func (t *TransitionTrie) UpdateStorage(addr common.Address, key, value []byte) error {
+	return t.overlay.UpdateStorage(addr, key, value)
+}
+
+// DeleteAccount implements state.Trie, marking the account as deleted in the
+// overlay.
+func (t *TransitionTrie) DeleteAccount(addr common.Address) error {
+	if err := t.overlay.DeleteAccount(addr); err != nil {
+		return err
+	}
+	// The accounts only present in the base are unknown to the overlay, leave
+	// a deleted header behind so they don't resurface from the base.
+	if _, found, err := t.overlay.getAccount(addr); err != nil || found {
+		return err
+	}
+	deleted := &types.StateAccount{
+		Balance:  new(uint256.Int),
+		CodeHash: make([]byte, common.HashLength),
+	}
+	return t.overlay.UpdateAccount(addr, deleted, 0)
+}
+
+// DeleteStorage implements state.Trie, marking the storage slot as deleted in
+// the overlay.
+func (t *TransitionTrie) DeleteStorage(addr common.Address, key []byte) error {
+	return t.overlay.DeleteStorage(addr, key)
+}
+
+// UpdateContractCode implements state.Trie, writing the contract code into
+// the overlay.
+func (t *TransitionTrie) UpdateContractCode(addr common.Address, codeHash common.Hash, code []byte) error {
+	return t.overlay.UpdateContractCode(addr, codeHash, code)
+}
+
+// Hash returns the root hash of the overlay, which is the state root during
+// the transition.
+func (t *TransitionTrie) Hash() common.Hash {
+	return t.overlay.Hash()
+}
+
+// Commit collects the dirty nodes of the overlay, the base is read-only.
+func (t *TransitionTrie) Commit(collectLeaf bool) (common.Hash, *trienode.NodeSet) {
+	return t.overlay.Commit(collectLeaf)
+}
+
+// Witness returns a set containing all nodes that have been accessed, in both
+// the overlay and the base MPT.
+func (t *TransitionTrie) Witness() map[string]struct{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	witness := make(map[string]struct{})
+	for blob := range t.overlay.Witness() {
+		witness[blob] = struct{}{}
+	}
+	for blob := range t.base.Witness() {
+		witness[blob] = struct{}{}
+	}
+	for _, st := range t.storage {
+		if st == nil {
+			continue
+		}
+		for blob := range st.Witness() {
+			witness[blob] = struct{}{}
+		}
+	}
+	if len(witness) == 0 {
+		return nil
+	}
+	return witness
+}
+
+// NodeIterator implements state.Trie, returning an iterator over the nodes of
+// the overlay.
+func (t *TransitionTrie) NodeIterator(startKey []byte) (trie.NodeIterator, error) {
+	return t.overlay.NodeIterator(startKey)
+}
+
+// Prove implements state.Trie, constructing a proof of the key against the
+// overlay.
+func (t *TransitionTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
+	return t.overlay.Prove(key, proofDb)
+}
+
+// IsVerkle indicates if the trie is a unified tree, which is true for the
+// transition tree.
+func (t *TransitionTrie) IsVerkle() bool {
+	return true
+}
+
+// Copy returns a deep-copied transition tree.
+func (t *TransitionTrie) Copy() *TransitionTrie {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	storage := make(map[common.Address]*trie.StateTrie, len(t.storage))
+	for addr, st := range t.storage {
+		if st != nil {
+			st = st.Copy()
+		}
+		storage[addr] = st
+	}
+	return &TransitionTrie{
+		overlay: t.overlay.Copy(),
+		base:    t.base.Copy(),
+		db:      t.db,
+		storage: storage,
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bintrie
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/triedb/database"
+	"github.com/holiman/uint256"
+)
+
+// BinaryTrie is a wrapper around the binary tree of EIP-7864 that implements
+// the state.Trie interface, holding the accounts, storage slots and contract
+// code of the entire state in a single unified tree.
+//
+// BinaryTrie is safe for concurrent use, as the storage tries of the state
+// objects all alias the same tree and may be committed concurrently.
+type BinaryTrie struct {
+	root    BinaryNode
+	reader  database.NodeReader // Node reader of the state, nil if the tree is empty
+	witness map[string]struct{} // Set of resolved node blobs
+	lock    sync.Mutex          // Lock protecting the tree
+}
+
+// NewBinaryTrie constructs a binary tree based on the specified root hash.
+func NewBinaryTrie(root common.Hash, db database.NodeDatabase) (*BinaryTrie, error) {
+	t := &BinaryTrie{
+		root:    Empty{},
+		witness: make(map[string]struct{}),
+	}
+	if root == (common.Hash{}) || root == types.EmptyRootHash {
+		return t, nil
+	}
+	reader, err := db.NodeReader(root)
+	if err != nil {
+		return nil, &trie.MissingNodeError{NodeHash: root, Path: nil}
+	}
+	t.reader = reader
+
+	blob, err := t.resolve(nil, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) == 0 {
+		return nil, &trie.MissingNodeError{NodeHash: root, Path: nil}
+	}
+	t.root, err = decodeNode(blob, 0, root)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// resolve implements NodeResolverFn, loading the node from the database and
+// tracking it in the witness.
+func (t *BinaryTrie) resolve(path []byte, hash common.Hash) ([]byte, error) {
+	if t.reader == nil {
+		return nil, &trie.MissingNodeError{NodeHash: hash, Path: path}
+	}
+	blob, err := t.reader.Node(common.Hash{}, path, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) != 0 {
+		t.witness[string(blob)] = struct{}{}
+	}
+	return blob, nil
+}
+
+// GetKey returns the sha3 preimage of a hashed key that was previously used
+// to store a value. The binary tree doesn't hash the keys, so it's returned
+// as is.
+func (t *BinaryTrie) GetKey(key []byte) []byte {
+	return key
+}
+
+// GetAccount implements state.Trie, retrieving the account with the specified
+// account address. If the specified account is not in the tree, nil will be
+// returned. If the tree is corrupted, an error will be returned.
+func (t *BinaryTrie) GetAccount(addr common.Address) (*types.StateAccount, error) {
+	acc, _, err := t.getAccount(addr)
+	return acc, err
+}
+
+// getAccount retrieves the account with the specified address, additionally
+// reporting whether the account header is present in the tree at all, even if
+// the account is deleted.
+func (t *BinaryTrie) getAccount(addr common.Address) (*types.StateAccount, bool, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	values, err := t.root.GetValuesAtStem(BasicDataKey(addr)[:StemSize], t.resolve)
+	if err != nil {
+		return nil, false, fmt.Errorf("GetAccount (%x) error: %v", addr, err)
+	}
+	if values == nil {
+		return nil, false, nil
+	}
+	basicData, codeHash := values[BasicDataLeafKey], values[CodeHashLeafKey]
+	if basicData == nil || codeHash == nil {
+		return nil, false, nil
+	}
+	// Accounts are never removed from the tree, the deleted ones are marked
+	// by a zero code hash instead.
+	if common.BytesToHash(codeHash) == (common.Hash{}) {
+		return nil, true, nil
+	}
+	return &types.StateAccount{
+		Nonce:    binary.BigEndian.Uint64(basicData[BasicDataNonceOffset:]),
+		Balance:  new(uint256.Int).SetBytes(basicData[BasicDataBalanceOffset : BasicDataBalanceOffset+16]),
+		Root:     types.EmptyRootHash, // storage is part of the unified tree
+		CodeHash: common.CopyBytes(codeHash),
+	}, true, nil
+}
+
+// GetStorage implements state.Trie, retrieving the storage slot with the specified
+// account address and storage key. If the specified slot is not in the tree, nil
+// will be returned. If the tree is corrupted, an error will be returned.
+func (t *BinaryTrie) GetStorage(addr common.Address, key []byte) ([]byte, error) {
+	val, err := t.getStorage(addr, key)
+	if err != nil {
+		return nil, err
+	}
+	return common.TrimLeftZeroes(val), nil
+}
+
+// getStorage retrieves the raw 32-byte storage slot, nil if it's not present
+// in the tree.
+func (t *BinaryTrie) getStorage(addr common.Address, key []byte) ([]byte, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.root.Get(StorageSlotKey(addr, key), t.resolve)
+}
+
+// insert writes the value into the tree, assuming the lock is held.
+func (t *BinaryTrie) insert(key []byte, value []byte) error {
+	if len(value) != 32 {
+		return errInvalidValue
+	}
+	root, err := t.root.Insert(key, value, t.resolve, 0)
+	if err != nil {
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+// UpdateAccount implements state.Trie, writing the provided account into the tree.
+// If the tree is corrupted, an error will be returned.
+func (t *BinaryTrie) UpdateAccount(addr common.Address, acc *types.StateAccount, codeLen int) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Code size is encoded in BasicData as a 3-byte big-endian integer. Spare bytes are present
+	// before the code size to support bigger integers in the future. PutUint32(...) requires
+	// 4 bytes, so we need to shift the offset 1 byte to the left.
+	var basicData [32]byte
+	binary.BigEndian.PutUint32(basicData[BasicDataCodeSizeOffset-1:], uint32(codeLen))
+	binary.BigEndian.PutUint64(basicData[BasicDataNonceOffset:], acc.Nonce)
+	if acc.Balance.ByteLen() > 16 {
+		return fmt.Errorf("UpdateAccount (%x) error: balance too large", addr)
+	}
+	acc.Balance.WriteToSlice(basicData[BasicDataBalanceOffset : BasicDataBalanceOffset+16])
+
+	if err := t.insert(BasicDataKey(addr), basicData[:]); err != nil {
+		return fmt.Errorf("UpdateAccount (%x) error: %v", addr, err)
+	}
+	if err := t.insert(CodeHashKey(addr), common.CopyBytes(acc.CodeHash)); err != nil {
+		return fmt.Errorf("UpdateAccount (%x) error: %v", addr, err)
+	}
+	return nil
+}
+
+// UpdateStorage implements state.Trie, writing the provided storage slot into
+// the tree. If the tree is corrupted, an error will be returned.
+func (t *BinaryTrie) UpdateStorage(addr common.Address, key, value []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Left padding the slot value to 32 bytes.
+	var v [32]byte
+	if len(value) >= 32 {
+		copy(v[:], value[:32])
+	} else {
+		copy(v[32-len(value):], value)
+	}
+	return t.insert(StorageSlotKey(addr, key), v[:])
+}
+
+// DeleteAccount implements state.Trie. The tree has no structural deletion, so
+// the leaves of the account are overwritten with zeros instead: the account
+// header, which marks the account as non-existent, the header storage slots
+// and all the code chunks.
+//
+// The main storage slots are spread across the tree and can't be enumerated,
+// so they are left untouched. The binary tree requires EIP-6780 to be active
+// since genesis, under which only the accounts created within the same
+// transaction can be destructed, whose storage never reaches the tree.
+func (t *BinaryTrie) DeleteAccount(addr common.Address) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	stem := BasicDataKey(addr)[:StemSize]
+	values, err := t.root.GetValuesAtStem(stem, t.resolve)
+	if err != nil {
+		return fmt.Errorf("DeleteAccount (%x) error: %v", addr, err)
+	}
+	if values == nil {
+		return nil
+	}
+	var chunks uint64
+	if basicData := values[BasicDataLeafKey]; basicData != nil {
+		size := binary.BigEndian.Uint32(basicData[BasicDataCodeSizeOffset-1:]) & 0xffffff
+		chunks = (uint64(size) + 30) / 31
+	}
+	// Clear all the leaves in the account stem, covering the header storage
+	// slots and the first code chunks.
+	var (
+		zero = make([]byte, 32)
+		key  = make([]byte, StemSize+1)
+	)
+	copy(key, stem)
+	for i, value := range values {
+		if value == nil && i != BasicDataLeafKey && i != CodeHashLeafKey {
+			continue
+		}
+		key[StemSize] = byte(i)
+		if err := t.insert(key, zero); err != nil {
+			return fmt.Errorf("DeleteAccount (%x) error: %v", addr, err)
+		}
+	}
+	// Clear the code chunks living outside of the account stem
+	for chunk := uint64(NodeWidth - codeOffset.Uint64()); chunk < chunks; chunk++ {
+		if err := t.insert(CodeChunkKey(addr, chunk), zero); err != nil {
+			return fmt.Errorf("DeleteAccount (%x) error: %v", addr, err)
+		}
+	}
+	return nil
+}
+
+// DeleteStorage implements state.Trie, deleting the specified storage slot by
+// overwriting it with zero.
+func (t *BinaryTrie) DeleteStorage(addr common.Address, key []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var zero [32]byte
+	return t.insert(StorageSlotKey(addr, key), zero[:])
+}
+
+// UpdateContractCode implements state.Trie, writing the provided contract code
+// into the tree in 31-byte chunks.
+func (t *BinaryTrie) UpdateContractCode(addr common.Address, codeHash common.Hash, code []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	chunks := trie.ChunkifyCode(code)
+	for i, chunk := 0, uint64(0); i < len(chunks); i, chunk = i+32, chunk+1 {
+		if err := t.insert(CodeChunkKey(addr, chunk), chunks[i:i+32]); err != nil {
+			return fmt.Errorf("UpdateContractCode (addr=%x) error: %w", addr[:], err)
+		}
+	}
+	return nil
+}
+
+// Hash returns the root hash of the tree. It does not write to the database and
+// can be used even if the tree doesn't have one.
+func (t *BinaryTrie) Hash() common.Hash {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.root.Hash()
+}
+
+// Commit collects all the dirty nodes of the tree and marks them as clean. The
+// returned node set is empty if the tree was already committed.
+func (t *BinaryTrie) Commit(_ bool) (common.Hash, *trienode.NodeSet) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	nodes := trienode.NewNodeSet(common.Hash{})
+	t.root.collectNodes(nil, func(path []byte, n BinaryNode) {
+		nodes.AddNode(path, trienode.New(n.Hash(), SerializeNode(n)))
+	})
+	return t.root.Hash(), nodes
+}
+
+// Witness returns a set containing all tree nodes that have been accessed.
+func (t *BinaryTrie) Witness() map[string]struct{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.witness) == 0 {
+		return nil
+	}
+	witness := make(map[string]struct{}, len(t.witness))
+	for blob := range t.witness {
+		witness[blob] = struct{}{}
+	}
+	return witness
+}
+
+// Copy returns a deep-copied binary tree.
+func (t *BinaryTrie) Copy() *BinaryTrie {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	witness := make(map[string]struct{}, len(t.witness))
+	for blob := range t.witness {
+		witness[blob] = struct{}{}
+	}
+	return &BinaryTrie{
+		root:    t.root.Copy(),
+		reader:  t.reader,
+		witness: witness,
+	}
+}
+
+// IsVerkle indicates if the trie is a unified tree holding the entire state,
+// which is true for the binary tree.
+func (t *BinaryTrie) IsVerkle() bool {
+	return true
+}
@@ -0,0 +1,441 @@
+// Copyright 2025 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bintrie
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/triedb/database"
+	"github.com/holiman/uint256"
+)
+
+// testDatabase is an in-memory node database keyed by owner and path, holding
+// only the latest version of each node.
+type testDatabase struct {
+	nodes map[string][]byte
+}
+
+func newTestDatabase() *testDatabase {
+	return &testDatabase{nodes: make(map[string][]byte)}
+}
+
+func (db *testDatabase) NodeReader(root common.Hash) (database.NodeReader, error) {
+	return db, nil
+}
+
+func (db *testDatabase) Node(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	return db.nodes[string(owner.Bytes())+string(path)], nil
+}
+
+func (db *testDatabase) commit(set *trienode.NodeSet) {
+	if set == nil {
+		return
+	}
+	set.ForEachWithOrder(func(path string, n *trienode.Node) {
+		db.nodes[string(set.Owner.Bytes())+path] = n.Blob
+	})
+}
+
+func randomKey(rng *rand.Rand) []byte {
+	key := make([]byte, 32)
+	rng.Read(key)
+	return key
+}
+
+func TestKeyEncoding(t *testing.T) {
+	addr := common.HexToAddress("0x1234")
+
+	header := BasicDataKey(addr)[:StemSize]
+	if !bytes.Equal(CodeHashKey(addr)[:StemSize], header) {
+		t.Fatal("code hash not in the account header stem")
+	}
+	if key := StorageSlotKey(addr, []byte{63}); !bytes.Equal(key[:StemSize], header) || key[StemSize] != 127 {
+		t.Fatalf("header storage slot misplaced: %x", key)
+	}
+	if key := StorageSlotKey(addr, []byte{64}); bytes.Equal(key[:StemSize], header) || key[StemSize] != 64 {
+		t.Fatalf("main storage slot misplaced: %x", key)
+	}
+	if key := CodeChunkKey(addr, 0); !bytes.Equal(key[:StemSize], header) || key[StemSize] != 128 {
+		t.Fatalf("first code chunk misplaced: %x", key)
+	}
+	if key := CodeChunkKey(addr, 128); bytes.Equal(key[:StemSize], header) || key[StemSize] != 0 {
+		t.Fatalf("code chunk 128 misplaced: %x", key)
+	}
+	// Adjacent main storage slots share the stem
+	a, b := StorageSlotKey(addr, []byte{1, 0}), StorageSlotKey(addr, []byte{1, 1})
+	if !bytes.Equal(a[:StemSize], b[:StemSize]) || a[StemSize] != 0 || b[StemSize] != 1 {
+		t.Fatalf("adjacent storage slots not grouped: %x %x", a, b)
+	}
+	// Different accounts must not share the stem
+	if bytes.Equal(BasicDataKey(common.HexToAddress("0x1235"))[:StemSize], header) {
+		t.Fatal("different accounts share the header stem")
+	}
+}
+
+func TestInsertOrderIndependence(t *testing.T) {
+	var (
+		rng  = rand.New(rand.NewSource(1))
+		keys [][]byte
+		vals [][]byte
+	)
+	for i := 0; i < 500; i++ {
+		key := randomKey(rng)
+		keys = append(keys, key)
+		vals = append(vals, randomKey(rng))
+
+		// Share the stems between some of the keys
+		if i%5 == 0 {
+			sibling := common.CopyBytes(key)
+			sibling[StemSize]++
+			keys = append(keys, sibling)
+			vals = append(vals, randomKey(rng))
+		}
+	}
+	build := func(order []int) *BinaryTrie {
+		tr, _ := NewBinaryTrie(common.Hash{}, newTestDatabase())
+		for _, i := range order {
+			if err := tr.insert(keys[i], vals[i]); err != nil {
+				t.Fatalf("Failed to insert: %v", err)
+			}
+		}
+		return tr
+	}
+	order := rng.Perm(len(keys))
+	tr1, tr2 := build(order), build(rng.Perm(len(keys)))
+	if tr1.Hash() != tr2.Hash() {
+		t.Fatalf("Root mismatch: %x != %x", tr1.Hash(), tr2.Hash())
+	}
+	for i, key := range keys {
+		got, err := tr1.root.Get(key, tr1.resolve)
+		if err != nil {
+			t.Fatalf("Failed to get: %v", err)
+		}
+		if !bytes.Equal(got, vals[i]) {
+			t.Fatalf("Value mismatch for %x: have %x, want %x", key, got, vals[i])
+		}
+	}
+	if got, _ := tr1.root.Get(randomKey(rng), tr1.resolve); got != nil {
+		t.Fatal("Unexpected value for absent key")
+	}
+}
+
+func TestCommitAndReload(t *testing.T) {
+	var (
+		db   = newTestDatabase()
+		addr = common.HexToAddress("0xdeadbeef")
+		code = []byte{0x60, 0x01, 0x60, 0x02, 0x01, 0x00}
+		acc  = &types.StateAccount{
+			Nonce:    5,
+			Balance:  uint256.NewInt(1000),
+			CodeHash: crypto.Keccak256(code),
+		}
+	)
+	tr, _ := NewBinaryTrie(common.Hash{}, db)
+	if err := tr.UpdateAccount(addr, acc, len(code)); err != nil {
+		t.Fatalf("Failed to update account: %v", err)
+	}
+	if err := tr.UpdateContractCode(addr, common.BytesToHash(acc.CodeHash), code); err != nil {
+		t.Fatalf("Failed to update code: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		slot := common.BigToHash(uint256.NewInt(uint64(i * 7)).ToBig())
+		if err := tr.UpdateStorage(addr, slot[:], []byte{byte(i + 1)}); err != nil {
+			t.Fatalf("Failed to update storage: %v", err)
+		}
+	}
+	root, set := tr.Commit(false)
+	db.commit(set)
+	if root != tr.Hash() {
+		t.Fatalf("Commit root mismatch")
+	}
+	if _, set := tr.Commit(false); len(set.Nodes) != 0 {
+		t.Fatalf("Unexpected nodes in second commit: %d", len(set.Nodes))
+	}
+	tr, err := NewBinaryTrie(root, db)
+	if err != nil {
+		t.Fatalf("Failed to reload tree: %v", err)
+	}
+	got, err := tr.GetAccount(addr)
+	if err != nil {
+		t.Fatalf("Failed to get account: %v", err)
+	}
+	if got.Nonce != acc.Nonce || !got.Balance.Eq(acc.Balance) || !bytes.Equal(got.CodeHash, acc.CodeHash) {
+		t.Fatalf("Account mismatch: have %v, want %v", got, acc)
+	}
+	for i := 0; i < 100; i++ {
+		slot := common.BigToHash(uint256.NewInt(uint64(i * 7)).ToBig())
+		val, err := tr.GetStorage(addr, slot[:])
+		if err != nil {
+			t.Fatalf("Failed to get storage: %v", err)
+		}
+		if !bytes.Equal(val, []byte{byte(i + 1)}) {
+			t.Fatalf("Storage mismatch for slot %d: %x", i, val)
+		}
+	}
+	if len(tr.Witness()) == 0 {
+		t.Fatal("Resolved nodes are not tracked in the witness")
+	}
+	// Modify a single slot, only the nodes on its path should be committed
+	if err := tr.DeleteStorage(addr, common.Hash{}.Bytes()); err != nil {
+		t.Fatalf("Failed to delete storage: %v", err)
+	}
+	root2, set := tr.Commit(false)
+	if root2 == root {
+		t.Fatal("Root not changed after deletion")
+	}
+	if len(set.Nodes) >= len(db.nodes) {
+		t.Fatalf("Too many nodes committed: %d of %d", len(set.Nodes), len(db.nodes))
+	}
+	db.commit(set)
+	tr, err = NewBinaryTrie(root2, db)
+	if err != nil {
+		t.Fatalf("Failed to reload tree: %v", err)
+	}
+	if val, _ := tr.GetStorage(addr, common.Hash{}.Bytes()); len(val) != 0 {
+		t.Fatalf("Deleted slot still present: %x", val)
+	}
+	// Delete the account, it should be reported as absent
+	if err := tr.DeleteAccount(addr); err != nil {
+		t.Fatalf("Failed to delete account: %v", err)
+	}
+	if got, _ := tr.GetAccount(addr); got != nil {
+		t.Fatalf("Deleted account still present: %v", got)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	var (
+		addr = common.HexToAddress("0xdeadbeef")
+		code = bytes.Repeat([]byte{0x5b}, 31*200) // spans beyond the account stem
+		acc  = &types.StateAccount{
+			Nonce:    1,
+			Balance:  uint256.NewInt(1),
+			CodeHash: crypto.Keccak256(code),
+		}
+		header = common.BigToHash(uint256.NewInt(3).ToBig())
+	)
+	tr, _ := NewBinaryTrie(common.Hash{}, newTestDatabase())
+	tr.UpdateAccount(addr, acc, len(code))
+	tr.UpdateContractCode(addr, common.BytesToHash(acc.CodeHash), code)
+	tr.UpdateStorage(addr, header[:], []byte{0x1})
+
+	if err := tr.DeleteAccount(addr); err != nil {
+		t.Fatalf("Failed to delete account: %v", err)
+	}
+	if got, _ := tr.GetAccount(addr); got != nil {
+		t.Fatalf("Deleted account still present: %v", got)
+	}
+	if val, _ := tr.GetStorage(addr, header[:]); len(val) != 0 {
+		t.Fatalf("Header storage slot not cleared: %x", val)
+	}
+	for _, chunk := range []uint64{0, 127, 128, 199} {
+		val, _ := tr.root.Get(CodeChunkKey(addr, chunk), nil)
+		if !bytes.Equal(val, make([]byte, 32)) {
+			t.Fatalf("Code chunk %d not cleared: %x", chunk, val)
+		}
+	}
+}
+
+func TestProof(t *testing.T) {
+	var (
+		rng  = rand.New(rand.NewSource(2))
+		db   = newTestDatabase()
+		keys [][]byte
+	)
+	tr, _ := NewBinaryTrie(common.Hash{}, db)
+	for i := 0; i < 200; i++ {
+		key := randomKey(rng)
+		keys = append(keys, key)
+		tr.insert(key, key)
+	}
+	root, set := tr.Commit(false)
+	db.commit(set)
+	tr, _ = NewBinaryTrie(root, db)
+
+	for _, key := range keys {
+		proof := rawdb.NewMemoryDatabase()
+		if err := tr.Prove(key, proof); err != nil {
+			t.Fatalf("Failed to prove: %v", err)
+		}
+		val, err := VerifyProof(root, key, proof)
+		if err != nil {
+			t.Fatalf("Failed to verify proof: %v", err)
+		}
+		if !bytes.Equal(val, key) {
+			t.Fatalf("Proven value mismatch: have %x, want %x", val, key)
+		}
+		// Tamper the proof, the verification must fail
+		it := proof.NewIterator(nil, nil)
+		it.Next()
+		bad := common.CopyBytes(it.Value())
+		bad[len(bad)-1] ^= 0xff
+		proof.Put(it.Key(), bad)
+		it.Release()
+		if _, err := VerifyProof(root, key, proof); err == nil {
+			t.Fatal("Tampered proof is accepted")
+		}
+	}
+	// Prove the absence of a key
+	absent := randomKey(rng)
+	proof := rawdb.NewMemoryDatabase()
+	if err := tr.Prove(absent, proof); err != nil {
+		t.Fatalf("Failed to prove absence: %v", err)
+	}
+	val, err := VerifyProof(root, absent, proof)
+	if err != nil || val != nil {
+		t.Fatalf("Failed to verify absence: %x %v", val, err)
+	}
+}
+
+func TestNodeIterator(t *testing.T) {
+	var (
+		rng  = rand.New(rand.NewSource(3))
+		db   = newTestDatabase()
+		keys [][]byte
+	)
+	tr, _ := NewBinaryTrie(common.Hash{}, db)
+	for i := 0; i < 300; i++ {
+		key := randomKey(rng)
+		keys = append(keys, key)
+		tr.insert(key, key)
+	}
+	root, set := tr.Commit(false)
+	db.commit(set)
+	tr, _ = NewBinaryTrie(root, db)
+
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	collect := func(start []byte) [][]byte {
+		it, _ := tr.NodeIterator(start)
+		var (
+			leaves [][]byte
+			nodes  int
+		)
+		for it.Next(true) {
+			if it.Leaf() {
+				if !bytes.Equal(it.LeafKey(), it.LeafBlob()) {
+					t.Fatalf("Leaf mismatch at %x", it.LeafKey())
+				}
+				leaves = append(leaves, it.LeafKey())
+				continue
+			}
+			if start == nil {
+				if blob, _ := db.Node(common.Hash{}, it.Path(), it.Hash()); !bytes.Equal(blob, it.NodeBlob()) {
+					t.Fatalf("Node mismatch at %x", it.Path())
+				}
+			}
+			nodes++
+		}
+		if it.Error() != nil {
+			t.Fatalf("Iteration failed: %v", it.Error())
+		}
+		if start == nil && nodes != len(db.nodes) {
+			t.Fatalf("Node count mismatch: have %d, want %d", nodes, len(db.nodes))
+		}
+		return leaves
+	}
+	leaves := collect(nil)
+	if len(leaves) != len(keys) {
+		t.Fatalf("Leaf count mismatch: have %d, want %d", len(leaves), len(keys))
+	}
+	for i := range keys {
+		if !bytes.Equal(leaves[i], keys[i]) {
+			t.Fatalf("Leaf %d out of order", i)
+		}
+	}
+	leaves = collect(keys[100])
+	if len(leaves) != len(keys)-100 || !bytes.Equal(leaves[0], keys[100]) {
+		t.Fatalf("Seek mismatch: have %d leaves", len(leaves))
+	}
+}
+
+func TestTransitionTrie(t *testing.T) {
+	var (
+		db      = newTestDatabase()
+		addr1   = common.HexToAddress("0x01")
+		addr2   = common.HexToAddress("0x02")
+		slot    = common.HexToHash("0xaa")
+		account = func(nonce uint64, root common.Hash) *types.StateAccount {
+			return &types.StateAccount{Nonce: nonce, Balance: uint256.NewInt(1), Root: root, CodeHash: types.EmptyCodeHash[:]}
+		}
+	)
+	// Construct the base MPT with two accounts, one of them with storage
+	st, _ := trie.NewStateTrie(trie.StorageTrieID(types.EmptyRootHash, crypto.Keccak256Hash(addr1[:]), types.EmptyRootHash), db)
+	st.UpdateStorage(addr1, slot[:], []byte{0x11})
+	stRoot, set := st.Commit(false)
+	db.commit(set)
+
+	base, _ := trie.NewStateTrie(trie.StateTrieID(types.EmptyRootHash), db)
+	base.UpdateAccount(addr1, account(1, stRoot), 0)
+	base.UpdateAccount(addr2, account(2, types.EmptyRootHash), 0)
+	baseRoot, set := base.Commit(false)
+	db.commit(set)
+
+	base, _ = trie.NewStateTrie(trie.StateTrieID(baseRoot), db)
+	overlay, _ := NewBinaryTrie(common.Hash{}, newTestDatabase())
+	tr := NewTransitionTrie(base, overlay, db)
+
+	// Reads fall back to the base
+	if acc, _ := tr.GetAccount(addr1); acc == nil || acc.Nonce != 1 {
+		t.Fatalf("Failed to read base account: %v", acc)
+	}
+	if val, _ := tr.GetStorage(addr1, slot[:]); !bytes.Equal(val, []byte{0x11}) {
+		t.Fatalf("Failed to read base storage: %x", val)
+	}
+	// Writes go to the overlay, and shadow the base
+	tr.UpdateAccount(addr1, account(3, types.EmptyRootHash), 0)
+	tr.UpdateStorage(addr1, slot[:], []byte{0x22})
+	tr.DeleteAccount(addr2)
+
+	if acc, _ := overlay.GetAccount(addr1); acc == nil || acc.Nonce != 3 {
+		t.Fatalf("Account not written into the overlay: %v", acc)
+	}
+	if val, _ := overlay.GetStorage(addr1, slot[:]); !bytes.Equal(val, []byte{0x22}) {
+		t.Fatalf("Storage not written into the overlay: %x", val)
+	}
+	if _, found, _ := overlay.getAccount(addr2); !found {
+		t.Fatal("Account deletion not recorded in the overlay")
+	}
+
+	if acc, _ := tr.GetAccount(addr1); acc == nil || acc.Nonce != 3 {
+		t.Fatalf("Failed to read overlay account: %v", acc)
+	}
+	if val, _ := tr.GetStorage(addr1, slot[:]); !bytes.Equal(val, []byte{0x22}) {
+		t.Fatalf("Failed to read overlay storage: %x", val)
+	}
+	if acc, _ := tr.GetAccount(addr2); acc != nil {
+		t.Fatalf("Deleted account resurfaced from the base: %v", acc)
+	}
+	if tr.Hash() != overlay.Hash() {
+		t.Fatal("Transition root differs from the overlay root")
+	}
+	// The base is left untouched
+	if base.Hash() != baseRoot {
+		t.Fatal("Base trie modified")
+	}
+	if acc, _ := base.GetAccount(addr2); acc == nil {
+		t.Fatal("Account deleted from the base trie")
+	}
+}
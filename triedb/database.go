@@ -33,6 +33,7 @@ import (
 type Config struct {
 	Preimages bool           // Flag whether the preimage of node key is recorded
 	IsVerkle  bool           // Flag whether the db is holding a verkle tree
+	IsBinary  bool           // Flag whether the db is holding a binary tree (EIP-7864)
 	HashDB    *hashdb.Config // Configs for hash-based scheme
 	PathDB    *pathdb.Config // Configs for experimental path-based scheme
 }
@@ -53,6 +54,14 @@ var VerkleDefaults = &Config{
 	PathDB:    pathdb.Defaults,
 }
 
+// BinaryDefaults represents a config for holding binary tree data using
+// path-based scheme with default settings.
+var BinaryDefaults = &Config{
+	Preimages: false,
+	IsBinary:  true,
+	PathDB:    pathdb.Defaults,
+}
+
 // backend defines the methods needed to access/update trie nodes in different
 // state scheme.
 type backend interface {
@@ -108,8 +117,19 @@ func NewDatabase(diskdb ethdb.Database, config *Config) *Database {
 	if config.HashDB != nil && config.PathDB != nil {
 		log.Crit("Both 'hash' and 'path' mode are configured")
 	}
+	if config.IsBinary && config.PathDB == nil {
+		log.Crit("Binary tree is only supported in 'path' mode")
+	}
 	if config.PathDB != nil {
-		db.backend = pathdb.New(diskdb, config.PathDB, config.IsVerkle)
+		// The binary tree shares the isolated namespace with the verkle tree,
+		// only the node format is different.
+		pconfig := config.PathDB
+		if config.IsBinary {
+			cpy := *config.PathDB
+			cpy.IsBinary = true
+			pconfig = &cpy
+		}
+		db.backend = pathdb.New(diskdb, pconfig, config.IsVerkle || config.IsBinary)
 	} else {
 		db.backend = hashdb.New(diskdb, config.HashDB)
 	}
@@ -138,6 +158,17 @@ func (db *Database) HistoricReader(root common.Hash) (*pathdb.HistoricalStateRea
 	return pdb.HistoricReader(root)
 }
 
+// StateChange returns the state mutations made by the transition into the given
+// state as recorded in the state history. It's only supported by the path-based
+// scheme.
+func (db *Database) StateChange(root common.Hash) (*pathdb.StateChange, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, errors.New("not supported")
+	}
+	return pdb.StateChange(root)
+}
+
 // Update performs a state transition by committing dirty nodes contained in the
 // given set in order to update state from the specified parent to the specified
 // root. The held pre-images accumulated up to this point will be flushed in case
@@ -371,6 +402,13 @@ func (db *Database) IsVerkle() bool {
 	return db.config.IsVerkle
 }
 
+// IsBinary returns the indicator if the database is holding a binary tree.
+// Like the verkle tree, it stores the entire state in a single tree instead
+// of the per-account storage tries.
+func (db *Database) IsBinary() bool {
+	return db.config.IsBinary
+}
+
 // Disk returns the underlying disk database.
 func (db *Database) Disk() ethdb.Database {
 	return db.disk
@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie/bintrie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-verkle"
 )
@@ -122,6 +123,7 @@ type Config struct {
 	WriteBufferSize     int    // Maximum memory allowance (in bytes) for write buffer
 	ReadOnly            bool   // Flag whether the database is opened in read only mode
 	JournalDirectory    string // Absolute path of journal directory (null means the journal data is persisted in key-value store)
+	IsBinary            bool   // Flag whether the database holds a binary tree instead of a verkle tree
 
 	// Testing configurations
 	SnapshotNoBuild   bool // Flag Whether the state generation is allowed
@@ -198,6 +200,18 @@ func verkleNodeHasher(blob []byte) (common.Hash, error) {
 	return n.Commit().Bytes(), nil
 }
 
+// binaryNodeHasher computes the hash of the given binary tree node.
+func binaryNodeHasher(blob []byte) (common.Hash, error) {
+	if len(blob) == 0 {
+		return common.Hash{}, nil
+	}
+	n, err := bintrie.DeserializeNode(blob, 0)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return n.Hash(), nil
+}
+
 // Database is a multiple-layered structure for maintaining in-memory states
 // along with its dirty trie nodes. It consists of one persistent base layer
 // backed by a key-value store, on top of which arbitrarily many in-memory diff
@@ -251,6 +265,9 @@ func New(diskdb ethdb.Database, config *Config, isVerkle bool) *Database {
 	if isVerkle {
 		db.diskdb = rawdb.NewTable(diskdb, string(rawdb.VerklePrefix))
 		db.hasher = verkleNodeHasher
+		if config.IsBinary {
+			db.hasher = binaryNodeHasher
+		}
 	}
 	// Construct the layer tree by resolving the in-disk singleton state
 	// and in-memory layer journal.
@@ -281,7 +298,11 @@ func New(diskdb ethdb.Database, config *Config, isVerkle bool) *Database {
 	}
 	fields := config.fields()
 	if db.isVerkle {
-		fields = append(fields, "verkle", true)
+		if config.IsBinary {
+			fields = append(fields, "binary", true)
+		} else {
+			fields = append(fields, "verkle", true)
+		}
 	}
 	log.Info("Initialized path database", fields...)
 	return db
@@ -726,6 +747,48 @@ func (db *Database) HistoryRange() (uint64, uint64, error) {
 	return historyRange(db.freezer)
 }
 
+// StateChange is the set of states mutated by a state transition along with
+// their values before the transition, as recorded in the state history. Values
+// are encoded in the slim format, with empty values denoting the absence of
+// the account or storage slot.
+type StateChange struct {
+	Block    uint64
+	Root     common.Hash
+	Parent   common.Hash
+	Accounts map[common.Address][]byte
+	Storages map[common.Address]map[common.Hash][]byte // keyed by the raw slot key
+}
+
+// StateChange returns the state mutations made by the transition into the given
+// state. Only state histories which have already been persisted are available,
+// and only those recording the raw storage slot keys are supported.
+func (db *Database) StateChange(root common.Hash) (*StateChange, error) {
+	if db.freezer == nil {
+		return nil, errors.New("state histories are not available")
+	}
+	id := rawdb.ReadStateID(db.diskdb, root)
+	if id == nil || *id == 0 {
+		return nil, fmt.Errorf("state history of %#x is not available", root)
+	}
+	h, err := readHistory(db.freezer, *id)
+	if err != nil {
+		return nil, err
+	}
+	if h.meta.root != root {
+		return nil, fmt.Errorf("state history %d is corrupted, root: %#x, want: %#x", *id, h.meta.root, root)
+	}
+	if h.meta.version == stateHistoryV0 {
+		return nil, fmt.Errorf("state history %d lacks raw storage keys", *id)
+	}
+	return &StateChange{
+		Block:    h.meta.block,
+		Root:     h.meta.root,
+		Parent:   h.meta.parent,
+		Accounts: h.accounts,
+		Storages: h.storages,
+	}, nil
+}
+
 // IndexProgress returns the indexing progress made so far. It provides the
 // number of states that remain unindexed.
 func (db *Database) IndexProgress() (uint64, error) {
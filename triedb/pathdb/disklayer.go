@@ -168,11 +168,18 @@ func (dl *diskLayer) node(owner common.Hash, path []byte, depth int) ([]byte, co
 //
 // Note the returned account is not a copy, please don't modify it.
 func (dl *diskLayer) account(hash common.Hash, depth int) ([]byte, error) {
+	blob, _, err := dl.locateAccount(hash, depth)
+	return blob, err
+}
+
+// locateAccount is the internal version of account, additionally returning the
+// location where the account is found.
+func (dl *diskLayer) locateAccount(hash common.Hash, depth int) ([]byte, string, error) {
 	dl.lock.RLock()
 	defer dl.lock.RUnlock()
 
 	if dl.stale {
-		return nil, errSnapshotStale
+		return nil, "", errSnapshotStale
 	}
 	// Try to retrieve the trie node from the not-yet-written node buffer first
 	// (both the live one and the frozen one). Note the buffer is lock free since
@@ -190,7 +197,7 @@ func (dl *diskLayer) account(hash common.Hash, depth int) ([]byte, error) {
 				} else {
 					stateAccountExistMeter.Mark(1)
 				}
-				return blob, nil
+				return blob, locDirtyCache, nil
 			}
 		}
 	}
@@ -200,7 +207,7 @@ func (dl *diskLayer) account(hash common.Hash, depth int) ([]byte, error) {
 	// already been covered by the generator.
 	marker := dl.genMarker()
 	if marker != nil && bytes.Compare(hash.Bytes(), marker) > 0 {
-		return nil, errNotCoveredYet
+		return nil, "", errNotCoveredYet
 	}
 	// Try to retrieve the account from the memory cache
 	if dl.states != nil {
@@ -213,7 +220,7 @@ func (dl *diskLayer) account(hash common.Hash, depth int) ([]byte, error) {
 			} else {
 				stateAccountExistMeter.Mark(1)
 			}
-			return blob, nil
+			return blob, locCleanCache, nil
 		}
 		cleanStateMissMeter.Mark(1)
 	}
@@ -236,7 +243,7 @@ func (dl *diskLayer) account(hash common.Hash, depth int) ([]byte, error) {
 		stateAccountExistMeter.Mark(1)
 		stateAccountExistDiskMeter.Mark(1)
 	}
-	return blob, nil
+	return blob, locDiskLayer, nil
 }
 
 // storage directly retrieves the storage data associated with a particular hash,
@@ -244,13 +251,20 @@ func (dl *diskLayer) account(hash common.Hash, depth int) ([]byte, error) {
 //
 // Note the returned account is not a copy, please don't modify it.
 func (dl *diskLayer) storage(accountHash, storageHash common.Hash, depth int) ([]byte, error) {
+	blob, _, err := dl.locateStorage(accountHash, storageHash, depth)
+	return blob, err
+}
+
+// locateStorage is the internal version of storage, additionally returning the
+// location where the storage slot is found.
+func (dl *diskLayer) locateStorage(accountHash, storageHash common.Hash, depth int) ([]byte, string, error) {
 	// Hold the lock, ensure the parent won't be changed during the
 	// state accessing.
 	dl.lock.RLock()
 	defer dl.lock.RUnlock()
 
 	if dl.stale {
-		return nil, errSnapshotStale
+		return nil, "", errSnapshotStale
 	}
 	// Try to retrieve the trie node from the not-yet-written node buffer first
 	// (both the live one and the frozen one). Note the buffer is lock free since
@@ -267,7 +281,7 @@ func (dl *diskLayer) storage(accountHash, storageHash common.Hash, depth int) ([
 				} else {
 					stateStorageExistMeter.Mark(1)
 				}
-				return blob, nil
+				return blob, locDirtyCache, nil
 			}
 		}
 	}
@@ -278,7 +292,7 @@ func (dl *diskLayer) storage(accountHash, storageHash common.Hash, depth int) ([
 	key := append(accountHash[:], storageHash[:]...)
 	marker := dl.genMarker()
 	if marker != nil && bytes.Compare(key, marker) > 0 {
-		return nil, errNotCoveredYet
+		return nil, "", errNotCoveredYet
 	}
 	// Try to retrieve the storage slot from the memory cache
 	if dl.states != nil {
@@ -291,7 +305,7 @@ func (dl *diskLayer) storage(accountHash, storageHash common.Hash, depth int) ([
 			} else {
 				stateStorageExistMeter.Mark(1)
 			}
-			return blob, nil
+			return blob, locCleanCache, nil
 		}
 		cleanStateMissMeter.Mark(1)
 	}
@@ -314,7 +328,7 @@ func (dl *diskLayer) storage(accountHash, storageHash common.Hash, depth int) ([
 		stateStorageExistMeter.Mark(1)
 		stateStorageExistDiskMeter.Mark(1)
 	}
-	return blob, nil
+	return blob, locDiskLayer, nil
 }
 
 // update implements the layer interface, returning a new diff layer on top
@@ -141,6 +141,43 @@ func (r *reader) Account(hash common.Hash) (*types.SlimAccount, error) {
 	return account, nil
 }
 
+// AccountWithLocation is similar to Account, but additionally reports where
+// the account is found, being one of "diff", "dirty", "clean" or "disk". It's
+// meant to be used for profiling the state access.
+func (r *reader) AccountWithLocation(hash common.Hash) (*types.SlimAccount, string, error) {
+	l, err := r.db.tree.lookupAccount(hash, r.state)
+	if err != nil {
+		return nil, "", err
+	}
+	var (
+		blob []byte
+		loc  = locDiffLayer
+	)
+	if dl, ok := l.(*diskLayer); ok {
+		blob, loc, err = dl.locateAccount(hash, 0)
+	} else {
+		blob, err = l.account(hash, 0)
+	}
+	// Fall back to the slow path if the located layer is stale, see AccountRLP
+	// for more details. The location is reported as disk as the exact one is
+	// unknown in this rare case.
+	if errors.Is(err, errSnapshotStale) {
+		blob, err = r.layer.account(hash, 0)
+		loc = locDiskLayer
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if len(blob) == 0 {
+		return nil, loc, nil
+	}
+	account := new(types.SlimAccount)
+	if err := rlp.DecodeBytes(blob, account); err != nil {
+		panic(err)
+	}
+	return account, loc, nil
+}
+
 // Storage directly retrieves the storage data associated with a particular hash,
 // within a particular account. An error will be returned if the read operation
 // exits abnormally. Specifically, if the layer is already stale.
@@ -168,6 +205,35 @@ func (r *reader) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
 	return blob, err
 }
 
+// StorageWithLocation is similar to Storage, but additionally reports where
+// the storage slot is found, being one of "diff", "dirty", "clean" or "disk".
+// It's meant to be used for profiling the state access.
+func (r *reader) StorageWithLocation(accountHash, storageHash common.Hash) ([]byte, string, error) {
+	l, err := r.db.tree.lookupStorage(accountHash, storageHash, r.state)
+	if err != nil {
+		return nil, "", err
+	}
+	var (
+		blob []byte
+		loc  = locDiffLayer
+	)
+	if dl, ok := l.(*diskLayer); ok {
+		blob, loc, err = dl.locateStorage(accountHash, storageHash, 0)
+	} else {
+		blob, err = l.storage(accountHash, storageHash, 0)
+	}
+	// Fall back to the slow path if the located layer is stale, see Storage
+	// for more details.
+	if errors.Is(err, errSnapshotStale) {
+		blob, err = r.layer.storage(accountHash, storageHash, 0)
+		loc = locDiskLayer
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return blob, loc, nil
+}
+
 // NodeReader retrieves a layer belonging to the given state root.
 func (db *Database) NodeReader(root common.Hash) (database.NodeReader, error) {
 	layer := db.tree.get(root)